@@ -5,6 +5,7 @@
 package manager
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
@@ -37,10 +38,13 @@ import (
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/apmserver"
 	asesassn "github.com/elastic/cloud-on-k8s/pkg/controller/apmserverelasticsearchassociation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/associationclone"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/associationtemplate"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
 	controllerscheme "github.com/elastic/cloud-on-k8s/pkg/controller/common/scheme"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana"
 	kbassn "github.com/elastic/cloud-on-k8s/pkg/controller/kibanaassociation"
@@ -52,6 +56,7 @@ import (
 	licensing "github.com/elastic/cloud-on-k8s/pkg/license"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
@@ -77,6 +82,111 @@ var (
 )
 
 func init() {
+	Cmd.Flags().String(
+		operator.AssociationAuditLogFlag,
+		"",
+		"Path of a file to which audit records of Kibana association reconciliations are appended as JSON lines (disabled if empty)",
+	)
+	Cmd.Flags().String(
+		operator.AssociationCredentialsNamespaceFlag,
+		"",
+		"Namespace in which the credentials of Kibana associations are created (defaults to the namespace of each Kibana resource)",
+	)
+	Cmd.Flags().Bool(
+		operator.AssociationDryRunFlag,
+		false,
+		"Report the changes to the Elasticsearch configuration of Kibana associations in their status instead of applying them",
+	)
+	Cmd.Flags().StringSlice(
+		operator.AssociationEncryptedNamespacesFlag,
+		nil,
+		fmt.Sprintf("Comma-separated list of namespaces attested to encrypt secrets at rest, or %q for all namespaces. "+
+			"If set, Kibana associations refuse to write secrets to other namespaces", association.AllNamespacesEncrypted),
+	)
+	Cmd.Flags().Duration(
+		operator.AssociationFailureGracePeriodFlag,
+		0,
+		"Duration an association failure must persist for before the association is reported as Failed rather than Pending (0 to disable)",
+	)
+	Cmd.Flags().String(
+		operator.AssociationGlobalCAFlag,
+		"",
+		"Name of a secret in the operator namespace holding CA certificates (in ca.crt) to trust in all associations, in addition to the Elasticsearch CA. "+
+			"The operator namespace must be managed by the operator",
+	)
+	Cmd.Flags().String(
+		operator.AssociationInventoryURLFlag,
+		"",
+		"URL of an inventory endpoint to which the state of Kibana associations is POSTed on status changes (disabled if empty)",
+	)
+	Cmd.Flags().String(
+		operator.AssociationInventorySigningSecretFlag,
+		"",
+		fmt.Sprintf("Name of a secret in the operator namespace holding a key (in %s) to sign association inventory requests with HMAC-SHA256 (unsigned if empty)",
+			association.InventorySigningKey),
+	)
+	Cmd.Flags().Duration(
+		operator.AssociationLivenessThresholdFlag,
+		0,
+		"Duration a reconciliation of a Kibana association can run for before the operator is reported as not live (0 to disable)",
+	)
+	Cmd.Flags().Int(
+		operator.AssociationMaxConcurrentReconcilesFlag,
+		1,
+		"Maximum number of Kibana associations reconciled concurrently",
+	)
+	Cmd.Flags().String(
+		operator.AssociationMinESHealthFlag,
+		"",
+		fmt.Sprintf("Minimum Elasticsearch health for Kibana associations to be established: %s or %s (not verified if empty)",
+			esv1.ElasticsearchYellowHealth, esv1.ElasticsearchGreenHealth),
+	)
+	Cmd.Flags().String(
+		operator.AssociationMinESVersionFlag,
+		"",
+		"Minimum Elasticsearch version Kibana can be associated with (no minimum if empty)",
+	)
+	Cmd.Flags().String(
+		operator.AssociationOwnerRefModeFlag,
+		string(association.DefaultOwnerRefMode),
+		fmt.Sprintf("Defines how resources derived from associations are cleaned up: garbage collected through an owner reference on the associated resource (%s), "+
+			"or exclusively deleted by the operator (%s)", association.OwnerRefModeAssociated, association.OwnerRefModeCleanup),
+	)
+	Cmd.Flags().Int(
+		operator.AssociationProbeRetriesFlag,
+		association.DefaultProbeRetries,
+		"Number of times a failed Elasticsearch probe of a Kibana association is retried",
+	)
+	Cmd.Flags().Duration(
+		operator.AssociationProbeTimeoutFlag,
+		association.DefaultProbeTimeout,
+		"Timeout of a single Elasticsearch probe attempt of a Kibana association",
+	)
+	Cmd.Flags().Duration(
+		operator.AssociationReconcileTimeoutFlag,
+		kbassn.DefaultReconcileTimeout,
+		"Duration after which a reconciliation of a Kibana association is aborted and requeued",
+	)
+	Cmd.Flags().Duration(
+		operator.AssociationRequeueIntervalFlag,
+		kbassn.DefaultRequeueInterval,
+		"Interval after which pending Kibana associations are reconciled again",
+	)
+	Cmd.Flags().Duration(
+		operator.AssociationStartupJitterFlag,
+		0,
+		"Duration over which the initial reconciliations of Kibana associations are spread when the operator starts (0 to disable)",
+	)
+	Cmd.Flags().Duration(
+		operator.AssociationStatusUpdateIntervalFlag,
+		0,
+		"Minimum interval between two status updates of the same Kibana association (0 to disable)",
+	)
+	Cmd.Flags().Bool(
+		operator.AssociationTolerateStatusUpdateFailuresFlag,
+		false,
+		"Log and requeue Kibana association status update failures instead of reporting the reconciliation as failed",
+	)
 	Cmd.Flags().Bool(
 		operator.AutoPortForwardFlag,
 		false,
@@ -122,6 +232,11 @@ func init() {
 		operator.EnableTracingFlag,
 		false,
 		"Enable APM tracing in the operator. Endpoint, token etc are to be configured via environment variables. See https://www.elastic.co/guide/en/apm/agent/go/1.x/configuration.html")
+	Cmd.Flags().Int(
+		operator.HealthProbePortFlag,
+		0,
+		"Port to use for exposing the liveness endpoint on /healthz (set 0 to disable)",
+	)
 	Cmd.Flags().Bool(
 		operator.ManageWebhookCertsFlag,
 		true,
@@ -257,6 +372,12 @@ func execute() {
 	}
 	opts.MetricsBindAddress = fmt.Sprintf(":%d", metricsPort) // 0 to disable
 
+	// only expose the liveness endpoint if provided a non-zero port
+	if healthProbePort := viper.GetInt(operator.HealthProbePortFlag); healthProbePort != 0 {
+		log.Info("Exposing the liveness endpoint on /healthz", "port", healthProbePort)
+		opts.HealthProbeBindAddress = fmt.Sprintf(":%d", healthProbePort)
+	}
+
 	opts.Port = WebhookPort
 	mgr, err := ctrl.NewManager(cfg, opts)
 	if err != nil {
@@ -288,6 +409,27 @@ func execute() {
 		log.Error(err, "unable to get operator info")
 		os.Exit(1)
 	}
+	ownerRefMode, err := association.ParseOwnerRefMode(viper.GetString(operator.AssociationOwnerRefModeFlag))
+	if err != nil {
+		log.Error(err, "invalid association owner reference mode")
+		os.Exit(1)
+	}
+
+	var minESVersion *version.Version
+	if minVersion := viper.GetString(operator.AssociationMinESVersionFlag); minVersion != "" {
+		minESVersion, err = version.Parse(minVersion)
+		if err != nil {
+			log.Error(err, "invalid association minimum Elasticsearch version")
+			os.Exit(1)
+		}
+	}
+
+	minESHealth, err := association.ParseMinESHealth(viper.GetString(operator.AssociationMinESHealthFlag))
+	if err != nil {
+		log.Error(err, "invalid association minimum Elasticsearch health")
+		os.Exit(1)
+	}
+
 	log.Info("Setting up controllers", "roles", roles)
 	var tracer *apm.Tracer
 	if viper.GetBool(operator.EnableTracingFlag) {
@@ -305,7 +447,48 @@ func execute() {
 			Validity:     certValidity,
 			RotateBefore: certRotateBefore,
 		},
-		Tracer: tracer,
+		Tracer:                                  tracer,
+		AssociationOwnerRefMode:                 string(ownerRefMode),
+		AssociationFailureGracePeriod:           viper.GetDuration(operator.AssociationFailureGracePeriodFlag),
+		AssociationInventoryURL:                 viper.GetString(operator.AssociationInventoryURLFlag),
+		AssociationEncryptedNamespaces:          viper.GetStringSlice(operator.AssociationEncryptedNamespacesFlag),
+		AssociationMinESHealth:                  minESHealth,
+		AssociationMinESVersion:                 minESVersion,
+		AssociationAuditLogPath:                 viper.GetString(operator.AssociationAuditLogFlag),
+		AssociationStartupJitter:                viper.GetDuration(operator.AssociationStartupJitterFlag),
+		AssociationProbeTimeout:                 viper.GetDuration(operator.AssociationProbeTimeoutFlag),
+		AssociationProbeRetries:                 viper.GetInt(operator.AssociationProbeRetriesFlag),
+		AssociationTolerateStatusUpdateFailures: viper.GetBool(operator.AssociationTolerateStatusUpdateFailuresFlag),
+		AssociationRequeueInterval:              viper.GetDuration(operator.AssociationRequeueIntervalFlag),
+		AssociationLivenessThreshold:            viper.GetDuration(operator.AssociationLivenessThresholdFlag),
+		AssociationReconcileTimeout:             viper.GetDuration(operator.AssociationReconcileTimeoutFlag),
+		AssociationDryRun:                       viper.GetBool(operator.AssociationDryRunFlag),
+		AssociationStatusUpdateInterval:         viper.GetDuration(operator.AssociationStatusUpdateIntervalFlag),
+		AssociationMaxConcurrentReconciles:      viper.GetInt(operator.AssociationMaxConcurrentReconcilesFlag),
+	}
+	if globalCA := viper.GetString(operator.AssociationGlobalCAFlag); globalCA != "" {
+		// the global CA secret is watched to update the associations, which requires its namespace to be cached
+		if !isManagedNamespace(managedNamespaces, operatorNamespace) {
+			log.Error(fmt.Errorf("%s requires the operator namespace to be managed", operator.AssociationGlobalCAFlag),
+				"invalid association global CA secret", "namespace", operatorNamespace, "managed_namespaces", managedNamespaces)
+			os.Exit(1)
+		}
+		params.AssociationGlobalCA = types.NamespacedName{Namespace: operatorNamespace, Name: globalCA}
+	}
+	if signingSecret := viper.GetString(operator.AssociationInventorySigningSecretFlag); signingSecret != "" {
+		params.AssociationInventorySigningSecret = types.NamespacedName{Namespace: operatorNamespace, Name: signingSecret}
+	}
+	if credentialsNamespace := viper.GetString(operator.AssociationCredentialsNamespaceFlag); credentialsNamespace != "" {
+		allowed, err := rbac.CanManageSecrets(clientset, credentialsNamespace)
+		if err != nil {
+			log.Error(err, "unable to check access to the association credentials namespace", "namespace", credentialsNamespace)
+			os.Exit(1)
+		}
+		if !allowed {
+			log.Error(errors.New("operator is not allowed to manage secrets"), "invalid association credentials namespace", "namespace", credentialsNamespace)
+			os.Exit(1)
+		}
+		params.AssociationCredentialsNamespace = credentialsNamespace
 	}
 
 	if operator.HasRole(operator.WebhookServer, roles) {
@@ -326,7 +509,7 @@ func execute() {
 			log.Error(err, "unable to create controller", "controller", "ApmServer")
 			os.Exit(1)
 		}
-		if err = elasticsearch.Add(mgr, params); err != nil {
+		if err = elasticsearch.Add(mgr, accessReviewer, params); err != nil {
 			log.Error(err, "unable to create controller", "controller", "Elasticsearch")
 			os.Exit(1)
 		}
@@ -342,6 +525,14 @@ func execute() {
 			log.Error(err, "unable to create controller", "controller", "KibanaAssociation")
 			os.Exit(1)
 		}
+		if err = associationtemplate.Add(mgr, params); err != nil {
+			log.Error(err, "unable to create controller", "controller", "AssociationTemplate")
+			os.Exit(1)
+		}
+		if err = associationclone.Add(mgr, params); err != nil {
+			log.Error(err, "unable to create controller", "controller", "AssociationClone")
+			os.Exit(1)
+		}
 
 		// Garbage collect any orphaned user Secrets leftover from deleted resources while the operator was not running.
 		garbageCollectUsers(cfg, managedNamespaces)
@@ -383,6 +574,20 @@ func ValidateCertExpirationFlags(validityFlag string, rotateBeforeFlag string) (
 	return certValidity, certRotateBefore
 }
 
+// isManagedNamespace returns true if the given namespace is managed, and therefore cached, by the operator.
+func isManagedNamespace(managedNamespaces []string, namespace string) bool {
+	if len(managedNamespaces) == 0 {
+		// all namespaces are managed
+		return true
+	}
+	for _, managed := range managedNamespaces {
+		if managed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 func garbageCollectUsers(cfg *rest.Config, managedNamespaces []string) {
 	ugc, err := association.NewUsersGarbageCollector(cfg, managedNamespaces)
 	if err != nil {
@@ -432,6 +637,10 @@ func setupWebhook(mgr manager.Manager, certRotation certificates.RotationParams,
 		log.Error(err, "unable to create webhook", "version", "v1beta1", "webhook", "Elasticsearch")
 		os.Exit(1)
 	}
+	if err := (&kbv1.Kibana{}).SetupWebhookWithManager(mgr); err != nil {
+		log.Error(err, "unable to create webhook", "version", "v1", "webhook", "Kibana")
+		os.Exit(1)
+	}
 
 	// wait for the secret to be populated in the local filesystem before returning
 	interval := time.Second * 1
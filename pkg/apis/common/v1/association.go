@@ -5,8 +5,11 @@
 package v1
 
 import (
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // AssociationStatus is the status of an association resource.
@@ -17,8 +20,116 @@ const (
 	AssociationPending     AssociationStatus = "Pending"
 	AssociationEstablished AssociationStatus = "Established"
 	AssociationFailed      AssociationStatus = "Failed"
+	// AssociationDegraded means the association could not be reconciled because of an error which is expected to be
+	// transient, such as an API server error. The reconciliation is retried.
+	AssociationDegraded AssociationStatus = "Degraded"
+)
+
+// AssociationAuthMode is the mode used by an associated resource to authenticate against Elasticsearch.
+type AssociationAuthMode string
+
+const (
+	// AssociationAuthSecretRef means the associated resource authenticates with the credentials of a dedicated
+	// Elasticsearch user, read from a secret managed by the association controller.
+	AssociationAuthSecretRef AssociationAuthMode = "secret-ref"
+)
+
+// AssociationHealth summarizes the health of an association, combining the health of the Elasticsearch cluster,
+// the availability of the associated resource and the validity of the association credentials.
+type AssociationHealth string
+
+const (
+	// AssociationHealthGreen means all the signals are healthy.
+	AssociationHealthGreen AssociationHealth = "green"
+	// AssociationHealthYellow means the association is usable but one of the signals is degraded.
+	AssociationHealthYellow AssociationHealth = "yellow"
+	// AssociationHealthRed means the association is not usable.
+	AssociationHealthRed AssociationHealth = "red"
+)
+
+// AssociationConfOwner designates who manages the Elasticsearch configuration of an associated resource.
+type AssociationConfOwner string
+
+const (
+	// AssociationConfOwnerOperator means the association controller manages the Elasticsearch configuration.
+	AssociationConfOwnerOperator AssociationConfOwner = "operator"
+	// AssociationConfOwnerManual means the Elasticsearch configuration is managed manually: the association controller
+	// leaves it untouched.
+	AssociationConfOwnerManual AssociationConfOwner = "manual"
+)
+
+// AssociationCARotationPhase is the phase of an orchestrated rotation of the Elasticsearch CA trusted by an associated
+// resource.
+type AssociationCARotationPhase string
+
+const (
+	// AssociationCARotationStaged means the new Elasticsearch CA is trusted along with the previous one, until the
+	// associated resource uses both.
+	AssociationCARotationStaged AssociationCARotationPhase = "Staged"
+)
+
+// AssociationDeferredChange describes the changes to the Elasticsearch configuration of an associated resource that
+// are deferred, to coalesce them into a single restart of the associated resource.
+type AssociationDeferredChange struct {
+	// Since is when the first deferred change was observed.
+	Since metav1.Time `json:"since"`
+	// Changes is the number of distinct changes observed since then.
+	Changes int `json:"changes"`
+	// Digest identifies the latest deferred change.
+	Digest string `json:"digest"`
+}
+
+// AssociationConditionReason is the reason of an association condition.
+type AssociationConditionReason string
+
+const (
+	// AssociationElasticsearchNotFound means the referenced Elasticsearch cluster does not exist.
+	AssociationElasticsearchNotFound AssociationConditionReason = "ElasticsearchNotFound"
+	// AssociationUsersSecretMissing means the secret of the Elasticsearch user of the association, or the credentials
+	// secret of the associated resource, does not exist.
+	AssociationUsersSecretMissing AssociationConditionReason = "UsersSecretMissing"
+	// AssociationCACertMissing means the secret holding the CA of the Elasticsearch cluster does not exist.
+	AssociationCACertMissing AssociationConditionReason = "CACertMissing"
+	// AssociationKibanaUpdated means the association configuration was set on Kibana by the association controller.
+	AssociationKibanaUpdated AssociationConditionReason = "KibanaUpdated"
 )
 
+// AssociationCondition details the state of an association.
+type AssociationCondition struct {
+	// Reason of the condition.
+	Reason AssociationConditionReason `json:"reason"`
+	// Message is a human readable message detailing the condition.
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the condition was seen changing.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// AssociationError is the last error encountered while reconciling an association.
+type AssociationError struct {
+	// Message of the error, truncated and with credentials redacted.
+	Message string `json:"message"`
+	// Time the error was first seen, it is not updated while the reconciliations keep failing with the same error.
+	Time metav1.Time `json:"time,omitempty"`
+}
+
+// AssociationDependency is the observed state of an object an association depends on.
+type AssociationDependency struct {
+	// Kind of the object, for instance Elasticsearch or Secret.
+	Kind string `json:"kind"`
+	// Namespace of the object.
+	Namespace string `json:"namespace"`
+	// Name of the object.
+	Name string `json:"name"`
+	// Exists is true if the object exists.
+	Exists bool `json:"exists"`
+	// ResourceVersion is the observed resource version of the object.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// Generation is the observed generation of the object, for objects with a spec such as Elasticsearch.
+	Generation int64 `json:"generation,omitempty"`
+	// LastTransitionTime is the last time the existence or the resource version of the object was seen changing.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
 // Associated interface represents a Elastic stack application that is associated with an Elasticsearch cluster.
 // An associated object needs some credentials to establish a connection to the Elasticsearch cluster and usually it
 // offers a keystore which in ECK is represented with an underlying Secret.
@@ -40,13 +151,56 @@ type Associator interface {
 	SetAssociationConf(*AssociationConf)
 }
 
+// ExternalElasticsearch references an Elasticsearch cluster which is not managed by the operator.
+type ExternalElasticsearch struct {
+	// URL of the Elasticsearch cluster, including its scheme and port.
+	URL string `json:"url"`
+	// AuthSecretName is the name of the secret holding the credentials of the Elasticsearch user, in the namespace of
+	// the associated resource.
+	AuthSecretName string `json:"authSecretName"`
+	// AuthSecretKey is the name of the Elasticsearch user, which is also the key of its password in the secret.
+	AuthSecretKey string `json:"authSecretKey"`
+	// CASecretName is the name of the secret holding the CA certificate of the Elasticsearch cluster in its ca.crt
+	// key, in the namespace of the associated resource. Not needed if the certificate of the cluster is trusted by
+	// default.
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+}
+
 // AssociationConf holds the association configuration of an Elasticsearch cluster.
 type AssociationConf struct {
 	AuthSecretName string `json:"authSecretName"`
 	AuthSecretKey  string `json:"authSecretKey"`
-	CACertProvided bool   `json:"caCertProvided"`
-	CASecretName   string `json:"caSecretName"`
-	URL            string `json:"url"`
+	// AuthSecretNamespace is the namespace of the auth secret, if it does not live in the namespace of the associated resource.
+	AuthSecretNamespace string `json:"authSecretNamespace,omitempty"`
+	CACertProvided      bool   `json:"caCertProvided"`
+	CASecretName        string `json:"caSecretName"`
+	URL                 string `json:"url"`
+	// SSLVerificationMode is how the certificate presented by Elasticsearch is verified, the default of the associated
+	// resource applies if empty.
+	SSLVerificationMode string `json:"sslVerificationMode,omitempty"`
+}
+
+const (
+	// SSLVerificationModeFull verifies the certificate presented by Elasticsearch and its host name.
+	SSLVerificationModeFull = "full"
+	// SSLVerificationModeCertificate verifies the certificate presented by Elasticsearch but not its host name.
+	SSLVerificationModeCertificate = "certificate"
+	// SSLVerificationModeNone does not verify the certificate presented by Elasticsearch.
+	SSLVerificationModeNone = "none"
+)
+
+// Equivalent returns true if both association configurations lead to the same connection to Elasticsearch for a
+// resource in the given namespace. Contrary to a strict equality check, a nil configuration is equivalent to an empty
+// one, an empty auth secret namespace is equivalent to the namespace of the associated resource, and trailing slashes
+// in URLs are ignored.
+func (ac *AssociationConf) Equivalent(other *AssociationConf, associatedNamespace string) bool {
+	return ac.AuthSecretRef(associatedNamespace) == other.AuthSecretRef(associatedNamespace) &&
+		ac.GetAuthSecretKey() == other.GetAuthSecretKey() &&
+		ac.GetCACertProvided() == other.GetCACertProvided() &&
+		ac.GetCASecretName() == other.GetCASecretName() &&
+		strings.TrimRight(ac.GetURL(), "/") == strings.TrimRight(other.GetURL(), "/") &&
+		ac.GetSSLVerificationMode() == other.GetSSLVerificationMode()
 }
 
 // IsConfigured returns true if all the fields are set.
@@ -85,6 +239,16 @@ func (ac *AssociationConf) GetAuthSecretName() string {
 	return ac.AuthSecretName
 }
 
+// AuthSecretRef returns the namespaced name of the auth secret, which lives in the namespace of the associated resource
+// unless configured otherwise.
+func (ac *AssociationConf) AuthSecretRef(associatedNamespace string) types.NamespacedName {
+	namespace := associatedNamespace
+	if ac != nil && ac.AuthSecretNamespace != "" {
+		namespace = ac.AuthSecretNamespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: ac.GetAuthSecretName()}
+}
+
 func (ac *AssociationConf) GetAuthSecretKey() string {
 	if ac == nil {
 		return ""
@@ -112,3 +276,10 @@ func (ac *AssociationConf) GetURL() string {
 	}
 	return ac.URL
 }
+
+func (ac *AssociationConf) GetSSLVerificationMode() string {
+	if ac == nil {
+		return ""
+	}
+	return ac.SSLVerificationMode
+}
@@ -75,3 +75,108 @@ func TestAssociationConfIsConfigured(t *testing.T) {
 		})
 	}
 }
+
+func TestAssociationConf_Equivalent(t *testing.T) {
+	conf := &AssociationConf{
+		AuthSecretName: "auth-secret",
+		AuthSecretKey:  "elastic",
+		CACertProvided: true,
+		CASecretName:   "ca-secret",
+		URL:            "https://my-es.svc:9200",
+	}
+	tests := []struct {
+		name string
+		a, b *AssociationConf
+		want bool
+	}{
+		{
+			name: "both nil",
+			want: true,
+		},
+		{
+			name: "nil and empty",
+			a:    &AssociationConf{},
+			want: true,
+		},
+		{
+			name: "nil and configured",
+			a:    conf,
+			want: false,
+		},
+		{
+			name: "identical",
+			a:    conf,
+			b: func() *AssociationConf {
+				c := *conf
+				return &c
+			}(),
+			want: true,
+		},
+		{
+			name: "auth secret in the namespace of the associated resource",
+			a:    conf,
+			b: func() *AssociationConf {
+				c := *conf
+				c.AuthSecretNamespace = "ns"
+				return &c
+			}(),
+			want: true,
+		},
+		{
+			name: "auth secret in another namespace",
+			a:    conf,
+			b: func() *AssociationConf {
+				c := *conf
+				c.AuthSecretNamespace = "credentials"
+				return &c
+			}(),
+			want: false,
+		},
+		{
+			name: "URL trailing slash",
+			a:    conf,
+			b: func() *AssociationConf {
+				c := *conf
+				c.URL = "https://my-es.svc:9200/"
+				return &c
+			}(),
+			want: true,
+		},
+		{
+			name: "different URL",
+			a:    conf,
+			b: func() *AssociationConf {
+				c := *conf
+				c.URL = "https://other-es.svc:9200"
+				return &c
+			}(),
+			want: false,
+		},
+		{
+			name: "different CA",
+			a:    conf,
+			b: func() *AssociationConf {
+				c := *conf
+				c.CACertProvided = false
+				return &c
+			}(),
+			want: false,
+		},
+		{
+			name: "different SSL verification mode",
+			a:    conf,
+			b: func() *AssociationConf {
+				c := *conf
+				c.SSLVerificationMode = SSLVerificationModeFull
+				return &c
+			}(),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.a.Equivalent(tt.b, "ns"))
+			require.Equal(t, tt.want, tt.b.Equivalent(tt.a, "ns"))
+		})
+	}
+}
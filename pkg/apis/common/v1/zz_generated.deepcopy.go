@@ -10,6 +10,22 @@ package v1
 
 import ()
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssociationCondition) DeepCopyInto(out *AssociationCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssociationCondition.
+func (in *AssociationCondition) DeepCopy() *AssociationCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(AssociationCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AssociationConf) DeepCopyInto(out *AssociationConf) {
 	*out = *in
@@ -25,6 +41,54 @@ func (in *AssociationConf) DeepCopy() *AssociationConf {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssociationDeferredChange) DeepCopyInto(out *AssociationDeferredChange) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssociationDeferredChange.
+func (in *AssociationDeferredChange) DeepCopy() *AssociationDeferredChange {
+	if in == nil {
+		return nil
+	}
+	out := new(AssociationDeferredChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssociationDependency) DeepCopyInto(out *AssociationDependency) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssociationDependency.
+func (in *AssociationDependency) DeepCopy() *AssociationDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(AssociationDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssociationError) DeepCopyInto(out *AssociationError) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssociationError.
+func (in *AssociationError) DeepCopy() *AssociationError {
+	if in == nil {
+		return nil
+	}
+	out := new(AssociationError)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Config.
 func (in *Config) DeepCopy() *Config {
 	if in == nil {
@@ -35,6 +99,21 @@ func (in *Config) DeepCopy() *Config {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalElasticsearch) DeepCopyInto(out *ExternalElasticsearch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalElasticsearch.
+func (in *ExternalElasticsearch) DeepCopy() *ExternalElasticsearch {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalElasticsearch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPConfig) DeepCopyInto(out *HTTPConfig) {
 	*out = *in
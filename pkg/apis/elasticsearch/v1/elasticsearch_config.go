@@ -30,6 +30,24 @@ type Node struct {
 	ML     bool `config:"ml"`
 }
 
+// Roles returns the names of the roles of the node. Coordinating-only nodes have no role.
+func (n Node) Roles() []string {
+	var roles []string
+	if n.Master {
+		roles = append(roles, "master")
+	}
+	if n.Data {
+		roles = append(roles, "data")
+	}
+	if n.Ingest {
+		roles = append(roles, "ingest")
+	}
+	if n.ML {
+		roles = append(roles, "ml")
+	}
+	return roles
+}
+
 // ElasticsearchSettings is a typed subset of elasticsearch.yml for purposes of the operator.
 type ElasticsearchSettings struct {
 	Node    Node            `config:"node"`
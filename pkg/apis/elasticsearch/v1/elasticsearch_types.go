@@ -5,6 +5,8 @@
 package v1
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -46,6 +48,27 @@ type ElasticsearchSpec struct {
 	// +kubebuilder:validation:Optional
 	SecureSettings []commonv1.SecretSource `json:"secureSettings,omitempty"`
 
+	// Snapshots specifies the snapshot repositories to register in Elasticsearch, and the policies taking snapshots
+	// on a schedule.
+	// +kubebuilder:validation:Optional
+	Snapshots *SnapshotsSpec `json:"snapshots,omitempty"`
+
+	// Plugins lists the plugins to install on all the nodes of the cluster before Elasticsearch starts. Each entry is
+	// a plugin name, URL or Maven coordinates, as accepted by the elasticsearch-plugin install command.
+	// +kubebuilder:validation:Optional
+	Plugins []string `json:"plugins,omitempty"`
+
+	// ZoneAwareness spreads the nodes of the cluster across the zones of the Kubernetes nodes, and enables zone-aware
+	// shard allocation so that copies of the same shard are allocated to nodes in different zones.
+	// +kubebuilder:validation:Optional
+	ZoneAwareness *ZoneAwareness `json:"zoneAwareness,omitempty"`
+
+	// RemoteClusters are the clusters this cluster connects to for cross-cluster search and replication. The
+	// operator configures the corresponding cluster.remote.* settings, and the trust between the transport layers of
+	// both clusters.
+	// +kubebuilder:validation:Optional
+	RemoteClusters []RemoteCluster `json:"remoteClusters,omitempty"`
+
 	// ServiceAccountName is used to check access from the current resource to a resource (eg. a remote Elasticsearch cluster) in a different namespace.
 	// Can only be used if ECK is enforcing RBAC on references.
 	// +optional
@@ -61,6 +84,12 @@ func (es ElasticsearchSpec) NodeCount() int32 {
 	return count
 }
 
+// ScaledToZero returns true if the count of all the NodeSets is zero: the cluster is stopped, its data being retained
+// in the volumes of the nodes until it is scaled up again.
+func (es ElasticsearchSpec) ScaledToZero() bool {
+	return len(es.NodeSets) > 0 && es.NodeCount() == 0
+}
+
 // NodeSet is the specification for a group of Elasticsearch nodes sharing the same configuration and a Pod template.
 type NodeSet struct {
 	// Name of this set of nodes. Becomes a part of the Elasticsearch node.name setting.
@@ -71,8 +100,8 @@ type NodeSet struct {
 	// Config holds the Elasticsearch configuration.
 	Config *commonv1.Config `json:"config,omitempty"`
 
-	// Count of Elasticsearch nodes to deploy.
-	// +kubebuilder:validation:Minimum=1
+	// Count of Elasticsearch nodes to deploy. If the count of all the NodeSets is zero, the cluster is stopped and its data retained until it is scaled up again.
+	// +kubebuilder:validation:Minimum=0
 	Count int32 `json:"count"`
 
 	// PodTemplate provides customisation options (labels, annotations, affinity rules, resource requests, and so on) for the Pods belonging to this NodeSet.
@@ -155,6 +184,117 @@ func (cb ChangeBudget) GetMaxUnavailableOrDefault() *int32 {
 	return maxUnavailable
 }
 
+// DefaultZoneTopologyKey is the label holding the zone of Kubernetes nodes.
+const DefaultZoneTopologyKey = "failure-domain.beta.kubernetes.io/zone"
+
+// ZoneAwareness specifies how to discover the zone of the Elasticsearch nodes.
+type ZoneAwareness struct {
+	// TopologyKey is the label of the Kubernetes nodes holding their zone, exposed to Elasticsearch as the zone
+	// node attribute. Defaults to failure-domain.beta.kubernetes.io/zone.
+	// +kubebuilder:validation:Optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+}
+
+// TopologyKeyOrDefault returns the label of the Kubernetes nodes holding their zone.
+func (z ZoneAwareness) TopologyKeyOrDefault() string {
+	if z.TopologyKey == "" {
+		return DefaultZoneTopologyKey
+	}
+	return z.TopologyKey
+}
+
+// SnapshotsSpec specifies the snapshot repositories and snapshot lifecycle policies managed by the operator.
+// Repositories and policies removed from the specification are not removed from Elasticsearch.
+type SnapshotsSpec struct {
+	// Repositories to register in Elasticsearch once the cluster is green.
+	// +kubebuilder:validation:Optional
+	Repositories []SnapshotRepository `json:"repositories,omitempty"`
+
+	// Policies are snapshot lifecycle policies taking snapshots on a schedule. Requires Elasticsearch 7.4.0 or later.
+	// +kubebuilder:validation:Optional
+	Policies []SnapshotPolicy `json:"policies,omitempty"`
+}
+
+// SnapshotRepository is a snapshot repository registered through the Elasticsearch snapshot API.
+type SnapshotRepository struct {
+	// Name of the repository.
+	Name string `json:"name"`
+
+	// Type of the repository, such as fs, s3 or gcs. Types other than fs require the corresponding plugin.
+	Type string `json:"type"`
+
+	// Settings of the repository, which depend on its type. Credentials must not be set here: they are read from the
+	// Elasticsearch keystore, populated with SecureSettings.
+	Settings *commonv1.Config `json:"settings,omitempty"`
+}
+
+// SnapshotPolicy is a snapshot lifecycle policy taking snapshots on a schedule.
+type SnapshotPolicy struct {
+	// Name of the policy.
+	Name string `json:"name"`
+
+	// Schedule of the snapshots, as a cron expression evaluated by Elasticsearch.
+	Schedule string `json:"schedule"`
+
+	// SnapshotName is the name given to the snapshots, which supports date math. Defaults to `<policy-name-{now/d}>`.
+	// +kubebuilder:validation:Optional
+	SnapshotName string `json:"snapshotName,omitempty"`
+
+	// Repository the snapshots are stored in.
+	Repository string `json:"repository"`
+
+	// Config of the snapshots, such as the indices they include.
+	// +kubebuilder:validation:Optional
+	Config *commonv1.Config `json:"config,omitempty"`
+
+	// Retention of the snapshots taken by the policy.
+	// +kubebuilder:validation:Optional
+	Retention *commonv1.Config `json:"retention,omitempty"`
+}
+
+// SnapshotNameOrDefault returns the name given to the snapshots taken by the policy.
+func (p SnapshotPolicy) SnapshotNameOrDefault() string {
+	if p.SnapshotName != "" {
+		return p.SnapshotName
+	}
+	return fmt.Sprintf("<%s-{now/d}>", p.Name)
+}
+
+// RemoteCluster is a cluster this cluster connects to. It is either an Elasticsearch cluster managed by the operator,
+// referenced with ElasticsearchRef, or any other cluster, reachable through Seeds.
+type RemoteCluster struct {
+	// Name of the remote cluster, as used in the cluster.remote.<name>.* settings and in cross-cluster requests.
+	Name string `json:"name"`
+
+	// ElasticsearchRef references an Elasticsearch cluster managed by the operator. The namespace defaults to the
+	// namespace of this cluster. Both clusters are configured to trust the transport CA of each other.
+	// +kubebuilder:validation:Optional
+	ElasticsearchRef *commonv1.ObjectSelector `json:"elasticsearchRef,omitempty"`
+
+	// Seeds are the transport addresses (host:port) of nodes of a remote cluster not managed by the operator.
+	// +kubebuilder:validation:Optional
+	Seeds []string `json:"seeds,omitempty"`
+
+	// CASecretName is the name of a secret in the namespace of this cluster, with the CA certificate of the transport
+	// layer of the remote cluster in its ca.crt entry. Required with Seeds. The remote cluster must trust the
+	// transport CA of this cluster, published in the <cluster-name>-es-transport-certs-public secret.
+	// +kubebuilder:validation:Optional
+	CASecretName string `json:"caSecretName,omitempty"`
+}
+
+// ElasticsearchRefOrDefault returns the reference to the remote Elasticsearch cluster, with its namespace defaulted to
+// the given namespace of the local cluster, or nil if the remote cluster is not managed by the operator.
+func (r RemoteCluster) ElasticsearchRefOrDefault(namespace string) *commonv1.ObjectSelector {
+	if !r.ElasticsearchRef.IsDefined() {
+		return nil
+	}
+	ref := *r.ElasticsearchRef
+	if ref.Namespace == "" {
+		ref.Namespace = namespace
+	}
+	return &ref
+}
+
 // ElasticsearchHealth is the health of the cluster as returned by the health API.
 type ElasticsearchHealth string
 
@@ -199,6 +339,66 @@ type ElasticsearchStatus struct {
 	commonv1.ReconcilerStatus `json:",inline"`
 	Health                    ElasticsearchHealth             `json:"health,omitempty"`
 	Phase                     ElasticsearchOrchestrationPhase `json:"phase,omitempty"`
+	// License is the license currently applied to the Elasticsearch cluster, as last observed.
+	License *ElasticsearchLicenseStatus `json:"license,omitempty"`
+	// SnapshotPolicies is the status of the snapshot lifecycle policies, as last observed.
+	SnapshotPolicies []SnapshotPolicyStatus `json:"snapshotPolicies,omitempty"`
+	// NodeSets is the status of the node sets of the cluster.
+	NodeSets []NodeSetStatus `json:"nodeSets,omitempty"`
+	// ExternalURL is the URL to reach Elasticsearch through the load balancer provisioned for the HTTP service,
+	// if the HTTP service is of type LoadBalancer.
+	ExternalURL string `json:"externalURL,omitempty"`
+	// Plugins are the plugins installed on all the nodes of the cluster, as last observed. Only reported if plugins
+	// are specified.
+	Plugins []string `json:"plugins,omitempty"`
+	// RemoteClusters is the status of the connections to the remote clusters, as last observed.
+	RemoteClusters []RemoteClusterStatus `json:"remoteClusters,omitempty"`
+}
+
+// RemoteClusterStatus is the observed state of the connection to a remote cluster.
+type RemoteClusterStatus struct {
+	// Name of the remote cluster.
+	Name string `json:"name"`
+	// Connected is true if at least one node of the remote cluster is connected.
+	Connected bool `json:"connected"`
+	// NodesConnected is the number of connected nodes of the remote cluster.
+	NodesConnected int `json:"nodesConnected,omitempty"`
+	// Error is set if the remote cluster could not be configured.
+	Error string `json:"error,omitempty"`
+}
+
+// NodeSetStatus is the observed state of a node set.
+type NodeSetStatus struct {
+	// Name of the node set.
+	Name string `json:"name"`
+	// Roles of the nodes of the node set. Empty for coordinating-only nodes.
+	Roles []string `json:"roles,omitempty"`
+	// ExpectedNodes is the number of nodes specified for the node set.
+	ExpectedNodes int32 `json:"expectedNodes"`
+	// ReadyNodes is the number of nodes of the node set that are ready.
+	ReadyNodes int32 `json:"readyNodes"`
+}
+
+// SnapshotPolicyStatus describes the last snapshots taken by a snapshot lifecycle policy.
+type SnapshotPolicyStatus struct {
+	// Name of the policy.
+	Name string `json:"name"`
+	// LastSuccessTime is the time of the last successful snapshot.
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+	// LastFailureTime is the time of the last failed snapshot.
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+	// LastFailureMessage describes the last failure.
+	LastFailureMessage string `json:"lastFailureMessage,omitempty"`
+	// Error is set if the policy could not be applied to Elasticsearch.
+	Error string `json:"error,omitempty"`
+}
+
+// ElasticsearchLicenseStatus describes the license applied to an Elasticsearch cluster.
+type ElasticsearchLicenseStatus struct {
+	// Type of the license, such as basic, trial or platinum.
+	Type string `json:"type,omitempty"`
+	// ExpiryTime is the time as of which the license is no longer valid. Not set for licenses that do not expire.
+	ExpiryTime *metav1.Time `json:"expiryTime,omitempty"`
 }
 
 type ZenDiscoveryStatus struct {
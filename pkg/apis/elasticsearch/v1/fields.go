@@ -7,6 +7,8 @@ package v1
 const (
 	ClusterName = "cluster.name"
 
+	ClusterRoutingAllocationAwarenessAttributes = "cluster.routing.allocation.awareness.attributes"
+
 	DiscoveryZenMinimumMasterNodes = "discovery.zen.minimum_master_nodes"
 	ClusterInitialMasterNodes      = "cluster.initial_master_nodes"
 
@@ -16,7 +18,8 @@ const (
 	NetworkHost        = "network.host"
 	NetworkPublishHost = "network.publish_host"
 
-	NodeName = "node.name"
+	NodeName     = "node.name"
+	NodeAttrZone = "node.attr.zone"
 
 	PathData = "path.data"
 	PathLogs = "path.logs"
@@ -62,3 +65,9 @@ var UnsupportedSettings = []string{
 	XPackSecurityTransportSslKey,
 	XPackSecurityTransportSslVerificationMode,
 }
+
+// ZoneAwarenessSettings are the settings managed by the operator when zone awareness is enabled.
+var ZoneAwarenessSettings = []string{
+	ClusterRoutingAllocationAwarenessAttributes,
+	NodeAttrZone,
+}
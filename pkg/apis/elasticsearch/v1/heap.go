@@ -0,0 +1,68 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// javaOptsEnvVar is the environment variable holding the JVM options of Elasticsearch.
+const javaOptsEnvVar = "ES_JAVA_OPTS"
+
+// maxHeapSizeRe is the pattern of the max Java heap size option (-Xmx<size>[g|G|m|M|k|K] in binary units)
+var maxHeapSizeRe = regexp.MustCompile(`^-Xmx([0-9]+)([gGmMkK]?)$`)
+
+// heapSizeMultipliers converts the Java size suffixes to bytes.
+var heapSizeMultipliers = map[string]int64{
+	"":  1,
+	"K": 1024,
+	"M": 1024 * 1024,
+	"G": 1024 * 1024 * 1024,
+}
+
+// MaxHeapSize returns the maximum heap size set in the given JVM options, if any. The JVM uses the last -Xmx option
+// when it is specified several times.
+func MaxHeapSize(javaOpts string) (resource.Quantity, bool) {
+	var heap resource.Quantity
+	var found bool
+	for _, opt := range strings.Fields(javaOpts) {
+		match := maxHeapSizeRe.FindStringSubmatch(opt)
+		if match == nil {
+			continue
+		}
+		value, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		heap = *resource.NewQuantity(value*heapSizeMultipliers[strings.ToUpper(match[2])], resource.BinarySI)
+		found = true
+	}
+	return heap, found
+}
+
+// heapAndMemoryLimit returns the maximum heap size and the memory limit set by the user in the Elasticsearch container
+// of the given NodeSet, if both are specified.
+func heapAndMemoryLimit(nodeSet NodeSet) (heap resource.Quantity, limit resource.Quantity, ok bool) {
+	container := nodeSet.GetESContainerTemplate()
+	if container == nil {
+		return heap, limit, false
+	}
+	limit, ok = container.Resources.Limits[corev1.ResourceMemory]
+	if !ok || limit.IsZero() {
+		return heap, limit, false
+	}
+	for _, env := range container.Env {
+		if env.Name == javaOptsEnvVar {
+			heap, ok = MaxHeapSize(env.Value)
+			return heap, limit, ok
+		}
+	}
+	return heap, limit, false
+}
@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// heapNodeSet returns a NodeSet fixture with the given JVM options and memory limit.
+func heapNodeSet(javaOpts string, memoryLimit string) NodeSet {
+	container := corev1.Container{Name: ElasticsearchContainerName}
+	if javaOpts != "" {
+		container.Env = []corev1.EnvVar{{Name: "ES_JAVA_OPTS", Value: javaOpts}}
+	}
+	if memoryLimit != "" {
+		container.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memoryLimit)}
+	}
+	return NodeSet{
+		Name:        "default",
+		Count:       1,
+		PodTemplate: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{container}}},
+	}
+}
+
+func TestMaxHeapSize(t *testing.T) {
+	tests := []struct {
+		javaOpts  string
+		wantHeap  string
+		wantFound bool
+	}{
+		{javaOpts: "", wantFound: false},
+		{javaOpts: "-Xms1g", wantFound: false},
+		{javaOpts: "-Xmx", wantFound: false},
+		{javaOpts: "-Xms2g -Xmx2g", wantHeap: "2Gi", wantFound: true},
+		{javaOpts: "-Xmx512M -XX:+UseG1GC", wantHeap: "512Mi", wantFound: true},
+		{javaOpts: "-Xmx1048576k", wantHeap: "1Gi", wantFound: true},
+		{javaOpts: "-Xmx1073741824", wantHeap: "1Gi", wantFound: true},
+		{javaOpts: "-Xmx1g -Xmx4g", wantHeap: "4Gi", wantFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.javaOpts, func(t *testing.T) {
+			heap, found := MaxHeapSize(tt.javaOpts)
+			require.Equal(t, tt.wantFound, found)
+			if tt.wantFound {
+				require.Equal(t, 0, heap.Cmp(resource.MustParse(tt.wantHeap)), heap.String())
+			}
+		})
+	}
+}
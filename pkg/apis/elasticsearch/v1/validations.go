@@ -13,6 +13,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	esversion "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version"
 	netutil "github.com/elastic/cloud-on-k8s/pkg/utils/net"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -22,7 +23,8 @@ const (
 	parseVersionErrMsg       = "Cannot parse Elasticsearch version"
 	parseStoredVersionErrMsg = "Cannot parse current Elasticsearch version"
 	invalidSanIPErrMsg       = "Invalid SAN IP address"
-	pvcImmutableMsg          = "Volume claim templates cannot be modified"
+	pvcImmutableMsg          = "Volume claim templates cannot be modified, except for increasing their storage request"
+	pvcShrinkMsg             = "Decreasing the storage request of volume claim templates is not supported"
 	invalidNamesErrMsg       = "Elasticsearch configuration would generate resources with invalid names"
 	unsupportedVersionErrMsg = "Unsupported version"
 	unsupportedConfigErrMsg  = "Configuration setting is reserved for internal use. User-configured use is unsupported"
@@ -30,8 +32,18 @@ const (
 	noDowngradesMsg          = "Downgrades are not supported"
 	unsupportedVersionMsg    = "Unsupported version"
 	unsupportedUpgradeMsg    = "Unsupported version upgrade path"
+	duplicateSnapshotNames   = "Snapshot repository and policy names must be unique"
+	snapshotPoliciesMsg      = "Snapshot lifecycle policies require Elasticsearch 7.4.0 or later"
+	heapExceedsLimitMsg      = "JVM heap size must be lower than the memory limit of the Elasticsearch container"
+	duplicateRemoteClusters  = "Remote cluster names must be unique"
+	remoteClusterTargetMsg   = "Remote clusters must specify either an Elasticsearch reference or seeds"
+	remoteClusterCAMsg       = "Remote clusters specified with seeds require a CA secret"
+	remoteClusterSelfMsg     = "Elasticsearch cannot be a remote cluster of itself"
 )
 
+// snapshotLifecycleMinVersion is the first Elasticsearch version supporting snapshot lifecycle policies.
+var snapshotLifecycleMinVersion = version.MustParse("7.4.0")
+
 type validation func(*Elasticsearch) field.ErrorList
 
 // validations are the validation funcs that apply to creates or updates
@@ -41,6 +53,9 @@ var validations = []validation{
 	hasMaster,
 	supportedVersion,
 	validSanIP,
+	validSnapshots,
+	heapWithinMemoryLimit,
+	validRemoteClusters,
 }
 
 type updateValidation func(*Elasticsearch, *Elasticsearch) field.ErrorList
@@ -89,7 +104,8 @@ func supportedVersion(es *Elasticsearch) field.ErrorList {
 	return field.ErrorList{field.Invalid(field.NewPath("spec").Child("version"), es.Spec.Version, unsupportedVersionErrMsg)}
 }
 
-// hasMaster checks if the given Elasticsearch cluster has at least one master node.
+// hasMaster checks if the given Elasticsearch cluster has at least one master node, unless it is scaled down to zero
+// nodes.
 func hasMaster(es *Elasticsearch) field.ErrorList {
 	var errs field.ErrorList
 	var hasMaster bool
@@ -100,7 +116,7 @@ func hasMaster(es *Elasticsearch) field.ErrorList {
 		}
 		hasMaster = hasMaster || (cfg.Node.Master && t.Count > 0)
 	}
-	if !hasMaster {
+	if !hasMaster && !es.Spec.ScaledToZero() {
 		errs = append(errs, field.Invalid(field.NewPath("spec").Child("nodeSets"), es.Spec.NodeSets, masterRequiredMsg))
 	}
 	return errs
@@ -122,6 +138,81 @@ func validSanIP(es *Elasticsearch) field.ErrorList {
 	return errs
 }
 
+// validSnapshots checks that snapshot repositories and policies have unique names, and that policies are supported by
+// the Elasticsearch version.
+func validSnapshots(es *Elasticsearch) field.ErrorList {
+	var errs field.ErrorList
+	if es.Spec.Snapshots == nil {
+		return errs
+	}
+	path := field.NewPath("spec").Child("snapshots")
+	repositories := make(map[string]struct{})
+	for i, repository := range es.Spec.Snapshots.Repositories {
+		if _, found := repositories[repository.Name]; found {
+			errs = append(errs, field.Invalid(path.Child("repositories").Index(i).Child("name"), repository.Name, duplicateSnapshotNames))
+		}
+		repositories[repository.Name] = struct{}{}
+	}
+	policies := make(map[string]struct{})
+	for i, policy := range es.Spec.Snapshots.Policies {
+		if _, found := policies[policy.Name]; found {
+			errs = append(errs, field.Invalid(path.Child("policies").Index(i).Child("name"), policy.Name, duplicateSnapshotNames))
+		}
+		policies[policy.Name] = struct{}{}
+	}
+	if len(es.Spec.Snapshots.Policies) > 0 {
+		ver, err := version.Parse(es.Spec.Version)
+		if err != nil {
+			// already reported by supportedVersion
+			return errs
+		}
+		if !ver.IsSameOrAfter(snapshotLifecycleMinVersion) {
+			errs = append(errs, field.Invalid(path.Child("policies"), es.Spec.Version, snapshotPoliciesMsg))
+		}
+	}
+	return errs
+}
+
+// heapWithinMemoryLimit checks that the JVM heap size set through ES_JAVA_OPTS is lower than the memory limit of the
+// Elasticsearch container, otherwise the container is OOM-killed as soon as the heap grows.
+func heapWithinMemoryLimit(es *Elasticsearch) field.ErrorList {
+	var errs field.ErrorList
+	for i, nodeSet := range es.Spec.NodeSets {
+		heap, limit, ok := heapAndMemoryLimit(nodeSet)
+		if !ok {
+			continue
+		}
+		if heap.Cmp(limit) >= 0 {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("nodeSets").Index(i).Child("podTemplate"), heap.String(), heapExceedsLimitMsg))
+		}
+	}
+	return errs
+}
+
+// validRemoteClusters checks that remote clusters have unique names, and either reference another Elasticsearch
+// cluster managed by the operator or specify seeds and the CA to trust.
+func validRemoteClusters(es *Elasticsearch) field.ErrorList {
+	var errs field.ErrorList
+	names := make(map[string]struct{})
+	for i, remote := range es.Spec.RemoteClusters {
+		path := field.NewPath("spec").Child("remoteClusters").Index(i)
+		if _, found := names[remote.Name]; found {
+			errs = append(errs, field.Invalid(path.Child("name"), remote.Name, duplicateRemoteClusters))
+		}
+		names[remote.Name] = struct{}{}
+		ref := remote.ElasticsearchRefOrDefault(es.Namespace)
+		switch {
+		case (ref == nil) == (len(remote.Seeds) == 0):
+			errs = append(errs, field.Invalid(path, remote.Name, remoteClusterTargetMsg))
+		case ref == nil && remote.CASecretName == "":
+			errs = append(errs, field.Required(path.Child("caSecretName"), remoteClusterCAMsg))
+		case ref != nil && ref.Name == es.Name && ref.Namespace == es.Namespace:
+			errs = append(errs, field.Invalid(path.Child("elasticsearchRef"), ref.Name, remoteClusterSelfMsg))
+		}
+	}
+	return errs
+}
+
 func checkNodeSetNameUniqueness(es *Elasticsearch) field.ErrorList {
 	var errs field.ErrorList
 	nodeSets := es.Spec.NodeSets
@@ -139,7 +230,8 @@ func checkNodeSetNameUniqueness(es *Elasticsearch) field.ErrorList {
 	return errs
 }
 
-// pvcModification ensures no PVCs are changed, as volume claim templates are immutable in stateful sets
+// pvcModification ensures PVCs are not changed, except for increasing their storage request, as volume claim templates
+// are immutable in stateful sets and volumes can only be expanded by the operator.
 func pvcModification(current, proposed *Elasticsearch) field.ErrorList {
 	var errs field.ErrorList
 	if current == nil || proposed == nil {
@@ -152,15 +244,37 @@ func pvcModification(current, proposed *Elasticsearch) field.ErrorList {
 			continue
 		}
 
+		path := field.NewPath("spec").Child("nodeSet").Index(i).Child("volumeClaimTemplates")
 		// ssets do not allow modifications to fields other than 'replicas', 'template', and 'updateStrategy'
 		// reflection isn't ideal, but okay here since the ES object does not have the status of the claims
-		if !reflect.DeepEqual(node.VolumeClaimTemplates, currNode.VolumeClaimTemplates) {
-			errs = append(errs, field.Invalid(field.NewPath("spec").Child("nodeSet").Index(i).Child("volumeClaimTemplates"), node.VolumeClaimTemplates, pvcImmutableMsg))
+		if !reflect.DeepEqual(withoutStorageRequests(node.VolumeClaimTemplates), withoutStorageRequests(currNode.VolumeClaimTemplates)) {
+			errs = append(errs, field.Invalid(path, node.VolumeClaimTemplates, pvcImmutableMsg))
+			continue
+		}
+		for j, claim := range node.VolumeClaimTemplates {
+			proposedSize := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+			if proposedSize.Cmp(currNode.VolumeClaimTemplates[j].Spec.Resources.Requests[corev1.ResourceStorage]) < 0 {
+				errs = append(errs, field.Invalid(path.Index(j), proposedSize.String(), pvcShrinkMsg))
+			}
 		}
 	}
 	return errs
 }
 
+// withoutStorageRequests returns a copy of the given claims without their storage request.
+func withoutStorageRequests(claims []corev1.PersistentVolumeClaim) []corev1.PersistentVolumeClaim {
+	result := make([]corev1.PersistentVolumeClaim, 0, len(claims))
+	for _, claim := range claims {
+		c := claim.DeepCopy()
+		delete(c.Spec.Resources.Requests, corev1.ResourceStorage)
+		if len(c.Spec.Resources.Requests) == 0 {
+			c.Spec.Resources.Requests = nil
+		}
+		result = append(result, *c)
+	}
+	return result
+}
+
 func noDowngrades(current, proposed *Elasticsearch) field.ErrorList {
 	var errs field.ErrorList
 	if current == nil || proposed == nil {
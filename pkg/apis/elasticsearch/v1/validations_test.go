@@ -5,9 +5,12 @@
 package v1
 
 import (
+	"io/ioutil"
 	"testing"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -105,6 +108,7 @@ func Test_hasMaster(t *testing.T) {
 									NodeML:     "false",
 								},
 							},
+							Count: 1,
 						},
 					},
 				},
@@ -127,11 +131,53 @@ func Test_hasMaster(t *testing.T) {
 								},
 							},
 						},
+						{
+							Config: &commonv1.Config{
+								Data: map[string]interface{}{
+									NodeMaster: "false",
+									NodeData:   "true",
+									NodeIngest: "false",
+									NodeML:     "false",
+								},
+							},
+							Count: 1,
+						},
 					},
 				},
 			},
 			expectErrors: true,
 		},
+		{
+			name: "scaled to zero",
+			es: &Elasticsearch{
+				Spec: ElasticsearchSpec{
+					Version: "7.0.0",
+					NodeSets: []NodeSet{
+						{
+							Config: &commonv1.Config{
+								Data: map[string]interface{}{
+									NodeMaster: "true",
+									NodeData:   "false",
+									NodeIngest: "false",
+									NodeML:     "false",
+								},
+							},
+						},
+						{
+							Config: &commonv1.Config{
+								Data: map[string]interface{}{
+									NodeMaster: "false",
+									NodeData:   "true",
+									NodeIngest: "false",
+									NodeML:     "false",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectErrors: false,
+		},
 		{
 			name: "has master",
 			es: &Elasticsearch{
@@ -250,6 +296,64 @@ func Test_validName(t *testing.T) {
 	}
 }
 
+func Test_validSnapshots(t *testing.T) {
+	repository := SnapshotRepository{Name: "repo", Type: "fs"}
+	policy := SnapshotPolicy{Name: "nightly", Schedule: "0 30 1 * * ?", Repository: "repo"}
+	tests := []struct {
+		name         string
+		version      string
+		snapshots    *SnapshotsSpec
+		expectErrors bool
+	}{
+		{
+			name:         "no snapshots",
+			version:      "6.8.0",
+			snapshots:    nil,
+			expectErrors: false,
+		},
+		{
+			name:         "repositories and policies",
+			version:      "7.4.0",
+			snapshots:    &SnapshotsSpec{Repositories: []SnapshotRepository{repository}, Policies: []SnapshotPolicy{policy}},
+			expectErrors: false,
+		},
+		{
+			name:         "repositories without policies before 7.4.0",
+			version:      "6.8.0",
+			snapshots:    &SnapshotsSpec{Repositories: []SnapshotRepository{repository}},
+			expectErrors: false,
+		},
+		{
+			name:         "policies before 7.4.0",
+			version:      "7.3.2",
+			snapshots:    &SnapshotsSpec{Repositories: []SnapshotRepository{repository}, Policies: []SnapshotPolicy{policy}},
+			expectErrors: true,
+		},
+		{
+			name:         "duplicate repositories",
+			version:      "7.4.0",
+			snapshots:    &SnapshotsSpec{Repositories: []SnapshotRepository{repository, repository}},
+			expectErrors: true,
+		},
+		{
+			name:         "duplicate policies",
+			version:      "7.4.0",
+			snapshots:    &SnapshotsSpec{Policies: []SnapshotPolicy{policy, policy}},
+			expectErrors: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := &Elasticsearch{Spec: ElasticsearchSpec{Version: tt.version, Snapshots: tt.snapshots}}
+			actual := validSnapshots(es)
+			actualErrors := len(actual) > 0
+			if tt.expectErrors != actualErrors {
+				t.Errorf("failed validSnapshots(). Name: %v, actual %v, wanted: %v, value: %v", tt.name, actual, tt.expectErrors, tt.snapshots)
+			}
+		})
+	}
+}
+
 func Test_validSanIP(t *testing.T) {
 	validIP := "3.4.5.6"
 	validIP2 := "192.168.12.13"
@@ -337,7 +441,7 @@ func Test_pvcModified(t *testing.T) {
 		expectErrors bool
 	}{
 		{
-			name:    "resize fails",
+			name:    "storage increase accepted",
 			current: current,
 			proposed: &Elasticsearch{
 				Spec: ElasticsearchSpec{
@@ -363,6 +467,36 @@ func Test_pvcModified(t *testing.T) {
 					},
 				},
 			},
+			expectErrors: false,
+		},
+
+		{
+			name:    "storage decrease rejected",
+			current: current,
+			proposed: &Elasticsearch{
+				Spec: ElasticsearchSpec{
+					Version: "7.2.0",
+					NodeSets: []NodeSet{
+						{
+							Name: "master",
+							VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+								{
+									ObjectMeta: metav1.ObjectMeta{
+										Name: "elasticsearch-data",
+									},
+									Spec: corev1.PersistentVolumeClaimSpec{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												corev1.ResourceStorage: resource.MustParse("1Gi"),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
 			expectErrors: true,
 		},
 
@@ -715,3 +849,175 @@ func getEsCluster() *Elasticsearch {
 		},
 	}
 }
+
+func Test_heapWithinMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		nodeSet      NodeSet
+		expectErrors bool
+	}{
+		{
+			name:         "no heap nor memory limit",
+			nodeSet:      heapNodeSet("", ""),
+			expectErrors: false,
+		},
+		{
+			name:         "heap without memory limit",
+			nodeSet:      heapNodeSet("-Xms8g -Xmx8g", ""),
+			expectErrors: false,
+		},
+		{
+			name:         "memory limit without heap",
+			nodeSet:      heapNodeSet("", "4Gi"),
+			expectErrors: false,
+		},
+		{
+			name:         "heap lower than the memory limit",
+			nodeSet:      heapNodeSet("-Xms2g -Xmx2g", "4Gi"),
+			expectErrors: false,
+		},
+		{
+			name:         "heap equal to the memory limit",
+			nodeSet:      heapNodeSet("-Xms4g -Xmx4g", "4Gi"),
+			expectErrors: true,
+		},
+		{
+			name:         "heap exceeding the memory limit",
+			nodeSet:      heapNodeSet("-Xms2g -Xmx8g", "4Gi"),
+			expectErrors: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := &Elasticsearch{Spec: ElasticsearchSpec{Version: "7.5.0", NodeSets: []NodeSet{tt.nodeSet}}}
+			actual := heapWithinMemoryLimit(es)
+			actualErrors := len(actual) > 0
+			if tt.expectErrors != actualErrors {
+				t.Errorf("failed heapWithinMemoryLimit(). Name: %v, actual %v, wanted: %v", tt.name, actual, tt.expectErrors)
+			}
+		})
+	}
+}
+
+func Test_validRemoteClusters(t *testing.T) {
+	tests := []struct {
+		name           string
+		remoteClusters []RemoteCluster
+		expectErrors   bool
+	}{
+		{
+			name:           "no remote clusters",
+			remoteClusters: nil,
+			expectErrors:   false,
+		},
+		{
+			name: "managed and external remote clusters",
+			remoteClusters: []RemoteCluster{
+				{Name: "east", ElasticsearchRef: &commonv1.ObjectSelector{Name: "east", Namespace: "other"}},
+				{Name: "west", Seeds: []string{"west.example.com:9300"}, CASecretName: "west-ca"},
+			},
+			expectErrors: false,
+		},
+		{
+			name: "duplicate names",
+			remoteClusters: []RemoteCluster{
+				{Name: "east", ElasticsearchRef: &commonv1.ObjectSelector{Name: "east"}},
+				{Name: "east", ElasticsearchRef: &commonv1.ObjectSelector{Name: "other"}},
+			},
+			expectErrors: true,
+		},
+		{
+			name:           "neither reference nor seeds",
+			remoteClusters: []RemoteCluster{{Name: "east"}},
+			expectErrors:   true,
+		},
+		{
+			name: "both reference and seeds",
+			remoteClusters: []RemoteCluster{
+				{Name: "east", ElasticsearchRef: &commonv1.ObjectSelector{Name: "east"}, Seeds: []string{"east:9300"}, CASecretName: "ca"},
+			},
+			expectErrors: true,
+		},
+		{
+			name:           "seeds without CA",
+			remoteClusters: []RemoteCluster{{Name: "west", Seeds: []string{"west.example.com:9300"}}},
+			expectErrors:   true,
+		},
+		{
+			name:           "reference to itself",
+			remoteClusters: []RemoteCluster{{Name: "self", ElasticsearchRef: &commonv1.ObjectSelector{Name: "foo"}}},
+			expectErrors:   true,
+		},
+		{
+			name:           "same name in another namespace",
+			remoteClusters: []RemoteCluster{{Name: "foo", ElasticsearchRef: &commonv1.ObjectSelector{Name: "foo", Namespace: "other"}}},
+			expectErrors:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := es("7.5.0")
+			es.Spec.RemoteClusters = tt.remoteClusters
+			actual := validRemoteClusters(es)
+			actualErrors := len(actual) > 0
+			if tt.expectErrors != actualErrors {
+				t.Errorf("failed validRemoteClusters(). Name: %v, actual %v, wanted: %v", tt.name, actual, tt.expectErrors)
+			}
+		})
+	}
+}
+
+func Test_countSchemaValidation(t *testing.T) {
+	// the CRD generated from the kubebuilder markers, validated by the API server
+	crdFile, err := ioutil.ReadFile("../../../../config/crds/bases/elasticsearch.k8s.elastic.co_elasticsearches.yaml")
+	require.NoError(t, err)
+	var crd struct {
+		Spec struct {
+			Versions []struct {
+				Name   string `json:"name"`
+				Schema struct {
+					OpenAPIV3Schema struct {
+						Properties struct {
+							Spec struct {
+								Properties struct {
+									NodeSets struct {
+										Items struct {
+											Properties struct {
+												Count struct {
+													Minimum *float64 `json:"minimum"`
+												} `json:"count"`
+											} `json:"properties"`
+										} `json:"items"`
+									} `json:"nodeSets"`
+								} `json:"properties"`
+							} `json:"spec"`
+						} `json:"properties"`
+					} `json:"openAPIV3Schema"`
+				} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}
+	require.NoError(t, yaml.Unmarshal(crdFile, &crd))
+	var minimum *float64
+	for _, version := range crd.Spec.Versions {
+		if version.Name == "v1" {
+			minimum = version.Schema.OpenAPIV3Schema.Properties.Spec.Properties.NodeSets.Items.Properties.Count.Minimum
+		}
+	}
+	require.NotNil(t, minimum)
+
+	tests := []struct {
+		name  string
+		count int32
+		valid bool
+	}{
+		{name: "scaled to zero", count: 0, valid: true},
+		{name: "one node", count: 1, valid: true},
+		{name: "negative count", count: -1, valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.valid, float64(tt.count) >= *minimum)
+		})
+	}
+}
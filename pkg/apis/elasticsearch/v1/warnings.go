@@ -11,10 +11,20 @@ import (
 
 var warnings = []validation{
 	noUnsupportedSettings,
+	noOversizedHeap,
 }
 
+const oversizedHeapMsg = "JVM heap size above 75% of the memory limit leaves little memory to Elasticsearch off-heap structures and the filesystem cache"
+
+// maxHeapToMemoryLimitRatio is the heap to memory limit ratio above which the JVM heap is considered too large.
+const maxHeapToMemoryLimitRatio = 0.75
+
 func noUnsupportedSettings(es *Elasticsearch) field.ErrorList {
 	var errs field.ErrorList
+	unsupportedSettings := UnsupportedSettings
+	if es.Spec.ZoneAwareness != nil {
+		unsupportedSettings = append(append([]string{}, UnsupportedSettings...), ZoneAwarenessSettings...)
+	}
 	for i, nodeSet := range es.Spec.NodeSets {
 		if nodeSet.Config == nil {
 			continue
@@ -24,7 +34,7 @@ func noUnsupportedSettings(es *Elasticsearch) field.ErrorList {
 			errs = append(errs, field.Invalid(field.NewPath("spec").Child("nodeSets").Index(i).Child("config"), es.Spec.NodeSets[i].Config, cfgInvalidMsg))
 			continue
 		}
-		unsupported := config.HasKeys(UnsupportedSettings)
+		unsupported := config.HasKeys(unsupportedSettings)
 		for _, setting := range unsupported {
 			errs = append(errs, field.Forbidden(field.NewPath("spec").Child("nodeSets").Index(i).Child("config").Child(setting), unsupportedConfigErrMsg))
 		}
@@ -32,6 +42,22 @@ func noUnsupportedSettings(es *Elasticsearch) field.ErrorList {
 	return errs
 }
 
+// noOversizedHeap warns about JVM heap sizes close to the memory limit of the Elasticsearch container. Heap sizes
+// exceeding the limit are rejected by heapWithinMemoryLimit.
+func noOversizedHeap(es *Elasticsearch) field.ErrorList {
+	var errs field.ErrorList
+	for i, nodeSet := range es.Spec.NodeSets {
+		heap, limit, ok := heapAndMemoryLimit(nodeSet)
+		if !ok || heap.Cmp(limit) >= 0 {
+			continue
+		}
+		if float64(heap.Value()) > maxHeapToMemoryLimitRatio*float64(limit.Value()) {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("nodeSets").Index(i).Child("podTemplate"), heap.String(), oversizedHeapMsg))
+		}
+	}
+	return errs
+}
+
 func (r *Elasticsearch) CheckForWarnings() error {
 	warnings := r.check(warnings)
 	if len(warnings) > 0 {
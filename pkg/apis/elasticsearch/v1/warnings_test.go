@@ -66,6 +66,43 @@ func Test_noUnsupportedSettings(t *testing.T) {
 			},
 			expectErrors: true,
 		},
+		{
+			name: "zone awareness settings without zone awareness OK",
+			es: &Elasticsearch{
+				Spec: ElasticsearchSpec{
+					Version: "7.0.0",
+					NodeSets: []NodeSet{
+						{
+							Config: &commonv1.Config{
+								Data: map[string]interface{}{
+									NodeAttrZone: "europe-west1-b",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectErrors: false,
+		},
+		{
+			name: "warn of zone awareness settings with zone awareness FAIL",
+			es: &Elasticsearch{
+				Spec: ElasticsearchSpec{
+					Version:       "7.0.0",
+					ZoneAwareness: &ZoneAwareness{},
+					NodeSets: []NodeSet{
+						{
+							Config: &commonv1.Config{
+								Data: map[string]interface{}{
+									NodeAttrZone: "europe-west1-b",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectErrors: true,
+		},
 		{
 			name: "non unsupported setting OK",
 			es: &Elasticsearch{
@@ -134,3 +171,47 @@ func Test_noUnsupportedSettings(t *testing.T) {
 		})
 	}
 }
+
+func Test_noOversizedHeap(t *testing.T) {
+	tests := []struct {
+		name         string
+		nodeSet      NodeSet
+		expectErrors bool
+	}{
+		{
+			name:         "no memory limit",
+			nodeSet:      heapNodeSet("-Xms8g -Xmx8g", ""),
+			expectErrors: false,
+		},
+		{
+			name:         "heap at half the memory limit",
+			nodeSet:      heapNodeSet("-Xms2g -Xmx2g", "4Gi"),
+			expectErrors: false,
+		},
+		{
+			name:         "heap at 75% of the memory limit",
+			nodeSet:      heapNodeSet("-Xms3g -Xmx3g", "4Gi"),
+			expectErrors: false,
+		},
+		{
+			name:         "heap above 75% of the memory limit",
+			nodeSet:      heapNodeSet("-Xms3500m -Xmx3500m", "4Gi"),
+			expectErrors: true,
+		},
+		{
+			name:         "heap exceeding the memory limit is left to validation",
+			nodeSet:      heapNodeSet("-Xms8g -Xmx8g", "4Gi"),
+			expectErrors: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := &Elasticsearch{Spec: ElasticsearchSpec{Version: "7.5.0", NodeSets: []NodeSet{tt.nodeSet}}}
+			actual := noOversizedHeap(es)
+			actualErrors := len(actual) > 0
+			if tt.expectErrors != actualErrors {
+				t.Errorf("failed noOversizedHeap(). Name: %v, actual %v, wanted: %v", tt.name, actual, tt.expectErrors)
+			}
+		})
+	}
+}
@@ -27,9 +27,20 @@ type KibanaSpec struct {
 	// ElasticsearchRef is a reference to an Elasticsearch cluster running in the same Kubernetes cluster.
 	ElasticsearchRef commonv1.ObjectSelector `json:"elasticsearchRef,omitempty"`
 
+	// ExternalElasticsearch references an Elasticsearch cluster not managed by the operator, to use instead of an
+	// Elasticsearch cluster referenced by ElasticsearchRef.
+	// +optional
+	ExternalElasticsearch *commonv1.ExternalElasticsearch `json:"externalElasticsearch,omitempty"`
+
 	// Config holds the Kibana configuration. See: https://www.elastic.co/guide/en/kibana/current/settings.html
 	Config *commonv1.Config `json:"config,omitempty"`
 
+	// ConfigRef references a ConfigMap, in the same namespace, holding additional Kibana configuration in its kibana.yml
+	// key. It is merged into the configuration generated by the operator, Config taking precedence over it.
+	// The Elasticsearch connection settings it holds are ignored if the connection is managed by the operator.
+	// +optional
+	ConfigRef *ConfigMapRef `json:"configRef,omitempty"`
+
 	// HTTP holds the HTTP layer configuration for Kibana.
 	HTTP commonv1.HTTPConfig `json:"http,omitempty"`
 
@@ -47,6 +58,12 @@ type KibanaSpec struct {
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 }
 
+// ConfigMapRef is a reference to a ConfigMap that exists in the same namespace.
+type ConfigMapRef struct {
+	// ConfigMapName is the name of the ConfigMap.
+	ConfigMapName string `json:"configMapName"`
+}
+
 // KibanaHealth expresses the status of the Kibana instances.
 type KibanaHealth string
 
@@ -60,8 +77,37 @@ const (
 // KibanaStatus defines the observed state of Kibana
 type KibanaStatus struct {
 	commonv1.ReconcilerStatus `json:",inline"`
-	Health                    KibanaHealth               `json:"health,omitempty"`
-	AssociationStatus         commonv1.AssociationStatus `json:"associationStatus,omitempty"`
+	// ReadyNodes is the number of Kibana instances ready to serve requests.
+	ReadyNodes int32 `json:"readyNodes,omitempty"`
+	// ExpectedNodes is the number of Kibana instances expected to run.
+	ExpectedNodes     int32                      `json:"expectedNodes,omitempty"`
+	Health            KibanaHealth               `json:"health,omitempty"`
+	AssociationStatus commonv1.AssociationStatus `json:"associationStatus,omitempty"`
+	// AssociationMessage is a human readable message detailing the association status.
+	AssociationMessage string `json:"associationMessage,omitempty"`
+	// AssociationObservedGeneration is the generation of Kibana last observed by the association controller.
+	AssociationObservedGeneration int64 `json:"associationObservedGeneration,omitempty"`
+	// AssociationAuthMode is the mode used by Kibana to authenticate against the associated Elasticsearch cluster.
+	AssociationAuthMode commonv1.AssociationAuthMode `json:"associationAuthMode,omitempty"`
+	// AssociationHealth summarizes the health of the association with Elasticsearch.
+	AssociationHealth commonv1.AssociationHealth `json:"associationHealth,omitempty"`
+	// AssociationDependencies is the observed state of the objects the association with Elasticsearch depends on.
+	AssociationDependencies []commonv1.AssociationDependency `json:"associationDependencies,omitempty"`
+	// AssociationPlannedChange describes, while the association is in dry-run mode, the change to the Elasticsearch
+	// configuration of Kibana the operator would apply.
+	AssociationPlannedChange string `json:"associationPlannedChange,omitempty"`
+	// AssociationConfOwner is who currently manages the Elasticsearch configuration of Kibana.
+	AssociationConfOwner commonv1.AssociationConfOwner `json:"associationConfOwner,omitempty"`
+	// AssociationCARotation is the phase of the orchestrated rotation of the Elasticsearch CA trusted by Kibana, if any.
+	AssociationCARotation commonv1.AssociationCARotationPhase `json:"associationCARotation,omitempty"`
+	// AssociationDeferredChange describes the changes to the Elasticsearch configuration of Kibana deferred until its
+	// next restart, if any.
+	AssociationDeferredChange *commonv1.AssociationDeferredChange `json:"associationDeferredChange,omitempty"`
+	// AssociationConditions details the state of the association with Elasticsearch.
+	AssociationConditions []commonv1.AssociationCondition `json:"associationConditions,omitempty"`
+	// AssociationLastError is the error the last reconciliation of the association with Elasticsearch failed with, if
+	// any. It is cleared once a reconciliation succeeds.
+	AssociationLastError *commonv1.AssociationError `json:"associationLastError,omitempty"`
 }
 
 // IsDegraded returns true if the current status is worse than the previous.
@@ -94,9 +140,10 @@ func (k *Kibana) SetAssociationConf(assocConf *commonv1.AssociationConf) {
 	k.assocConf = assocConf
 }
 
-// RequiresAssociation returns true if the spec specifies an Elasticsearch reference.
+// RequiresAssociation returns true if the spec specifies an Elasticsearch reference or an external Elasticsearch
+// cluster.
 func (k *Kibana) RequiresAssociation() bool {
-	return k.Spec.ElasticsearchRef.Name != ""
+	return k.Spec.ElasticsearchRef.Name != "" || k.Spec.ExternalElasticsearch != nil
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,85 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1
+
+import (
+	"net/url"
+	"strings"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	missingNameErrMsg  = "Elasticsearch reference name is required when its namespace is set"
+	invalidRefErrMsg   = "Invalid Elasticsearch reference"
+	exclusiveRefErrMsg = "Elasticsearch reference and external Elasticsearch are mutually exclusive"
+	invalidURLErrMsg   = "External Elasticsearch URL must be an absolute http or https URL"
+)
+
+type validation func(*Kibana) field.ErrorList
+
+// validations are the validation funcs that apply to creates or updates
+var validations = []validation{
+	validElasticsearchRef,
+	validExternalElasticsearch,
+}
+
+func (k *Kibana) check(validations []validation) field.ErrorList {
+	var errs field.ErrorList
+	for _, val := range validations {
+		if err := val(k); err != nil {
+			errs = append(errs, err...)
+		}
+	}
+	return errs
+}
+
+// validElasticsearchRef checks that the Elasticsearch reference, if any, names a resource which can exist. A reference
+// to a missing Elasticsearch cluster is accepted, since it may be created later on.
+func validElasticsearchRef(k *Kibana) field.ErrorList {
+	ref := k.Spec.ElasticsearchRef
+	path := field.NewPath("spec").Child("elasticsearchRef")
+	var errs field.ErrorList
+	if ref.Name == "" {
+		if ref.Namespace != "" {
+			errs = append(errs, field.Required(path.Child("name"), missingNameErrMsg))
+		}
+		return errs
+	}
+	if msgs := k8svalidation.IsDNS1123Subdomain(ref.Name); len(msgs) > 0 {
+		errs = append(errs, field.Invalid(path.Child("name"), ref.Name, invalidRefErrMsg+": "+strings.Join(msgs, ", ")))
+	}
+	if ref.Namespace != "" {
+		if msgs := k8svalidation.IsDNS1123Label(ref.Namespace); len(msgs) > 0 {
+			errs = append(errs, field.Invalid(path.Child("namespace"), ref.Namespace, invalidRefErrMsg+": "+strings.Join(msgs, ", ")))
+		}
+	}
+	return errs
+}
+
+// validExternalElasticsearch checks that an external Elasticsearch cluster, if any, is not referenced along with an
+// Elasticsearch cluster managed by the operator, and specifies where and how to reach it.
+func validExternalElasticsearch(k *Kibana) field.ErrorList {
+	external := k.Spec.ExternalElasticsearch
+	if external == nil {
+		return nil
+	}
+	path := field.NewPath("spec").Child("externalElasticsearch")
+	var errs field.ErrorList
+	if k.Spec.ElasticsearchRef.IsDefined() {
+		errs = append(errs, field.Forbidden(path, exclusiveRefErrMsg))
+	}
+	if u, err := url.Parse(external.URL); err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		errs = append(errs, field.Invalid(path.Child("url"), external.URL, invalidURLErrMsg))
+	}
+	if external.AuthSecretName == "" {
+		errs = append(errs, field.Required(path.Child("authSecretName"), ""))
+	}
+	if external.AuthSecretKey == "" {
+		errs = append(errs, field.Required(path.Child("authSecretKey"), ""))
+	}
+	return errs
+}
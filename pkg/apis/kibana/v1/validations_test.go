@@ -0,0 +1,136 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+func Test_validElasticsearchRef(t *testing.T) {
+	tests := []struct {
+		name       string
+		ref        commonv1.ObjectSelector
+		wantFields []string
+	}{
+		{
+			name: "no reference",
+		},
+		{
+			name: "reference in the same namespace",
+			ref:  commonv1.ObjectSelector{Name: "es"},
+		},
+		{
+			name: "reference in another namespace",
+			ref:  commonv1.ObjectSelector{Namespace: "elastic-system", Name: "es"},
+		},
+		{
+			name:       "namespace without name",
+			ref:        commonv1.ObjectSelector{Namespace: "elastic-system"},
+			wantFields: []string{"spec.elasticsearchRef.name"},
+		},
+		{
+			name:       "invalid name",
+			ref:        commonv1.ObjectSelector{Name: "My_ES"},
+			wantFields: []string{"spec.elasticsearchRef.name"},
+		},
+		{
+			name:       "invalid namespace",
+			ref:        commonv1.ObjectSelector{Namespace: "elastic.system", Name: "es"},
+			wantFields: []string{"spec.elasticsearchRef.namespace"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validElasticsearchRef(&Kibana{Spec: KibanaSpec{ElasticsearchRef: tt.ref}})
+			fields := make([]string, 0, len(errs))
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			require.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func Test_validExternalElasticsearch(t *testing.T) {
+	valid := func() *commonv1.ExternalElasticsearch {
+		return &commonv1.ExternalElasticsearch{
+			URL:            "https://es.example.com:9200",
+			AuthSecretName: "es-credentials",
+			AuthSecretKey:  "kibana",
+		}
+	}
+	tests := []struct {
+		name       string
+		ref        commonv1.ObjectSelector
+		external   func() *commonv1.ExternalElasticsearch
+		wantFields []string
+	}{
+		{
+			name:     "no external Elasticsearch",
+			external: func() *commonv1.ExternalElasticsearch { return nil },
+		},
+		{
+			name:     "external Elasticsearch",
+			external: valid,
+		},
+		{
+			name: "external Elasticsearch with a CA",
+			external: func() *commonv1.ExternalElasticsearch {
+				external := valid()
+				external.CASecretName = "es-ca"
+				return external
+			},
+		},
+		{
+			name:       "along with an Elasticsearch reference",
+			ref:        commonv1.ObjectSelector{Name: "es"},
+			external:   valid,
+			wantFields: []string{"spec.externalElasticsearch"},
+		},
+		{
+			name: "relative URL",
+			external: func() *commonv1.ExternalElasticsearch {
+				external := valid()
+				external.URL = "es.example.com:9200"
+				return external
+			},
+			wantFields: []string{"spec.externalElasticsearch.url"},
+		},
+		{
+			name: "missing credentials",
+			external: func() *commonv1.ExternalElasticsearch {
+				external := valid()
+				external.AuthSecretName = ""
+				external.AuthSecretKey = ""
+				return external
+			},
+			wantFields: []string{"spec.externalElasticsearch.authSecretName", "spec.externalElasticsearch.authSecretKey"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validExternalElasticsearch(&Kibana{Spec: KibanaSpec{ElasticsearchRef: tt.ref, ExternalElasticsearch: tt.external()}})
+			fields := make([]string, 0, len(errs))
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			require.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestKibana_ValidateCreate(t *testing.T) {
+	kb := &Kibana{Spec: KibanaSpec{Version: "7.6.0", ElasticsearchRef: commonv1.ObjectSelector{Name: "es"}}}
+	require.NoError(t, kb.ValidateCreate())
+	kb.Spec.ElasticsearchRef = commonv1.ObjectSelector{Namespace: "elastic-system"}
+	err := kb.ValidateCreate()
+	require.True(t, apierrors.IsInvalid(err))
+	require.Contains(t, err.Error(), missingNameErrMsg)
+}
@@ -0,0 +1,53 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:path=/validate-kibana-k8s-elastic-co-v1-kibana,mutating=false,failurePolicy=ignore,groups=kibana.k8s.elastic.co,resources=kibanas,verbs=create;update,versions=v1,name=elastic-kb-validation-v1.k8s.elastic.co
+
+func (k *Kibana) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(k).
+		Complete()
+}
+
+var kblog = logf.Log.WithName("kb-validation")
+
+var _ webhook.Validator = &Kibana{}
+
+func (k *Kibana) ValidateCreate() error {
+	kblog.V(1).Info("validate create", "name", k.Name)
+	return k.validateKibana()
+}
+
+// ValidateDelete is required to implement webhook.Validator, but we do not actually validate deletes
+func (k *Kibana) ValidateDelete() error {
+	return nil
+}
+
+func (k *Kibana) ValidateUpdate(_ runtime.Object) error {
+	kblog.V(1).Info("validate update", "name", k.Name)
+	return k.validateKibana()
+}
+
+func (k *Kibana) validateKibana() error {
+	errs := k.check(validations)
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "kibana.k8s.elastic.co", Kind: "Kibana"},
+			k.Name,
+			errs,
+		)
+	}
+	return nil
+}
@@ -13,13 +13,28 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapRef.
+func (in *ConfigMapRef) DeepCopy() *ConfigMapRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Kibana) DeepCopyInto(out *Kibana) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	if in.assocConf != nil {
 		in, out := &in.assocConf, &out.assocConf
 		*out = new(commonv1.AssociationConf)
@@ -81,10 +96,20 @@ func (in *KibanaList) DeepCopyObject() runtime.Object {
 func (in *KibanaSpec) DeepCopyInto(out *KibanaSpec) {
 	*out = *in
 	out.ElasticsearchRef = in.ElasticsearchRef
+	if in.ExternalElasticsearch != nil {
+		in, out := &in.ExternalElasticsearch, &out.ExternalElasticsearch
+		*out = new(commonv1.ExternalElasticsearch)
+		**out = **in
+	}
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = (*in).DeepCopy()
 	}
+	if in.ConfigRef != nil {
+		in, out := &in.ConfigRef, &out.ConfigRef
+		*out = new(ConfigMapRef)
+		**out = **in
+	}
 	in.HTTP.DeepCopyInto(&out.HTTP)
 	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
 	if in.SecureSettings != nil {
@@ -110,6 +135,30 @@ func (in *KibanaSpec) DeepCopy() *KibanaSpec {
 func (in *KibanaStatus) DeepCopyInto(out *KibanaStatus) {
 	*out = *in
 	out.ReconcilerStatus = in.ReconcilerStatus
+	if in.AssociationDependencies != nil {
+		in, out := &in.AssociationDependencies, &out.AssociationDependencies
+		*out = make([]commonv1.AssociationDependency, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AssociationDeferredChange != nil {
+		in, out := &in.AssociationDeferredChange, &out.AssociationDeferredChange
+		*out = new(commonv1.AssociationDeferredChange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AssociationConditions != nil {
+		in, out := &in.AssociationConditions, &out.AssociationConditions
+		*out = make([]commonv1.AssociationCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AssociationLastError != nil {
+		in, out := &in.AssociationLastError, &out.AssociationLastError
+		*out = new(commonv1.AssociationError)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaStatus.
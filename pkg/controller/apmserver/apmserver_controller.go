@@ -197,7 +197,7 @@ func (r *ReconcileApmServer) Reconcile(request reconcile.Request) (reconcile.Res
 
 	if common.IsPaused(as.ObjectMeta) {
 		log.Info("Object is paused. Skipping reconciliation", "namespace", as.Namespace, "as_name", as.Name)
-		return common.PauseRequeue, nil
+		return common.PauseRequeueFor(as.ObjectMeta), nil
 	}
 
 	if compatible, err := r.isCompatible(ctx, &as); err != nil || !compatible {
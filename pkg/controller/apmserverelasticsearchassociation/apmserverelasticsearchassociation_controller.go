@@ -35,7 +35,6 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
-	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
@@ -75,6 +74,8 @@ func newReconciler(mgr manager.Manager, accessReviewer rbac.AccessReviewer, para
 		scheme:         mgr.GetScheme(),
 		watches:        watches.NewDynamicWatches(),
 		recorder:       mgr.GetEventRecorderFor(name),
+		ownerRefMode:   association.OwnerRefMode(params.AssociationOwnerRefMode),
+		globalCA:       association.GlobalCA{Reader: mgr.GetAPIReader(), Secret: params.AssociationGlobalCA},
 		Parameters:     params,
 	}
 }
@@ -125,6 +126,10 @@ type ReconcileApmServerElasticsearchAssociation struct {
 	scheme         *runtime.Scheme
 	recorder       record.EventRecorder
 	watches        watches.DynamicWatches
+	// ownerRefMode defines how the resources derived from associations are owned and cleaned up
+	ownerRefMode association.OwnerRefMode
+	// globalCA is the CA trusted by all associated resources, if configured
+	globalCA association.GlobalCA
 	operator.Parameters
 	// iteration is the number of times this controller has run its Reconcile method
 	iteration uint64
@@ -134,6 +139,12 @@ func (r *ReconcileApmServerElasticsearchAssociation) onDelete(obj types.Namespac
 	// Clean up memory
 	r.watches.ElasticsearchClusters.RemoveHandlerForKey(elasticsearchWatchName(obj))
 	r.watches.Secrets.RemoveHandlerForKey(esCAWatchName(obj))
+	// Delete derived secrets if they are not garbage collected through an owner reference
+	if err := association.DeleteDerivedSecrets(
+		r.Client, r.ownerRefMode, obj.Namespace, NewResourceLabels(obj.Name),
+	); err != nil {
+		return err
+	}
 	// Delete user
 	return user.DeleteUser(r.Client, NewUserLabelSelector(obj))
 }
@@ -159,7 +170,7 @@ func (r *ReconcileApmServerElasticsearchAssociation) Reconcile(request reconcile
 
 	if common.IsPaused(apmServer.ObjectMeta) {
 		log.Info("Object is paused. Skipping reconciliation", "namespace", apmServer.Namespace, "as_name", apmServer.Name)
-		return common.PauseRequeue, nil
+		return common.PauseRequeueFor(apmServer.ObjectMeta), nil
 	}
 
 	// ApmServer is being deleted, short-circuit reconciliation and remove artifacts related to the association.
@@ -293,6 +304,8 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileInternal(ctx conte
 		"superuser",
 		apmUserSuffix,
 		es,
+		r.ownerRefMode,
+		"",
 	); err != nil { // TODO distinguish conflicts and non-recoverable errors here
 		return commonv1.AssociationPending, err
 	}
@@ -303,7 +316,7 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileInternal(ctx conte
 	}
 
 	// construct the expected ES output configuration
-	authSecretRef := association.ClearTextSecretKeySelector(apmServer, apmUserSuffix)
+	authSecretRef := association.ClearTextSecretKeySelector(apmServer, "", apmUserSuffix)
 	expectedAssocConf := &commonv1.AssociationConf{
 		AuthSecretName: authSecretRef.Name,
 		AuthSecretKey:  authSecretRef.Key,
@@ -318,7 +331,7 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileInternal(ctx conte
 		return status, err
 	}
 
-	if err := deleteOrphanedResources(ctx, r, apmServer); err != nil {
+	if err := deleteOrphanedResources(ctx, r, apmServer, r.ownerRefMode); err != nil {
 		log.Error(err, "Error while trying to delete orphaned resources. Continuing.", "namespace", apmServer.Namespace, "as_name", apmServer.Name)
 	}
 	return commonv1.AssociationEstablished, nil
@@ -350,7 +363,7 @@ func (r *ReconcileApmServerElasticsearchAssociation) updateAssocConf(ctx context
 	span, _ := apm.StartSpan(ctx, "update_apm_assoc", tracing.SpanTypeApp)
 	defer span.End()
 
-	if !reflect.DeepEqual(expectedAssocConf, apmServer.AssociationConf()) {
+	if !expectedAssocConf.Equivalent(apmServer.AssociationConf(), apmServer.Namespace) {
 		log.Info("Updating APMServer spec with Elasticsearch association configuration", "namespace", apmServer.Namespace, "name", apmServer.Name)
 		if err := association.UpdateAssociationConf(r.Client, apmServer, expectedAssocConf); err != nil {
 			if errors.IsConflict(err) {
@@ -383,11 +396,16 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileElasticsearchCA(ct
 	// watch ES CA secret to reconcile on any change
 	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
 		Name:    esCAWatchName(apmKey),
-		Watched: []types.NamespacedName{http.PublicCertsSecretRef(esv1.ESNamer, es)},
+		Watched: association.CAWatchedSecrets(es, r.globalCA),
 		Watcher: apmKey,
 	}); err != nil {
 		return association.CASecret{}, err
 	}
+	if err := association.CheckGlobalCA(r.globalCA); err != nil {
+		// the watch set above triggers a reconciliation once the global CA secret is fixed
+		r.recorder.Eventf(as, corev1.EventTypeWarning, events.EventAssociationError,
+			"Global CA secret %s unavailable, trusting the Elasticsearch CA only: %v", r.globalCA.Secret, err)
+	}
 	// Build the labels applied on the secret
 	labels := labels.NewLabels(as.Name)
 	labels[AssociationLabelName] = as.Name
@@ -398,6 +416,8 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileElasticsearchCA(ct
 		es,
 		labels,
 		elasticsearchCASecretSuffix,
+		r.ownerRefMode,
+		r.globalCA,
 	)
 }
 
@@ -405,7 +425,7 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileElasticsearchCA(ct
 // attempts. If a user changes namespace on a vertex of an association the standard reconcile mechanism will not delete the
 // now redundant old user object/secret. This function lists all resources that don't match the current name/namespace
 // combinations and deletes them.
-func deleteOrphanedResources(ctx context.Context, c k8s.Client, as *apmv1.ApmServer) error {
+func deleteOrphanedResources(ctx context.Context, c k8s.Client, as *apmv1.ApmServer, ownerRefMode association.OwnerRefMode) error {
 	span, _ := apm.StartSpan(ctx, "delete_orphaned_resources", tracing.SpanTypeApp)
 	defer span.End()
 
@@ -417,7 +437,8 @@ func deleteOrphanedResources(ctx context.Context, c k8s.Client, as *apmv1.ApmSer
 	}
 
 	for _, s := range secrets.Items {
-		controlledBy := metav1.IsControlledBy(&s, as)
+		// without owner references, derived secrets can only be identified through their labels
+		controlledBy := metav1.IsControlledBy(&s, as) || ownerRefMode == association.OwnerRefModeCleanup
 		if controlledBy && !as.Spec.ElasticsearchRef.IsDefined() {
 			log.Info("Deleting secret", "namespace", s.Namespace, "secret_name", s.Name, "as_name", as.Name)
 			if err := c.Delete(&s); err != nil {
@@ -0,0 +1,269 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package associationclone
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// Association clone controller
+//
+// This controller clones the association of a Kibana resource annotated with the reference of another Elasticsearch
+// cluster, for instance a standby cluster in disaster recovery tests. The clone is a copy of the Kibana resource
+// referencing that cluster instead, whose association is established independently by the Kibana association
+// controller. The clone is owned by the source Kibana resource, so it is garbage collected along with it.
+//
+// The clone is kept in sync with the source Kibana resource, and deleted once the annotation is removed.
+
+const name = "association-clone-controller"
+
+// cloneSuffix is appended to the name of the source Kibana resource to name its clone.
+const cloneSuffix = "-clone"
+
+var log = logf.Log.WithName(name)
+
+// uncloned are the annotations of the source Kibana resource that are not copied to its clone, because they describe
+// the association of the source Kibana resource or its management by the operator.
+var uncloned = map[string]bool{
+	annotation.CloneToAnnotation:                  true,
+	annotation.AssociationConfAnnotation:          true,
+	annotation.CurrAssocStatusAnnotation:          true,
+	annotation.PrevAssocStatusAnnotation:          true,
+	annotation.ElasticsearchAliasAnnotation:       true,
+	annotation.ElasticsearchURLOverrideAnnotation: true,
+	annotation.ElasticsearchCAOverrideAnnotation:  true,
+	annotation.ElasticsearchUIDAnnotation:         true,
+	annotation.CASecretResourceVersionAnnotation:  true,
+	annotation.ProvenanceUpdatedAtAnnotation:      true,
+	annotation.ControllerVersionAnnotation:        true,
+	corev1.LastAppliedConfigAnnotation:            true,
+}
+
+// Add creates a new association clone controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, params operator.Parameters) error {
+	return add(mgr, newReconciler(mgr, params))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileClones {
+	return &ReconcileClones{
+		Client:     k8s.WrapClient(mgr.GetClient()),
+		scheme:     mgr.GetScheme(),
+		recorder:   mgr.GetEventRecorderFor(name),
+		Parameters: params,
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileClones) error {
+	c, err := controller.New(name, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// watch the source Kibana resources
+	if err := c.Watch(&source.Kind{Type: &kbv1.Kibana{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	// watch the clones, to restore them if they are modified or deleted
+	return c.Watch(&source.Kind{Type: &kbv1.Kibana{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &kbv1.Kibana{},
+		IsController: true,
+	})
+}
+
+var _ reconcile.Reconciler = &ReconcileClones{}
+
+// ReconcileClones maintains the clones of the associations of Kibana resources.
+type ReconcileClones struct {
+	k8s.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	operator.Parameters
+	// iteration is the number of times this controller has run its Reconcile method
+	iteration uint64
+}
+
+// Reconcile maintains the clone of the association of a Kibana resource, if it is annotated with the reference of the
+// Elasticsearch cluster to clone it to.
+func (r *ReconcileClones) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	defer common.LogReconciliationRun(log, request, "kibana_name", &r.iteration)()
+
+	var kb kbv1.Kibana
+	if err := r.Get(request.NamespacedName, &kb); err != nil {
+		if apierrors.IsNotFound(err) {
+			// clones are garbage collected
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if common.IsPaused(kb.ObjectMeta) {
+		log.Info("Object is paused. Skipping reconciliation", "namespace", kb.Namespace, "kibana_name", kb.Name)
+		return common.PauseRequeueFor(kb.ObjectMeta), nil
+	}
+
+	if !kb.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	value, exists := kb.Annotations[annotation.CloneToAnnotation]
+	if !exists {
+		return reconcile.Result{}, r.deleteClone(kb)
+	}
+	target, err := parseElasticsearchRef(value)
+	if err != nil {
+		// the annotation must be fixed first, which triggers a new reconciliation
+		r.recorder.Eventf(&kb, corev1.EventTypeWarning, events.EventReconciliationError, "Invalid association clone target: %v", err)
+		return reconcile.Result{}, nil
+	}
+
+	expected, err := Clone(kb, target, r.scheme)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	var existing kbv1.Kibana
+	err = r.Get(k8s.ExtractNamespacedName(&expected), &existing)
+	switch {
+	case err == nil:
+		if !metav1.IsControlledBy(&existing, &kb) {
+			r.recorder.Eventf(&kb, corev1.EventTypeWarning, events.EventReconciliationError,
+				"Kibana %s already exists and is not a clone of %s", existing.Name, kb.Name)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, r.updateClone(existing, expected)
+	case apierrors.IsNotFound(err):
+		log.Info("Creating association clone", "namespace", expected.Namespace, "kibana_name", expected.Name,
+			"source_name", kb.Name, "es_namespace", target.Namespace, "es_name", target.Name)
+		return reconcile.Result{}, r.Create(&expected)
+	default:
+		return reconcile.Result{}, err
+	}
+}
+
+// updateClone updates the existing clone with the expected spec, labels and cloned annotations, preserving the
+// annotations maintained on the clone itself such as its association configuration.
+func (r *ReconcileClones) updateClone(existing, expected kbv1.Kibana) error {
+	annotations := make(map[string]string, len(expected.Annotations))
+	for k, v := range existing.Annotations {
+		if uncloned[k] {
+			annotations[k] = v
+		}
+	}
+	for k, v := range expected.Annotations {
+		annotations[k] = v
+	}
+	if reflect.DeepEqual(existing.Spec, expected.Spec) &&
+		reflect.DeepEqual(existing.Labels, expected.Labels) &&
+		reflect.DeepEqual(existing.Annotations, annotations) {
+		return nil
+	}
+	log.Info("Updating association clone", "namespace", existing.Namespace, "kibana_name", existing.Name)
+	existing.Spec = expected.Spec
+	existing.Labels = expected.Labels
+	existing.Annotations = annotations
+	return r.Update(&existing)
+}
+
+// deleteClone deletes the clone of the given Kibana resource, if any.
+func (r *ReconcileClones) deleteClone(kb kbv1.Kibana) error {
+	var clone kbv1.Kibana
+	if err := r.Get(types.NamespacedName{Namespace: kb.Namespace, Name: CloneName(kb.Name)}, &clone); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !metav1.IsControlledBy(&clone, &kb) {
+		return nil
+	}
+	log.Info("Deleting association clone", "namespace", clone.Namespace, "kibana_name", clone.Name)
+	if err := r.Delete(&clone); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// CloneName returns the name of the clone of the Kibana resource with the given name.
+func CloneName(kibanaName string) string {
+	return kibanaName + cloneSuffix
+}
+
+// Clone returns a clone of the given Kibana resource referencing the given Elasticsearch cluster instead. The clone has
+// the same spec, labels and annotations, except for the ones describing the association of the source Kibana resource,
+// so that its own association is established from scratch. It lives in the same namespace and is controlled by the
+// source Kibana resource.
+func Clone(source kbv1.Kibana, target commonv1.ObjectSelector, scheme *runtime.Scheme) (kbv1.Kibana, error) {
+	clone := kbv1.Kibana{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: source.Namespace,
+			Name:      CloneName(source.Name),
+		},
+		Spec: *source.Spec.DeepCopy(),
+	}
+	clone.Spec.ElasticsearchRef = target
+	if len(source.Labels) > 0 {
+		clone.Labels = make(map[string]string, len(source.Labels))
+		for k, v := range source.Labels {
+			clone.Labels[k] = v
+		}
+	}
+	for k, v := range source.Annotations {
+		if uncloned[k] {
+			continue
+		}
+		if clone.Annotations == nil {
+			clone.Annotations = make(map[string]string)
+		}
+		clone.Annotations[k] = v
+	}
+	if err := reconciler.SetControllerReference(&source, &clone, scheme); err != nil {
+		return kbv1.Kibana{}, err
+	}
+	return clone, nil
+}
+
+// parseElasticsearchRef parses an Elasticsearch reference in the <namespace>/<name> or <name> format.
+func parseElasticsearchRef(value string) (commonv1.ObjectSelector, error) {
+	parts := strings.Split(value, "/")
+	for _, part := range parts {
+		if part == "" {
+			return commonv1.ObjectSelector{}, fmt.Errorf("invalid Elasticsearch reference %q, expected <namespace>/<name> or <name>", value)
+		}
+	}
+	switch len(parts) {
+	case 1:
+		return commonv1.ObjectSelector{Name: parts[0]}, nil
+	case 2:
+		return commonv1.ObjectSelector{Namespace: parts[0], Name: parts[1]}, nil
+	default:
+		return commonv1.ObjectSelector{}, fmt.Errorf("invalid Elasticsearch reference %q, expected <namespace>/<name> or <name>", value)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package associationclone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func sourceKibana(cloneTo *string) *kbv1.Kibana {
+	kb := &kbv1.Kibana{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "kb",
+			UID:       "kb-uid",
+			Labels:    map[string]string{"team": "a"},
+			Annotations: map[string]string{
+				annotation.AssociationConfAnnotation: `{"url":"https://es-es-http.ns.svc:9200"}`,
+				annotation.CredentialsTTLAnnotation:  "24h",
+			},
+		},
+		Spec: kbv1.KibanaSpec{
+			Version:          "7.6.0",
+			Count:            1,
+			ElasticsearchRef: commonv1.ObjectSelector{Name: "es"},
+		},
+	}
+	if cloneTo != nil {
+		kb.Annotations[annotation.CloneToAnnotation] = *cloneTo
+	}
+	return kb
+}
+
+func TestClone(t *testing.T) {
+	target := commonv1.ObjectSelector{Namespace: "standby", Name: "es"}
+	clone, err := Clone(*sourceKibana(nil), target, k8s.Scheme())
+	require.NoError(t, err)
+	require.Equal(t, types.NamespacedName{Namespace: "ns", Name: "kb-clone"}, k8s.ExtractNamespacedName(&clone))
+	require.Equal(t, target, clone.Spec.ElasticsearchRef)
+	require.Equal(t, "7.6.0", clone.Spec.Version)
+	require.Equal(t, map[string]string{"team": "a"}, clone.Labels)
+	// the association configuration of the source is not cloned
+	require.Equal(t, map[string]string{annotation.CredentialsTTLAnnotation: "24h"}, clone.Annotations)
+	require.True(t, metav1.IsControlledBy(&clone, sourceKibana(nil)))
+}
+
+func Test_parseElasticsearchRef(t *testing.T) {
+	ref, err := parseElasticsearchRef("es")
+	require.NoError(t, err)
+	require.Equal(t, commonv1.ObjectSelector{Name: "es"}, ref)
+	ref, err = parseElasticsearchRef("standby/es")
+	require.NoError(t, err)
+	require.Equal(t, commonv1.ObjectSelector{Namespace: "standby", Name: "es"}, ref)
+	for _, invalid := range []string{"", "standby/", "a/b/c"} {
+		_, err = parseElasticsearchRef(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestReconcileClones_Reconcile(t *testing.T) {
+	standby := "standby/es"
+	invalid := "a/b/c"
+	expectedClone := func() *kbv1.Kibana {
+		clone, err := Clone(*sourceKibana(&standby), commonv1.ObjectSelector{Namespace: "standby", Name: "es"}, k8s.Scheme())
+		require.NoError(t, err)
+		return &clone
+	}
+	outdatedClone := expectedClone()
+	outdatedClone.Spec.Version = "7.5.0"
+	// maintained by the association controller of the clone
+	outdatedClone.Annotations[annotation.AssociationConfAnnotation] = `{"url":"https://es-es-http.standby.svc:9200"}`
+	unrelated := &kbv1.Kibana{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "kb-clone"}, Spec: kbv1.KibanaSpec{Version: "7.4.0"}}
+	tests := []struct {
+		name      string
+		objs      []runtime.Object
+		wantClone *kbv1.KibanaSpec
+		// wantAnnotations are annotations expected on the clone
+		wantAnnotations map[string]string
+		wantEvent       bool
+	}{
+		{
+			name: "Kibana does not exist",
+		},
+		{
+			name: "no clone annotation",
+			objs: []runtime.Object{sourceKibana(nil)},
+		},
+		{
+			name:            "clone is created",
+			objs:            []runtime.Object{sourceKibana(&standby)},
+			wantClone:       &expectedClone().Spec,
+			wantAnnotations: map[string]string{annotation.CredentialsTTLAnnotation: "24h"},
+		},
+		{
+			name:      "invalid clone target",
+			objs:      []runtime.Object{sourceKibana(&invalid)},
+			wantEvent: true,
+		},
+		{
+			name:      "clone is updated, preserving its association configuration",
+			objs:      []runtime.Object{sourceKibana(&standby), outdatedClone},
+			wantClone: &expectedClone().Spec,
+			wantAnnotations: map[string]string{
+				annotation.CredentialsTTLAnnotation:  "24h",
+				annotation.AssociationConfAnnotation: `{"url":"https://es-es-http.standby.svc:9200"}`,
+			},
+		},
+		{
+			name: "clone is deleted once the annotation is removed",
+			objs: []runtime.Object{sourceKibana(nil), expectedClone()},
+		},
+		{
+			name:      "existing Kibana which is not a clone is preserved",
+			objs:      []runtime.Object{sourceKibana(&standby), unrelated},
+			wantClone: &unrelated.Spec,
+			wantEvent: true,
+		},
+		{
+			name:      "existing Kibana which is not a clone is not deleted",
+			objs:      []runtime.Object{sourceKibana(nil), unrelated},
+			wantClone: &unrelated.Spec,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			c := k8s.WrappedFakeClient(tt.objs...)
+			r := &ReconcileClones{Client: c, scheme: k8s.Scheme(), recorder: recorder}
+			_, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "kb"}})
+			require.NoError(t, err)
+
+			var clone kbv1.Kibana
+			err = c.Get(types.NamespacedName{Namespace: "ns", Name: "kb-clone"}, &clone)
+			if tt.wantClone == nil {
+				require.True(t, apierrors.IsNotFound(err))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, *tt.wantClone, clone.Spec)
+				for k, v := range tt.wantAnnotations {
+					require.Equal(t, v, clone.Annotations[k])
+				}
+			}
+			require.Equal(t, tt.wantEvent, len(recorder.Events) > 0)
+		})
+	}
+}
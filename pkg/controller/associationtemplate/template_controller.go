@@ -0,0 +1,157 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package associationtemplate
+
+import (
+	"github.com/ghodss/yaml"
+	pkgerrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// Association template controller
+//
+// This controller creates the Kibana resource described by the template annotation of an Elasticsearch resource,
+// associated with that Elasticsearch cluster. The Kibana resource is owned by the Elasticsearch resource, so it is
+// garbage collected along with it.
+//
+// The Kibana resource is only created if it does not exist: it can be modified afterwards, changes to the template are
+// not propagated to it.
+
+const name = "association-template-controller"
+
+var log = logf.Log.WithName(name)
+
+// Add creates a new association template controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, params operator.Parameters) error {
+	return add(mgr, newReconciler(mgr, params))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileTemplates {
+	return &ReconcileTemplates{
+		Client:     k8s.WrapClient(mgr.GetClient()),
+		scheme:     mgr.GetScheme(),
+		recorder:   mgr.GetEventRecorderFor(name),
+		Parameters: params,
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileTemplates) error {
+	c, err := controller.New(name, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// watch Elasticsearch resources
+	if err := c.Watch(&source.Kind{Type: &esv1.Elasticsearch{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	// watch the Kibana resources created from templates, to recreate them if they are deleted
+	return c.Watch(&source.Kind{Type: &kbv1.Kibana{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &esv1.Elasticsearch{},
+		IsController: true,
+	})
+}
+
+var _ reconcile.Reconciler = &ReconcileTemplates{}
+
+// ReconcileTemplates creates the resources described by the association templates of Elasticsearch resources.
+type ReconcileTemplates struct {
+	k8s.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	operator.Parameters
+	// iteration is the number of times this controller has run its Reconcile method
+	iteration uint64
+}
+
+// Reconcile creates the Kibana resource described by the template annotation of an Elasticsearch resource, if any.
+func (r *ReconcileTemplates) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	defer common.LogReconciliationRun(log, request, "es_name", &r.iteration)()
+
+	var es esv1.Elasticsearch
+	if err := r.Get(request.NamespacedName, &es); err != nil {
+		if apierrors.IsNotFound(err) {
+			// resources created from the template are garbage collected
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if common.IsPaused(es.ObjectMeta) {
+		log.Info("Object is paused. Skipping reconciliation", "namespace", es.Namespace, "es_name", es.Name)
+		return common.PauseRequeueFor(es.ObjectMeta), nil
+	}
+
+	template, exists := es.Annotations[annotation.KibanaTemplateAnnotation]
+	if !exists || !es.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	expected, err := kibanaFromTemplate(es, template, r.scheme)
+	if err != nil {
+		// the template must be fixed first, which triggers a new reconciliation
+		r.recorder.Eventf(&es, corev1.EventTypeWarning, events.EventReconciliationError, "Invalid Kibana template: %v", err)
+		return reconcile.Result{}, nil
+	}
+
+	var existing kbv1.Kibana
+	err = r.Get(k8s.ExtractNamespacedName(&expected), &existing)
+	switch {
+	case err == nil:
+		if !metav1.IsControlledBy(&existing, &es) {
+			r.recorder.Eventf(&es, corev1.EventTypeWarning, events.EventReconciliationError,
+				"Kibana %s already exists and was not created from the template", existing.Name)
+		}
+		return reconcile.Result{}, nil
+	case apierrors.IsNotFound(err):
+		log.Info("Creating Kibana from template", "namespace", expected.Namespace, "kibana_name", expected.Name, "es_name", es.Name)
+		return reconcile.Result{}, r.Create(&expected)
+	default:
+		return reconcile.Result{}, err
+	}
+}
+
+// kibanaFromTemplate returns the Kibana resource described by the given template for the given Elasticsearch resource.
+// It has the same name and namespace as the Elasticsearch resource, references it and is controlled by it.
+func kibanaFromTemplate(es esv1.Elasticsearch, template string, scheme *runtime.Scheme) (kbv1.Kibana, error) {
+	var spec kbv1.KibanaSpec
+	if err := yaml.Unmarshal([]byte(template), &spec); err != nil {
+		return kbv1.Kibana{}, pkgerrors.Wrap(err, "failed to parse the Kibana specification")
+	}
+	spec.ElasticsearchRef = commonv1.ObjectSelector{Name: es.Name}
+	kb := kbv1.Kibana{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: es.Namespace,
+			Name:      es.Name,
+		},
+		Spec: spec,
+	}
+	if err := reconciler.SetControllerReference(&es, &kb, scheme); err != nil {
+		return kbv1.Kibana{}, err
+	}
+	return kb, nil
+}
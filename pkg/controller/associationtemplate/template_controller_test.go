@@ -0,0 +1,103 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package associationtemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const kibanaTemplate = `
+version: 7.6.0
+count: 1
+elasticsearchRef:
+  name: ignored
+`
+
+func esWithTemplate(template *string) *esv1.Elasticsearch {
+	es := &esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es", UID: "es-uid"},
+	}
+	if template != nil {
+		es.Annotations = map[string]string{annotation.KibanaTemplateAnnotation: *template}
+	}
+	return es
+}
+
+func TestReconcileTemplates_Reconcile(t *testing.T) {
+	template := kibanaTemplate
+	invalidTemplate := "count: not-a-number"
+	existingKibana := &kbv1.Kibana{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}, Spec: kbv1.KibanaSpec{Version: "7.5.0"}}
+	tests := []struct {
+		name        string
+		objs        []runtime.Object
+		wantKibana  *kbv1.KibanaSpec
+		wantControl bool
+		wantEvent   bool
+	}{
+		{
+			name: "Elasticsearch does not exist",
+		},
+		{
+			name: "no template",
+			objs: []runtime.Object{esWithTemplate(nil)},
+		},
+		{
+			name: "Kibana is created from the template",
+			objs: []runtime.Object{esWithTemplate(&template)},
+			wantKibana: &kbv1.KibanaSpec{
+				Version:          "7.6.0",
+				Count:            1,
+				ElasticsearchRef: commonv1.ObjectSelector{Name: "es"},
+			},
+			wantControl: true,
+		},
+		{
+			name:      "invalid template",
+			objs:      []runtime.Object{esWithTemplate(&invalidTemplate)},
+			wantEvent: true,
+		},
+		{
+			name:       "existing Kibana not created from the template is preserved",
+			objs:       []runtime.Object{esWithTemplate(&template), existingKibana},
+			wantKibana: &existingKibana.Spec,
+			wantEvent:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			c := k8s.WrappedFakeClient(tt.objs...)
+			r := &ReconcileTemplates{Client: c, scheme: k8s.Scheme(), recorder: recorder}
+			key := types.NamespacedName{Namespace: "ns", Name: "es"}
+			_, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+			require.NoError(t, err)
+
+			var kb kbv1.Kibana
+			err = c.Get(key, &kb)
+			if tt.wantKibana == nil {
+				require.True(t, apierrors.IsNotFound(err))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, *tt.wantKibana, kb.Spec)
+				require.Equal(t, tt.wantControl, metav1.IsControlledBy(&kb, esWithTemplate(nil)))
+			}
+			require.Equal(t, tt.wantEvent, len(recorder.Events) > 0)
+		})
+	}
+}
@@ -5,7 +5,10 @@
 package annotation
 
 import (
+	"strconv"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 )
@@ -17,6 +20,79 @@ const (
 	PrevAssocStatusAnnotation = "association.k8s.elastic.co/previous-status"
 	// AssociationConfAnnotation is the annotation used to define the config for associated Elasticsearch cluster.
 	AssociationConfAnnotation = "association.k8s.elastic.co/es-conf"
+	// ElasticsearchAliasAnnotation references the associated Elasticsearch cluster through an alias, resolved by the
+	// association controller, rather than through its name and namespace.
+	ElasticsearchAliasAnnotation = "association.k8s.elastic.co/es-alias"
+	// DependsOnAnnotation lists the associations that must be established before the association of the annotated
+	// resource is established, as a comma-separated list of <kind>/<namespace>/<name> or <kind>/<name>.
+	DependsOnAnnotation = "association.k8s.elastic.co/depends-on"
+	// CredentialsKeysAnnotation maps the username and password of an association to additional keys of the credentials
+	// secret created in the namespace of the annotated resource, as a comma-separated list of username=<key>,password=<key>.
+	CredentialsKeysAnnotation = "association.k8s.elastic.co/credentials-keys"
+	// ForwardReferenceAnnotation marks an association as deliberately referencing an Elasticsearch cluster that may not
+	// exist yet, in which case the association controller requeues less frequently while waiting for its creation.
+	ForwardReferenceAnnotation = "association.k8s.elastic.co/forward-reference"
+	// ElasticsearchURLOverrideAnnotation temporarily overrides the URL used by the annotated resource to reach the
+	// associated Elasticsearch cluster, for instance to target a specific node while troubleshooting.
+	ElasticsearchURLOverrideAnnotation = "association.k8s.elastic.co/es-url-override"
+	// ElasticsearchCAOverrideAnnotation names a secret in the namespace of the annotated resource whose ca.crt is
+	// trusted instead of the Elasticsearch CA, for instance when Elasticsearch is reached through a gateway.
+	ElasticsearchCAOverrideAnnotation = "association.k8s.elastic.co/es-ca-secret"
+	// ElasticsearchSSLVerificationModeAnnotation sets how the annotated resource verifies the certificate presented by
+	// the associated Elasticsearch cluster: full, certificate or none.
+	ElasticsearchSSLVerificationModeAnnotation = "association.k8s.elastic.co/es-ssl-verification-mode"
+	// ElasticsearchSSLInsecureAnnotation must be set to "true" along with the none SSL verification mode, to acknowledge
+	// that the certificate presented by Elasticsearch is not verified.
+	ElasticsearchSSLInsecureAnnotation = "association.k8s.elastic.co/es-ssl-insecure"
+	// PausedUntilAnnotation pauses the association of the annotated resource until the given RFC3339 timestamp,
+	// after which the association is reconciled again.
+	PausedUntilAnnotation = "association.k8s.elastic.co/paused-until"
+	// KibanaTemplateAnnotation holds, on an Elasticsearch resource, the specification in JSON or YAML of a Kibana
+	// resource to create along with the Elasticsearch cluster and associate with it.
+	KibanaTemplateAnnotation = "association.k8s.elastic.co/kibana-template"
+	// ElasticsearchUIDAnnotation records the UID of the Elasticsearch cluster the association was last established with.
+	ElasticsearchUIDAnnotation = "association.k8s.elastic.co/es-uid"
+	// CASecretResourceVersionAnnotation records the resource version of the Elasticsearch CA secret copy the association
+	// was last established with.
+	CASecretResourceVersionAnnotation = "association.k8s.elastic.co/ca-secret-resource-version"
+	// ProvenanceUpdatedAtAnnotation records, in RFC3339 format, when the association provenance annotations were last
+	// updated, which is when the association was established with another Elasticsearch cluster or CA secret copy.
+	ProvenanceUpdatedAtAnnotation = "association.k8s.elastic.co/provenance-updated-at"
+	// CredentialsTTLAnnotation sets, as a Go duration, how long the credentials of the association of the annotated
+	// resource are used for before being rotated. Credentials are not rotated if not set.
+	CredentialsTTLAnnotation = "association.k8s.elastic.co/credentials-ttl"
+	// CredentialsRotatedAtAnnotation records, in RFC3339 format, when the password of a credentials secret was last
+	// rotated.
+	CredentialsRotatedAtAnnotation = "association.k8s.elastic.co/credentials-rotated-at"
+	// DryRunAnnotation puts the association of the annotated resource in dry-run mode when set to "true": the change
+	// to its configuration is reported in its status instead of being applied.
+	DryRunAnnotation = "association.k8s.elastic.co/dry-run"
+	// ConfOwnerAnnotation designates who manages the Elasticsearch configuration of the annotated resource: the
+	// association controller (operator, the default) or the user (manual). Changing it transfers the ownership.
+	ConfOwnerAnnotation = "association.k8s.elastic.co/es-conf-owner"
+	// CARotationAnnotation enables, when set to "orchestrated", the orchestrated rotation of the Elasticsearch CA
+	// trusted by the annotated resource: a new CA is trusted along with the previous one until the annotated resource
+	// has been restarted with both, then the previous one is pruned.
+	CARotationAnnotation = "association.k8s.elastic.co/ca-rotation"
+	// CARotationStagedAtAnnotation records, in RFC3339 format, on the copy of the Elasticsearch CA when the bundle of
+	// the new and previous CAs was staged.
+	CARotationStagedAtAnnotation = "association.k8s.elastic.co/ca-rotation-staged-at"
+	// RestartWindowAnnotation defers the changes to the Elasticsearch configuration of the annotated resource that
+	// restart it until a daily maintenance window, in UTC, in the HH:MM-HH:MM format. Changes of the Elasticsearch CA and
+	// rotations of the credentials are never deferred.
+	RestartWindowAnnotation = "association.k8s.elastic.co/restart-window"
+	// RestartBatchAnnotation defers the changes to the Elasticsearch configuration of the annotated resource that
+	// restart it until the given number of distinct changes accumulated, to apply them at once. Changes of the
+	// Elasticsearch CA and rotations of the credentials are never deferred.
+	RestartBatchAnnotation = "association.k8s.elastic.co/restart-batch"
+	// CloneToAnnotation clones the association of the annotated Kibana resource with the Elasticsearch cluster it holds
+	// the reference of, as <namespace>/<name> or <name>: a copy of the Kibana resource referencing that cluster instead
+	// is created and establishes its own association, for instance with a standby cluster.
+	CloneToAnnotation = "association.k8s.elastic.co/clone-to"
+	// IterationAnnotation records, on an association event, the iteration of the reconciliation that emitted it.
+	IterationAnnotation = "association.k8s.elastic.co/iteration"
+	// DependencyAnnotation records, on an association event, the namespaced name of the dependency the event is about.
+	DependencyAnnotation = "association.k8s.elastic.co/dependency"
 )
 
 // ForAssociationStatusChange constructs the annotation map for an association status change event.
@@ -27,6 +103,15 @@ func ForAssociationStatusChange(prevStatus, currStatus commonv1.AssociationStatu
 	}
 }
 
+// ForAssociationTransition constructs the annotation map for an association transition event involving the given
+// dependency, emitted during the given reconciliation iteration.
+func ForAssociationTransition(iteration uint64, dependency types.NamespacedName) map[string]string {
+	return map[string]string{
+		IterationAnnotation:  strconv.FormatUint(iteration, 10),
+		DependencyAnnotation: dependency.String(),
+	}
+}
+
 // ExtractAssociationStatus extracts the association status values from the provided meta object.
 func ExtractAssociationStatus(obj metav1.ObjectMeta) (prevStatus, currStatus commonv1.AssociationStatus) {
 	if obj.Annotations == nil {
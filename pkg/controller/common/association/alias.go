@@ -0,0 +1,83 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+)
+
+// AliasesConfigMapName is the name of the ConfigMap, in the operator namespace, holding the mapping between
+// Elasticsearch aliases and the Elasticsearch clusters they point to.
+// Each key is an alias, each value is the reference of an Elasticsearch cluster as <namespace>/<name>, or <name> to
+// reference a cluster in the namespace of the associated resource.
+const AliasesConfigMapName = "elastic-association-aliases"
+
+// AliasNotResolvedError is returned when the Elasticsearch alias of an associated resource cannot be resolved.
+type AliasNotResolvedError struct {
+	Alias string
+}
+
+func (e *AliasNotResolvedError) Error() string {
+	return fmt.Sprintf("Elasticsearch alias %s is not defined in %s", e.Alias, AliasesConfigMapName)
+}
+
+// IsAliasNotResolved returns true if the error is an AliasNotResolvedError.
+func IsAliasNotResolved(err error) bool {
+	_, ok := err.(*AliasNotResolvedError)
+	return ok
+}
+
+// ElasticsearchAlias returns the Elasticsearch alias the associated resource is annotated with, if any.
+func ElasticsearchAlias(associated commonv1.Associated) string {
+	return associated.GetAnnotations()[annotation.ElasticsearchAliasAnnotation]
+}
+
+// ElasticsearchRefKey returns the namespaced name of the Elasticsearch cluster referenced by the associated resource.
+// If the associated resource is annotated with an Elasticsearch alias, it is resolved using the aliases ConfigMap
+// in the operator namespace and takes precedence over the Elasticsearch reference of the spec. The operator namespace
+// may not be cached: the aliases ConfigMap is read through aliasesReader, which should read from the API server.
+func ElasticsearchRefKey(aliasesReader client.Reader, associated commonv1.Associated, operatorNamespace string) (types.NamespacedName, error) {
+	alias := ElasticsearchAlias(associated)
+	if alias == "" {
+		esRef := associated.ElasticsearchRef()
+		if esRef.Namespace == "" {
+			// no namespace provided: default to the namespace of the associated resource
+			esRef.Namespace = associated.GetNamespace()
+		}
+		return esRef.NamespacedName(), nil
+	}
+
+	var aliases corev1.ConfigMap
+	err := aliasesReader.Get(context.Background(), types.NamespacedName{Namespace: operatorNamespace, Name: AliasesConfigMapName}, &aliases)
+	if apierrors.IsNotFound(err) {
+		return types.NamespacedName{}, &AliasNotResolvedError{Alias: alias}
+	}
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	target, exists := aliases.Data[alias]
+	if !exists || strings.TrimSpace(target) == "" {
+		return types.NamespacedName{}, &AliasNotResolvedError{Alias: alias}
+	}
+	return parseAliasTarget(strings.TrimSpace(target), associated.GetNamespace()), nil
+}
+
+// parseAliasTarget parses an alias target formatted as <namespace>/<name> or <name>.
+func parseAliasTarget(target string, defaultNamespace string) types.NamespacedName {
+	if i := strings.Index(target, "/"); i >= 0 {
+		return types.NamespacedName{Namespace: target[:i], Name: target[i+1:]}
+	}
+	return types.NamespacedName{Namespace: defaultNamespace, Name: target}
+}
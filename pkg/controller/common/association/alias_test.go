@@ -0,0 +1,91 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestElasticsearchRefKey(t *testing.T) {
+	operatorNs := "elastic-system"
+	aliases := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorNs, Name: AliasesConfigMapName},
+		Data: map[string]string{
+			"prod":  "ns2/es-prod",
+			"local": "es-local",
+		},
+	}
+	withAlias := func(alias string) kbv1.Kibana {
+		kb := *kibanaFixture.DeepCopy()
+		kb.Annotations = map[string]string{annotation.ElasticsearchAliasAnnotation: alias}
+		return kb
+	}
+	tests := []struct {
+		name        string
+		kibana      kbv1.Kibana
+		runtimeObjs []runtime.Object
+		want        types.NamespacedName
+		wantErr     func(err error) bool
+	}{
+		{
+			name:   "no alias: use the spec reference",
+			kibana: kibanaFixture,
+			want:   types.NamespacedName{Namespace: "default", Name: "es-foo"},
+		},
+		{
+			name: "no alias, no namespace in the spec reference: default to the associated resource namespace",
+			kibana: kbv1.Kibana{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "kb"},
+				Spec:       kbv1.KibanaSpec{ElasticsearchRef: commonv1.ObjectSelector{Name: "es"}},
+			},
+			want: types.NamespacedName{Namespace: "ns1", Name: "es"},
+		},
+		{
+			name:        "alias with a namespace",
+			kibana:      withAlias("prod"),
+			runtimeObjs: []runtime.Object{aliases},
+			want:        types.NamespacedName{Namespace: "ns2", Name: "es-prod"},
+		},
+		{
+			name:        "alias without a namespace: default to the associated resource namespace",
+			kibana:      withAlias("local"),
+			runtimeObjs: []runtime.Object{aliases},
+			want:        types.NamespacedName{Namespace: "default", Name: "es-local"},
+		},
+		{
+			name:        "unknown alias",
+			kibana:      withAlias("unknown"),
+			runtimeObjs: []runtime.Object{aliases},
+			wantErr:     IsAliasNotResolved,
+		},
+		{
+			name:    "aliases ConfigMap does not exist",
+			kibana:  withAlias("prod"),
+			wantErr: IsAliasNotResolved,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ElasticsearchRefKey(k8s.FakeClient(tt.runtimeObjs...), &tt.kibana, operatorNs)
+			if tt.wantErr != nil {
+				require.True(t, tt.wantErr(err), "unexpected error: %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -24,7 +23,7 @@ func ElasticsearchAuthSettings(
 		return "", "", nil
 	}
 
-	secretObjKey := types.NamespacedName{Namespace: associated.GetNamespace(), Name: assocConf.AuthSecretName}
+	secretObjKey := assocConf.AuthSecretRef(associated.GetNamespace())
 	var secret v1.Secret
 	if err := c.Get(secretObjKey, &secret); err != nil {
 		return "", "", err
@@ -0,0 +1,98 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+// AuditRecord describes the inputs and outputs of an association reconciliation.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Kind, Namespace and Name identify the associated resource.
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Dependencies are the objects the association depends on, with the resource versions they were resolved at.
+	Dependencies []commonv1.AssociationDependency `json:"dependencies,omitempty"`
+	// Status and Message are the resulting association status.
+	Status  commonv1.AssociationStatus `json:"status"`
+	Message string                     `json:"message,omitempty"`
+	// Updated is true if the associated resource was modified by the reconciliation.
+	Updated bool `json:"updated"`
+	// Error is the reconciliation error, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditLogger writes audit records as JSON lines to a sink, separately from the operator logs.
+type AuditLogger struct {
+	mutex  sync.Mutex
+	sink   io.Writer
+	closed bool
+}
+
+// NewAuditLogger returns an AuditLogger writing to the given sink.
+func NewAuditLogger(sink io.Writer) *AuditLogger {
+	return &AuditLogger{sink: sink}
+}
+
+// NewFileAuditLogger returns an AuditLogger appending to the file at the given path, which is created if needed.
+func NewFileAuditLogger(path string) (*AuditLogger, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuditLogger(file), nil
+}
+
+// Log writes the given record. Errors are logged rather than returned so that audit failures do not impact the
+// reconciliation.
+func (a *AuditLogger) Log(record AuditRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		log.Error(err, "Failed to serialize association audit record", "namespace", record.Namespace, "name", record.Name)
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.closed {
+		log.V(1).Info("Association audit logger closed, dropping record", "namespace", record.Namespace, "name", record.Name)
+		return
+	}
+	if _, err := a.sink.Write(append(line, '\n')); err != nil {
+		log.Error(err, "Failed to write association audit record", "namespace", record.Namespace, "name", record.Name)
+	}
+}
+
+// Close flushes the records written to a file sink to disk and closes it. Records logged afterwards are dropped.
+func (a *AuditLogger) Close() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	file, isFile := a.sink.(*os.File)
+	if !isFile {
+		return nil
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Start closes the audit logger once the stop channel is closed. It implements manager.Runnable.
+func (a *AuditLogger) Start(stop <-chan struct{}) error {
+	<-stop
+	return a.Close()
+}
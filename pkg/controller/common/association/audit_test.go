@@ -0,0 +1,99 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+func TestAuditLogger_Log(t *testing.T) {
+	now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	records := []AuditRecord{
+		{
+			Time: now, Kind: "Kibana", Namespace: "ns", Name: "kb",
+			Dependencies: []commonv1.AssociationDependency{{Kind: "Elasticsearch", Namespace: "ns", Name: "es", Exists: true, ResourceVersion: "1"}},
+			Status:       commonv1.AssociationEstablished,
+			Updated:      true,
+		},
+		{
+			Time: now, Kind: "Kibana", Namespace: "ns", Name: "kb",
+			Status: commonv1.AssociationPending,
+			Error:  "conflict",
+		},
+	}
+	var sink bytes.Buffer
+	logger := NewAuditLogger(&sink)
+	for _, record := range records {
+		logger.Log(record)
+	}
+
+	// one JSON record per line
+	scanner := bufio.NewScanner(&sink)
+	var logged []AuditRecord
+	for scanner.Scan() {
+		var record AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		logged = append(logged, record)
+	}
+	require.Equal(t, records, logged)
+}
+
+func TestNewFileAuditLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("existing\n"), 0600))
+
+	logger, err := NewFileAuditLogger(path)
+	require.NoError(t, err)
+	logger.Log(AuditRecord{Kind: "Kibana", Namespace: "ns", Name: "kb", Status: commonv1.AssociationEstablished})
+
+	// records are appended to the existing file
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+	require.Len(t, lines, 2)
+	require.Equal(t, "existing", string(lines[0]))
+	require.Contains(t, string(lines[1]), `"status":"Established"`)
+}
+
+func TestAuditLogger_Start(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	logger, err := NewFileAuditLogger(path)
+	require.NoError(t, err)
+	logger.Log(AuditRecord{Kind: "Kibana", Namespace: "ns", Name: "kb", Status: commonv1.AssociationEstablished})
+
+	// the file is closed when the manager stops
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- logger.Start(stop) }()
+	close(stop)
+	require.NoError(t, <-done)
+	// records logged afterwards are dropped
+	logger.Log(AuditRecord{Kind: "Kibana", Namespace: "ns", Name: "kb", Status: commonv1.AssociationFailed})
+	require.NoError(t, logger.Close())
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+	require.Len(t, lines, 1)
+	require.Contains(t, string(lines[0]), `"status":"Established"`)
+}
@@ -0,0 +1,63 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+// DefaultMaxPendingBackoff is the default maximum delay between two reconciliations of a pending association.
+const DefaultMaxPendingBackoff = 2 * time.Minute
+
+// PendingBackoff exponentially increases the delay between the reconciliations of associations which remain pending or
+// degraded, for instance while waiting for an Elasticsearch cluster to become available, up to a maximum delay.
+// Pending reconciliations are tracked in memory: the delay starts over if the operator restarts.
+type PendingBackoff struct {
+	mutex    sync.Mutex
+	max      time.Duration
+	attempts map[types.NamespacedName]uint
+}
+
+// NewPendingBackoff returns a PendingBackoff capping the delay to the given maximum.
+func NewPendingBackoff(max time.Duration) *PendingBackoff {
+	return &PendingBackoff{
+		max:      max,
+		attempts: make(map[types.NamespacedName]uint),
+	}
+}
+
+// Next returns the delay before the next reconciliation of the given association if it is pending or degraded: the base
+// delay, doubled on each consecutive such reconciliation up to the maximum delay, or the base delay if it is greater.
+// Any other status resets the delay and returns zero.
+func (b *PendingBackoff) Next(association types.NamespacedName, status commonv1.AssociationStatus, base time.Duration) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if status != commonv1.AssociationPending && status != commonv1.AssociationDegraded {
+		delete(b.attempts, association)
+		return 0
+	}
+	attempts := b.attempts[association]
+	b.attempts[association] = attempts + 1
+	delay := base
+	for i := uint(0); i < attempts && delay < b.max; i++ {
+		delay *= 2
+	}
+	if delay > b.max && base <= b.max {
+		return b.max
+	}
+	return delay
+}
+
+// Forget removes the pending reconciliations tracking state of the given association.
+func (b *PendingBackoff) Forget(association types.NamespacedName) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.attempts, association)
+}
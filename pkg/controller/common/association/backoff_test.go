@@ -0,0 +1,44 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+func TestPendingBackoff_Next(t *testing.T) {
+	assoc := types.NamespacedName{Namespace: "ns", Name: "kb"}
+	base := 10 * time.Second
+	b := NewPendingBackoff(DefaultMaxPendingBackoff)
+
+	// the delay doubles on each consecutive pending reconciliation, up to the maximum
+	for _, expected := range []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, 2 * time.Minute, 2 * time.Minute} {
+		require.Equal(t, expected, b.Next(assoc, commonv1.AssociationPending, base))
+	}
+	// other associations are tracked independently
+	other := types.NamespacedName{Namespace: "ns", Name: "other"}
+	require.Equal(t, base, b.Next(other, commonv1.AssociationPending, base))
+
+	// degraded associations are backed off as well
+	require.Equal(t, 2*time.Minute, b.Next(assoc, commonv1.AssociationDegraded, base))
+
+	// the association is established: the delay is reset
+	require.Equal(t, time.Duration(0), b.Next(assoc, commonv1.AssociationEstablished, base))
+	require.Equal(t, base, b.Next(assoc, commonv1.AssociationPending, base))
+
+	// forgotten associations start over
+	require.Equal(t, 2*base, b.Next(other, commonv1.AssociationPending, base))
+	b.Forget(other)
+	require.Equal(t, base, b.Next(other, commonv1.AssociationPending, base))
+
+	// a base delay greater than the maximum is not reduced
+	require.Equal(t, 5*time.Minute, b.Next(types.NamespacedName{Name: "slow"}, commonv1.AssociationPending, 5*time.Minute))
+}
@@ -5,6 +5,8 @@
 package association
 
 import (
+	"bytes"
+	"context"
 	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
@@ -12,9 +14,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
@@ -23,8 +27,9 @@ import (
 
 // CASecret is a container to hold information about the Elasticsearch CA secret.
 type CASecret struct {
-	Name           string
-	CACertProvided bool
+	Name            string
+	CACertProvided  bool
+	ResourceVersion string
 }
 
 // ElasticsearchCACertSecretName returns the name of the secret holding the certificate chain used
@@ -33,8 +38,28 @@ func ElasticsearchCACertSecretName(associated commonv1.Associated, suffix string
 	return associated.GetName() + "-" + suffix
 }
 
+// GlobalCA references a secret holding CA certificates trusted by all associated resources, in addition to the
+// Elasticsearch CA.
+type GlobalCA struct {
+	// Reader reads the secret directly from the API server, the operator namespace may not be cached.
+	Reader client.Reader
+	// Secret is the reference to the secret, with an empty name if there is no global CA.
+	Secret types.NamespacedName
+}
+
+// CAWatchedSecrets returns the secrets to watch in order to keep the copy of the Elasticsearch CA in sync.
+func CAWatchedSecrets(es types.NamespacedName, globalCA GlobalCA) []types.NamespacedName {
+	watched := []types.NamespacedName{http.PublicCertsSecretRef(esv1.ESNamer, es)}
+	if globalCA.Secret.Name != "" {
+		watched = append(watched, globalCA.Secret)
+	}
+	return watched
+}
+
 // ReconcileCASecret keeps in sync a copy of the Elasticsearch CA.
-// It is the responsibility of the controller to set a watch on the ES CA.
+// If globalCA references a secret, the CA certificates it contains are appended to the Elasticsearch CA so that
+// the associated resource also trusts them.
+// It is the responsibility of the controller to set a watch on the ES CA and on the global CA.
 func ReconcileCASecret(
 	client k8s.Client,
 	scheme *runtime.Scheme,
@@ -42,18 +67,30 @@ func ReconcileCASecret(
 	es types.NamespacedName,
 	labels map[string]string,
 	suffix string,
+	ownerRefMode OwnerRefMode,
+	globalCA GlobalCA,
 ) (CASecret, error) {
-	publicESHTTPCertificatesNSN := http.PublicCertsSecretRef(esv1.ESNamer, es)
-
-	// retrieve the HTTP certificates from ES namespace
-	var publicESHTTPCertificatesSecret corev1.Secret
-	if err := client.Get(publicESHTTPCertificatesNSN, &publicESHTTPCertificatesSecret); err != nil {
-		if errors.IsNotFound(err) {
-			return CASecret{}, nil // probably not created yet, we'll be notified to reconcile later
-		}
+	data, found, err := caSecretData(client, es, globalCA)
+	if err != nil || !found {
+		// the certificates are probably not created yet, we'll be notified to reconcile later
 		return CASecret{}, err
 	}
 
+	return reconcileCASecretCopy(client, scheme, associated, labels, suffix, ownerRefMode, data, "")
+}
+
+// reconcileCASecretCopy reconciles the copy of the Elasticsearch CA in the namespace of the associated resource with
+// the given data. The CA rotation staged-at annotation is set to the given value, or removed if empty.
+func reconcileCASecretCopy(
+	client k8s.Client,
+	scheme *runtime.Scheme,
+	associated commonv1.Associated,
+	labels map[string]string,
+	suffix string,
+	ownerRefMode OwnerRefMode,
+	data map[string][]byte,
+	stagedAt string,
+) (CASecret, error) {
 	// Certificate data should be copied over a secret in the associated namespace
 	expectedSecret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -61,25 +98,152 @@ func ReconcileCASecret(
 			Name:      ElasticsearchCACertSecretName(associated, suffix),
 			Labels:    labels,
 		},
-		Data: publicESHTTPCertificatesSecret.Data,
+		Data: data,
 	}
+	setCARotationStagedAt(&expectedSecret, stagedAt)
 	var reconciledSecret corev1.Secret
 	if err := reconciler.ReconcileResource(reconciler.Params{
 		Client:     client,
 		Scheme:     scheme,
-		Owner:      associated,
+		Owner:      ownerRefMode.Owner(associated),
 		Expected:   &expectedSecret,
 		Reconciled: &reconciledSecret,
 		NeedsUpdate: func() bool {
-			return !reflect.DeepEqual(expectedSecret.Data, reconciledSecret.Data)
+			return !reflect.DeepEqual(expectedSecret.Data, reconciledSecret.Data) ||
+				!hasExpectedLabels(&expectedSecret, &reconciledSecret) ||
+				reconciledSecret.Annotations[annotation.CARotationStagedAtAnnotation] != stagedAt
 		},
 		UpdateReconciled: func() {
+			setExpectedLabels(&expectedSecret, &reconciledSecret)
 			reconciledSecret.Data = expectedSecret.Data
+			setCARotationStagedAt(&reconciledSecret, stagedAt)
 		},
 	}); err != nil {
 		return CASecret{}, err
 	}
 
 	caCertProvided := len(expectedSecret.Data[certificates.CAFileName]) > 0
-	return CASecret{Name: expectedSecret.Name, CACertProvided: caCertProvided}, nil
+	return CASecret{
+		Name:            expectedSecret.Name,
+		CACertProvided:  caCertProvided,
+		ResourceVersion: reconciledSecret.ResourceVersion,
+	}, nil
+}
+
+// PlanCASecret returns the copy of the Elasticsearch CA ReconcileCASecret would maintain, without creating or updating
+// it. Its resource version is left empty.
+func PlanCASecret(
+	client k8s.Client,
+	associated commonv1.Associated,
+	es types.NamespacedName,
+	suffix string,
+	globalCA GlobalCA,
+) (CASecret, error) {
+	data, found, err := caSecretData(client, es, globalCA)
+	if err != nil || !found {
+		return CASecret{}, err
+	}
+	return CASecret{
+		Name:           ElasticsearchCACertSecretName(associated, suffix),
+		CACertProvided: len(data[certificates.CAFileName]) > 0,
+	}, nil
+}
+
+// CASecretChanged returns true if ReconcileCASecret or ReconcileRotatedCASecret would change the content of the copy of
+// the Elasticsearch CA, along with the Elasticsearch CA it would contain. A rotation staged to the current
+// Elasticsearch CA is not considered a change.
+func CASecretChanged(
+	client k8s.Client,
+	associated commonv1.Associated,
+	es types.NamespacedName,
+	suffix string,
+	globalCA GlobalCA,
+) (bool, []byte, error) {
+	data, found, err := caSecretData(client, es, globalCA)
+	if err != nil || !found {
+		return false, nil, err
+	}
+	var current corev1.Secret
+	key := types.NamespacedName{Namespace: associated.GetNamespace(), Name: ElasticsearchCACertSecretName(associated, suffix)}
+	if err := client.Get(key, &current); err != nil {
+		if errors.IsNotFound(err) {
+			return true, data[certificates.CAFileName], nil
+		}
+		return false, nil, err
+	}
+	expected := data
+	if previousCA, staged := current.Data[PreviousCAFileName]; staged && IsCARotationOrchestrated(associated) {
+		expected = withPreviousCA(data, previousCA)
+	}
+	return !reflect.DeepEqual(current.Data, expected), data[certificates.CAFileName], nil
+}
+
+// caSecretData returns the content of the copy of the Elasticsearch CA of the given cluster, and false if the
+// Elasticsearch HTTP certificates do not exist.
+func caSecretData(client k8s.Client, es types.NamespacedName, globalCA GlobalCA) (map[string][]byte, bool, error) {
+	// retrieve the HTTP certificates from ES namespace
+	var publicESHTTPCertificatesSecret corev1.Secret
+	if err := client.Get(http.PublicCertsSecretRef(esv1.ESNamer, es), &publicESHTTPCertificatesSecret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	data, err := withGlobalCA(httpCertificatesData(publicESHTTPCertificatesSecret.Data), globalCA)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// httpCertificatesData returns the HTTP CA and certificate from the given public HTTP certificates data. Elasticsearch
+// uses distinct CAs for the HTTP and transport layers: only the HTTP layer ones are relevant to associated resources,
+// any other key is ignored.
+func httpCertificatesData(data map[string][]byte) map[string][]byte {
+	selected := make(map[string][]byte, 2)
+	for _, key := range []string{certificates.CAFileName, certificates.CertFileName} {
+		if value, exists := data[key]; exists {
+			selected[key] = value
+		}
+	}
+	return selected
+}
+
+// CheckGlobalCA returns an error if the given global CA secret is configured but cannot be read, in which case the
+// associated resources only trust the Elasticsearch CA.
+func CheckGlobalCA(globalCA GlobalCA) error {
+	if globalCA.Secret.Name == "" {
+		return nil
+	}
+	return globalCA.Reader.Get(context.Background(), globalCA.Secret, &corev1.Secret{})
+}
+
+// withGlobalCA returns a copy of the given certificates data in which the CA certificates of the global CA secret,
+// if any, are appended to the CA certificates. The Elasticsearch CA alone is returned if the global CA secret cannot
+// be read, rather than failing all associations: this is reported through CheckGlobalCA.
+func withGlobalCA(data map[string][]byte, globalCA GlobalCA) (map[string][]byte, error) {
+	if globalCA.Secret.Name == "" {
+		return data, nil
+	}
+	var globalCASecret corev1.Secret
+	if err := globalCA.Reader.Get(context.Background(), globalCA.Secret, &globalCASecret); err != nil {
+		log.V(1).Info("Global CA secret unavailable, falling back to the Elasticsearch CA",
+			"namespace", globalCA.Secret.Namespace, "secret_name", globalCA.Secret.Name, "error", err.Error())
+		return data, nil
+	}
+	globalCACerts := globalCASecret.Data[certificates.CAFileName]
+	if len(globalCACerts) == 0 {
+		return data, nil
+	}
+
+	merged := make(map[string][]byte, len(data)+1)
+	for k, v := range data {
+		merged[k] = v
+	}
+	caCerts := append([]byte{}, data[certificates.CAFileName]...)
+	if len(caCerts) > 0 && !bytes.HasSuffix(caCerts, []byte("\n")) {
+		caCerts = append(caCerts, '\n')
+	}
+	merged[certificates.CAFileName] = append(caCerts, globalCACerts...)
+	return merged, nil
 }
@@ -0,0 +1,176 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"bytes"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const (
+	// CARotationOrchestrated is the value of the CA rotation annotation enabling the orchestrated CA rotation.
+	CARotationOrchestrated = "orchestrated"
+	// PreviousCAFileName holds, in the copy of the Elasticsearch CA, the CA being rotated out while a rotation is staged.
+	PreviousCAFileName = "previous-ca.crt"
+)
+
+// StagedCAInUseFunc returns true if the associated resource uses the CA bundle staged at the given time.
+type StagedCAInUseFunc func(stagedAt time.Time) (bool, error)
+
+// IsCARotationOrchestrated returns true if the given associated resource is annotated to orchestrate the rotation of
+// the Elasticsearch CA it trusts.
+func IsCARotationOrchestrated(associated commonv1.Associated) bool {
+	return associated.GetAnnotations()[annotation.CARotationAnnotation] == CARotationOrchestrated
+}
+
+// ReconcileRotatedCASecret keeps in sync a copy of the Elasticsearch CA like ReconcileCASecret, but orchestrates the
+// rotation of the CA rather than replacing it: when the Elasticsearch CA changes, a bundle of the new and previous CAs
+// is staged in the copy. The previous CA is pruned once inUse confirms the associated resource uses the staged bundle.
+// It returns whether a rotation is staged.
+func ReconcileRotatedCASecret(
+	client k8s.Client,
+	scheme *runtime.Scheme,
+	associated commonv1.Associated,
+	es types.NamespacedName,
+	labels map[string]string,
+	suffix string,
+	ownerRefMode OwnerRefMode,
+	globalCA GlobalCA,
+	inUse StagedCAInUseFunc,
+	now time.Time,
+) (CASecret, bool, error) {
+	data, found, err := caSecretData(client, es, globalCA)
+	if err != nil || !found {
+		return CASecret{}, false, err
+	}
+
+	var current corev1.Secret
+	key := types.NamespacedName{Namespace: associated.GetNamespace(), Name: ElasticsearchCACertSecretName(associated, suffix)}
+	if err := client.Get(key, &current); err != nil && !errors.IsNotFound(err) {
+		return CASecret{}, false, err
+	}
+
+	newCA := data[certificates.CAFileName]
+	currentCA := current.Data[certificates.CAFileName]
+	previousCA, staged := current.Data[PreviousCAFileName]
+	stagedAt, err := caRotationStagedAt(current)
+	if err != nil {
+		// restage the rotation, the previous CA is still trusted until the associated resource is restarted
+		log.Error(err, "Invalid CA rotation staged-at annotation, restaging the CA rotation",
+			"namespace", current.Namespace, "secret_name", current.Name)
+		stagedAt = time.Time{}
+	}
+
+	switch {
+	case staged && !stagedAt.IsZero() && bytes.Equal(currentCA, caBundle(newCA, previousCA)):
+		used, err := inUse(stagedAt)
+		if err != nil {
+			return CASecret{}, true, err
+		}
+		if !used {
+			// keep the staged bundle
+			caSecret, err := reconcileCASecretCopy(client, scheme, associated, labels, suffix, ownerRefMode,
+				withPreviousCA(data, previousCA), stagedAt.Format(time.RFC3339))
+			return caSecret, true, err
+		}
+		log.Info("Staged Elasticsearch CA in use, pruning the previous CA",
+			"namespace", current.Namespace, "secret_name", current.Name)
+	case staged:
+		// the Elasticsearch CA changed again or the staged-at annotation is invalid: stage it along with the CA that
+		// was trusted before the rotation started
+		return stageCARotation(client, scheme, associated, labels, suffix, ownerRefMode, data, previousCA, now)
+	case len(currentCA) > 0 && !bytes.Equal(currentCA, newCA):
+		return stageCARotation(client, scheme, associated, labels, suffix, ownerRefMode, data, currentCA, now)
+	}
+	caSecret, err := reconcileCASecretCopy(client, scheme, associated, labels, suffix, ownerRefMode, data, "")
+	return caSecret, false, err
+}
+
+// IsCARotationStaged returns true if a rotation is staged in the copy of the Elasticsearch CA of the given associated
+// resource.
+func IsCARotationStaged(client k8s.Client, associated commonv1.Associated, suffix string) (bool, error) {
+	var caCopy corev1.Secret
+	key := types.NamespacedName{Namespace: associated.GetNamespace(), Name: ElasticsearchCACertSecretName(associated, suffix)}
+	if err := client.Get(key, &caCopy); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	_, staged := caCopy.Data[PreviousCAFileName]
+	return staged, nil
+}
+
+// stageCARotation stages in the copy of the Elasticsearch CA a bundle of the CA in the given data and of the given
+// previous CA.
+func stageCARotation(
+	client k8s.Client,
+	scheme *runtime.Scheme,
+	associated commonv1.Associated,
+	labels map[string]string,
+	suffix string,
+	ownerRefMode OwnerRefMode,
+	data map[string][]byte,
+	previousCA []byte,
+	now time.Time,
+) (CASecret, bool, error) {
+	log.Info("Elasticsearch CA changed, staging the new CA along with the previous one",
+		"namespace", associated.GetNamespace(), "name", associated.GetName())
+	caSecret, err := reconcileCASecretCopy(client, scheme, associated, labels, suffix, ownerRefMode,
+		withPreviousCA(data, previousCA), now.UTC().Format(time.RFC3339))
+	return caSecret, true, err
+}
+
+// withPreviousCA returns a copy of the given CA data where the CA bundle also contains the given previous CA, which is
+// kept on its own as well to be pruned once the rotation is over.
+func withPreviousCA(data map[string][]byte, previousCA []byte) map[string][]byte {
+	staged := make(map[string][]byte, len(data)+1)
+	for k, v := range data {
+		staged[k] = v
+	}
+	staged[certificates.CAFileName] = caBundle(data[certificates.CAFileName], previousCA)
+	staged[PreviousCAFileName] = previousCA
+	return staged
+}
+
+// caBundle concatenates the given PEM encoded CAs.
+func caBundle(newCA, previousCA []byte) []byte {
+	bundle := append([]byte{}, newCA...)
+	if len(bundle) > 0 && !bytes.HasSuffix(bundle, []byte("\n")) {
+		bundle = append(bundle, '\n')
+	}
+	return append(bundle, previousCA...)
+}
+
+// caRotationStagedAt returns when the CA rotation was staged in the given copy of the Elasticsearch CA, zero if not set.
+func caRotationStagedAt(secret corev1.Secret) (time.Time, error) {
+	value, exists := secret.Annotations[annotation.CARotationStagedAtAnnotation]
+	if !exists {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// setCARotationStagedAt sets the CA rotation staged-at annotation of the given secret, or removes it if empty.
+func setCARotationStagedAt(secret *corev1.Secret, stagedAt string) {
+	if stagedAt == "" {
+		delete(secret.Annotations, annotation.CARotationStagedAtAnnotation)
+		return
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string)
+	}
+	secret.Annotations[annotation.CARotationStagedAtAnnotation] = stagedAt
+}
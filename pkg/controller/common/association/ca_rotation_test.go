@@ -0,0 +1,118 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileRotatedCASecret(t *testing.T) {
+	es := types.NamespacedName{Namespace: esFixture.Namespace, Name: esFixture.Name}
+	esCA := func(ca string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: es.Namespace,
+				Name:      certificates.PublicSecretName(esv1.ESNamer, es.Name, certificates.HTTPCAType),
+			},
+			Data: map[string][]byte{certificates.CAFileName: []byte(ca)},
+		}
+	}
+	copyKey := types.NamespacedName{
+		Namespace: kibanaFixture.Namespace,
+		Name:      ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
+	}
+	stagedAt := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	var inUseSince []time.Time
+	inUse := func(used bool) StagedCAInUseFunc {
+		return func(at time.Time) (bool, error) {
+			inUseSince = append(inUseSince, at)
+			return used, nil
+		}
+	}
+	reconcile := func(t *testing.T, c k8s.Client, used bool, now time.Time) (corev1.Secret, bool) {
+		_, staged, err := ReconcileRotatedCASecret(c, scheme.Scheme, &kibanaFixture, es, nil, ElasticsearchCASecretSuffix,
+			DefaultOwnerRefMode, GlobalCA{}, inUse(used), now)
+		require.NoError(t, err)
+		var caCopy corev1.Secret
+		require.NoError(t, c.Get(copyKey, &caCopy))
+		return caCopy, staged
+	}
+
+	// no copy yet: nothing to rotate
+	c := k8s.WrappedFakeClient(esCA("old"))
+	caCopy, staged := reconcile(t, c, false, stagedAt)
+	require.False(t, staged)
+	require.Equal(t, map[string][]byte{certificates.CAFileName: []byte("old")}, caCopy.Data)
+
+	// the Elasticsearch CA changes: the new CA is staged along with the old one
+	require.NoError(t, c.Update(esCA("new")))
+	caCopy, staged = reconcile(t, c, false, stagedAt)
+	require.True(t, staged)
+	require.Equal(t, "new\nold", string(caCopy.Data[certificates.CAFileName]))
+	require.Equal(t, "old", string(caCopy.Data[PreviousCAFileName]))
+	require.Equal(t, "2020-01-01T10:00:00Z", caCopy.Annotations[annotation.CARotationStagedAtAnnotation])
+
+	// the staged bundle is kept until it is in use
+	caCopy, staged = reconcile(t, c, false, stagedAt.Add(time.Minute))
+	require.True(t, staged)
+	require.Equal(t, "new\nold", string(caCopy.Data[certificates.CAFileName]))
+	require.Equal(t, []time.Time{stagedAt}, inUseSince)
+
+	// the Elasticsearch CA changes again before the end of the rotation: restaged with the CA trusted beforehand
+	require.NoError(t, c.Update(esCA("newer")))
+	restagedAt := stagedAt.Add(2 * time.Minute)
+	caCopy, staged = reconcile(t, c, false, restagedAt)
+	require.True(t, staged)
+	require.Equal(t, "newer\nold", string(caCopy.Data[certificates.CAFileName]))
+	require.Equal(t, "old", string(caCopy.Data[PreviousCAFileName]))
+	require.Equal(t, "2020-01-01T10:02:00Z", caCopy.Annotations[annotation.CARotationStagedAtAnnotation])
+
+	// the staged bundle is in use: the previous CA is pruned
+	caCopy, staged = reconcile(t, c, true, restagedAt.Add(time.Minute))
+	require.False(t, staged)
+	require.Equal(t, map[string][]byte{certificates.CAFileName: []byte("newer")}, caCopy.Data)
+	require.NotContains(t, caCopy.Annotations, annotation.CARotationStagedAtAnnotation)
+	require.Equal(t, restagedAt, inUseSince[len(inUseSince)-1])
+}
+
+func TestReconcileCASecret_abortsRotation(t *testing.T) {
+	es := types.NamespacedName{Namespace: esFixture.Namespace, Name: esFixture.Name}
+	esCA := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: es.Namespace,
+			Name:      certificates.PublicSecretName(esv1.ESNamer, es.Name, certificates.HTTPCAType),
+		},
+		Data: map[string][]byte{certificates.CAFileName: []byte("new")},
+	}
+	stagedCopy := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   kibanaFixture.Namespace,
+			Name:        ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
+			Annotations: map[string]string{annotation.CARotationStagedAtAnnotation: "2020-01-01T10:00:00Z"},
+		},
+		Data: map[string][]byte{certificates.CAFileName: []byte("new\nold"), PreviousCAFileName: []byte("old")},
+	}
+	c := k8s.WrappedFakeClient(&esCA, &stagedCopy)
+
+	// the rotation is not orchestrated anymore: the new CA replaces the staged bundle
+	_, err := ReconcileCASecret(c, scheme.Scheme, &kibanaFixture, es, nil, ElasticsearchCASecretSuffix, DefaultOwnerRefMode, GlobalCA{})
+	require.NoError(t, err)
+	var caCopy corev1.Secret
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(&stagedCopy), &caCopy))
+	require.Equal(t, map[string][]byte{certificates.CAFileName: []byte("new")}, caCopy.Data)
+	require.NotContains(t, caCopy.Annotations, annotation.CARotationStagedAtAnnotation)
+}
@@ -7,14 +7,17 @@ package association
 import (
 	"testing"
 
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 )
@@ -54,6 +57,25 @@ func TestReconcileAssociation_reconcileCASecret(t *testing.T) {
 			certificates.CAFileName:   []byte("updated-fake-ca-cert"),
 		},
 	}
+	// mock existing transport CA secret for ES
+	esTransportCA := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: es.Namespace,
+			Name:      certificates.PublicSecretName(esv1.ESNamer, es.Name, certificates.TransportCAType),
+		},
+		Data: map[string][]byte{
+			certificates.CAFileName: []byte("fake-transport-ca-cert"),
+		},
+	}
+	// mock existing HTTP CA secret for ES holding unrelated keys
+	esCAWithExtraKeys := corev1.Secret{
+		ObjectMeta: esCA.ObjectMeta,
+		Data: map[string][]byte{
+			certificates.CertFileName: []byte("fake-cert"),
+			certificates.CAFileName:   []byte("fake-ca-cert"),
+			"transport.ca.crt":        []byte("fake-transport-ca-cert"),
+		},
+	}
 	// mock existing ES CA secret for Kibana
 	kibanaEsCA := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -95,6 +117,30 @@ func TestReconcileAssociation_reconcileCASecret(t *testing.T) {
 			certificates.CAFileName:   {},
 		},
 	}
+	// mock global CA secret in the operator namespace
+	globalCA := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "elastic-system",
+			Name:      "corporate-ca",
+		},
+		Data: map[string][]byte{
+			certificates.CAFileName: []byte("corporate-ca-cert\n"),
+		},
+	}
+	kibanaEsCAWithGlobalCA := corev1.Secret{
+		ObjectMeta: kibanaEsCA.ObjectMeta,
+		Data: map[string][]byte{
+			certificates.CertFileName: []byte("fake-cert"),
+			certificates.CAFileName:   []byte("fake-ca-cert\ncorporate-ca-cert\n"),
+		},
+	}
+	kibanaEmptyEsCAWithGlobalCA := corev1.Secret{
+		ObjectMeta: kibanaEsCA.ObjectMeta,
+		Data: map[string][]byte{
+			certificates.CertFileName: []byte("fake-cert"),
+			certificates.CAFileName:   []byte("corporate-ca-cert\n"),
+		},
+	}
 	tests := []struct {
 		name               string
 		client             k8s.Client
@@ -103,6 +149,7 @@ func TestReconcileAssociation_reconcileCASecret(t *testing.T) {
 		want               string
 		wantCA             *corev1.Secret
 		wantCACertProvided bool
+		globalCA           GlobalCA
 	}{
 		{
 			name:               "create new CA in kibana namespace",
@@ -113,6 +160,24 @@ func TestReconcileAssociation_reconcileCASecret(t *testing.T) {
 			wantCA:             &kibanaEsCA,
 			wantCACertProvided: true,
 		},
+		{
+			name:               "only the HTTP CA is copied if ES also exposes a transport CA",
+			client:             k8s.WrappedFakeClient(&es, &esCA, &esTransportCA),
+			kibana:             kibanaFixture,
+			es:                 esFixture,
+			want:               ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
+			wantCA:             &kibanaEsCA,
+			wantCACertProvided: true,
+		},
+		{
+			name:               "keys unrelated to the HTTP CA are not copied",
+			client:             k8s.WrappedFakeClient(&es, &esCAWithExtraKeys),
+			kibana:             kibanaFixture,
+			es:                 esFixture,
+			want:               ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
+			wantCA:             &kibanaEsCA,
+			wantCACertProvided: true,
+		},
 		{
 			name:               "update existing CA in kibana namespace",
 			client:             k8s.WrappedFakeClient(&es, &updatedEsCA, &kibanaEsCA),
@@ -141,6 +206,37 @@ func TestReconcileAssociation_reconcileCASecret(t *testing.T) {
 			wantCA:             &kibanaEmptyEsCA,
 			wantCACertProvided: false,
 		},
+		{
+			name:               "global CA is appended to the ES CA",
+			client:             k8s.WrappedFakeClient(&es, &esCA),
+			kibana:             kibanaFixture,
+			es:                 esFixture,
+			want:               ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
+			wantCA:             &kibanaEsCAWithGlobalCA,
+			wantCACertProvided: true,
+			// the global CA is not in a namespace cached by the client
+			globalCA: GlobalCA{Reader: k8s.FakeClient(&globalCA), Secret: k8s.ExtractNamespacedName(&globalCA)},
+		},
+		{
+			name:               "global CA is provided even if the ES CA is empty",
+			client:             k8s.WrappedFakeClient(&es, &esEmptyCA),
+			kibana:             kibanaFixture,
+			es:                 esFixture,
+			want:               ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
+			wantCA:             &kibanaEmptyEsCAWithGlobalCA,
+			wantCACertProvided: true,
+			globalCA:           GlobalCA{Reader: k8s.FakeClient(&globalCA), Secret: k8s.ExtractNamespacedName(&globalCA)},
+		},
+		{
+			name:               "missing global CA falls back to the ES CA",
+			client:             k8s.WrappedFakeClient(&es, &esCA),
+			kibana:             kibanaFixture,
+			es:                 esFixture,
+			want:               ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
+			wantCA:             &kibanaEsCA,
+			wantCACertProvided: true,
+			globalCA:           GlobalCA{Reader: k8s.FakeClient(), Secret: k8s.ExtractNamespacedName(&globalCA)},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -151,6 +247,8 @@ func TestReconcileAssociation_reconcileCASecret(t *testing.T) {
 				k8s.ExtractNamespacedName(&tt.es),
 				map[string]string{},
 				ElasticsearchCASecretSuffix,
+				DefaultOwnerRefMode,
+				tt.globalCA,
 			)
 			require.NoError(t, err)
 
@@ -169,3 +267,106 @@ func TestReconcileAssociation_reconcileCASecret(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckGlobalCA(t *testing.T) {
+	globalCA := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "elastic-system", Name: "corporate-ca"}}
+	// no global CA configured
+	require.NoError(t, CheckGlobalCA(GlobalCA{}))
+	// global CA configured and available
+	require.NoError(t, CheckGlobalCA(GlobalCA{Reader: k8s.FakeClient(&globalCA), Secret: k8s.ExtractNamespacedName(&globalCA)}))
+	// global CA configured but missing
+	require.Error(t, CheckGlobalCA(GlobalCA{Reader: k8s.FakeClient(), Secret: k8s.ExtractNamespacedName(&globalCA)}))
+}
+
+func TestReconcileCASecret_labels(t *testing.T) {
+	es := types.NamespacedName{Namespace: esFixture.Namespace, Name: esFixture.Name}
+	data := map[string][]byte{
+		certificates.CertFileName: []byte("fake-cert"),
+		certificates.CAFileName:   []byte("fake-ca-cert"),
+	}
+	esCA := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: es.Namespace,
+			Name:      certificates.PublicSecretName(esv1.ESNamer, es.Name, certificates.HTTPCAType),
+		},
+		Data: data,
+	}
+	// up-to-date copy of the CA, missing the expected labels
+	kibanaEsCA := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: kibanaFixture.Namespace,
+			Name:      ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
+		},
+		Data: data,
+	}
+	c := k8s.WrappedFakeClient(&esCA, &kibanaEsCA)
+	labels := map[string]string{TenantLabelName: "team-a"}
+	_, err := ReconcileCASecret(c, scheme.Scheme, &kibanaFixture, es, labels, ElasticsearchCASecretSuffix, DefaultOwnerRefMode, GlobalCA{})
+	require.NoError(t, err)
+
+	var updated corev1.Secret
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(&kibanaEsCA), &updated))
+	require.Equal(t, "team-a", updated.Labels[TenantLabelName])
+}
+
+func TestCASecretChanged(t *testing.T) {
+	es := types.NamespacedName{Namespace: esFixture.Namespace, Name: esFixture.Name}
+	esCA := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: es.Namespace,
+			Name:      certificates.PublicSecretName(esv1.ESNamer, es.Name, certificates.HTTPCAType),
+		},
+		Data: map[string][]byte{certificates.CAFileName: []byte("new")},
+	}
+	caCopy := func(data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: kibanaFixture.Namespace,
+				Name:      ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
+			},
+			Data: data,
+		}
+	}
+	orchestrated := kibanaFixture.DeepCopy()
+	orchestrated.Annotations = map[string]string{annotation.CARotationAnnotation: CARotationOrchestrated}
+	staged := caCopy(map[string][]byte{certificates.CAFileName: []byte("new\nold"), PreviousCAFileName: []byte("old")})
+	tests := []struct {
+		name        string
+		associated  commonv1.Associated
+		objs        []runtime.Object
+		wantChanged bool
+		wantCA      string
+	}{
+		{name: "no Elasticsearch CA", associated: &kibanaFixture},
+		{name: "no copy", associated: &kibanaFixture, objs: []runtime.Object{esCA}, wantChanged: true, wantCA: "new"},
+		{
+			name:       "up-to-date copy",
+			associated: &kibanaFixture,
+			objs:       []runtime.Object{esCA, caCopy(map[string][]byte{certificates.CAFileName: []byte("new")})},
+			wantCA:     "new",
+		},
+		{
+			name:        "outdated copy",
+			associated:  &kibanaFixture,
+			objs:        []runtime.Object{esCA, caCopy(map[string][]byte{certificates.CAFileName: []byte("old")})},
+			wantChanged: true,
+			wantCA:      "new",
+		},
+		{name: "rotation staged", associated: orchestrated, objs: []runtime.Object{esCA, staged}, wantCA: "new"},
+		{
+			name:        "rotation staged but not orchestrated anymore",
+			associated:  &kibanaFixture,
+			objs:        []runtime.Object{esCA, staged},
+			wantChanged: true,
+			wantCA:      "new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, ca, err := CASecretChanged(k8s.WrappedFakeClient(tt.objs...), tt.associated, es, ElasticsearchCASecretSuffix, GlobalCA{})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantChanged, changed)
+			require.Equal(t, tt.wantCA, string(ca))
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	pkgerrors "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// CleanupStep is one of the independent concerns to clean up when an association is removed.
+type CleanupStep struct {
+	// Name identifies the step in logs and errors.
+	Name string
+	Run  func() error
+}
+
+// RunCleanup runs all the given cleanup steps of the association of the given associated resource. A failing step does
+// not prevent the next ones from running: the errors of all failed steps are returned as an aggregate, each one
+// prefixed with the name of its step.
+func RunCleanup(associated types.NamespacedName, steps ...CleanupStep) error {
+	var errs []error
+	for _, step := range steps {
+		if err := step.Run(); err != nil {
+			log.Error(err, "Association cleanup step failed",
+				"step", step.Name, "namespace", associated.Namespace, "name", associated.Name)
+			errs = append(errs, pkgerrors.Wrapf(err, "cleanup step %s", step.Name))
+			continue
+		}
+		log.V(1).Info("Association cleanup step completed",
+			"step", step.Name, "namespace", associated.Namespace, "name", associated.Name)
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,38 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRunCleanup(t *testing.T) {
+	kb := types.NamespacedName{Namespace: "ns", Name: "kb"}
+	var ran []string
+	step := func(name string, err error) CleanupStep {
+		return CleanupStep{Name: name, Run: func() error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+
+	require.NoError(t, RunCleanup(kb))
+	require.NoError(t, RunCleanup(kb, step("secrets", nil), step("user", nil)))
+	require.Equal(t, []string{"secrets", "user"}, ran)
+
+	// failures do not prevent the next steps from running
+	ran = nil
+	err := RunCleanup(kb,
+		step("secrets", errors.New("secrets failure")),
+		step("credentials", nil),
+		step("user", errors.New("user failure")),
+	)
+	require.Equal(t, []string{"secrets", "credentials", "user"}, ran)
+	require.EqualError(t, err, "[cleanup step secrets: secrets failure, cleanup step user: user failure]")
+}
@@ -0,0 +1,120 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+)
+
+const day = 24 * time.Hour
+
+// MaintenanceWindow is a daily time range, in UTC, during which changes restarting the associated resource are
+// applied. The window may span midnight.
+type MaintenanceWindow struct {
+	// Start and End are offsets from midnight.
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseMaintenanceWindow parses a maintenance window in the HH:MM-HH:MM format.
+func ParseMaintenanceWindow(value string) (MaintenanceWindow, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q, expected HH:MM-HH:MM", value)
+	}
+	var offsets [2]time.Duration
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q, expected HH:MM-HH:MM", value)
+		}
+		offsets[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if offsets[0] == offsets[1] {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q, start and end are equal", value)
+	}
+	return MaintenanceWindow{Start: offsets[0], End: offsets[1]}, nil
+}
+
+// Contains returns true if the given time is within the window.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	offset := sinceMidnight(t)
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	// the window spans midnight
+	return offset >= w.Start || offset < w.End
+}
+
+// Until returns how long until the next start of the window, zero if the given time is within the window.
+func (w MaintenanceWindow) Until(t time.Time) time.Duration {
+	if w.Contains(t) {
+		return 0
+	}
+	return (w.Start - sinceMidnight(t) + day) % day
+}
+
+// String returns the window in the HH:MM-HH:MM format.
+func (w MaintenanceWindow) String() string {
+	midnight := time.Time{}
+	return midnight.Add(w.Start).Format("15:04") + "-" + midnight.Add(w.End).Format("15:04")
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	t = t.UTC()
+	return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+}
+
+// RestartDeferral defers the changes restarting an associated resource, so that they are coalesced into a single
+// restart: deferred changes are applied during the maintenance window, or once enough of them accumulated.
+type RestartDeferral struct {
+	// Window is the maintenance window, changes are only applied once enough of them accumulated if nil.
+	Window *MaintenanceWindow
+	// Batch is the number of deferred changes applied at once outside of the window, no limit if zero.
+	Batch int
+}
+
+// RestartDeferralFor returns the restart deferral configured through the annotations of the given object, and false
+// if the changes are not deferred.
+func RestartDeferralFor(meta metav1.ObjectMeta) (RestartDeferral, bool, error) {
+	var deferral RestartDeferral
+	if value := meta.Annotations[annotation.RestartWindowAnnotation]; value != "" {
+		window, err := ParseMaintenanceWindow(value)
+		if err != nil {
+			return RestartDeferral{}, false, err
+		}
+		deferral.Window = &window
+	}
+	if value := meta.Annotations[annotation.RestartBatchAnnotation]; value != "" {
+		batch, err := strconv.Atoi(value)
+		if err != nil || batch <= 0 {
+			return RestartDeferral{}, false, fmt.Errorf("invalid value %q for annotation %s, expected a positive integer",
+				value, annotation.RestartBatchAnnotation)
+		}
+		deferral.Batch = batch
+	}
+	return deferral, deferral.Window != nil || deferral.Batch > 0, nil
+}
+
+// Apply returns true if the given number of deferred changes should be applied now.
+func (d RestartDeferral) Apply(changes int, now time.Time) bool {
+	return d.Window != nil && d.Window.Contains(now) || d.Batch > 0 && changes >= d.Batch
+}
+
+// Until returns how long until the deferred changes are applied because the maintenance window starts, zero if there
+// is no window.
+func (d RestartDeferral) Until(now time.Time) time.Duration {
+	if d.Window == nil {
+		return 0
+	}
+	return d.Window.Until(now)
+}
@@ -0,0 +1,129 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    MaintenanceWindow
+		wantErr bool
+	}{
+		{value: "02:00-04:30", want: MaintenanceWindow{Start: 2 * time.Hour, End: 4*time.Hour + 30*time.Minute}},
+		{value: "22:00 - 02:00", want: MaintenanceWindow{Start: 22 * time.Hour, End: 2 * time.Hour}},
+		{value: "02:00", wantErr: true},
+		{value: "02:00-25:00", wantErr: true},
+		{value: "02:00-02:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			window, err := ParseMaintenanceWindow(tt.value)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, window)
+		})
+	}
+}
+
+func TestMaintenanceWindow_Until(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2020, 1, 1, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name   string
+		window string
+		now    time.Time
+		want   time.Duration
+	}{
+		{name: "before the window", window: "02:00-04:00", now: at(1, 30), want: 30 * time.Minute},
+		{name: "start of the window", window: "02:00-04:00", now: at(2, 0), want: 0},
+		{name: "end of the window", window: "02:00-04:00", now: at(4, 0), want: 22 * time.Hour},
+		{name: "window spanning midnight, before midnight", window: "22:00-02:00", now: at(23, 0), want: 0},
+		{name: "window spanning midnight, after midnight", window: "22:00-02:00", now: at(1, 0), want: 0},
+		{name: "window spanning midnight, outside", window: "22:00-02:00", now: at(12, 0), want: 10 * time.Hour},
+		{
+			name:   "time zone offset",
+			window: "02:00-04:00",
+			now:    time.Date(2020, 1, 1, 2, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)),
+			want:   2 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := ParseMaintenanceWindow(tt.window)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, window.Until(tt.now))
+			require.Equal(t, tt.want == 0, window.Contains(tt.now))
+		})
+	}
+}
+
+func TestRestartDeferralFor(t *testing.T) {
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		want         RestartDeferral
+		wantDeferred bool
+		wantErr      bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:         "maintenance window",
+			annotations:  map[string]string{annotation.RestartWindowAnnotation: "02:00-04:00"},
+			want:         RestartDeferral{Window: &MaintenanceWindow{Start: 2 * time.Hour, End: 4 * time.Hour}},
+			wantDeferred: true,
+		},
+		{
+			name: "maintenance window and batch",
+			annotations: map[string]string{
+				annotation.RestartWindowAnnotation: "02:00-04:00",
+				annotation.RestartBatchAnnotation:  "3",
+			},
+			want:         RestartDeferral{Window: &MaintenanceWindow{Start: 2 * time.Hour, End: 4 * time.Hour}, Batch: 3},
+			wantDeferred: true,
+		},
+		{
+			name:        "invalid window",
+			annotations: map[string]string{annotation.RestartWindowAnnotation: "tonight"},
+			wantErr:     true,
+		},
+		{
+			name:        "invalid batch",
+			annotations: map[string]string{annotation.RestartBatchAnnotation: "0"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deferral, deferred, err := RestartDeferralFor(metav1.ObjectMeta{Annotations: tt.annotations})
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantDeferred, deferred)
+			require.Equal(t, tt.want, deferral)
+		})
+	}
+}
+
+func TestRestartDeferral_Apply(t *testing.T) {
+	inWindow := time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC)
+	outsideWindow := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	window := &MaintenanceWindow{Start: 2 * time.Hour, End: 4 * time.Hour}
+
+	require.True(t, RestartDeferral{Window: window}.Apply(1, inWindow))
+	require.False(t, RestartDeferral{Window: window}.Apply(10, outsideWindow))
+	require.False(t, RestartDeferral{Window: window, Batch: 3}.Apply(2, outsideWindow))
+	require.True(t, RestartDeferral{Window: window, Batch: 3}.Apply(3, outsideWindow))
+	require.True(t, RestartDeferral{Batch: 3}.Apply(3, outsideWindow))
+	require.Equal(t, time.Duration(0), RestartDeferral{Batch: 3}.Until(outsideWindow))
+}
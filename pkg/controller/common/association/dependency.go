@@ -0,0 +1,163 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	apmv1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1"
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// Kinds of associated resources that can be referenced as a dependency.
+const (
+	kibanaKind    = "Kibana"
+	apmServerKind = "ApmServer"
+)
+
+// Dependency is a reference to another association that must be established first.
+type Dependency struct {
+	Kind string
+	types.NamespacedName
+}
+
+func (d Dependency) String() string {
+	return d.Kind + "/" + d.Namespace + "/" + d.Name
+}
+
+// Dependencies returns the associations the given object depends on, as declared through the depends-on annotation.
+func Dependencies(obj metav1.Object) ([]Dependency, error) {
+	value := obj.GetAnnotations()[annotation.DependsOnAnnotation]
+	if value == "" {
+		return nil, nil
+	}
+	var dependencies []Dependency
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, "/")
+		var dependency Dependency
+		switch len(parts) {
+		case 2:
+			dependency = Dependency{Kind: parts[0], NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: parts[1]}}
+		case 3:
+			dependency = Dependency{Kind: parts[0], NamespacedName: types.NamespacedName{Namespace: parts[1], Name: parts[2]}}
+		default:
+			return nil, fmt.Errorf("invalid association dependency %q, expected <kind>/<namespace>/<name> or <kind>/<name>", entry)
+		}
+		if dependency.Kind != kibanaKind && dependency.Kind != apmServerKind {
+			return nil, fmt.Errorf("invalid association dependency %q, kind must be one of [%s, %s]", entry, kibanaKind, apmServerKind)
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, nil
+}
+
+// BlockingDependency returns the first association the given object depends on that is not established yet, if any.
+// A dependency that does not exist is considered as not established.
+func BlockingDependency(c k8s.Client, obj metav1.Object) (*Dependency, error) {
+	dependencies, err := Dependencies(obj)
+	if err != nil {
+		return nil, err
+	}
+	for i := range dependencies {
+		status, err := dependencyStatus(c, dependencies[i])
+		if err != nil {
+			return nil, err
+		}
+		if status != commonv1.AssociationEstablished {
+			return &dependencies[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// DependencyCycle returns the chain of dependencies leading from the given association back to itself, if any. Such
+// associations would wait for each other forever.
+func DependencyCycle(c k8s.Client, start Dependency) ([]Dependency, error) {
+	visited := map[Dependency]bool{start: true}
+	var visit func(path []Dependency) ([]Dependency, error)
+	visit = func(path []Dependency) ([]Dependency, error) {
+		dependencies, err := dependenciesOf(c, path[len(path)-1])
+		if err != nil {
+			return nil, err
+		}
+		for _, dependency := range dependencies {
+			next := append(append([]Dependency{}, path...), dependency)
+			if dependency == start {
+				return next, nil
+			}
+			if visited[dependency] {
+				continue
+			}
+			visited[dependency] = true
+			cycle, err := visit(next)
+			if err != nil || cycle != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+	return visit([]Dependency{start})
+}
+
+// dependenciesOf returns the dependencies declared by the given association. Associations that do not exist or declare
+// invalid dependencies do not have any.
+func dependenciesOf(c k8s.Client, dependency Dependency) ([]Dependency, error) {
+	var obj runtime.Object
+	switch dependency.Kind {
+	case kibanaKind:
+		obj = &kbv1.Kibana{}
+	case apmServerKind:
+		obj = &apmv1.ApmServer{}
+	}
+	if err := c.Get(dependency.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := Dependencies(accessor)
+	if err != nil {
+		// reported by the reconciliation of that association
+		return nil, nil
+	}
+	return dependencies, nil
+}
+
+func dependencyStatus(c k8s.Client, dependency Dependency) (commonv1.AssociationStatus, error) {
+	var err error
+	var status commonv1.AssociationStatus
+	switch dependency.Kind {
+	case kibanaKind:
+		var kb kbv1.Kibana
+		err = c.Get(dependency.NamespacedName, &kb)
+		status = kb.Status.AssociationStatus
+	case apmServerKind:
+		var as apmv1.ApmServer
+		err = c.Get(dependency.NamespacedName, &as)
+		status = as.Status.Association
+	}
+	if apierrors.IsNotFound(err) {
+		return commonv1.AssociationUnknown, nil
+	}
+	return status, err
+}
@@ -0,0 +1,188 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	apmv1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1"
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func dependentKibana(dependsOn string) *kbv1.Kibana {
+	kb := kibanaFixture.DeepCopy()
+	kb.Annotations = map[string]string{annotation.DependsOnAnnotation: dependsOn}
+	return kb
+}
+
+func TestDependencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		dependsOn string
+		want      []Dependency
+		wantErr   bool
+	}{
+		{
+			name:      "no dependency",
+			dependsOn: "",
+			want:      nil,
+		},
+		{
+			name:      "dependencies with and without namespace",
+			dependsOn: "Kibana/ns1/kb-monitoring, ApmServer/apm",
+			want: []Dependency{
+				{Kind: "Kibana", NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "kb-monitoring"}},
+				{Kind: "ApmServer", NamespacedName: types.NamespacedName{Namespace: "default", Name: "apm"}},
+			},
+		},
+		{
+			name:      "invalid format",
+			dependsOn: "kb-monitoring",
+			wantErr:   true,
+		},
+		{
+			name:      "unsupported kind",
+			dependsOn: "Elasticsearch/es",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Dependencies(dependentKibana(tt.dependsOn))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBlockingDependency(t *testing.T) {
+	establishedKibana := &kbv1.Kibana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kb-monitoring"},
+		Status:     kbv1.KibanaStatus{AssociationStatus: commonv1.AssociationEstablished},
+	}
+	pendingApmServer := &apmv1.ApmServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "apm"},
+		Status:     apmv1.ApmServerStatus{Association: commonv1.AssociationPending},
+	}
+	tests := []struct {
+		name        string
+		dependsOn   string
+		runtimeObjs []runtime.Object
+		want        *Dependency
+	}{
+		{
+			name:      "no dependency",
+			dependsOn: "",
+			want:      nil,
+		},
+		{
+			name:        "dependency established",
+			dependsOn:   "Kibana/kb-monitoring",
+			runtimeObjs: []runtime.Object{establishedKibana},
+			want:        nil,
+		},
+		{
+			name:        "dependency pending",
+			dependsOn:   "Kibana/kb-monitoring,ApmServer/apm",
+			runtimeObjs: []runtime.Object{establishedKibana, pendingApmServer},
+			want:        &Dependency{Kind: "ApmServer", NamespacedName: types.NamespacedName{Namespace: "default", Name: "apm"}},
+		},
+		{
+			name:      "dependency does not exist",
+			dependsOn: "Kibana/kb-monitoring",
+			want:      &Dependency{Kind: "Kibana", NamespacedName: types.NamespacedName{Namespace: "default", Name: "kb-monitoring"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrappedFakeClient(tt.runtimeObjs...)
+			got, err := BlockingDependency(c, dependentKibana(tt.dependsOn))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDependencyCycle(t *testing.T) {
+	dependentOn := func(name, dependsOn string) *kbv1.Kibana {
+		kb := dependentKibana(dependsOn)
+		kb.Name = name
+		return kb
+	}
+	kibana := func(name string) Dependency {
+		return Dependency{Kind: "Kibana", NamespacedName: types.NamespacedName{Namespace: "default", Name: name}}
+	}
+	tests := []struct {
+		name        string
+		runtimeObjs []runtime.Object
+		want        []Dependency
+	}{
+		{
+			name:        "no dependency",
+			runtimeObjs: []runtime.Object{dependentOn("kb-a", "")},
+			want:        nil,
+		},
+		{
+			name:        "missing dependency",
+			runtimeObjs: []runtime.Object{dependentOn("kb-a", "Kibana/kb-b")},
+			want:        nil,
+		},
+		{
+			name: "dependency chain",
+			runtimeObjs: []runtime.Object{
+				dependentOn("kb-a", "Kibana/kb-b,ApmServer/apm"),
+				dependentOn("kb-b", "Kibana/kb-c"),
+				dependentOn("kb-c", ""),
+			},
+			want: nil,
+		},
+		{
+			name: "mutual dependency",
+			runtimeObjs: []runtime.Object{
+				dependentOn("kb-a", "Kibana/kb-b"),
+				dependentOn("kb-b", "Kibana/kb-a"),
+			},
+			want: []Dependency{kibana("kb-a"), kibana("kb-b"), kibana("kb-a")},
+		},
+		{
+			name: "indirect cycle",
+			runtimeObjs: []runtime.Object{
+				dependentOn("kb-a", "Kibana/kb-b"),
+				dependentOn("kb-b", "Kibana/kb-c"),
+				dependentOn("kb-c", "Kibana/kb-a"),
+			},
+			want: []Dependency{kibana("kb-a"), kibana("kb-b"), kibana("kb-c"), kibana("kb-a")},
+		},
+		{
+			name: "cycle not involving the association",
+			runtimeObjs: []runtime.Object{
+				dependentOn("kb-a", "Kibana/kb-b"),
+				dependentOn("kb-b", "Kibana/kb-c"),
+				dependentOn("kb-c", "Kibana/kb-b"),
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrappedFakeClient(tt.runtimeObjs...)
+			got, err := DependencyCycle(c, kibana("kb-a"))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// AllNamespacesEncrypted can be used in an EncryptionAtRestPolicy to attest that secrets are encrypted at rest in all
+// the namespaces of the cluster.
+const AllNamespacesEncrypted = "*"
+
+// EncryptionAtRestPolicy lists the namespaces in which secrets are known to be encrypted at rest. Whether secrets are
+// encrypted at rest is a property of the API server configuration which cannot be inspected through the Kubernetes
+// API, so it must be attested by the operator administrator.
+// An empty policy disables the verification.
+type EncryptionAtRestPolicy []string
+
+// IsEnabled returns true if the policy must be verified.
+func (p EncryptionAtRestPolicy) IsEnabled() bool {
+	return len(p) > 0
+}
+
+// Unencrypted returns the sorted list of the given namespaces in which secrets are not attested to be encrypted at
+// rest by the policy. It is always empty if the policy is disabled.
+func (p EncryptionAtRestPolicy) Unencrypted(namespaces ...string) []string {
+	if !p.IsEnabled() {
+		return nil
+	}
+	encrypted := sets.NewString(p...)
+	if encrypted.Has(AllNamespacesEncrypted) {
+		return nil
+	}
+	unencrypted := sets.NewString()
+	for _, ns := range namespaces {
+		if !encrypted.Has(ns) {
+			unencrypted.Insert(ns)
+		}
+	}
+	return unencrypted.List()
+}
@@ -0,0 +1,49 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptionAtRestPolicy_Unencrypted(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     EncryptionAtRestPolicy
+		namespaces []string
+		want       []string
+	}{
+		{
+			name:       "disabled policy",
+			namespaces: []string{"kb", "es"},
+			want:       nil,
+		},
+		{
+			name:       "all namespaces encrypted",
+			policy:     EncryptionAtRestPolicy{AllNamespacesEncrypted},
+			namespaces: []string{"kb", "es"},
+			want:       nil,
+		},
+		{
+			name:       "all namespaces listed",
+			policy:     EncryptionAtRestPolicy{"es", "kb", "other"},
+			namespaces: []string{"kb", "es"},
+			want:       []string{},
+		},
+		{
+			name:       "some namespaces not listed",
+			policy:     EncryptionAtRestPolicy{"es"},
+			namespaces: []string{"kb", "es", "credentials", "kb"},
+			want:       []string{"credentials", "kb"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.policy.Unencrypted(tt.namespaces...))
+		})
+	}
+}
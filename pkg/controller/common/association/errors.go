@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"regexp"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+// MaxErrorMessageLength is the maximum length of an error message reported in the status of an association.
+const MaxErrorMessageLength = 512
+
+// credentialsPatterns match the credentials error messages may hold, surrounded by the groups preserved when redacting
+// them.
+var credentialsPatterns = []*regexp.Regexp{
+	// user information of URLs
+	regexp.MustCompile(`(://)[^/@\s]+(@)`),
+	// values of authorization headers
+	regexp.MustCompile(`(?i)(authorization"?\s*[:=]\s*"?(?:basic |bearer |apikey )?)[^"\s,}]+()`),
+	// passwords, either as key-value pairs or JSON fields
+	regexp.MustCompile(`(?i)(password"?\s*[:=]\s*"?)[^"\s,}]+()`),
+}
+
+// ErrorMessage returns the message of the given error as reported in the status of an association: credentials are
+// redacted, and the message is truncated to MaxErrorMessageLength bytes.
+func ErrorMessage(err error) string {
+	message := err.Error()
+	for _, pattern := range credentialsPatterns {
+		message = pattern.ReplaceAllString(message, "${1}"+redacted+"${2}")
+	}
+	if len(message) > MaxErrorMessageLength {
+		message = message[:MaxErrorMessageLength-3] + "..."
+	}
+	return message
+}
+
+// LastError returns the last error of an association after a reconciliation which failed with the given error, nil if
+// it succeeded. The time of the previous last error is preserved if the message did not change, so that the status is
+// not updated on every failing reconciliation.
+func LastError(previous *commonv1.AssociationError, err error, now time.Time) *commonv1.AssociationError {
+	if err == nil {
+		return nil
+	}
+	message := ErrorMessage(err)
+	if previous != nil && previous.Message == message {
+		return previous.DeepCopy()
+	}
+	return &commonv1.AssociationError{Message: message, Time: metav1.NewTime(now.Truncate(time.Second))}
+}
@@ -0,0 +1,61 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+// FailureGracePeriod delays the transition of associations to the Failed status until the failure has persisted for a
+// given duration, reporting them as Pending in the meantime. This avoids flagging transient failures.
+// Failures are tracked in memory: the grace period starts over if the operator restarts.
+type FailureGracePeriod struct {
+	mutex    sync.Mutex
+	duration time.Duration
+	since    map[types.NamespacedName]time.Time
+}
+
+// NewFailureGracePeriod returns a FailureGracePeriod of the given duration. A zero duration disables the grace period.
+func NewFailureGracePeriod(duration time.Duration) *FailureGracePeriod {
+	return &FailureGracePeriod{
+		duration: duration,
+		since:    make(map[types.NamespacedName]time.Time),
+	}
+}
+
+// Apply returns the status to report for the given association, depending on how long it has been failing.
+func (g *FailureGracePeriod) Apply(association types.NamespacedName, status commonv1.AssociationStatus, now time.Time) commonv1.AssociationStatus {
+	if g.duration <= 0 {
+		return status
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if status != commonv1.AssociationFailed {
+		delete(g.since, association)
+		return status
+	}
+	since, exists := g.since[association]
+	if !exists {
+		since = now
+		g.since[association] = now
+	}
+	if now.Sub(since) < g.duration {
+		return commonv1.AssociationPending
+	}
+	return status
+}
+
+// Forget removes the failure tracking state of the given association.
+func (g *FailureGracePeriod) Forget(association types.NamespacedName) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	delete(g.since, association)
+}
@@ -0,0 +1,41 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+func TestFailureGracePeriod_Apply(t *testing.T) {
+	assoc := types.NamespacedName{Namespace: "ns", Name: "kb"}
+	now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// disabled
+	require.Equal(t, commonv1.AssociationFailed, NewFailureGracePeriod(0).Apply(assoc, commonv1.AssociationFailed, now))
+
+	g := NewFailureGracePeriod(time.Minute)
+	// failures are reported as pending during the grace period
+	require.Equal(t, commonv1.AssociationPending, g.Apply(assoc, commonv1.AssociationFailed, now))
+	require.Equal(t, commonv1.AssociationPending, g.Apply(assoc, commonv1.AssociationFailed, now.Add(30*time.Second)))
+	// other associations are tracked independently
+	other := types.NamespacedName{Namespace: "ns", Name: "other"}
+	require.Equal(t, commonv1.AssociationPending, g.Apply(other, commonv1.AssociationFailed, now.Add(time.Minute)))
+	// the failure persisted beyond the grace period
+	require.Equal(t, commonv1.AssociationFailed, g.Apply(assoc, commonv1.AssociationFailed, now.Add(time.Minute)))
+
+	// recovery resets the grace period
+	require.Equal(t, commonv1.AssociationEstablished, g.Apply(assoc, commonv1.AssociationEstablished, now.Add(2*time.Minute)))
+	require.Equal(t, commonv1.AssociationPending, g.Apply(assoc, commonv1.AssociationFailed, now.Add(3*time.Minute)))
+
+	// forgotten associations start over
+	g.Forget(other)
+	require.Equal(t, commonv1.AssociationPending, g.Apply(other, commonv1.AssociationFailed, now.Add(10*time.Minute)))
+}
@@ -0,0 +1,32 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+)
+
+// ParseMinESHealth returns the minimum Elasticsearch health corresponding to the given string, or an error if it is not
+// supported. An empty string results in no minimum health.
+func ParseMinESHealth(health string) (esv1.ElasticsearchHealth, error) {
+	switch esv1.ElasticsearchHealth(health) {
+	case "", esv1.ElasticsearchYellowHealth, esv1.ElasticsearchGreenHealth:
+		return esv1.ElasticsearchHealth(health), nil
+	default:
+		return "", fmt.Errorf("unsupported minimum Elasticsearch health %q, must be one of [%s, %s]",
+			health, esv1.ElasticsearchYellowHealth, esv1.ElasticsearchGreenHealth)
+	}
+}
+
+// HasMinESHealth returns true if the given Elasticsearch health is at least the given minimum health, if any.
+// An unknown health does not meet any minimum.
+func HasMinESHealth(health esv1.ElasticsearchHealth, minHealth esv1.ElasticsearchHealth) bool {
+	if minHealth == "" {
+		return true
+	}
+	return health == minHealth || minHealth.Less(health)
+}
@@ -0,0 +1,46 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+)
+
+func TestParseMinESHealth(t *testing.T) {
+	for _, health := range []string{"", "yellow", "green"} {
+		got, err := ParseMinESHealth(health)
+		assert.NoError(t, err)
+		assert.Equal(t, esv1.ElasticsearchHealth(health), got)
+	}
+	for _, health := range []string{"red", "unknown", "Green"} {
+		_, err := ParseMinESHealth(health)
+		assert.Error(t, err, health)
+	}
+}
+
+func TestHasMinESHealth(t *testing.T) {
+	tests := []struct {
+		health    esv1.ElasticsearchHealth
+		minHealth esv1.ElasticsearchHealth
+		want      bool
+	}{
+		{health: "", minHealth: "", want: true},
+		{health: esv1.ElasticsearchRedHealth, minHealth: "", want: true},
+		{health: esv1.ElasticsearchRedHealth, minHealth: esv1.ElasticsearchYellowHealth, want: false},
+		{health: esv1.ElasticsearchYellowHealth, minHealth: esv1.ElasticsearchYellowHealth, want: true},
+		{health: esv1.ElasticsearchGreenHealth, minHealth: esv1.ElasticsearchYellowHealth, want: true},
+		{health: esv1.ElasticsearchYellowHealth, minHealth: esv1.ElasticsearchGreenHealth, want: false},
+		{health: esv1.ElasticsearchGreenHealth, minHealth: esv1.ElasticsearchGreenHealth, want: true},
+		{health: esv1.ElasticsearchUnknownHealth, minHealth: esv1.ElasticsearchYellowHealth, want: false},
+		{health: "", minHealth: esv1.ElasticsearchYellowHealth, want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, HasMinESHealth(tt.health, tt.minHealth), "health %q, min health %q", tt.health, tt.minHealth)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+const (
+	inventoryRequestTimeout = 10 * time.Second
+	inventoryMinBackoff     = time.Second
+	inventoryMaxBackoff     = 5 * time.Minute
+
+	// InventorySignatureHeader is the header of inventory requests holding the signature of their body, in the
+	// sha256=<hex encoded HMAC-SHA256> format.
+	InventorySignatureHeader = "X-Elastic-Signature"
+	// InventorySigningKey is the key of the signing secret holding the HMAC key.
+	InventorySigningKey = "signing-key"
+)
+
+// InventoryRecord is the resolved state of an association, as exported to an inventory endpoint.
+type InventoryRecord struct {
+	// Kind, Namespace and Name identify the associated resource.
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// URL is the URL of the associated resource.
+	URL string `json:"url,omitempty"`
+	// Elasticsearch identifies the Elasticsearch cluster the resource is associated with.
+	Elasticsearch InventoryTarget `json:"elasticsearch"`
+	// Status is the association status.
+	Status commonv1.AssociationStatus `json:"status"`
+	// Deleted is true if the associated resource was deleted, in which case only Kind, Namespace and Name are set.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// inventoryKey identifies the associated resource of an InventoryRecord.
+type inventoryKey struct {
+	kind string
+	types.NamespacedName
+}
+
+// InventoryTarget identifies the target of an association.
+type InventoryTarget struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	URL       string `json:"url,omitempty"`
+}
+
+// InventoryExporter POSTs association records to an inventory endpoint asynchronously, so that inventory outages do
+// not slow down reconciliations. Only the latest record of each association is kept while waiting to be sent, failed
+// requests are retried with an exponential backoff.
+type InventoryExporter struct {
+	url    string
+	client *http.Client
+
+	mutex   sync.Mutex
+	pending map[inventoryKey]InventoryRecord
+	notify  chan struct{}
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	// signingKey returns the key inventory requests are signed with, requests are not signed if nil
+	signingKey SigningKeyFunc
+}
+
+// SigningKeyFunc returns the key inventory requests are signed with.
+type SigningKeyFunc func() ([]byte, error)
+
+// SecretSigningKey returns a SigningKeyFunc reading the signing key from the given secret for each request, so that
+// the key can be rotated without restarting the operator.
+func SecretSigningKey(c client.Reader, secretRef types.NamespacedName) SigningKeyFunc {
+	return func() ([]byte, error) {
+		var secret corev1.Secret
+		if err := c.Get(context.Background(), secretRef, &secret); err != nil {
+			return nil, err
+		}
+		key := secret.Data[InventorySigningKey]
+		if len(key) == 0 {
+			return nil, fmt.Errorf("no %s in inventory signing secret %s", InventorySigningKey, secretRef)
+		}
+		return key, nil
+	}
+}
+
+// Sign returns the signature of the given body with the given key, as set in the InventorySignatureHeader.
+func Sign(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewInventoryExporter returns an InventoryExporter sending records to the given URL.
+// Records are only sent once the exporter is started.
+func NewInventoryExporter(url string) *InventoryExporter {
+	return &InventoryExporter{
+		url:        url,
+		client:     &http.Client{Timeout: inventoryRequestTimeout},
+		pending:    make(map[inventoryKey]InventoryRecord),
+		notify:     make(chan struct{}, 1),
+		minBackoff: inventoryMinBackoff,
+		maxBackoff: inventoryMaxBackoff,
+	}
+}
+
+// WithSigningKey signs the requests sent by the exporter with the key returned by the given function.
+func (e *InventoryExporter) WithSigningKey(signingKey SigningKeyFunc) *InventoryExporter {
+	e.signingKey = signingKey
+	return e
+}
+
+// Export schedules the given record to be sent, replacing any record of the same association not sent yet.
+// It never blocks.
+func (e *InventoryExporter) Export(record InventoryRecord) {
+	e.mutex.Lock()
+	e.pending[inventoryKey{kind: record.Kind, NamespacedName: types.NamespacedName{Namespace: record.Namespace, Name: record.Name}}] = record
+	e.mutex.Unlock()
+	select {
+	case e.notify <- struct{}{}:
+	default:
+		// a notification is already pending
+	}
+}
+
+// ExportDeletion schedules a record of the deletion of the given associated resource to be sent, replacing any record
+// of the same association not sent yet. It never blocks.
+func (e *InventoryExporter) ExportDeletion(kind string, associated types.NamespacedName) {
+	e.Export(InventoryRecord{Kind: kind, Namespace: associated.Namespace, Name: associated.Name, Deleted: true})
+}
+
+// Start sends the exported records until the stop channel is closed. It implements manager.Runnable.
+func (e *InventoryExporter) Start(stop <-chan struct{}) error {
+	backoff := e.minBackoff
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-e.notify:
+		}
+		for e.sendPending() {
+			// wait before retrying the records that could not be sent
+			select {
+			case <-stop:
+				return nil
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > e.maxBackoff {
+				backoff = e.maxBackoff
+			}
+		}
+		backoff = e.minBackoff
+	}
+}
+
+// sendPending sends all pending records, it returns true if some of them could not be sent and must be retried.
+func (e *InventoryExporter) sendPending() bool {
+	e.mutex.Lock()
+	records := e.pending
+	e.pending = make(map[inventoryKey]InventoryRecord)
+	e.mutex.Unlock()
+
+	retry := false
+	for key, record := range records {
+		if err := e.send(record); err != nil {
+			log.Error(err, "Failed to export association to inventory, will retry",
+				"kind", key.kind, "namespace", key.Namespace, "name", key.Name)
+			e.mutex.Lock()
+			// do not override a more recent record exported in the meantime
+			if _, exists := e.pending[key]; !exists {
+				e.pending[key] = record
+			}
+			e.mutex.Unlock()
+			retry = true
+		}
+	}
+	return retry
+}
+
+func (e *InventoryExporter) send(record InventoryRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.signingKey != nil {
+		key, err := e.signingKey()
+		if err != nil {
+			return err
+		}
+		req.Header.Set(InventorySignatureHeader, Sign(key, body))
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected inventory response status %d", resp.StatusCode)
+	}
+	return nil
+}
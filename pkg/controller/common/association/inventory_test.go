@@ -0,0 +1,130 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestInventoryExporter(t *testing.T) {
+	var mutex sync.Mutex
+	var received []InventoryRecord
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls++
+		// simulate an inventory outage
+		if calls <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var record InventoryRecord
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+		received = append(received, record)
+	}))
+	defer server.Close()
+
+	e := NewInventoryExporter(server.URL)
+	e.minBackoff = time.Millisecond
+	e.maxBackoff = 10 * time.Millisecond
+
+	record := InventoryRecord{
+		Kind:          "Kibana",
+		Namespace:     "ns",
+		Name:          "kb",
+		Elasticsearch: InventoryTarget{Namespace: "ns", Name: "es", URL: "https://es-es-http.ns.svc:9200"},
+		Status:        commonv1.AssociationEstablished,
+	}
+	// exporting does not block, even if the exporter is not started
+	e.Export(record)
+	e.Export(record)
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- e.Start(stop)
+	}()
+
+	require.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(received) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, record, received[0])
+
+	close(stop)
+	require.NoError(t, <-done)
+}
+
+func TestInventoryExporter_pending(t *testing.T) {
+	var received []InventoryRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record InventoryRecord
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+		received = append(received, record)
+	}))
+	defer server.Close()
+
+	e := NewInventoryExporter(server.URL)
+	kibana := InventoryRecord{Kind: "Kibana", Namespace: "ns", Name: "foo", Status: commonv1.AssociationEstablished}
+	apmServer := InventoryRecord{Kind: "ApmServer", Namespace: "ns", Name: "foo", Status: commonv1.AssociationEstablished}
+	e.Export(kibana)
+	e.Export(apmServer)
+	// resources of different kinds with the same name are exported independently
+	require.Len(t, e.pending, 2)
+	// the deletion replaces the pending record of the same resource
+	e.ExportDeletion("Kibana", types.NamespacedName{Namespace: "ns", Name: "foo"})
+	require.Len(t, e.pending, 2)
+
+	require.False(t, e.sendPending())
+	require.ElementsMatch(t, []InventoryRecord{
+		apmServer,
+		{Kind: "Kibana", Namespace: "ns", Name: "foo", Deleted: true},
+	}, received)
+}
+
+func TestInventoryExporter_signature(t *testing.T) {
+	key := []byte("secret-key")
+	signatures := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		signatures <- r.Header.Get(InventorySignatureHeader) == Sign(key, body)
+	}))
+	defer server.Close()
+
+	c := k8s.FakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "elastic-system", Name: "inventory-signing"},
+		Data:       map[string][]byte{InventorySigningKey: key},
+	})
+	e := NewInventoryExporter(server.URL).
+		WithSigningKey(SecretSigningKey(c, types.NamespacedName{Namespace: "elastic-system", Name: "inventory-signing"}))
+	require.NoError(t, e.send(InventoryRecord{Kind: "Kibana", Namespace: "ns", Name: "kb", Status: commonv1.AssociationEstablished}))
+	require.True(t, <-signatures)
+
+	// requests are not sent if the signing key cannot be retrieved
+	e.WithSigningKey(SecretSigningKey(c, types.NamespacedName{Namespace: "elastic-system", Name: "missing"}))
+	require.Error(t, e.send(InventoryRecord{Kind: "Kibana", Namespace: "ns", Name: "kb"}))
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"status":"Established"}' | openssl dgst -sha256 -hmac key
+	require.Equal(t, "sha256=f947cc5bd82baedc9a989bb31d55f3a75a11ad0efe9d59bb9347bd85246d0480", Sign([]byte("key"), []byte(`{"status":"Established"}`)))
+}
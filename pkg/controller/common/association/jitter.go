@@ -0,0 +1,67 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// StartupJitter spreads the initial reconciliations of associations over a window starting when the operator starts,
+// rather than having all of them reconciled at once. Each association is given a fixed offset in the window, derived
+// from its name. Only the first reconciliation of each association is delayed, and only until its offset is reached:
+// reconciliations are not delayed anymore once the window is over.
+type StartupJitter struct {
+	mutex  sync.Mutex
+	window time.Duration
+	start  time.Time
+	seen   map[types.NamespacedName]struct{}
+}
+
+// NewStartupJitter returns a StartupJitter of the given window, starting at the given time. A zero window disables
+// the jitter.
+func NewStartupJitter(window time.Duration, start time.Time) *StartupJitter {
+	return &StartupJitter{
+		window: window,
+		start:  start,
+		seen:   make(map[types.NamespacedName]struct{}),
+	}
+}
+
+// Delay returns how long the reconciliation of the given association should be delayed, zero if it should proceed.
+func (j *StartupJitter) Delay(association types.NamespacedName, now time.Time) time.Duration {
+	elapsed := now.Sub(j.start)
+	if j.window <= 0 || elapsed >= j.window {
+		return 0
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if _, seen := j.seen[association]; seen {
+		return 0
+	}
+	j.seen[association] = struct{}{}
+	if delay := j.offset(association) - elapsed; delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// Forget removes the state of the given association.
+func (j *StartupJitter) Forget(association types.NamespacedName) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	delete(j.seen, association)
+}
+
+// offset returns the offset of the given association in the window.
+func (j *StartupJitter) offset(association types.NamespacedName) time.Duration {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(association.String()))
+	return time.Duration(h.Sum64() % uint64(j.window))
+}
@@ -0,0 +1,48 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestStartupJitter_Delay(t *testing.T) {
+	start := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	window := time.Minute
+	kb := types.NamespacedName{Namespace: "ns", Name: "kb"}
+
+	// disabled
+	require.Zero(t, NewStartupJitter(0, start).Delay(kb, start))
+
+	// only the first reconciliation is delayed
+	jitter := NewStartupJitter(window, start)
+	delay := jitter.Delay(kb, start)
+	require.True(t, delay >= 0 && delay < window)
+	require.Equal(t, jitter.offset(kb), delay)
+	require.Zero(t, jitter.Delay(kb, start))
+
+	// the delay accounts for the time elapsed since the start
+	jitter = NewStartupJitter(window, start)
+	require.Equal(t, delay-time.Duration(delay/2), jitter.Delay(kb, start.Add(delay/2)))
+	jitter = NewStartupJitter(window, start)
+	require.Zero(t, jitter.Delay(kb, start.Add(delay)))
+
+	// no delay once the window is over
+	jitter = NewStartupJitter(window, start)
+	require.Zero(t, jitter.Delay(kb, start.Add(window)))
+
+	// associations are spread over the window
+	jitter = NewStartupJitter(window, start)
+	offsets := make(map[time.Duration]struct{})
+	for i := 0; i < 100; i++ {
+		offsets[jitter.Delay(types.NamespacedName{Namespace: "ns", Name: fmt.Sprintf("kb-%d", i)}, start)] = struct{}{}
+	}
+	require.True(t, len(offsets) > 90)
+}
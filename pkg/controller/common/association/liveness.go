@@ -0,0 +1,75 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// ReconciliationLiveness tracks the reconciliations of an association controller to detect a wedged reconcile loop:
+// the controller is not live if a reconciliation has been running for longer than a given threshold. An idle
+// controller, or one whose reconciliations keep failing, is still live.
+type ReconciliationLiveness struct {
+	mutex       sync.Mutex
+	threshold   time.Duration
+	running     map[types.NamespacedName]time.Time
+	lastSuccess time.Time
+}
+
+// NewReconciliationLiveness returns a ReconciliationLiveness considering reconciliations running for longer than the
+// given threshold as wedged.
+func NewReconciliationLiveness(threshold time.Duration) *ReconciliationLiveness {
+	return &ReconciliationLiveness{
+		threshold: threshold,
+		running:   make(map[types.NamespacedName]time.Time),
+	}
+}
+
+// Started records the start of the reconciliation of the given association.
+func (l *ReconciliationLiveness) Started(association types.NamespacedName, now time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.running[association] = now
+}
+
+// Completed records the completion of the reconciliation of the given association, successful if err is nil.
+func (l *ReconciliationLiveness) Completed(association types.NamespacedName, now time.Time, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.running, association)
+	if err == nil {
+		l.lastSuccess = now
+	}
+}
+
+// Check returns an error if a reconciliation has been running for longer than the threshold, reporting the time since
+// the last successful reconciliation.
+func (l *ReconciliationLiveness) Check(now time.Time) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for association, since := range l.running {
+		if elapsed := now.Sub(since); elapsed > l.threshold {
+			lastSuccess := "never"
+			if !l.lastSuccess.IsZero() {
+				lastSuccess = now.Sub(l.lastSuccess).String() + " ago"
+			}
+			return fmt.Errorf("reconciliation of %s running for %s, last successful reconciliation %s", association, elapsed, lastSuccess)
+		}
+	}
+	return nil
+}
+
+// Checker returns a health checker failing while a reconciliation has been running for longer than the threshold.
+func (l *ReconciliationLiveness) Checker() healthz.Checker {
+	return func(_ *http.Request) error {
+		return l.Check(time.Now())
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReconciliationLiveness_Check(t *testing.T) {
+	assoc := types.NamespacedName{Namespace: "ns", Name: "kb"}
+	now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	l := NewReconciliationLiveness(time.Minute)
+
+	// idle
+	require.NoError(t, l.Check(now))
+
+	// wedged before any successful reconciliation
+	l.Started(assoc, now)
+	require.NoError(t, l.Check(now.Add(time.Minute)))
+	require.EqualError(t, l.Check(now.Add(2*time.Minute)), "reconciliation of ns/kb running for 2m0s, last successful reconciliation never")
+
+	// failed reconciliations do not make the controller wedged
+	l.Completed(assoc, now.Add(2*time.Minute), errors.New("boom"))
+	require.NoError(t, l.Check(now.Add(time.Hour)))
+
+	// wedged after a successful reconciliation
+	l.Started(assoc, now.Add(time.Hour))
+	l.Completed(assoc, now.Add(time.Hour), nil)
+	l.Started(assoc, now.Add(2*time.Hour))
+	require.EqualError(t, l.Check(now.Add(2*time.Hour+5*time.Minute)), "reconciliation of ns/kb running for 5m0s, last successful reconciliation 1h5m0s ago")
+
+	require.Error(t, l.Checker()(nil))
+}
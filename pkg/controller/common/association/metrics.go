@@ -0,0 +1,101 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+// reconciliationsTotal counts the reconciliations of associations by kind and namespace of associated resource, and
+// resulting status.
+var reconciliationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "elastic",
+	Subsystem: "association",
+	Name:      "reconciliations_total",
+	Help:      "Number of association reconciliations by associated resource kind, namespace and resulting status",
+}, []string{"kind", "namespace", "status"})
+
+// reconciliationDuration observes the duration of the reconciliations of associations by kind and namespace of
+// associated resource.
+var reconciliationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "elastic",
+	Subsystem: "association",
+	Name:      "reconciliation_duration_seconds",
+	Help:      "Duration of association reconciliations by associated resource kind and namespace",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"kind", "namespace"})
+
+// associations counts the associations by kind and namespace of associated resource, and status.
+var associations = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "elastic",
+	Subsystem: "association",
+	Name:      "associations",
+	Help:      "Number of associations by associated resource kind, namespace and status",
+}, []string{"kind", "namespace", "status"})
+
+func init() {
+	// exposed along with the controller-runtime metrics
+	metrics.Registry.MustRegister(reconciliationsTotal, reconciliationDuration, associations)
+}
+
+// RecordReconciliation records the reconciliation of an association in the given namespace, resulting in the given
+// status.
+func RecordReconciliation(kind string, namespace string, status commonv1.AssociationStatus) {
+	reconciliationsTotal.WithLabelValues(kind, namespace, string(status)).Inc()
+}
+
+// RecordReconciliationDuration records the duration of a reconciliation of an association in the given namespace,
+// started at the given time. It is meant to be deferred at the beginning of the reconciliation.
+func RecordReconciliationDuration(kind string, namespace string, start time.Time) {
+	reconciliationDuration.WithLabelValues(kind, namespace).Observe(time.Since(start).Seconds())
+}
+
+// associationKey identifies an associated resource across kinds.
+type associationKey struct {
+	kind string
+	types.NamespacedName
+}
+
+var (
+	statusesMutex sync.Mutex
+	// statuses are the last recorded statuses of the associations, to maintain the associations gauge
+	statuses = make(map[associationKey]commonv1.AssociationStatus)
+)
+
+// RecordStatus records the current status of the given association in the associations gauge.
+func RecordStatus(kind string, associated types.NamespacedName, status commonv1.AssociationStatus) {
+	statusesMutex.Lock()
+	defer statusesMutex.Unlock()
+	key := associationKey{kind: kind, NamespacedName: associated}
+	previous, exists := statuses[key]
+	if exists && previous == status {
+		return
+	}
+	if exists {
+		associations.WithLabelValues(kind, associated.Namespace, string(previous)).Dec()
+	}
+	statuses[key] = status
+	associations.WithLabelValues(kind, associated.Namespace, string(status)).Inc()
+}
+
+// ForgetStatus removes the given association from the associations gauge, once it is deleted.
+func ForgetStatus(kind string, associated types.NamespacedName) {
+	statusesMutex.Lock()
+	defer statusesMutex.Unlock()
+	key := associationKey{kind: kind, NamespacedName: associated}
+	previous, exists := statuses[key]
+	if !exists {
+		return
+	}
+	associations.WithLabelValues(kind, associated.Namespace, string(previous)).Dec()
+	delete(statuses, key)
+}
@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+func TestRecordReconciliation(t *testing.T) {
+	counter := func(namespace string, status commonv1.AssociationStatus) float64 {
+		return testutil.ToFloat64(reconciliationsTotal.WithLabelValues("Kibana", namespace, string(status)))
+	}
+	before := counter("team-a", commonv1.AssociationEstablished)
+	beforeOther := counter("team-b", commonv1.AssociationEstablished)
+	RecordReconciliation("Kibana", "team-a", commonv1.AssociationEstablished)
+	RecordReconciliation("Kibana", "team-a", commonv1.AssociationFailed)
+	RecordReconciliation("Kibana", "team-b", commonv1.AssociationEstablished)
+	// reconciliations are counted per namespace
+	require.Equal(t, before+1, counter("team-a", commonv1.AssociationEstablished))
+	require.Equal(t, beforeOther+1, counter("team-b", commonv1.AssociationEstablished))
+}
+
+func TestRecordStatus(t *testing.T) {
+	kb := types.NamespacedName{Namespace: "status-test", Name: "kb"}
+	other := types.NamespacedName{Namespace: "status-test", Name: "other"}
+	gauge := func(status commonv1.AssociationStatus) float64 {
+		return testutil.ToFloat64(associations.WithLabelValues("Kibana", "status-test", string(status)))
+	}
+
+	RecordStatus("Kibana", kb, commonv1.AssociationPending)
+	RecordStatus("Kibana", other, commonv1.AssociationPending)
+	require.Equal(t, float64(2), gauge(commonv1.AssociationPending))
+	// recording the same status again does not count the association twice
+	RecordStatus("Kibana", kb, commonv1.AssociationPending)
+	require.Equal(t, float64(2), gauge(commonv1.AssociationPending))
+
+	// the association moves to another status
+	RecordStatus("Kibana", kb, commonv1.AssociationEstablished)
+	require.Equal(t, float64(1), gauge(commonv1.AssociationPending))
+	require.Equal(t, float64(1), gauge(commonv1.AssociationEstablished))
+
+	// deleted associations are not counted anymore
+	ForgetStatus("Kibana", kb)
+	ForgetStatus("Kibana", kb)
+	require.Equal(t, float64(0), gauge(commonv1.AssociationEstablished))
+	require.Equal(t, float64(1), gauge(commonv1.AssociationPending))
+}
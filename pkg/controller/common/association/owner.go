@@ -0,0 +1,80 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// OwnerRefMode controls how the resources derived from an association in the namespace of the associated resource
+// (Elasticsearch CA copy, user credentials) are owned and cleaned up.
+type OwnerRefMode string
+
+const (
+	// OwnerRefModeAssociated sets the associated resource as the controller owner of the derived resources, so they are
+	// garbage collected by Kubernetes on top of the clean up performed by the association controller. This is the default.
+	OwnerRefModeAssociated OwnerRefMode = "associated-owns"
+	// OwnerRefModeCleanup does not set any owner reference on the derived resources. They are exclusively removed by the
+	// association controller, based on their labels, when the association is removed.
+	// This is intended for environments where setting owner references is restricted.
+	OwnerRefModeCleanup OwnerRefMode = "cleanup"
+)
+
+// DefaultOwnerRefMode is the OwnerRefMode used if none is specified.
+const DefaultOwnerRefMode = OwnerRefModeAssociated
+
+// ParseOwnerRefMode returns the OwnerRefMode corresponding to the given string, or an error if it is not supported.
+// An empty string results in the default mode.
+func ParseOwnerRefMode(mode string) (OwnerRefMode, error) {
+	switch OwnerRefMode(mode) {
+	case "":
+		return DefaultOwnerRefMode, nil
+	case OwnerRefModeAssociated, OwnerRefModeCleanup:
+		return OwnerRefMode(mode), nil
+	default:
+		return "", fmt.Errorf("unsupported owner reference mode %q, must be one of [%s, %s]", mode, OwnerRefModeAssociated, OwnerRefModeCleanup)
+	}
+}
+
+// Owner returns the object to set as the owner of a derived resource, or nil if no owner reference should be set.
+func (m OwnerRefMode) Owner(associated metav1.Object) metav1.Object {
+	if m == OwnerRefModeCleanup {
+		return nil
+	}
+	return associated
+}
+
+// DeleteDerivedSecrets deletes the Secrets derived from an association which match the given labels in the given namespace.
+// Derived secrets are garbage collected through their owner reference, unless the OwnerRefModeCleanup mode is used:
+// in this case they must be explicitly deleted.
+func DeleteDerivedSecrets(c k8s.Client, mode OwnerRefMode, namespace string, labels map[string]string) error {
+	if mode != OwnerRefModeCleanup {
+		return nil
+	}
+	return DeleteSecrets(c, namespace, labels)
+}
+
+// DeleteSecrets deletes the Secrets matching the given labels in the given namespace.
+func DeleteSecrets(c k8s.Client, namespace string, labels map[string]string) error {
+	var secrets corev1.SecretList
+	if err := c.List(&secrets, client.InNamespace(namespace), client.MatchingLabels(labels)); err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		s := &secrets.Items[i]
+		log.Info("Deleting secret", "namespace", s.Namespace, "secret_name", s.Name)
+		if err := c.Delete(s); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
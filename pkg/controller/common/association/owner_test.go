@@ -0,0 +1,110 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestParseOwnerRefMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		want    OwnerRefMode
+		wantErr bool
+	}{
+		{
+			name: "empty: default mode",
+			mode: "",
+			want: OwnerRefModeAssociated,
+		},
+		{
+			name: "associated resource owns",
+			mode: "associated-owns",
+			want: OwnerRefModeAssociated,
+		},
+		{
+			name: "cleanup",
+			mode: "cleanup",
+			want: OwnerRefModeCleanup,
+		},
+		{
+			name:    "unsupported mode",
+			mode:    "kibana-owns",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOwnerRefMode(tt.mode)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOwnerRefMode_Owner(t *testing.T) {
+	require.Equal(t, &kibanaFixture, OwnerRefModeAssociated.Owner(&kibanaFixture))
+	require.Equal(t, &kibanaFixture, OwnerRefMode("").Owner(&kibanaFixture))
+	require.Nil(t, OwnerRefModeCleanup.Owner(&kibanaFixture))
+}
+
+func TestDeleteDerivedSecrets(t *testing.T) {
+	labels := map[string]string{associationLabelName: kibanaFixture.Name}
+	derived := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: kibanaFixture.Namespace,
+			Name:      "derived",
+			Labels:    labels,
+		},
+	}
+	otherNamespace := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "other",
+			Name:      "derived",
+			Labels:    labels,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		mode        OwnerRefMode
+		wantDeleted bool
+	}{
+		{
+			name:        "owner references: rely on the garbage collector",
+			mode:        OwnerRefModeAssociated,
+			wantDeleted: false,
+		},
+		{
+			name:        "cleanup: delete derived secrets",
+			mode:        OwnerRefModeCleanup,
+			wantDeleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrappedFakeClient(derived.DeepCopy(), otherNamespace.DeepCopy())
+			require.NoError(t, DeleteDerivedSecrets(c, tt.mode, kibanaFixture.Namespace, labels))
+
+			err := c.Get(k8s.ExtractNamespacedName(&derived), &corev1.Secret{})
+			require.Equal(t, tt.wantDeleted, apierrors.IsNotFound(err))
+			// secrets in other namespaces are left untouched
+			require.NoError(t, c.Get(types.NamespacedName{Namespace: "other", Name: "derived"}, &corev1.Secret{}))
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+)
+
+// ConfOwner returns who manages the Elasticsearch configuration of the given object according to its es-conf-owner
+// annotation, the association controller if not set.
+func ConfOwner(meta metav1.ObjectMeta) (commonv1.AssociationConfOwner, error) {
+	switch owner := commonv1.AssociationConfOwner(meta.Annotations[annotation.ConfOwnerAnnotation]); owner {
+	case "", commonv1.AssociationConfOwnerOperator:
+		return commonv1.AssociationConfOwnerOperator, nil
+	case commonv1.AssociationConfOwnerManual:
+		return owner, nil
+	default:
+		return "", fmt.Errorf("invalid value %q for annotation %s, expected %s or %s",
+			owner, annotation.ConfOwnerAnnotation, commonv1.AssociationConfOwnerOperator, commonv1.AssociationConfOwnerManual)
+	}
+}
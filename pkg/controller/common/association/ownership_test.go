@@ -0,0 +1,36 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+)
+
+func TestConfOwner(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        commonv1.AssociationConfOwner
+		wantErr     bool
+	}{
+		{name: "no annotation", want: commonv1.AssociationConfOwnerOperator},
+		{name: "operator", annotations: map[string]string{annotation.ConfOwnerAnnotation: "operator"}, want: commonv1.AssociationConfOwnerOperator},
+		{name: "manual", annotations: map[string]string{annotation.ConfOwnerAnnotation: "manual"}, want: commonv1.AssociationConfOwnerManual},
+		{name: "invalid", annotations: map[string]string{annotation.ConfOwnerAnnotation: "someone"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, err := ConfOwner(metav1.ObjectMeta{Annotations: tt.annotations})
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, owner)
+		})
+	}
+}
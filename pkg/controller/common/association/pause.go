@@ -0,0 +1,30 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+)
+
+// PausedFor returns how long the association of the given object remains paused according to its paused-until
+// annotation, and whether it is currently paused. An invalid timestamp does not pause the association.
+func PausedFor(meta metav1.ObjectMeta, now time.Time) (time.Duration, bool) {
+	value, exists := meta.Annotations[annotation.PausedUntilAnnotation]
+	if !exists || value == "" {
+		return 0, false
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Error(err, "Cannot parse association pause timestamp, ignoring it",
+			"annotation", annotation.PausedUntilAnnotation, "namespace", meta.Namespace, "name", meta.Name)
+		return 0, false
+	}
+	remaining := until.Sub(now)
+	return remaining, remaining > 0
+}
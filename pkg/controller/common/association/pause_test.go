@@ -0,0 +1,62 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+)
+
+func TestPausedFor(t *testing.T) {
+	now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		wantRemaining time.Duration
+		wantPaused    bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:        "empty annotation",
+			annotations: map[string]string{annotation.PausedUntilAnnotation: ""},
+		},
+		{
+			name:        "invalid timestamp",
+			annotations: map[string]string{annotation.PausedUntilAnnotation: "tomorrow"},
+		},
+		{
+			name:          "timestamp in the future",
+			annotations:   map[string]string{annotation.PausedUntilAnnotation: "2020-01-01T12:30:00Z"},
+			wantRemaining: 150 * time.Minute,
+			wantPaused:    true,
+		},
+		{
+			name:          "timestamp in the future with a time zone offset",
+			annotations:   map[string]string{annotation.PausedUntilAnnotation: "2020-01-01T11:30:00+01:00"},
+			wantRemaining: 30 * time.Minute,
+			wantPaused:    true,
+		},
+		{
+			name:          "timestamp in the past",
+			annotations:   map[string]string{annotation.PausedUntilAnnotation: "2020-01-01T09:00:00Z"},
+			wantRemaining: -time.Hour,
+			wantPaused:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, paused := PausedFor(metav1.ObjectMeta{Annotations: tt.annotations}, now)
+			require.Equal(t, tt.wantPaused, paused)
+			require.Equal(t, tt.wantRemaining, remaining)
+		})
+	}
+}
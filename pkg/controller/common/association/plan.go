@@ -0,0 +1,71 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+// redacted replaces sensitive values in planned changes.
+const redacted = "REDACTED"
+
+// PlannedChange describes the change from the current to the expected association configuration of a resource in the
+// given namespace, one line per modified field, or returns an empty string if both are equivalent. The configuration
+// only references secrets, but credentials embedded in URLs are redacted.
+func PlannedChange(current, expected *commonv1.AssociationConf, associatedNamespace string) string {
+	if expected.Equivalent(current, associatedNamespace) {
+		return ""
+	}
+	before := plannedFields(current, associatedNamespace)
+	after := plannedFields(expected, associatedNamespace)
+	var lines []string
+	for i := range before {
+		if before[i].value != after[i].value {
+			lines = append(lines, fmt.Sprintf("%s: %q -> %q", before[i].name, before[i].value, after[i].value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+type plannedField struct {
+	name  string
+	value string
+}
+
+// plannedFields returns the fields of the given association configuration compared by PlannedChange, in a stable order.
+func plannedFields(conf *commonv1.AssociationConf, associatedNamespace string) []plannedField {
+	var authSecretNamespace string
+	if conf.AuthIsConfigured() {
+		authSecretNamespace = conf.AuthSecretRef(associatedNamespace).Namespace
+	}
+	return []plannedField{
+		{name: "authSecretName", value: conf.GetAuthSecretName()},
+		{name: "authSecretKey", value: conf.GetAuthSecretKey()},
+		{name: "authSecretNamespace", value: authSecretNamespace},
+		{name: "caCertProvided", value: strconv.FormatBool(conf.GetCACertProvided())},
+		{name: "caSecretName", value: conf.GetCASecretName()},
+		{name: "url", value: redactURL(strings.TrimRight(conf.GetURL(), "/"))},
+		{name: "sslVerificationMode", value: conf.GetSSLVerificationMode()},
+	}
+}
+
+// redactURL redacts the user information of the given URL, if any.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// do not risk leaking an unparsable value
+		return redacted
+	}
+	if u.User == nil {
+		return rawURL
+	}
+	u.User = url.User(redacted)
+	return u.String()
+}
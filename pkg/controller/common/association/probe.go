@@ -0,0 +1,108 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultProbeTimeout is the default timeout of a single Elasticsearch probe attempt.
+	DefaultProbeTimeout = 5 * time.Second
+	// DefaultProbeRetries is the default number of times a failed Elasticsearch probe is retried.
+	DefaultProbeRetries = 2
+	// probeRetryInterval is the time waited for between two probe attempts.
+	probeRetryInterval = 1 * time.Second
+)
+
+// ProbeFunc performs a single attempt of an Elasticsearch probe.
+type ProbeFunc func(ctx context.Context) error
+
+// ProbeConfig bounds the time spent probing Elasticsearch: each attempt is given Timeout to complete, and failed
+// attempts are retried Retries times, so that a slow Elasticsearch does not stall the reconciliation for long nor
+// cause a false failure.
+type ProbeConfig struct {
+	Timeout time.Duration
+	Retries int
+
+	// retryInterval is the time waited for between two attempts, overridden in tests.
+	retryInterval time.Duration
+}
+
+// NewProbeConfig returns a ProbeConfig with the given timeout and retries, falling back to the defaults for
+// non-positive timeouts and negative retries.
+func NewProbeConfig(timeout time.Duration, retries int) ProbeConfig {
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	if retries < 0 {
+		retries = DefaultProbeRetries
+	}
+	return ProbeConfig{Timeout: timeout, Retries: retries, retryInterval: probeRetryInterval}
+}
+
+// Run runs the given probe until it succeeds, it fails with an error for which retryable returns false, or all
+// attempts are exhausted. The error of the last attempt is returned.
+func (c ProbeConfig) Run(ctx context.Context, probe ProbeFunc, retryable func(error) bool) error {
+	var err error
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(c.retryInterval):
+			}
+		}
+		if err = c.attempt(ctx, probe); err == nil || !retryable(err) {
+			return err
+		}
+		log.V(1).Info("Elasticsearch probe attempt failed", "attempt", attempt+1, "error", err.Error())
+	}
+	return err
+}
+
+func (c ProbeConfig) attempt(ctx context.Context, probe ProbeFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	return probe(ctx)
+}
+
+// ProbeVerdicts keeps the last verdict of a probe for each association, so that it can be reused while the probe
+// cannot be run, for example when it is rate limited or Elasticsearch does not respond.
+type ProbeVerdicts struct {
+	mutex    sync.Mutex
+	verdicts map[types.NamespacedName]bool
+}
+
+// NewProbeVerdicts returns an empty ProbeVerdicts.
+func NewProbeVerdicts() *ProbeVerdicts {
+	return &ProbeVerdicts{verdicts: make(map[types.NamespacedName]bool)}
+}
+
+// Get returns the last verdict of the given association, true if the probe never concluded.
+func (v *ProbeVerdicts) Get(association types.NamespacedName) bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	verdict, exists := v.verdicts[association]
+	return !exists || verdict
+}
+
+// Set records the last verdict of the given association.
+func (v *ProbeVerdicts) Set(association types.NamespacedName, verdict bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.verdicts[association] = verdict
+}
+
+// Forget removes the verdict of the given association.
+func (v *ProbeVerdicts) Forget(association types.NamespacedName) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	delete(v.verdicts, association)
+}
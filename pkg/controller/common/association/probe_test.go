@@ -0,0 +1,82 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestNewProbeConfig(t *testing.T) {
+	require.Equal(t, DefaultProbeTimeout, NewProbeConfig(0, -1).Timeout)
+	require.Equal(t, DefaultProbeRetries, NewProbeConfig(0, -1).Retries)
+	config := NewProbeConfig(time.Second, 0)
+	require.Equal(t, time.Second, config.Timeout)
+	require.Equal(t, 0, config.Retries)
+}
+
+func TestProbeConfig_Run(t *testing.T) {
+	errTransient := errors.New("transient")
+	errPermanent := errors.New("permanent")
+	retryable := func(err error) bool { return err != errPermanent }
+	// probe returns the given errors, one per attempt, then succeeds
+	probe := func(attempts *int, errs ...error) ProbeFunc {
+		return func(ctx context.Context) error {
+			*attempts++
+			if *attempts <= len(errs) {
+				return errs[*attempts-1]
+			}
+			return nil
+		}
+	}
+	config := ProbeConfig{Timeout: time.Second, Retries: 2}
+
+	tests := []struct {
+		name         string
+		errs         []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "success", wantAttempts: 1},
+		{name: "success after retries", errs: []error{errTransient, errTransient}, wantAttempts: 3},
+		{name: "attempts exhausted", errs: []error{errTransient, errTransient, errTransient}, wantErr: errTransient, wantAttempts: 3},
+		{name: "no retry on permanent error", errs: []error{errTransient, errPermanent}, wantErr: errPermanent, wantAttempts: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := config.Run(context.Background(), probe(&attempts, tt.errs...), retryable)
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
+
+func TestProbeConfig_Run_Timeout(t *testing.T) {
+	config := ProbeConfig{Timeout: 10 * time.Millisecond, Retries: 1}
+	attempts := 0
+	err := config.Run(context.Background(), func(ctx context.Context) error {
+		attempts++
+		<-ctx.Done()
+		return ctx.Err()
+	}, func(error) bool { return true })
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, 2, attempts)
+}
+
+func TestProbeVerdicts(t *testing.T) {
+	kb := types.NamespacedName{Namespace: "ns", Name: "kb"}
+	verdicts := NewProbeVerdicts()
+	require.True(t, verdicts.Get(kb))
+	verdicts.Set(kb, false)
+	require.False(t, verdicts.Get(kb))
+	verdicts.Forget(kb)
+	require.True(t, verdicts.Get(kb))
+}
@@ -0,0 +1,80 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// Provenance describes the resources an association was established with.
+type Provenance struct {
+	ElasticsearchUID        types.UID
+	CASecretResourceVersion string
+}
+
+// UpdateProvenance records the given provenance in the annotations of the associated object.
+// The object is only updated if the provenance changed, along with the provenance update time: updating it on every
+// reconciliation would trigger a new reconciliation of the associated object.
+func UpdateProvenance(client k8s.Client, obj runtime.Object, provenance Provenance, now time.Time) error {
+	accessor := meta.NewAccessor()
+	annotations, err := accessor.Annotations(obj)
+	if err != nil {
+		return err
+	}
+
+	if annotations[annotation.ElasticsearchUIDAnnotation] == string(provenance.ElasticsearchUID) &&
+		annotations[annotation.CASecretResourceVersionAnnotation] == provenance.CASecretResourceVersion {
+		return nil
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[annotation.ElasticsearchUIDAnnotation] = string(provenance.ElasticsearchUID)
+	annotations[annotation.CASecretResourceVersionAnnotation] = provenance.CASecretResourceVersion
+	annotations[annotation.ProvenanceUpdatedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	if err := accessor.SetAnnotations(obj, annotations); err != nil {
+		return err
+	}
+
+	return client.Update(obj)
+}
+
+// RemoveProvenance removes the provenance annotations from the associated object.
+func RemoveProvenance(client k8s.Client, obj runtime.Object) error {
+	accessor := meta.NewAccessor()
+	annotations, err := accessor.Annotations(obj)
+	if err != nil {
+		return err
+	}
+
+	updated := false
+	for _, key := range []string{
+		annotation.ElasticsearchUIDAnnotation,
+		annotation.CASecretResourceVersionAnnotation,
+		annotation.ProvenanceUpdatedAtAnnotation,
+	} {
+		if _, exists := annotations[key]; exists {
+			delete(annotations, key)
+			updated = true
+		}
+	}
+	if !updated {
+		return nil
+	}
+
+	if err := accessor.SetAnnotations(obj, annotations); err != nil {
+		return err
+	}
+
+	return client.Update(obj)
+}
@@ -0,0 +1,47 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateProvenance(t *testing.T) {
+	kb := kibanaFixture.DeepCopy()
+	c := k8s.WrappedFakeClient(kb)
+	provenance := Provenance{ElasticsearchUID: esFixture.UID, CASecretResourceVersion: "1"}
+	firstReconcile := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, UpdateProvenance(c, kb, provenance, firstReconcile))
+	var updated kbv1.Kibana
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(kb), &updated))
+	require.Equal(t, string(esFixture.UID), updated.Annotations[annotation.ElasticsearchUIDAnnotation])
+	require.Equal(t, "1", updated.Annotations[annotation.CASecretResourceVersionAnnotation])
+	require.Equal(t, "2020-01-01T10:00:00Z", updated.Annotations[annotation.ProvenanceUpdatedAtAnnotation])
+
+	// unchanged provenance: no update
+	require.NoError(t, UpdateProvenance(c, &updated, provenance, firstReconcile.Add(time.Hour)))
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(kb), &updated))
+	require.Equal(t, "2020-01-01T10:00:00Z", updated.Annotations[annotation.ProvenanceUpdatedAtAnnotation])
+
+	// new CA secret version
+	provenance.CASecretResourceVersion = "2"
+	require.NoError(t, UpdateProvenance(c, &updated, provenance, firstReconcile.Add(time.Hour)))
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(kb), &updated))
+	require.Equal(t, "2", updated.Annotations[annotation.CASecretResourceVersionAnnotation])
+	require.Equal(t, "2020-01-01T11:00:00Z", updated.Annotations[annotation.ProvenanceUpdatedAtAnnotation])
+
+	require.NoError(t, RemoveProvenance(c, &updated))
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(kb), &updated))
+	require.NotContains(t, updated.Annotations, annotation.ElasticsearchUIDAnnotation)
+	require.NotContains(t, updated.Annotations, annotation.CASecretResourceVersionAnnotation)
+	require.NotContains(t, updated.Annotations, annotation.ProvenanceUpdatedAtAnnotation)
+}
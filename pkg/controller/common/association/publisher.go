@@ -0,0 +1,113 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+// LifecycleEventType is the type of an association lifecycle event.
+type LifecycleEventType string
+
+const (
+	// LifecycleEventCreated is published when an association is reconciled for the first time.
+	LifecycleEventCreated LifecycleEventType = "created"
+	// LifecycleEventEstablished is published when an association becomes established.
+	LifecycleEventEstablished LifecycleEventType = "established"
+	// LifecycleEventFailed is published when an association fails.
+	LifecycleEventFailed LifecycleEventType = "failed"
+	// LifecycleEventDeleted is published when an association is removed, along with the associated resource or not.
+	LifecycleEventDeleted LifecycleEventType = "deleted"
+)
+
+// LifecycleEvent describes a transition in the lifecycle of an association.
+type LifecycleEvent struct {
+	Type LifecycleEventType `json:"type"`
+	// Kind, Namespace and Name identify the associated resource.
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Status is the association status after the transition.
+	Status commonv1.AssociationStatus `json:"status"`
+	Time   time.Time                  `json:"time"`
+}
+
+// Publisher publishes association lifecycle events, for instance to a message queue.
+// Implementations should not block: Publish is called during reconciliations.
+type Publisher interface {
+	Publish(event LifecycleEvent) error
+}
+
+// NoopPublisher is a Publisher discarding all events. It is used if no Publisher is configured.
+type NoopPublisher struct{}
+
+// Publish implements Publisher.
+func (NoopPublisher) Publish(LifecycleEvent) error {
+	return nil
+}
+
+// LifecycleEventTypes returns the types of the lifecycle events corresponding to the given association status change.
+func LifecycleEventTypes(previous, current commonv1.AssociationStatus) []LifecycleEventType {
+	if previous == current {
+		return nil
+	}
+	var eventTypes []LifecycleEventType
+	if previous == commonv1.AssociationUnknown {
+		eventTypes = append(eventTypes, LifecycleEventCreated)
+	}
+	switch current {
+	case commonv1.AssociationEstablished:
+		eventTypes = append(eventTypes, LifecycleEventEstablished)
+	case commonv1.AssociationFailed:
+		eventTypes = append(eventTypes, LifecycleEventFailed)
+	case commonv1.AssociationUnknown:
+		eventTypes = append(eventTypes, LifecycleEventDeleted)
+	}
+	return eventTypes
+}
+
+// PublishLifecycleEvents publishes the lifecycle events corresponding to the given association status change. Errors
+// are logged rather than returned so that publication failures do not impact the reconciliation.
+func PublishLifecycleEvents(
+	publisher Publisher,
+	kind string,
+	associated types.NamespacedName,
+	previous, current commonv1.AssociationStatus,
+	now time.Time,
+) {
+	for _, eventType := range LifecycleEventTypes(previous, current) {
+		publish(publisher, LifecycleEvent{
+			Type:      eventType,
+			Kind:      kind,
+			Namespace: associated.Namespace,
+			Name:      associated.Name,
+			Status:    current,
+			Time:      now,
+		})
+	}
+}
+
+// PublishDeletion publishes the deletion of the association of the given associated resource, which was deleted.
+func PublishDeletion(publisher Publisher, kind string, associated types.NamespacedName, now time.Time) {
+	publish(publisher, LifecycleEvent{
+		Type:      LifecycleEventDeleted,
+		Kind:      kind,
+		Namespace: associated.Namespace,
+		Name:      associated.Name,
+		Status:    commonv1.AssociationUnknown,
+		Time:      now,
+	})
+}
+
+func publish(publisher Publisher, event LifecycleEvent) {
+	if err := publisher.Publish(event); err != nil {
+		log.Error(err, "Failed to publish association lifecycle event",
+			"type", event.Type, "namespace", event.Namespace, "name", event.Name)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+type fakePublisher struct {
+	events []LifecycleEvent
+	err    error
+}
+
+func (p *fakePublisher) Publish(event LifecycleEvent) error {
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func TestLifecycleEventTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous commonv1.AssociationStatus
+		current  commonv1.AssociationStatus
+		want     []LifecycleEventType
+	}{
+		{
+			name:     "no change",
+			previous: commonv1.AssociationEstablished,
+			current:  commonv1.AssociationEstablished,
+		},
+		{
+			name:     "created",
+			previous: commonv1.AssociationUnknown,
+			current:  commonv1.AssociationPending,
+			want:     []LifecycleEventType{LifecycleEventCreated},
+		},
+		{
+			name:     "created and established",
+			previous: commonv1.AssociationUnknown,
+			current:  commonv1.AssociationEstablished,
+			want:     []LifecycleEventType{LifecycleEventCreated, LifecycleEventEstablished},
+		},
+		{
+			name:     "established",
+			previous: commonv1.AssociationPending,
+			current:  commonv1.AssociationEstablished,
+			want:     []LifecycleEventType{LifecycleEventEstablished},
+		},
+		{
+			name:     "failed",
+			previous: commonv1.AssociationEstablished,
+			current:  commonv1.AssociationFailed,
+			want:     []LifecycleEventType{LifecycleEventFailed},
+		},
+		{
+			name:     "pending again",
+			previous: commonv1.AssociationEstablished,
+			current:  commonv1.AssociationPending,
+		},
+		{
+			name:     "deleted",
+			previous: commonv1.AssociationEstablished,
+			current:  commonv1.AssociationUnknown,
+			want:     []LifecycleEventType{LifecycleEventDeleted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, LifecycleEventTypes(tt.previous, tt.current))
+		})
+	}
+}
+
+func TestPublishLifecycleEvents(t *testing.T) {
+	now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	kb := types.NamespacedName{Namespace: "ns", Name: "kb"}
+	// errors do not prevent the other events from being published
+	publisher := &fakePublisher{err: errors.New("queue unavailable")}
+	PublishLifecycleEvents(publisher, "Kibana", kb, commonv1.AssociationUnknown, commonv1.AssociationEstablished, now)
+	require.Equal(t, []LifecycleEvent{
+		{Type: LifecycleEventCreated, Kind: "Kibana", Namespace: "ns", Name: "kb", Status: commonv1.AssociationEstablished, Time: now},
+		{Type: LifecycleEventEstablished, Kind: "Kibana", Namespace: "ns", Name: "kb", Status: commonv1.AssociationEstablished, Time: now},
+	}, publisher.events)
+}
@@ -0,0 +1,73 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultESCallsInterval is the default minimum interval between two Elasticsearch API calls performed on behalf of
+	// the same association, once the burst is consumed.
+	DefaultESCallsInterval = 10 * time.Second
+	// DefaultESCallsBurst is the default number of Elasticsearch API calls an association can perform in a row.
+	DefaultESCallsBurst = 3
+)
+
+// ESCallsLimiter rate limits the Elasticsearch API calls (health checks, authentication verification) performed by
+// an association controller, independently for each association. This prevents a flapping association from
+// overwhelming the Elasticsearch cluster with probe traffic, without impacting the other associations.
+type ESCallsLimiter struct {
+	mutex    sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[types.NamespacedName]*rate.Limiter
+}
+
+// NewESCallsLimiter returns an ESCallsLimiter allowing burst calls in a row, then one call per interval, for each association.
+func NewESCallsLimiter(interval time.Duration, burst int) *ESCallsLimiter {
+	return &ESCallsLimiter{
+		limit:    rate.Every(interval),
+		burst:    burst,
+		limiters: make(map[types.NamespacedName]*rate.Limiter),
+	}
+}
+
+// Allow returns true if the given association is allowed to perform an Elasticsearch API call now.
+func (l *ESCallsLimiter) Allow(association types.NamespacedName) bool {
+	return l.limiter(association).Allow()
+}
+
+// Delay returns how long the given association must wait before performing its next Elasticsearch API call, without
+// consuming any call.
+func (l *ESCallsLimiter) Delay(association types.NamespacedName) time.Duration {
+	// the reservation must be cancelled at the time it was made for its token to be restored
+	now := time.Now()
+	r := l.limiter(association).ReserveN(now, 1)
+	defer r.CancelAt(now)
+	return r.DelayFrom(now)
+}
+
+// Forget removes the rate limiting state of the given association.
+func (l *ESCallsLimiter) Forget(association types.NamespacedName) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.limiters, association)
+}
+
+func (l *ESCallsLimiter) limiter(association types.NamespacedName) *rate.Limiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	limiter, exists := l.limiters[association]
+	if !exists {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[association] = limiter
+	}
+	return limiter
+}
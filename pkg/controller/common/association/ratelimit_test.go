@@ -0,0 +1,35 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestESCallsLimiter(t *testing.T) {
+	l := NewESCallsLimiter(time.Hour, 2)
+	a := types.NamespacedName{Namespace: "ns", Name: "a"}
+	b := types.NamespacedName{Namespace: "ns", Name: "b"}
+
+	// burst is allowed
+	require.True(t, l.Allow(a))
+	require.True(t, l.Allow(a))
+	// then the association is rate limited
+	require.False(t, l.Allow(a))
+	require.True(t, l.Delay(a) > 0)
+	// other associations are not impacted, checking the delay does not consume any call
+	require.Equal(t, time.Duration(0), l.Delay(b))
+	require.Equal(t, time.Duration(0), l.Delay(b))
+	require.True(t, l.Allow(b))
+	require.True(t, l.Allow(b))
+	require.False(t, l.Allow(b))
+	// forgetting the association resets its state
+	l.Forget(a)
+	require.True(t, l.Allow(a))
+}
@@ -0,0 +1,102 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// credentialsTTL parses the credentials TTL annotation of the associated resource. It returns zero if credentials
+// are not rotated.
+func credentialsTTL(associated commonv1.Associated) (time.Duration, error) {
+	value := associated.GetAnnotations()[annotation.CredentialsTTLAnnotation]
+	if value == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid credentials TTL %q: %v", value, err)
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("invalid credentials TTL %q: must be positive", value)
+	}
+	return ttl, nil
+}
+
+// credentialsRotatedAt returns when the password of the given credentials secret was generated: the time of its last
+// rotation, or its creation time if it was never rotated.
+func credentialsRotatedAt(secret corev1.Secret) time.Time {
+	if value, exists := secret.Annotations[annotation.CredentialsRotatedAtAnnotation]; exists {
+		if rotatedAt, err := time.Parse(time.RFC3339, value); err == nil {
+			return rotatedAt
+		}
+	}
+	return secret.CreationTimestamp.Time
+}
+
+// untilRotation returns how long the password of the given credentials secret remains valid for the given TTL.
+// It returns a non-positive duration if the password must be rotated.
+func untilRotation(secret corev1.Secret, ttl time.Duration, now time.Time) time.Duration {
+	rotatedAt := credentialsRotatedAt(secret)
+	if rotatedAt.IsZero() {
+		// unknown age, consider the password was just generated
+		return ttl
+	}
+	return rotatedAt.Add(ttl).Sub(now)
+}
+
+// credentialsRotationDue returns true if the password of the credentials secret of the associated resource must be
+// rotated according to its TTL.
+func credentialsRotationDue(c k8s.Client, associated commonv1.Associated, key types.NamespacedName, now time.Time) (bool, error) {
+	ttl, err := credentialsTTL(associated)
+	if err != nil || ttl == 0 {
+		return false, err
+	}
+	var secret corev1.Secret
+	if err := c.Get(key, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return untilRotation(secret, ttl, now) <= 0, nil
+}
+
+// CredentialsRotationDelay returns how long until the credentials of the associated resource must be rotated, or zero
+// if they are not rotated or do not exist yet. It is intended to requeue the reconciliation of the association once
+// ReconcileEsUser has been called, which performs the rotation.
+func CredentialsRotationDelay(
+	c k8s.Client,
+	associated commonv1.Associated,
+	credentialsNamespace string,
+	userSuffix string,
+	now time.Time,
+) (time.Duration, error) {
+	ttl, err := credentialsTTL(associated)
+	if err != nil || ttl == 0 {
+		return 0, err
+	}
+	var secret corev1.Secret
+	if err := c.Get(secretKey(associated, credentialsNamespace, userSuffix), &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if delay := untilRotation(secret, ttl, now); delay > 0 {
+		return delay, nil
+	}
+	// already rotated by ReconcileEsUser
+	return 0, nil
+}
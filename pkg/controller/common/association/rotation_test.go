@@ -0,0 +1,87 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestCredentialsRotationDelay(t *testing.T) {
+	now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	withTTL := func(ttl string) *kbv1.Kibana {
+		kb := kibanaFixture.DeepCopy()
+		if ttl != "" {
+			kb.Annotations = map[string]string{annotation.CredentialsTTLAnnotation: ttl}
+		}
+		return kb
+	}
+	credentials := func(createdAt time.Time, rotatedAt string) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              userSecretName,
+			CreationTimestamp: metav1.NewTime(createdAt),
+		}}
+		if rotatedAt != "" {
+			s.Annotations = map[string]string{annotation.CredentialsRotatedAtAnnotation: rotatedAt}
+		}
+		return s
+	}
+	tests := []struct {
+		name    string
+		kibana  *kbv1.Kibana
+		objs    []runtime.Object
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name:   "no TTL",
+			kibana: withTTL(""),
+			objs:   []runtime.Object{credentials(now.Add(-time.Hour), "")},
+		},
+		{
+			name:    "invalid TTL",
+			kibana:  withTTL("-1h"),
+			wantErr: true,
+		},
+		{
+			name:   "no credentials yet",
+			kibana: withTTL("1h"),
+		},
+		{
+			name:   "credentials never rotated",
+			kibana: withTTL("1h"),
+			objs:   []runtime.Object{credentials(now.Add(-20*time.Minute), "")},
+			want:   40 * time.Minute,
+		},
+		{
+			name:   "credentials rotated",
+			kibana: withTTL("1h"),
+			objs:   []runtime.Object{credentials(now.Add(-2*time.Hour), now.Add(-30*time.Minute).Format(time.RFC3339))},
+			want:   30 * time.Minute,
+		},
+		{
+			name:   "rotation overdue",
+			kibana: withTTL("1h"),
+			objs:   []runtime.Object{credentials(now.Add(-2*time.Hour), "")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CredentialsRotationDelay(k8s.WrappedFakeClient(tt.objs...), tt.kibana, "", "kibana-user", now)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TenantLabelName attributes an associated resource to a tenant of a shared operator. The tenant is propagated to the
+// resources derived from the association, to its events and to its logs. It is not used as a metrics label, since its
+// values are set by users and would not bound the number of series.
+const TenantLabelName = "association.k8s.elastic.co/tenant"
+
+// Tenant returns the tenant the given associated resource is attributed to, empty if none.
+func Tenant(associated metav1.Object) string {
+	return associated.GetLabels()[TenantLabelName]
+}
+
+// WithTenant adds the tenant label to the given labels, unless the tenant is empty. The given labels are returned.
+func WithTenant(labels map[string]string, tenant string) map[string]string {
+	if tenant == "" {
+		return labels
+	}
+	if labels == nil {
+		labels = make(map[string]string, 1)
+	}
+	labels[TenantLabelName] = tenant
+	return labels
+}
@@ -0,0 +1,23 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTenant(t *testing.T) {
+	require.Equal(t, "", Tenant(&metav1.ObjectMeta{}))
+	require.Equal(t, "team-a", Tenant(&metav1.ObjectMeta{Labels: map[string]string{TenantLabelName: "team-a"}}))
+}
+
+func TestWithTenant(t *testing.T) {
+	require.Equal(t, map[string]string{"a": "b"}, WithTenant(map[string]string{"a": "b"}, ""))
+	require.Equal(t, map[string]string{"a": "b", TenantLabelName: "team-a"}, WithTenant(map[string]string{"a": "b"}, "team-a"))
+	require.Equal(t, map[string]string{TenantLabelName: "team-a"}, WithTenant(nil, "team-a"))
+}
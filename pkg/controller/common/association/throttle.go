@@ -0,0 +1,68 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// StatusUpdateThrottle enforces a minimum interval between two status updates of the same association, so that
+// flapping associations do not generate a write to the API server on every reconciliation.
+// Updates are tracked in memory: the first update after an operator restart is never throttled.
+type StatusUpdateThrottle struct {
+	mutex      sync.Mutex
+	interval   time.Duration
+	lastUpdate map[types.NamespacedName]time.Time
+}
+
+// NewStatusUpdateThrottle returns a StatusUpdateThrottle of the given interval. A zero interval disables throttling, as
+// well as a nil StatusUpdateThrottle.
+func NewStatusUpdateThrottle(interval time.Duration) *StatusUpdateThrottle {
+	return &StatusUpdateThrottle{
+		interval:   interval,
+		lastUpdate: make(map[types.NamespacedName]time.Time),
+	}
+}
+
+// Delay returns how long to wait before the status of the given association can be updated, or zero if it can be
+// updated now.
+func (t *StatusUpdateThrottle) Delay(association types.NamespacedName, now time.Time) time.Duration {
+	if t == nil || t.interval <= 0 {
+		return 0
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	last, exists := t.lastUpdate[association]
+	if !exists {
+		return 0
+	}
+	if elapsed := now.Sub(last); elapsed < t.interval {
+		return t.interval - elapsed
+	}
+	return 0
+}
+
+// Updated records that the status of the given association was updated at the given time.
+func (t *StatusUpdateThrottle) Updated(association types.NamespacedName, now time.Time) {
+	if t == nil || t.interval <= 0 {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.lastUpdate[association] = now
+}
+
+// Forget removes the status updates tracking state of the given association.
+func (t *StatusUpdateThrottle) Forget(association types.NamespacedName) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.lastUpdate, association)
+}
@@ -0,0 +1,40 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestStatusUpdateThrottle_Delay(t *testing.T) {
+	assoc := types.NamespacedName{Namespace: "ns", Name: "kb"}
+	now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// disabled
+	disabled := NewStatusUpdateThrottle(0)
+	disabled.Updated(assoc, now)
+	require.Equal(t, time.Duration(0), disabled.Delay(assoc, now))
+	var none *StatusUpdateThrottle
+	none.Updated(assoc, now)
+	require.Equal(t, time.Duration(0), none.Delay(assoc, now))
+
+	th := NewStatusUpdateThrottle(time.Minute)
+	// the first update is not throttled
+	require.Equal(t, time.Duration(0), th.Delay(assoc, now))
+	th.Updated(assoc, now)
+	// the next one is throttled until the interval elapsed
+	require.Equal(t, 40*time.Second, th.Delay(assoc, now.Add(20*time.Second)))
+	require.Equal(t, time.Duration(0), th.Delay(assoc, now.Add(time.Minute)))
+	// other associations are tracked independently
+	require.Equal(t, time.Duration(0), th.Delay(types.NamespacedName{Namespace: "ns", Name: "other"}, now))
+
+	// forgotten associations start over
+	th.Forget(assoc)
+	require.Equal(t, time.Duration(0), th.Delay(assoc, now.Add(time.Second)))
+}
@@ -7,17 +7,23 @@ package association
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
 	"go.elastic.co/apm"
 	"golang.org/x/crypto/bcrypt"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	commonuser "github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
@@ -32,18 +38,19 @@ func elasticsearchUserName(associated commonv1.Associated, userSuffix string) st
 }
 
 // userSecretObjectName identifies the associated secret object.
-func userSecretObjectName(associated commonv1.Associated, userSuffix string) string {
+func userSecretObjectName(associated commonv1.Associated, credentialsNamespace string, userSuffix string) string {
+	if credentialsNamespace != "" && credentialsNamespace != associated.GetNamespace() {
+		// must be namespace-aware since the secrets of associated instances running in different namespaces with the
+		// same name all live in the credentials namespace
+		return associated.GetNamespace() + "-" + associated.GetName() + "-" + userSuffix
+	}
 	// does not need to be namespace aware, since it lives in associated object namespace.
 	return associated.GetName() + "-" + userSuffix
 }
 
-// UserKey is the namespaced name to identify the user resource created by the controller.
-func UserKey(associated commonv1.Associated, userSuffix string) types.NamespacedName {
-	esNamespace := associated.ElasticsearchRef().Namespace
-	if esNamespace == "" {
-		// no namespace given, default to the associated object's one
-		esNamespace = associated.GetNamespace()
-	}
+// UserKey is the namespaced name to identify the user resource created by the controller
+// for an association with an Elasticsearch cluster in the given namespace.
+func UserKey(associated commonv1.Associated, esNamespace string, userSuffix string) types.NamespacedName {
 	return types.NamespacedName{
 		// user lives in the ES namespace
 		Namespace: esNamespace,
@@ -52,25 +59,111 @@ func UserKey(associated commonv1.Associated, userSuffix string) types.Namespaced
 }
 
 // secretKey is the namespaced name to identify the secret containing the password for the user.
-// It uses the same resource name as the associated user.
-func secretKey(associated commonv1.Associated, userSuffix string) types.NamespacedName {
+// It lives in the namespace of the associated resource unless another credentials namespace is specified.
+func secretKey(associated commonv1.Associated, credentialsNamespace string, userSuffix string) types.NamespacedName {
+	if credentialsNamespace == "" {
+		credentialsNamespace = associated.GetNamespace()
+	}
 	return types.NamespacedName{
-		Namespace: associated.GetNamespace(),
-		Name:      userSecretObjectName(associated, userSuffix),
+		Namespace: credentialsNamespace,
+		Name:      userSecretObjectName(associated, credentialsNamespace, userSuffix),
 	}
 }
 
-// ClearTextSecretKeySelector creates a SecretKeySelector for the associated user secret
-func ClearTextSecretKeySelector(associated commonv1.Associated, userSuffix string) *corev1.SecretKeySelector {
+// ClearTextSecretKeySelector creates a SecretKeySelector for the associated user secret, created in the given
+// credentials namespace if not empty, or in the namespace of the associated resource otherwise.
+func ClearTextSecretKeySelector(associated commonv1.Associated, credentialsNamespace string, userSuffix string) *corev1.SecretKeySelector {
 	return &corev1.SecretKeySelector{
 		LocalObjectReference: corev1.LocalObjectReference{
-			Name: userSecretObjectName(associated, userSuffix),
+			Name: userSecretObjectName(associated, credentialsNamespace, userSuffix),
 		},
 		Key: elasticsearchUserName(associated, userSuffix),
 	}
 }
 
+const (
+	// usernameCredentialsKey is the name used to map the username in the credentials keys annotation.
+	usernameCredentialsKey = "username"
+	// passwordCredentialsKey is the name used to map the password in the credentials keys annotation.
+	passwordCredentialsKey = "password"
+)
+
+// credentialsKeys holds the additional keys under which the username and the password are stored in the credentials
+// secret of the associated resource.
+type credentialsKeys struct {
+	username string
+	password string
+}
+
+// parseCredentialsKeys parses the credentials keys annotation of the associated resource.
+func parseCredentialsKeys(associated commonv1.Associated) (credentialsKeys, error) {
+	var keys credentialsKeys
+	value := associated.GetAnnotations()[annotation.CredentialsKeysAnnotation]
+	if value == "" {
+		return keys, nil
+	}
+	for _, mapping := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(mapping), "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[1]) == "" {
+			return keys, fmt.Errorf("invalid credentials key mapping %q, expected <field>=<key>", mapping)
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case usernameCredentialsKey:
+			keys.username = strings.TrimSpace(kv[1])
+		case passwordCredentialsKey:
+			keys.password = strings.TrimSpace(kv[1])
+		default:
+			return keys, fmt.Errorf("invalid credentials key mapping %q, field must be one of [%s, %s]",
+				mapping, usernameCredentialsKey, passwordCredentialsKey)
+		}
+	}
+	return keys, nil
+}
+
+// credentialsData returns the content of the credentials secret of the associated resource.
+// The password is always stored under the username key, which is what the associated resource consumes.
+func (k credentialsKeys) credentialsData(username string, password []byte) map[string][]byte {
+	data := map[string][]byte{
+		username: password,
+	}
+	if k.username != "" {
+		data[k.username] = []byte(username)
+	}
+	if k.password != "" {
+		data[k.password] = password
+	}
+	return data
+}
+
+// movedCredentialsPassword returns the password of the credentials currently used by the associated resource if they
+// are stored in a secret other than the expected one, or nil otherwise.
+func movedCredentialsPassword(c k8s.Client, associated commonv1.Associated, expected types.NamespacedName) ([]byte, error) {
+	conf := associated.AssociationConf()
+	if !conf.AuthIsConfigured() {
+		return nil, nil
+	}
+	current := conf.AuthSecretRef(associated.GetNamespace())
+	if current == expected {
+		return nil, nil
+	}
+	var currentSecret corev1.Secret
+	if err := c.Get(current, &currentSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if pw := currentSecret.Data[conf.GetAuthSecretKey()]; len(pw) > 0 {
+		return pw, nil
+	}
+	// an empty password cannot be used to authenticate, a new one is generated instead
+	return nil, nil
+}
+
 // ReconcileEsUser creates a User resource and a corresponding secret or updates those as appropriate.
+// The secret is created in credentialsNamespace if not empty, or in the namespace of the associated resource otherwise.
+// Since owner references cannot cross namespaces, a secret created in another namespace has no owner and must be
+// explicitly deleted.
 func ReconcileEsUser(
 	ctx context.Context,
 	c k8s.Client,
@@ -80,38 +173,77 @@ func ReconcileEsUser(
 	userRoles string,
 	userObjectSuffix string,
 	es esv1.Elasticsearch,
+	ownerRefMode OwnerRefMode,
+	credentialsNamespace string,
 ) error {
 	span, _ := apm.StartSpan(ctx, "reconcile_es_user", tracing.SpanTypeApp)
 	defer span.End()
 
-	pw := commonuser.RandomPasswordBytes()
+	keys, err := parseCredentialsKeys(associated)
+	if err != nil {
+		return err
+	}
+
+	secKey := secretKey(associated, credentialsNamespace, userObjectSuffix)
+	// reuse the password currently used by the associated resource if the credentials are moved to another secret,
+	// so that both the previous and the new credentials remain valid until the associated resource is updated
+	pw, err := movedCredentialsPassword(c, associated, secKey)
+	if err != nil {
+		return err
+	}
+	// rotate the password once its TTL is over, the previous one is revoked when the user is updated below
+	rotate, err := credentialsRotationDue(c, associated, secKey, time.Now())
+	if err != nil {
+		return err
+	}
+	if pw == nil || rotate {
+		pw = commonuser.RandomPasswordBytes()
+	}
 
-	secKey := secretKey(associated, userObjectSuffix)
-	usrKey := UserKey(associated, userObjectSuffix)
+	owner := ownerRefMode.Owner(associated)
+	if secKey.Namespace != associated.GetNamespace() {
+		owner = nil
+	}
+	usrKey := UserKey(associated, es.Namespace, userObjectSuffix)
 	expectedSecret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      secKey.Name,
 			Namespace: secKey.Namespace,
 			Labels:    labels,
 		},
-		Data: map[string][]byte{
-			usrKey.Name: pw,
-		},
+		Data: keys.credentialsData(usrKey.Name, pw),
 	}
 
 	reconciledSecret := corev1.Secret{}
-	err := reconciler.ReconcileResource(reconciler.Params{
+	err = reconciler.ReconcileResource(reconciler.Params{
 		Client:     c,
 		Scheme:     s,
-		Owner:      associated,
+		Owner:      owner,
 		Expected:   &expectedSecret,
 		Reconciled: &reconciledSecret,
 		NeedsUpdate: func() bool {
-			_, ok := reconciledSecret.Data[usrKey.Name]
-			return !ok || !hasExpectedLabels(&expectedSecret, &reconciledSecret)
+			reconciledPw := reconciledSecret.Data[usrKey.Name]
+			return len(reconciledPw) == 0 || rotate || !hasExpectedLabels(&expectedSecret, &reconciledSecret) ||
+				!reflect.DeepEqual(keys.credentialsData(usrKey.Name, reconciledPw), reconciledSecret.Data)
 		},
 		UpdateReconciled: func() {
 			setExpectedLabels(&expectedSecret, &reconciledSecret)
+			if rotate {
+				if reconciledSecret.Annotations == nil {
+					reconciledSecret.Annotations = make(map[string]string)
+				}
+				reconciledSecret.Annotations[annotation.CredentialsRotatedAtAnnotation] = time.Now().Format(time.RFC3339)
+				reconciledSecret.Data = expectedSecret.Data
+				return
+			}
+			if reconciledPw := reconciledSecret.Data[usrKey.Name]; len(reconciledPw) > 0 {
+				// keep the existing password, only update the keys it is stored under
+				reconciledSecret.Data = keys.credentialsData(usrKey.Name, reconciledPw)
+				return
+			}
+			// the password is missing or empty, the associated resource would not be able to authenticate with it
+			log.Info("Password missing from the credentials secret, generating a new one",
+				"namespace", reconciledSecret.Namespace, "secret_name", reconciledSecret.Name)
 			reconciledSecret.Data = expectedSecret.Data
 		},
 	})
@@ -156,18 +288,28 @@ func ReconcileEsUser(
 		Expected:   expectedEsUser,
 		Reconciled: &reconciledEsSecret,
 		NeedsUpdate: func() bool {
-			return !hasExpectedLabels(expectedEsUser, &reconciledEsSecret) ||
+			// the user is owned by the Elasticsearch cluster currently referenced, which may not be the one
+			// referenced when the user was created if the Elasticsearch reference was updated
+			return isControlledByAnother(&reconciledEsSecret, &es) ||
+				!hasExpectedLabels(expectedEsUser, &reconciledEsSecret) ||
 				!bytes.Equal(expectedEsUser.Data[commonuser.UserName], reconciledEsSecret.Data[commonuser.UserName]) ||
 				!bytes.Equal(expectedEsUser.Data[commonuser.UserRoles], reconciledEsSecret.Data[commonuser.UserRoles]) ||
 				bcrypt.CompareHashAndPassword(reconciledEsSecret.Data[commonuser.PasswordHash], reconciledPw) != nil
 		},
 		UpdateReconciled: func() {
 			setExpectedLabels(expectedEsUser, &reconciledEsSecret)
+			reconciledEsSecret.OwnerReferences = expectedEsUser.OwnerReferences
 			reconciledEsSecret.Data = expectedEsUser.Data
 		},
 	})
 }
 
+// isControlledByAnother returns true if object has a controller reference to an owner other than the given one.
+func isControlledByAnother(object metav1.Object, owner metav1.Object) bool {
+	ref := metav1.GetControllerOf(object)
+	return ref != nil && ref.UID != owner.GetUID()
+}
+
 // hasExpectedLabels does a left-biased comparison ensuring all key/value pairs in expected exist in actual.
 func hasExpectedLabels(expected, actual metav1.Object) bool {
 	actualLabels := actual.GetLabels()
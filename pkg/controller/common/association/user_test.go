@@ -7,6 +7,7 @@ package association
 import (
 	"context"
 	"testing"
+	"time"
 
 	"k8s.io/client-go/kubernetes/scheme"
 
@@ -14,6 +15,7 @@ import (
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	elasticsearchuser "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
@@ -22,6 +24,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -30,7 +33,8 @@ import (
 
 const (
 	userName                  = "default-kibana-foo-kibana-user"
-	userSecretName            = "kibana-foo-kibana-user" // nolint
+	userSecretName            = "kibana-foo-kibana-user"         // nolint
+	credentialsSecretName     = "default-kibana-foo-kibana-user" // nolint
 	associationLabelName      = "association.k8s.elastic.co/name"
 	associationLabelNamespace = "association.k8s.elastic.co/namespace"
 )
@@ -62,10 +66,12 @@ var kibanaFixture = kbv1.Kibana{
 }
 
 func Test_reconcileEsUser(t *testing.T) {
+	isController := true
 	type args struct {
-		initialObjects []runtime.Object
-		kibana         kbv1.Kibana
-		es             esv1.Elasticsearch
+		initialObjects       []runtime.Object
+		kibana               kbv1.Kibana
+		es                   esv1.Elasticsearch
+		credentialsNamespace string
 	}
 	tests := []struct {
 		name          string
@@ -217,6 +223,230 @@ func Test_reconcileEsUser(t *testing.T) {
 				require.Equal(t, "$2a$10$mE3yo/AkZgR4eVW9kbA1TeIQ40Jv6WaWU494rx4C6EhLvuY0BSg4e", string(userSecret.Data[user.PasswordHash]))
 			},
 		},
+		{
+			name: "Reconcile swaps the owner of the user when the Elasticsearch reference is updated",
+			args: args{
+				initialObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      userName,
+							Namespace: "default",
+							OwnerReferences: []metav1.OwnerReference{{
+								APIVersion: "elasticsearch.k8s.elastic.co/v1",
+								Kind:       "Elasticsearch",
+								Name:       "es-old",
+								UID:        "3a4c4e80-4c5c-11ea-b77f-2e728ce88125",
+								Controller: &isController,
+							}},
+						},
+					}},
+				kibana: kibanaFixture,
+				es:     esFixture,
+			},
+			wantErr: false,
+			postCondition: func(c k8s.Client) {
+				var esUser corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: userName, Namespace: "default"}, &esUser))
+				require.True(t, metav1.IsControlledBy(&esUser, &esFixture))
+				require.Len(t, esUser.OwnerReferences, 1)
+				require.Equal(t, esFixture.Name, esUser.Labels[label.ClusterNameLabelName])
+			},
+		},
+		{
+			name: "Credentials are also stored under the mapped keys",
+			args: args{
+				initialObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "default",
+							Name:      userSecretName,
+						},
+						Data: map[string][]byte{
+							userName: []byte("my-secret-pw"),
+						},
+					}},
+				kibana: kbv1.Kibana{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        kibanaFixture.Name,
+						Namespace:   kibanaFixture.Namespace,
+						Annotations: map[string]string{annotation.CredentialsKeysAnnotation: "username=user, password=pass"},
+					},
+					Spec: kibanaFixture.Spec,
+				},
+				es: esFixture,
+			},
+			wantErr: false,
+			postCondition: func(c k8s.Client) {
+				var s corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: userSecretName, Namespace: "default"}, &s))
+				require.Equal(t, map[string][]byte{
+					userName: []byte("my-secret-pw"),
+					"user":   []byte(userName),
+					"pass":   []byte("my-secret-pw"),
+				}, s.Data)
+			},
+		},
+		{
+			name: "Invalid credentials keys mapping",
+			args: args{
+				kibana: kbv1.Kibana{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        kibanaFixture.Name,
+						Namespace:   kibanaFixture.Namespace,
+						Annotations: map[string]string{annotation.CredentialsKeysAnnotation: "token=tk"},
+					},
+					Spec: kibanaFixture.Spec,
+				},
+				es: esFixture,
+			},
+			wantErr:       true,
+			postCondition: func(c k8s.Client) {},
+		},
+		{
+			name: "Credentials secret in a dedicated namespace",
+			args: args{
+				kibana:               kibanaFixture,
+				es:                   esFixture,
+				credentialsNamespace: "credentials",
+			},
+			wantErr: false,
+			postCondition: func(c k8s.Client) {
+				var s corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: credentialsSecretName, Namespace: "credentials"}, &s))
+				// owner references cannot cross namespaces
+				assert.Empty(t, s.OwnerReferences)
+				assert.NotEmpty(t, s.Data[userName])
+				// no secret in the Kibana namespace
+				list := corev1.SecretList{}
+				assert.NoError(t, c.List(&list))
+				assert.Nil(t, user.GetSecret(list, types.NamespacedName{Namespace: kibanaFixture.Namespace, Name: userSecretName}))
+			},
+		},
+		{
+			name: "Moving the credentials to a dedicated namespace keeps the current password",
+			args: args{
+				initialObjects: []runtime.Object{&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: userSecretName, Namespace: "default"},
+					Data:       map[string][]byte{userName: []byte("current-password")},
+				}},
+				kibana: func() kbv1.Kibana {
+					kb := *kibanaFixture.DeepCopy()
+					kb.SetAssociationConf(&commonv1.AssociationConf{AuthSecretName: userSecretName, AuthSecretKey: userName})
+					return kb
+				}(),
+				es:                   esFixture,
+				credentialsNamespace: "credentials",
+			},
+			wantErr: false,
+			postCondition: func(c k8s.Client) {
+				var s corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: credentialsSecretName, Namespace: "credentials"}, &s))
+				assert.Equal(t, "current-password", string(s.Data[userName]))
+				var esUser corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: userName, Namespace: "default"}, &esUser))
+				assert.NoError(t, bcrypt.CompareHashAndPassword(esUser.Data[user.PasswordHash], []byte("current-password")))
+			},
+		},
+		{
+			name: "Credentials older than their TTL are rotated",
+			args: args{
+				initialObjects: []runtime.Object{&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              userSecretName,
+						Namespace:         "default",
+						CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+					},
+					Data: map[string][]byte{userName: []byte("expired-password")},
+				}},
+				kibana: kbv1.Kibana{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        kibanaFixture.Name,
+						Namespace:   kibanaFixture.Namespace,
+						Annotations: map[string]string{annotation.CredentialsTTLAnnotation: "1h"},
+					},
+					Spec: kibanaFixture.Spec,
+				},
+				es: esFixture,
+			},
+			wantErr: false,
+			postCondition: func(c k8s.Client) {
+				var s corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: userSecretName, Namespace: "default"}, &s))
+				assert.NotEqual(t, "expired-password", string(s.Data[userName]))
+				assert.NotEmpty(t, s.Annotations[annotation.CredentialsRotatedAtAnnotation])
+				// the previous password is revoked
+				var esUser corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: userName, Namespace: "default"}, &esUser))
+				assert.NoError(t, bcrypt.CompareHashAndPassword(esUser.Data[user.PasswordHash], s.Data[userName]))
+			},
+		},
+		{
+			name: "Credentials within their TTL are kept",
+			args: args{
+				initialObjects: []runtime.Object{&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              userSecretName,
+						Namespace:         "default",
+						CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+						Annotations: map[string]string{
+							annotation.CredentialsRotatedAtAnnotation: time.Now().Add(-10 * time.Minute).Format(time.RFC3339),
+						},
+					},
+					Data: map[string][]byte{userName: []byte("current-password")},
+				}},
+				kibana: kbv1.Kibana{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        kibanaFixture.Name,
+						Namespace:   kibanaFixture.Namespace,
+						Annotations: map[string]string{annotation.CredentialsTTLAnnotation: "1h"},
+					},
+					Spec: kibanaFixture.Spec,
+				},
+				es: esFixture,
+			},
+			wantErr: false,
+			postCondition: func(c k8s.Client) {
+				var s corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: userSecretName, Namespace: "default"}, &s))
+				assert.Equal(t, "current-password", string(s.Data[userName]))
+			},
+		},
+		{
+			name: "Empty password is replaced",
+			args: args{
+				initialObjects: []runtime.Object{&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: userSecretName, Namespace: "default"},
+					Data:       map[string][]byte{userName: []byte("")},
+				}},
+				kibana: kibanaFixture,
+				es:     esFixture,
+			},
+			wantErr: false,
+			postCondition: func(c k8s.Client) {
+				var s corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: userSecretName, Namespace: "default"}, &s))
+				assert.NotEmpty(t, s.Data[userName])
+				var esUser corev1.Secret
+				assert.NoError(t, c.Get(types.NamespacedName{Name: userName, Namespace: "default"}, &esUser))
+				assert.NoError(t, bcrypt.CompareHashAndPassword(esUser.Data[user.PasswordHash], s.Data[userName]))
+			},
+		},
+		{
+			name: "Invalid credentials TTL",
+			args: args{
+				kibana: kbv1.Kibana{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        kibanaFixture.Name,
+						Namespace:   kibanaFixture.Namespace,
+						Annotations: map[string]string{annotation.CredentialsTTLAnnotation: "one day"},
+					},
+					Spec: kibanaFixture.Spec,
+				},
+				es: esFixture,
+			},
+			wantErr:       true,
+			postCondition: func(c k8s.Client) {},
+		},
 		{
 			name: "Reconcile is namespace aware",
 			args: args{
@@ -265,6 +495,8 @@ func Test_reconcileEsUser(t *testing.T) {
 				elasticsearchuser.KibanaSystemUserBuiltinRole,
 				"kibana-user",
 				tt.args.es,
+				DefaultOwnerRefMode,
+				tt.args.credentialsNamespace,
 			); (err != nil) != tt.wantErr {
 				t.Errorf("reconcileEsUser() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -272,3 +504,36 @@ func Test_reconcileEsUser(t *testing.T) {
 		})
 	}
 }
+
+func Test_reconcileEsUser_sameNameInCredentialsNamespace(t *testing.T) {
+	c := k8s.WrappedFakeClient()
+	kibanas := make([]kbv1.Kibana, 2)
+	for i, ns := range []string{"ns-a", "ns-b"} {
+		kibanas[i] = kbv1.Kibana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "kb", UID: types.UID(ns)},
+			Spec:       kibanaFixture.Spec,
+		}
+		require.NoError(t, ReconcileEsUser(
+			context.Background(),
+			c,
+			scheme.Scheme,
+			&kibanas[i],
+			map[string]string{associationLabelName: "kb", associationLabelNamespace: ns},
+			elasticsearchuser.KibanaSystemUserBuiltinRole,
+			"kibana-user",
+			esFixture,
+			DefaultOwnerRefMode,
+			"credentials",
+		))
+	}
+
+	// each Kibana has its own credentials secret in the credentials namespace
+	var secretA, secretB corev1.Secret
+	require.NoError(t, c.Get(types.NamespacedName{Namespace: "credentials", Name: "ns-a-kb-kibana-user"}, &secretA))
+	require.NoError(t, c.Get(types.NamespacedName{Namespace: "credentials", Name: "ns-b-kb-kibana-user"}, &secretB))
+	require.NotEmpty(t, secretA.Data["ns-a-kb-kibana-user"])
+	require.NotEmpty(t, secretB.Data["ns-b-kb-kibana-user"])
+	// and the secret selectors used in the association configuration reference them
+	require.Equal(t, "ns-a-kb-kibana-user", ClearTextSecretKeySelector(&kibanas[0], "credentials", "kibana-user").Name)
+	require.Equal(t, "ns-b-kb-kibana-user", ClearTextSecretKeySelector(&kibanas[1], "credentials", "kibana-user").Name)
+}
@@ -22,6 +22,8 @@ const (
 	EventReasonStateChange = "StateChange"
 	// EventReasonRestart describes events where one or multiple Elasticsearch nodes are scheduled for a restart.
 	EventReasonRestart = "Restart"
+	// EventReasonLicenseExpiring describes events where the license of an Elasticsearch cluster expires soon or has expired.
+	EventReasonLicenseExpiring = "LicenseExpiring"
 )
 
 // Event reasons for Association controllers
@@ -30,6 +32,8 @@ const (
 	EventAssociationError = "AssociationError"
 	// EventAssociationStatusChange describes association status change events.
 	EventAssociationStatusChange = "AssociationStatusChange"
+	// EventAssociationConfigured describes events fired when the association configuration of a resource is updated.
+	EventAssociationConfigured = "AssociationConfigured"
 )
 
 // Event reasons for common error conditions
@@ -5,20 +5,41 @@
 package operator
 
 const (
-	AutoPortForwardFlag    = "auto-port-forward"
-	CACertRotateBeforeFlag = "ca-cert-rotate-before"
-	CACertValidityFlag     = "ca-cert-validity"
-	CertRotateBeforeFlag   = "cert-rotate-before"
-	CertValidityFlag       = "cert-validity"
-	ContainerRegistryFlag  = "container-registry"
-	DebugHTTPListenFlag    = "debug-http-listen"
-	EnableTracingFlag      = "enable-tracing"
-	EnforceRBACOnRefsFlag  = "enforce-rbac-on-refs"
-	ManageWebhookCertsFlag = "manage-webhook-certs"
-	MetricsPortFlag        = "metrics-port"
-	NamespacesFlag         = "namespaces"
-	OperatorNamespaceFlag  = "operator-namespace"
-	OperatorRolesFlag      = "operator-roles"
-	WebhookCertDirFlag     = "webhook-cert-dir"
-	WebhookSecretFlag      = "webhook-secret"
+	AssociationAuditLogFlag                     = "association-audit-log"
+	AssociationCredentialsNamespaceFlag         = "association-credentials-namespace"
+	AssociationDryRunFlag                       = "association-dry-run"
+	AssociationEncryptedNamespacesFlag          = "association-encrypted-namespaces"
+	AssociationFailureGracePeriodFlag           = "association-failure-grace-period"
+	AssociationGlobalCAFlag                     = "association-global-ca-secret"
+	AssociationInventoryURLFlag                 = "association-inventory-url"
+	AssociationInventorySigningSecretFlag       = "association-inventory-signing-secret"
+	AssociationLivenessThresholdFlag            = "association-liveness-threshold"
+	AssociationMaxConcurrentReconcilesFlag      = "association-max-concurrent-reconciles"
+	AssociationMinESHealthFlag                  = "association-min-es-health"
+	AssociationMinESVersionFlag                 = "association-min-es-version"
+	AssociationOwnerRefModeFlag                 = "association-owner-ref-mode"
+	AssociationProbeRetriesFlag                 = "association-probe-retries"
+	AssociationProbeTimeoutFlag                 = "association-probe-timeout"
+	AssociationReconcileTimeoutFlag             = "association-reconcile-timeout"
+	AssociationRequeueIntervalFlag              = "association-requeue-interval"
+	AssociationStartupJitterFlag                = "association-startup-jitter"
+	AssociationStatusUpdateIntervalFlag         = "association-status-update-interval"
+	AssociationTolerateStatusUpdateFailuresFlag = "association-tolerate-status-update-failures"
+	AutoPortForwardFlag                         = "auto-port-forward"
+	CACertRotateBeforeFlag                      = "ca-cert-rotate-before"
+	CACertValidityFlag                          = "ca-cert-validity"
+	CertRotateBeforeFlag                        = "cert-rotate-before"
+	CertValidityFlag                            = "cert-validity"
+	ContainerRegistryFlag                       = "container-registry"
+	DebugHTTPListenFlag                         = "debug-http-listen"
+	EnableTracingFlag                           = "enable-tracing"
+	EnforceRBACOnRefsFlag                       = "enforce-rbac-on-refs"
+	HealthProbePortFlag                         = "health-probe-port"
+	ManageWebhookCertsFlag                      = "manage-webhook-certs"
+	MetricsPortFlag                             = "metrics-port"
+	NamespacesFlag                              = "namespaces"
+	OperatorNamespaceFlag                       = "operator-namespace"
+	OperatorRolesFlag                           = "operator-roles"
+	WebhookCertDirFlag                          = "webhook-cert-dir"
+	WebhookSecretFlag                           = "webhook-secret"
 )
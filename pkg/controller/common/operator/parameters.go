@@ -5,10 +5,15 @@
 package operator
 
 import (
+	"time"
+
 	"github.com/elastic/cloud-on-k8s/pkg/about"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
 	"go.elastic.co/apm"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // Parameters contain parameters to create new operators.
@@ -25,4 +30,61 @@ type Parameters struct {
 	CertRotation certificates.RotationParams
 	// Tracer is a shared APM tracer instance or nil
 	Tracer *apm.Tracer
+	// AssociationOwnerRefMode defines how the resources derived from associations are owned and cleaned up. Defaults to
+	// the associated resource owning them if empty.
+	AssociationOwnerRefMode string
+	// AssociationFailureGracePeriod is how long an association failure must persist for before the association is
+	// reported as Failed. Disabled if zero.
+	AssociationFailureGracePeriod time.Duration
+	// AssociationGlobalCA references a secret holding CA certificates trusted by all associated resources, in addition
+	// to the Elasticsearch CA. Ignored if empty.
+	AssociationGlobalCA types.NamespacedName
+	// AssociationCredentialsNamespace is the namespace in which the Kibana association credentials secrets are created.
+	// Defaults to the namespace of each Kibana resource if empty.
+	AssociationCredentialsNamespace string
+	// AssociationInventoryURL is the URL of an inventory endpoint the association state is exported to on status
+	// changes. Disabled if empty.
+	AssociationInventoryURL string
+	// AssociationInventorySigningSecret references a secret holding the key inventory requests are signed with.
+	// Requests are not signed if empty.
+	AssociationInventorySigningSecret types.NamespacedName
+	// AssociationEncryptedNamespaces lists the namespaces in which association secrets can be written, because secrets
+	// are encrypted at rest there. Not verified if empty.
+	AssociationEncryptedNamespaces []string
+	// AssociationMinESHealth is the minimum health of Elasticsearch for associations to be established. Not verified if
+	// empty.
+	AssociationMinESHealth esv1.ElasticsearchHealth
+	// AssociationMinESVersion is the minimum Elasticsearch version associations can be established with. No minimum
+	// is enforced if nil.
+	AssociationMinESVersion *version.Version
+	// AssociationAuditLogPath is the path of the file association audit records are appended to. Disabled if empty.
+	AssociationAuditLogPath string
+	// AssociationStartupJitter is the window over which the initial reconciliations of associations are spread when
+	// the operator starts. Disabled if zero.
+	AssociationStartupJitter time.Duration
+	// AssociationProbeTimeout is the timeout of a single attempt of the Elasticsearch probes performed for associations.
+	AssociationProbeTimeout time.Duration
+	// AssociationProbeRetries is the number of times a failed Elasticsearch probe is retried.
+	AssociationProbeRetries int
+	// AssociationTolerateStatusUpdateFailures makes association status update failures non-fatal: they are logged and
+	// the reconciliation is requeued without being reported as failed.
+	AssociationTolerateStatusUpdateFailures bool
+	// AssociationRequeueInterval is the interval after which pending associations are reconciled again. Defaults to 10
+	// seconds if zero.
+	AssociationRequeueInterval time.Duration
+	// AssociationStatusUpdateInterval is the minimum interval between two status updates of the same association.
+	// Disabled if zero.
+	AssociationStatusUpdateInterval time.Duration
+	// AssociationDryRun puts all the associations in dry-run mode: the changes to their configuration are reported in
+	// their status instead of being applied.
+	AssociationDryRun bool
+	// AssociationReconcileTimeout is the duration after which a reconciliation of an association is aborted and
+	// requeued. Defaults to 2 minutes if zero.
+	AssociationReconcileTimeout time.Duration
+	// AssociationLivenessThreshold is how long a reconciliation of an association can run for before the association
+	// controller is reported as not live by the liveness endpoint. Disabled if zero.
+	AssociationLivenessThreshold time.Duration
+	// AssociationMaxConcurrentReconciles is the maximum number of associations reconciled concurrently. Defaults to 1
+	// if zero.
+	AssociationMaxConcurrentReconciles int
 }
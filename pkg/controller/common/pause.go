@@ -23,28 +23,46 @@ var (
 	PauseRequeue = reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}
 )
 
-// IsPaused computes if a given controller is paused.
+// IsPaused computes if a given controller is paused. The pause annotation is either a boolean, or the time until which
+// the controller is paused in the RFC 3339 format.
 func IsPaused(meta metav1.ObjectMeta) bool {
-	return getBoolFromAnnotation(meta.Annotations)
+	paused, _ := pauseState(meta.Annotations, time.Now())
+	return paused
 }
 
-// Extract the desired state from the map that contains annotations.
-func getBoolFromAnnotation(annotations map[string]string) bool {
+// PauseRequeueFor returns the requeue result of a paused controller: when the pause expires if it is time-boxed,
+// PauseRequeue otherwise.
+func PauseRequeueFor(meta metav1.ObjectMeta) reconcile.Result {
+	_, remaining := pauseState(meta.Annotations, time.Now())
+	if remaining <= 0 {
+		return PauseRequeue
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: remaining}
+}
+
+// Extract the desired state at the given time from the map that contains annotations, and how long the pause lasts
+// for if it is time-boxed.
+func pauseState(annotations map[string]string, now time.Time) (bool, time.Duration) {
 	if annotations == nil {
-		return false
+		return false, 0
 	}
 
 	stateAsString, exists := annotations[PauseAnnotationName]
 
 	if !exists {
-		return false
+		return false, 0
+	}
+
+	if until, err := time.Parse(time.RFC3339, stateAsString); err == nil {
+		remaining := until.Sub(now)
+		return remaining > 0, remaining
 	}
 
 	expectedState, err := strconv.ParseBool(stateAsString)
 	if err != nil {
-		log.Error(err, "Cannot parse %s as a bool, defaulting to %s: \"false\"", annotations[PauseAnnotationName], PauseAnnotationName)
-		return false
+		log.Error(err, "Cannot parse %s as a bool or a time, defaulting to %s: \"false\"", annotations[PauseAnnotationName], PauseAnnotationName)
+		return false, 0
 	}
 
-	return expectedState
+	return expectedState, 0
 }
@@ -6,6 +6,7 @@ package common
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -71,3 +72,52 @@ func TestPauseCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestPauseState_timeBoxed(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		value         string
+		wantPaused    bool
+		wantRemaining time.Duration
+	}{
+		{
+			name:          "pause not expired",
+			value:         "2020-01-01T13:30:00Z",
+			wantPaused:    true,
+			wantRemaining: 90 * time.Minute,
+		},
+		{
+			name:          "pause not expired, with a time zone",
+			value:         "2020-01-01T14:00:00+01:00",
+			wantPaused:    true,
+			wantRemaining: time.Hour,
+		},
+		{
+			name:          "pause expired",
+			value:         "2020-01-01T11:00:00Z",
+			wantPaused:    false,
+			wantRemaining: -time.Hour,
+		},
+		{
+			name:       "pause not time-boxed",
+			value:      "true",
+			wantPaused: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paused, remaining := pauseState(map[string]string{PauseAnnotationName: tt.value}, now)
+			assert.Equal(t, tt.wantPaused, paused)
+			assert.Equal(t, tt.wantRemaining, remaining)
+		})
+	}
+}
+
+func TestPauseRequeueFor(t *testing.T) {
+	assert.Equal(t, PauseRequeue, PauseRequeueFor(v1.ObjectMeta{Annotations: map[string]string{PauseAnnotationName: "true"}}))
+	until := time.Now().Add(time.Hour).Format(time.RFC3339)
+	result := PauseRequeueFor(v1.ObjectMeta{Annotations: map[string]string{PauseAnnotationName: until}})
+	assert.True(t, result.Requeue)
+	assert.True(t, result.RequeueAfter > 59*time.Minute && result.RequeueAfter <= time.Hour)
+}
@@ -131,6 +131,19 @@ func (c *CanonicalConfig) MergeWith(cfgs ...*CanonicalConfig) error {
 	return nil
 }
 
+// Remove removes the given keys from c, if they exist.
+func (c *CanonicalConfig) Remove(keys ...string) error {
+	if c == nil {
+		return nil
+	}
+	for _, k := range keys {
+		if _, err := c.asUCfg().Remove(k, -1, Options...); err != nil && !isMissing(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 // HasKeys returns all keys in c that are also in keys
 func (c *CanonicalConfig) HasKeys(keys []string) []string {
 	var has []string
@@ -304,3 +317,8 @@ func (c *CanonicalConfig) asUCfg() *ucfg.Config {
 func fromConfig(in *ucfg.Config) *CanonicalConfig {
 	return (*CanonicalConfig)(in)
 }
+
+func isMissing(err error) bool {
+	ucfgErr, ok := err.(ucfg.Error)
+	return ok && ucfgErr.Reason() == ucfg.ErrMissing
+}
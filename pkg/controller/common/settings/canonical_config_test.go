@@ -448,3 +448,15 @@ func TestNewCanonicalConfigFrom(t *testing.T) {
 		})
 	}
 }
+
+func TestCanonicalConfig_Remove(t *testing.T) {
+	c := MustCanonicalConfig(map[string]interface{}{
+		"a":   []string{"foo", "bar"},
+		"b.c": "baz",
+		"b.d": "buzz",
+	})
+	require.NoError(t, c.Remove("a", "b.c", "missing", "b.missing"))
+	require.Empty(t, c.Diff(MustCanonicalConfig(map[string]interface{}{"b.d": "buzz"}), nil))
+	// removing keys from a nil config is a no-op
+	require.NoError(t, (*CanonicalConfig)(nil).Remove("a"))
+}
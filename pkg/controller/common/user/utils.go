@@ -14,8 +14,11 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+var log = logf.Log.WithName("user")
+
 // GetSecret gets the first secret in a list that matches the namespace and the name.
 func GetSecret(list corev1.SecretList, namespacedName types.NamespacedName) *corev1.Secret {
 	for _, secret := range list.Items {
@@ -40,13 +43,14 @@ func ChecksUser(t *testing.T, secret *corev1.Secret, expectedUsername string, ex
 	assert.ElementsMatch(t, expectedRoles, strings.Split(string(currentRoles), ","))
 }
 
-// DeleteUser deletes the user Secrets using the provided label selector.
+// DeleteUser deletes the user Secrets using the provided label selector. Secrets already deleted are ignored.
 func DeleteUser(c k8s.Client, opts ...client.ListOption) error {
 	var secrets corev1.SecretList
 	if err := c.List(&secrets, opts...); err != nil {
 		return err
 	}
 	for _, s := range secrets.Items {
+		log.Info("Deleting user secret", "namespace", s.Namespace, "secret_name", s.Name)
 		if err := c.Delete(&s); err != nil && !apierrors.IsNotFound(err) {
 			return err
 		}
@@ -31,16 +31,35 @@ var downwardAPIVolumeMount = corev1.VolumeMount{
 	ReadOnly:  true,
 }
 
-type DownwardAPI struct{}
+// DownwardAPI is a volume exposing the labels of the pod, and optionally its annotations.
+type DownwardAPI struct {
+	withAnnotations bool
+}
 
 var _ VolumeLike = DownwardAPI{}
 
+// WithAnnotations returns a DownwardAPI volume also exposing the annotations of the pod.
+func (d DownwardAPI) WithAnnotations(withAnnotations bool) DownwardAPI {
+	d.withAnnotations = withAnnotations
+	return d
+}
+
 func (DownwardAPI) Name() string {
 	return volume.DownwardAPIVolumeName
 }
 
-func (DownwardAPI) Volume() corev1.Volume {
-	return downwardAPIVolume
+func (d DownwardAPI) Volume() corev1.Volume {
+	if !d.withAnnotations {
+		return downwardAPIVolume
+	}
+	vol := *downwardAPIVolume.DeepCopy()
+	vol.DownwardAPI.Items = append(vol.DownwardAPI.Items, corev1.DownwardAPIVolumeFile{
+		Path: volume.AnnotationsFile,
+		FieldRef: &corev1.ObjectFieldSelector{
+			FieldPath: "metadata.annotations",
+		},
+	})
+	return vol
 }
 
 func (DownwardAPI) VolumeMount() corev1.VolumeMount {
@@ -5,11 +5,13 @@
 package watches
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -39,6 +41,8 @@ func NewDynamicEnqueueRequest() *DynamicEnqueueRequest {
 
 // DynamicEnqueueRequest is an EventHandler that allows addition and removal of
 // event handler registrations at runtime allowing dynamic reconciliation based on specific resources.
+// Registrations are identified by their key only: several of them can watch the same resource, removing one of them
+// does not affect the others, and the informer of the watched type is shared by all of them.
 type DynamicEnqueueRequest struct {
 	mutex         sync.RWMutex
 	registrations map[string]HandlerRegistration
@@ -59,6 +63,9 @@ func (d *DynamicEnqueueRequest) AddHandlers(handlers ...HandlerRegistration) err
 
 // AddHandler adds a new event handler to this DynamicEnqueueRequest.
 func (d *DynamicEnqueueRequest) AddHandler(handler HandlerRegistration) error {
+	if d == nil {
+		return errors.New("DynamicEnqueueRequest is not initialised")
+	}
 	if d.scheme == nil {
 		return errors.New("DynamicEnqueueRequest is not initialised yet. No scheme")
 	}
@@ -85,8 +92,12 @@ func (d *DynamicEnqueueRequest) RemoveHandler(handler HandlerRegistration) {
 	d.RemoveHandlerForKey(handler.Key())
 }
 
-// RemoveHandlerForKey removes the handler identified by the given key.
+// RemoveHandlerForKey removes the handler identified by the given key. It is a no-op on a nil DynamicEnqueueRequest,
+// which has no handler, so that resources can always be cleaned up.
 func (d *DynamicEnqueueRequest) RemoveHandlerForKey(key string) {
+	if d == nil {
+		return
+	}
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 	delete(d.registrations, key)
@@ -94,6 +105,9 @@ func (d *DynamicEnqueueRequest) RemoveHandlerForKey(key string) {
 
 // Registrations returns the list of registered handler names.
 func (d *DynamicEnqueueRequest) Registrations() []string {
+	if d == nil {
+		return nil
+	}
 	d.mutex.RLock()
 	defer d.mutex.RUnlock()
 	keys := make([]string, 0, len(d.registrations))
@@ -103,6 +117,39 @@ func (d *DynamicEnqueueRequest) Registrations() []string {
 	return keys
 }
 
+// Watchers returns the sorted watchers of the named watches registered for the given resource, for instance the
+// associated resources of an Elasticsearch cluster. Owner watches are not named and are not considered.
+func (d *DynamicEnqueueRequest) Watchers(watched types.NamespacedName) []types.NamespacedName {
+	if d == nil {
+		return nil
+	}
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	seen := make(map[types.NamespacedName]bool)
+	var watchers []types.NamespacedName
+	for _, registration := range d.registrations {
+		var watch NamedWatch
+		switch r := registration.(type) {
+		case NamedWatch:
+			watch = r
+		case *NamedWatch:
+			watch = *r
+		default:
+			continue
+		}
+		for _, w := range watch.Watched {
+			if w == watched && !seen[watch.Watcher] {
+				seen[watch.Watcher] = true
+				watchers = append(watchers, watch.Watcher)
+			}
+		}
+	}
+	sort.Slice(watchers, func(i, j int) bool {
+		return watchers[i].String() < watchers[j].String()
+	})
+	return watchers
+}
+
 // DynamicEnqueueRequest implements EventHandler
 var _ handler.EventHandler = &DynamicEnqueueRequest{}
 
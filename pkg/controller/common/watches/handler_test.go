@@ -123,6 +123,86 @@ func TestDynamicEnqueueRequest_RemoveHandler(t *testing.T) {
 	}
 }
 
+func TestDynamicEnqueueRequest_uninitialized(t *testing.T) {
+	// the zero value of DynamicWatches holds nil DynamicEnqueueRequests
+	var w DynamicWatches
+	w.Secrets.RemoveHandlerForKey("foo")
+	w.ElasticsearchClusters.RemoveHandler(&fakeHandler{name: "foo"})
+	require.Empty(t, w.Secrets.Registrations())
+	require.Empty(t, w.ElasticsearchClusters.Watchers(types.NamespacedName{Namespace: "default", Name: "es"}))
+	require.Error(t, w.Kibanas.AddHandler(&fakeHandler{name: "foo"}))
+}
+
+func TestDynamicEnqueueRequest_Watchers(t *testing.T) {
+	es := types.NamespacedName{Namespace: "default", Name: "es"}
+	other := types.NamespacedName{Namespace: "default", Name: "other-es"}
+	kb1 := types.NamespacedName{Namespace: "ns1", Name: "kb"}
+	kb2 := types.NamespacedName{Namespace: "default", Name: "kb"}
+	d := NewDynamicEnqueueRequest()
+	require.NoError(t, d.InjectScheme(scheme.Scheme))
+	require.Empty(t, d.Watchers(es))
+
+	require.NoError(t, d.AddHandlers(
+		NamedWatch{Name: "kb1-es", Watched: []types.NamespacedName{es}, Watcher: kb1},
+		&NamedWatch{Name: "kb2-es", Watched: []types.NamespacedName{other, es}, Watcher: kb2},
+		NamedWatch{Name: "kb2-es-again", Watched: []types.NamespacedName{es}, Watcher: kb2},
+		NamedWatch{Name: "other", Watched: []types.NamespacedName{other}, Watcher: kb1},
+		&fakeHandler{name: "fake"},
+	))
+	require.Equal(t, []types.NamespacedName{kb2, kb1}, d.Watchers(es))
+
+	d.RemoveHandlerForKey("kb1-es")
+	require.Equal(t, []types.NamespacedName{kb2}, d.Watchers(es))
+}
+
+func TestDynamicEnqueueRequest_sharedWatched(t *testing.T) {
+	es := types.NamespacedName{Namespace: "default", Name: "es"}
+	kb1 := types.NamespacedName{Namespace: "default", Name: "kb1"}
+	kb2 := types.NamespacedName{Namespace: "default", Name: "kb2"}
+	d := NewDynamicEnqueueRequest()
+	require.NoError(t, d.InjectScheme(scheme.Scheme))
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+	// requests enqueued when the watched resource is updated
+	requests := func() []types.NamespacedName {
+		d.Update(event.UpdateEvent{
+			MetaOld: &metav1.ObjectMeta{Namespace: es.Namespace, Name: es.Name},
+			MetaNew: &metav1.ObjectMeta{Namespace: es.Namespace, Name: es.Name},
+		}, q)
+		var watchers []types.NamespacedName
+		for q.Len() > 0 {
+			item, _ := q.Get()
+			watchers = append(watchers, item.(reconcile.Request).NamespacedName)
+			q.Done(item)
+			q.Forget(item)
+		}
+		return watchers
+	}
+
+	// watches are added again on every reconciliation of their watcher
+	for i := 0; i < 2; i++ {
+		require.NoError(t, d.AddHandlers(
+			NamedWatch{Name: "kb1-es", Watched: []types.NamespacedName{es}, Watcher: kb1},
+			NamedWatch{Name: "kb2-es", Watched: []types.NamespacedName{es}, Watcher: kb2},
+		))
+	}
+	require.Len(t, d.Registrations(), 2)
+	require.ElementsMatch(t, []types.NamespacedName{kb1, kb2}, requests())
+
+	// removing the watch of one watcher does not affect the other one
+	d.RemoveHandlerForKey("kb1-es")
+	require.Equal(t, []types.NamespacedName{kb2}, requests())
+	require.Equal(t, []types.NamespacedName{kb2}, d.Watchers(es))
+	// removing it again is a no-op
+	d.RemoveHandlerForKey("kb1-es")
+	require.Equal(t, []types.NamespacedName{kb2}, requests())
+
+	// the last watcher goes away
+	d.RemoveHandlerForKey("kb2-es")
+	require.Empty(t, requests())
+	require.Empty(t, d.Registrations())
+}
+
 func TestDynamicEnqueueRequest_EventHandler(t *testing.T) {
 	// Fixtures
 	nsn1 := types.NamespacedName{
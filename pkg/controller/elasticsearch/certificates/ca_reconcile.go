@@ -40,6 +40,7 @@ func Reconcile(
 	driver driver.Interface,
 	es esv1.Elasticsearch,
 	services []corev1.Service,
+	remoteCAs []byte,
 	caRotation certificates.RotationParams,
 	certRotation certificates.RotationParams,
 ) (*CertificateResources, *reconciler.Results) {
@@ -115,6 +116,7 @@ func Reconcile(
 		driver.K8sClient(),
 		driver.Scheme(),
 		transportCA,
+		remoteCAs,
 		es,
 		certRotation,
 	)
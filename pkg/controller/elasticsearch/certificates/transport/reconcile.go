@@ -26,11 +26,12 @@ import (
 var log = logf.Log.WithName("transport")
 
 // ReconcileTransportCertificatesSecrets reconciles the secret containing transport certificates for all nodes in the
-// cluster.
+// cluster. The nodes trust the given CA, and the additional PEM-encoded CA certificates of the remote clusters.
 func ReconcileTransportCertificatesSecrets(
 	c k8s.Client,
 	scheme *runtime.Scheme,
 	ca *certificates.CA,
+	remoteCAs []byte,
 	es esv1.Elasticsearch,
 	rotationParams certificates.RotationParams,
 ) (reconcile.Result, error) {
@@ -86,7 +87,7 @@ func ReconcileTransportCertificatesSecrets(
 		}
 	}
 
-	caBytes := certificates.EncodePEMCert(ca.Cert.Raw)
+	caBytes := append(certificates.EncodePEMCert(ca.Cert.Raw), remoteCAs...)
 
 	// compare with current trusted CA certs.
 	if !bytes.Equal(caBytes, secret.Data[certificates.CAFileName]) {
@@ -59,6 +59,8 @@ type Client interface {
 	AllocationSetter
 	ShardLister
 	LicenseClient
+	SnapshotClient
+	RemoteClusterClient
 	// Close idle connections in the underlying http client.
 	Close()
 	// Equal returns true if other can be considered as the same client.
@@ -196,6 +198,16 @@ func IsConflict(err error) bool {
 	}
 }
 
+// IsUnauthorized checks whether the error was an HTTP 401 error.
+func IsUnauthorized(err error) bool {
+	switch err := err.(type) {
+	case *APIError:
+		return err.response.StatusCode == http.StatusUnauthorized
+	default:
+		return false
+	}
+}
+
 // IsForbidden checks whether the error was an HTTP 403 error.
 func IsForbidden(err error) bool {
 	switch err := err.(type) {
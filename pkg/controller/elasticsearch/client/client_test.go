@@ -300,7 +300,7 @@ func TestAPIError_Error(t *testing.T) {
 }
 
 func TestClientGetNodes(t *testing.T) {
-	expectedPath := "/_nodes/_all/jvm,settings"
+	expectedPath := "/_nodes/_all/jvm,settings,plugins"
 	testClient := NewMockClient(version.MustParse("6.8.0"), func(req *http.Request) *http.Response {
 		require.Equal(t, expectedPath, req.URL.Path)
 		return &http.Response{
@@ -547,11 +547,12 @@ func TestAPIError_Types(t *testing.T) {
 		err error
 	}
 	tests := []struct {
-		name          string
-		args          args
-		wantConflict  bool
-		wantForbidden bool
-		wantNotFound  bool
+		name             string
+		args             args
+		wantConflict     bool
+		wantForbidden    bool
+		wantNotFound     bool
+		wantUnauthorized bool
 	}{
 		{
 			name: "500 is not any of the explicitly supported error types",
@@ -573,6 +574,13 @@ func TestAPIError_Types(t *testing.T) {
 			},
 			wantForbidden: true,
 		},
+		{
+			name: "401 is unauthorized",
+			args: args{
+				err: &APIError{response: NewMockResponse(401, nil, "")}, // nolint
+			},
+			wantUnauthorized: true,
+		},
 		{
 			name: "404 is not found",
 			args: args{
@@ -599,6 +607,9 @@ func TestAPIError_Types(t *testing.T) {
 			if got := IsConflict(tt.args.err); got != tt.wantConflict {
 				t.Errorf("IsConflict() = %v, want %v", got, tt.wantConflict)
 			}
+			if got := IsUnauthorized(tt.args.err); got != tt.wantUnauthorized {
+				t.Errorf("IsUnauthorized() = %v, want %v", got, tt.wantUnauthorized)
+			}
 		})
 	}
 }
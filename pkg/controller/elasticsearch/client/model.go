@@ -7,6 +7,7 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -74,6 +75,24 @@ func (n Nodes) Names() []string {
 	return names
 }
 
+// Plugins returns the sorted names of the plugins installed on all the nodes.
+func (n Nodes) Plugins() []string {
+	installed := make(map[string]int)
+	for _, node := range n.Nodes {
+		for _, plugin := range node.Plugins {
+			installed[plugin.Name]++
+		}
+	}
+	var plugins []string
+	for name, count := range installed {
+		if count == len(n.Nodes) {
+			plugins = append(plugins, name)
+		}
+	}
+	sort.Strings(plugins)
+	return plugins
+}
+
 // Node partially models an Elasticsearch node retrieved from /_nodes
 type Node struct {
 	Name    string   `json:"name"`
@@ -85,6 +104,9 @@ type Node struct {
 			HeapMaxInBytes int `json:"heap_max_in_bytes"`
 		} `json:"mem"`
 	} `json:"jvm"`
+	Plugins []struct {
+		Name string `json:"name"`
+	} `json:"plugins"`
 }
 
 func (n Node) isV7OrAbove() (bool, error) {
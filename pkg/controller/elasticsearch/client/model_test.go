@@ -114,6 +114,18 @@ func TestClusterRoutingAllocation(t *testing.T) {
 	require.Equal(t, false, settings.Transient.IsShardsAllocationEnabled())
 }
 
+func TestNodes_Plugins(t *testing.T) {
+	nodesSample := `{"nodes":{
+"node-0":{"name":"es-0","plugins":[{"name":"repository-s3"},{"name":"analysis-icu"}]},
+"node-1":{"name":"es-1","plugins":[{"name":"analysis-icu"},{"name":"repository-s3"}]},
+"node-2":{"name":"es-2","plugins":[{"name":"analysis-icu"}]}}}`
+	var nodes Nodes
+	require.NoError(t, json.Unmarshal([]byte(nodesSample), &nodes))
+	// only the plugins installed on all nodes are returned
+	require.Equal(t, []string{"analysis-icu"}, nodes.Plugins())
+	require.Empty(t, Nodes{}.Plugins())
+}
+
 func TestLicenseUpdateResponse_IsSuccess(t *testing.T) {
 	type fields struct {
 		Acknowledged  bool
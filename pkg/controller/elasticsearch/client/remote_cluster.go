@@ -0,0 +1,35 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"context"
+)
+
+type RemoteClusterClient interface {
+	// UpdateSettings updates the persistent and transient cluster settings, such as the remote cluster settings.
+	UpdateSettings(ctx context.Context, settings Settings) error
+	// GetRemoteClusters returns the remote clusters configured in Elasticsearch and the state of their connection.
+	GetRemoteClusters(ctx context.Context) (map[string]RemoteClusterInfo, error)
+}
+
+// RemoteClusterInfo is the state of the connection to a remote cluster, as returned by the remote info API.
+type RemoteClusterInfo struct {
+	Seeds                    []string `json:"seeds"`
+	Connected                bool     `json:"connected"`
+	NumNodesConnected        int      `json:"num_nodes_connected"`
+	MaxConnectionsPerCluster int      `json:"max_connections_per_cluster"`
+	SkipUnavailable          bool     `json:"skip_unavailable"`
+}
+
+func (c *clientV6) UpdateSettings(ctx context.Context, settings Settings) error {
+	return c.put(ctx, "/_cluster/settings", settings, nil)
+}
+
+func (c *clientV6) GetRemoteClusters(ctx context.Context) (map[string]RemoteClusterInfo, error) {
+	var remoteClusters map[string]RemoteClusterInfo
+	err := c.get(ctx, "/_remote/info", &remoteClusters)
+	return remoteClusters, err
+}
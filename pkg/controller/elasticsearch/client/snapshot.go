@@ -0,0 +1,104 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type SnapshotClient interface {
+	// GetSnapshotRepository returns the snapshot repository with the given name.
+	GetSnapshotRepository(ctx context.Context, name string) (SnapshotRepository, error)
+	// UpsertSnapshotRepository registers or updates the snapshot repository with the given name.
+	UpsertSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository) error
+	// GetSnapshotLifecyclePolicy returns the snapshot lifecycle policy with the given name.
+	//
+	// Introduced in: Elasticsearch 7.4.0
+	GetSnapshotLifecyclePolicy(ctx context.Context, name string) (SnapshotLifecyclePolicy, error)
+	// UpsertSnapshotLifecyclePolicy creates or updates the snapshot lifecycle policy with the given name.
+	//
+	// Introduced in: Elasticsearch 7.4.0
+	UpsertSnapshotLifecyclePolicy(ctx context.Context, name string, policy SnapshotLifecyclePolicyDefinition) error
+}
+
+// SnapshotRepository models a snapshot repository registered in Elasticsearch.
+type SnapshotRepository struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// SnapshotLifecyclePolicyDefinition is the definition of a snapshot lifecycle policy.
+type SnapshotLifecyclePolicyDefinition struct {
+	Name       string                 `json:"name"`
+	Schedule   string                 `json:"schedule"`
+	Repository string                 `json:"repository"`
+	Config     map[string]interface{} `json:"config,omitempty"`
+	Retention  map[string]interface{} `json:"retention,omitempty"`
+}
+
+// SnapshotInvocation is a snapshot taken, or attempted to be taken, by a snapshot lifecycle policy.
+type SnapshotInvocation struct {
+	SnapshotName string `json:"snapshot_name"`
+	TimeInMillis int64  `json:"time"`
+	Details      string `json:"details,omitempty"`
+}
+
+// Time is the time of the invocation.
+func (i SnapshotInvocation) Time() time.Time {
+	return time.Unix(0, i.TimeInMillis*int64(time.Millisecond))
+}
+
+// SnapshotLifecyclePolicy models a snapshot lifecycle policy and its last invocations.
+type SnapshotLifecyclePolicy struct {
+	Version     int64                             `json:"version"`
+	Policy      SnapshotLifecyclePolicyDefinition `json:"policy"`
+	LastSuccess *SnapshotInvocation               `json:"last_success,omitempty"`
+	LastFailure *SnapshotInvocation               `json:"last_failure,omitempty"`
+}
+
+func (c *clientV6) GetSnapshotRepository(ctx context.Context, name string) (SnapshotRepository, error) {
+	var repositories map[string]SnapshotRepository
+	if err := c.get(ctx, fmt.Sprintf("/_snapshot/%s", url.PathEscape(name)), &repositories); err != nil {
+		return SnapshotRepository{}, err
+	}
+	repository, exists := repositories[name]
+	if !exists {
+		return SnapshotRepository{}, fmt.Errorf("snapshot repository %s not returned", name)
+	}
+	return repository, nil
+}
+
+func (c *clientV6) UpsertSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository) error {
+	return c.put(ctx, fmt.Sprintf("/_snapshot/%s", url.PathEscape(name)), repository, nil)
+}
+
+func (c *clientV6) GetSnapshotLifecyclePolicy(ctx context.Context, name string) (SnapshotLifecyclePolicy, error) {
+	return SnapshotLifecyclePolicy{}, errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) UpsertSnapshotLifecyclePolicy(ctx context.Context, name string, policy SnapshotLifecyclePolicyDefinition) error {
+	return errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV7) GetSnapshotLifecyclePolicy(ctx context.Context, name string) (SnapshotLifecyclePolicy, error) {
+	var policies map[string]SnapshotLifecyclePolicy
+	if err := c.get(ctx, fmt.Sprintf("/_slm/policy/%s", url.PathEscape(name)), &policies); err != nil {
+		return SnapshotLifecyclePolicy{}, err
+	}
+	policy, exists := policies[name]
+	if !exists {
+		return SnapshotLifecyclePolicy{}, fmt.Errorf("snapshot lifecycle policy %s not returned", name)
+	}
+	return policy, nil
+}
+
+func (c *clientV7) UpsertSnapshotLifecyclePolicy(ctx context.Context, name string, policy SnapshotLifecyclePolicyDefinition) error {
+	return c.put(ctx, fmt.Sprintf("/_slm/policy/%s", url.PathEscape(name)), policy, nil)
+}
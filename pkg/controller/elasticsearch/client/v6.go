@@ -75,7 +75,7 @@ func (c *clientV6) ReloadSecureSettings(ctx context.Context) error {
 func (c *clientV6) GetNodes(ctx context.Context) (Nodes, error) {
 	var nodes Nodes
 	// restrict call to basic node info only
-	return nodes, c.get(ctx, "/_nodes/_all/jvm,settings", &nodes)
+	return nodes, c.get(ctx, "/_nodes/_all/jvm,settings,plugins", &nodes)
 }
 
 func (c *clientV6) GetNodesStats(ctx context.Context) (NodesStats, error) {
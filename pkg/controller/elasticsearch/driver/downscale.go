@@ -9,6 +9,7 @@ import (
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/expectations"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
@@ -20,6 +21,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version/zen1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version/zen2"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/pointer"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -73,6 +75,31 @@ func HandleDownscale(
 	return results
 }
 
+// HandleScaleToZero removes all the nodes of the cluster at once, when it is scaled down to zero nodes.
+// Unlike a regular downscale, data is not migrated away and master nodes are not excluded from voting: the nodes
+// keep their data and the cluster state in their volumes, from which the cluster is restarted when scaled up again.
+// This does not require the Elasticsearch API.
+func HandleScaleToZero(
+	k8sClient k8s.Client,
+	expectations *expectations.Expectations,
+	actualStatefulSets sset.StatefulSetList,
+) error {
+	for _, statefulSet := range actualStatefulSets {
+		replicas := sset.GetReplicas(statefulSet)
+		if replicas == 0 {
+			continue
+		}
+		ssetLogger(statefulSet).Info("Scaling replicas down to zero", "from", replicas)
+		nodespec.UpdateReplicas(&statefulSet, pointer.Int32(0))
+		if err := k8sClient.Update(&statefulSet); err != nil {
+			return err
+		}
+		// Expect the updated statefulset in the cache for next reconciliation.
+		expectations.ExpectGeneration(statefulSet)
+	}
+	return nil
+}
+
 // deleteStatefulSets deletes the given StatefulSets along with their associated resources.
 func deleteStatefulSets(toDelete sset.StatefulSetList, k8sClient k8s.Client, es esv1.Elasticsearch) error {
 	for _, toDelete := range toDelete {
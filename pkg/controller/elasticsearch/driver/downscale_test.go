@@ -292,6 +292,27 @@ func TestHandleDownscale(t *testing.T) {
 	require.True(t, apierrors.IsNotFound(err))
 }
 
+func TestHandleScaleToZero(t *testing.T) {
+	ssetMasters := *ssetMaster3Replicas.DeepCopy()
+	ssetData := *ssetData4Replicas.DeepCopy()
+	ssetStopped := sset.TestSset{Name: "ssetStopped", Namespace: "ns", Version: "7.2.0", Replicas: 0, Data: true}.Build()
+	k8sClient := k8s.WrappedFakeClient(&ssetMasters, &ssetData, &ssetStopped)
+	exp := expectations.NewExpectations(k8sClient)
+
+	err := HandleScaleToZero(k8sClient, exp, sset.StatefulSetList{ssetMasters, ssetData, ssetStopped})
+	require.NoError(t, err)
+
+	// all nodes are removed at once, without going through data migration or voting exclusions
+	var actual appsv1.StatefulSetList
+	require.NoError(t, k8sClient.List(&actual))
+	require.Len(t, actual.Items, 3)
+	for _, s := range actual.Items {
+		require.Equal(t, int32(0), sset.GetReplicas(s), s.Name)
+	}
+	// expectations are only registered for updated StatefulSets
+	require.Len(t, exp.GetGenerations(), 2)
+}
+
 func Test_calculateDownscales(t *testing.T) {
 	ssets := sset.StatefulSetList{
 		{
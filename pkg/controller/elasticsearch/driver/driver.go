@@ -13,6 +13,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	controller "sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
@@ -35,15 +36,23 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/license"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/remotecluster"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/snapshot"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
 	esversion "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/zone"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
 )
 
 var (
 	defaultRequeue = controller.Result{Requeue: true, RequeueAfter: 10 * time.Second}
+	// snapshotStatusRefreshPeriod is the period at which the status of snapshot lifecycle policies is refreshed.
+	snapshotStatusRefreshPeriod = 10 * time.Minute
+	// remoteClustersStatusRefreshPeriod is the period at which the status of the remote cluster connections is refreshed.
+	remoteClustersStatusRefreshPeriod = time.Minute
 )
 
 // Driver orchestrates the reconciliation of an Elasticsearch resource.
@@ -71,9 +80,12 @@ type DefaultDriverParameters struct {
 	// Version is the version of Elasticsearch we want to reconcile towards.
 	Version version.Version
 	// Client is used to access the Kubernetes API.
-	Client   k8s.Client
-	Scheme   *runtime.Scheme
-	Recorder record.EventRecorder
+	Client k8s.Client
+	// APIReader reads cluster-scoped resources such as Kubernetes nodes directly from the API server,
+	// since the Client cache only covers the managed namespaces.
+	APIReader client.Reader
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
 
 	// State holds the accumulated state during the reconcile loop
 	ReconcileState *reconcile.State
@@ -84,6 +96,8 @@ type DefaultDriverParameters struct {
 	// Expectations control some expectations set on resources in the cache, in order to
 	// avoid doing certain operations if the cache hasn't seen an up-to-date resource yet.
 	Expectations *expectations.Expectations
+	// AccessReviewer checks whether the cluster is allowed to reference remote clusters in other namespaces.
+	AccessReviewer rbac.AccessReviewer
 }
 
 // defaultDriver is the default Driver implementation
@@ -127,11 +141,38 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 		return results.WithError(err)
 	}
 
+	externalURL := services.LoadBalancerURL(d.ES, *externalService)
+	d.ReconcileState.UpdateElasticsearchExternalURL(externalURL)
+	if externalService.Spec.Type == corev1.ServiceTypeLoadBalancer && externalURL == "" {
+		// check again later whether the load balancer is provisioned
+		log.V(1).Info("Load balancer not provisioned yet", "namespace", d.ES.Namespace, "es_name", d.ES.Name)
+		results.WithResult(defaultRequeue)
+	}
+
+	// the init container of scheduled pods waits for their zone to be set before starting Elasticsearch
+	if err := zone.AnnotatePods(d.Client, d.APIReader, d.ES); err != nil {
+		d.ReconcileState.AddEvent(
+			corev1.EventTypeWarning,
+			events.EventReasonUnexpected,
+			fmt.Sprintf("Could not set the zone of pods: %s", err.Error()),
+		)
+		results.WithError(err)
+	}
+
+	remoteCAs, remoteCASecrets, err := remotecluster.TrustedCAs(d.Client, d.AccessReviewer, d.ES)
+	if err != nil {
+		return results.WithError(err)
+	}
+	if err := remotecluster.WatchCASecrets(d.DynamicWatches(), k8s.ExtractNamespacedName(&d.ES), remoteCASecrets); err != nil {
+		return results.WithError(err)
+	}
+
 	certificateResources, res := certificates.Reconcile(
 		ctx,
 		d,
 		d.ES,
 		[]corev1.Service{*externalService},
+		remoteCAs,
 		d.OperatorParameters.CACertRotation,
 		d.OperatorParameters.CertRotation,
 	)
@@ -157,6 +198,7 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 	}
 
 	warnUnsupportedDistro(resourcesState.AllPods, d.ReconcileState.Recorder)
+	warnZoneNotSet(resourcesState.AllPods, d.ReconcileState.Recorder)
 
 	observedState := d.Observers.ObservedStateResolver(
 		k8s.ExtractNamespacedName(&d.ES),
@@ -192,6 +234,15 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 	results.Apply(
 		"reconcile-cluster-license",
 		func(ctx context.Context) (controller.Result, error) {
+			d.ReconcileState.UpdateElasticsearchLicense(license.Status(observedState.ClusterLicense))
+			if license.ExpiresSoon(observedState.ClusterLicense, time.Now()) {
+				d.ReconcileState.AddEvent(
+					corev1.EventTypeWarning,
+					events.EventReasonLicenseExpiring,
+					fmt.Sprintf("Elasticsearch %s license expires at %s", observedState.ClusterLicense.Type,
+						observedState.ClusterLicense.ExpiryTime().UTC().Format(time.RFC3339)),
+				)
+			}
 			if !esReachable {
 				return defaultRequeue, nil
 			}
@@ -216,6 +267,84 @@ func (d *defaultDriver) Reconcile(ctx context.Context) *reconciler.Results {
 		},
 	)
 
+	results.Apply(
+		"reconcile-snapshots",
+		func(ctx context.Context) (controller.Result, error) {
+			if d.ES.Spec.Snapshots == nil {
+				d.ReconcileState.UpdateElasticsearchSnapshotPolicies(nil)
+				return controller.Result{}, nil
+			}
+			// repositories are only registered once the cluster is green: they may be verified by every node
+			if !esReachable || observedState.ClusterHealth == nil || observedState.ClusterHealth.Status != esv1.ElasticsearchGreenHealth {
+				return defaultRequeue, nil
+			}
+			policies, err := snapshot.Reconcile(ctx, d.ES, esClient)
+			if err != nil {
+				d.ReconcileState.AddEvent(
+					corev1.EventTypeWarning,
+					events.EventReasonUnexpected,
+					fmt.Sprintf("Could not reconcile snapshots: %s", err.Error()),
+				)
+			}
+			if err == nil || len(policies) > 0 {
+				d.ReconcileState.UpdateElasticsearchSnapshotPolicies(policies)
+			}
+			if len(d.ES.Spec.Snapshots.Policies) > 0 {
+				// refresh the status of the last snapshots
+				return controller.Result{RequeueAfter: snapshotStatusRefreshPeriod}, err
+			}
+			return controller.Result{}, err
+		},
+	)
+
+	results.Apply(
+		"reconcile-remote-clusters",
+		func(ctx context.Context) (controller.Result, error) {
+			if len(d.ES.Spec.RemoteClusters) == 0 && len(d.ES.Status.RemoteClusters) == 0 {
+				return controller.Result{}, nil
+			}
+			if !esReachable {
+				return defaultRequeue, nil
+			}
+			remoteClusters, err := remotecluster.Reconcile(ctx, d.Client, d.AccessReviewer, d.ES, esClient)
+			if err != nil {
+				d.ReconcileState.AddEvent(
+					corev1.EventTypeWarning,
+					events.EventReasonUnexpected,
+					fmt.Sprintf("Could not reconcile remote clusters: %s", err.Error()),
+				)
+				return defaultRequeue, err
+			}
+			d.ReconcileState.UpdateElasticsearchRemoteClusters(remoteClusters)
+			if len(remoteClusters) > 0 {
+				// refresh the status of the connections
+				return controller.Result{RequeueAfter: remoteClustersStatusRefreshPeriod}, nil
+			}
+			return controller.Result{}, nil
+		},
+	)
+
+	results.Apply(
+		"observe-plugins",
+		func(ctx context.Context) (controller.Result, error) {
+			if len(d.ES.Spec.Plugins) == 0 {
+				d.ReconcileState.UpdateElasticsearchPlugins(nil)
+				return controller.Result{}, nil
+			}
+			if !esReachable {
+				return defaultRequeue, nil
+			}
+			reqCtx, cancel := context.WithTimeout(ctx, esclient.DefaultReqTimeout)
+			defer cancel()
+			nodes, err := esClient.GetNodes(reqCtx)
+			if err != nil {
+				return defaultRequeue, err
+			}
+			d.ReconcileState.UpdateElasticsearchPlugins(nodes.Plugins())
+			return controller.Result{}, nil
+		},
+	)
+
 	// Compute seed hosts based on current masters with a podIP
 	if err := settings.UpdateSeedHostsConfigMap(ctx, d.Client, d.Scheme(), d.ES, resourcesState.AllPods); err != nil {
 		return results.WithError(err)
@@ -279,3 +408,18 @@ func warnUnsupportedDistro(pods []corev1.Pod, recorder *events.Recorder) {
 		}
 	}
 }
+
+// warnZoneNotSet sends an event of type warning if the prepare fs init container of a pod terminated with the
+// ZoneNotSet exit code, because the zone of the pod was not set in time.
+func warnZoneNotSet(pods []corev1.Pod, recorder *events.Recorder) {
+	for _, p := range pods {
+		for _, s := range p.Status.InitContainerStatuses {
+			state := s.LastTerminationState.Terminated
+			if s.Name == initcontainer.PrepareFilesystemContainerName &&
+				state != nil && state.ExitCode == initcontainer.ZoneNotSetExitCode {
+				recorder.AddEvent(corev1.EventTypeWarning, events.EventReasonUnexpected,
+					fmt.Sprintf("Zone of pod %s not set in time", p.Name))
+			}
+		}
+	}
+}
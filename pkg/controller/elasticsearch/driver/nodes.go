@@ -65,6 +65,16 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		return results.WithError(err)
 	}
 
+	// Expand the volumes of StatefulSets requesting more storage, which requires recreating them.
+	recreating, err := handleVolumeExpansion(d.K8sClient(), d.APIReader, d.Scheme(), &d.ES, expectedResources.StatefulSets(), actualStatefulSets)
+	if err != nil {
+		reconcileState.AddEvent(corev1.EventTypeWarning, events.EventReconciliationError, fmt.Sprintf("Failed to expand volumes: %v", err))
+		return results.WithError(err)
+	}
+	if recreating {
+		return results.WithResult(defaultRequeue)
+	}
+
 	esState := NewMemoizingESState(ctx, esClient)
 
 	// Phase 1: apply expected StatefulSets resources and scale up.
@@ -98,6 +108,19 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		return results.WithError(err)
 	}
 
+	// Scaling the cluster down to zero nodes stops all of them at once, there is nothing else to orchestrate.
+	if d.ES.Spec.ScaledToZero() {
+		if err := HandleScaleToZero(d.K8sClient(), d.Expectations, actualStatefulSets); err != nil {
+			return results.WithError(err)
+		}
+		if len(resourcesState.CurrentPods) == 0 {
+			reconcileState.UpdateElasticsearchReady(resourcesState, observedState)
+		} else {
+			reconcileState.UpdateElasticsearchApplyingChanges(resourcesState.CurrentPods)
+		}
+		return results
+	}
+
 	// Next operations require the Elasticsearch API to be available.
 	if !esReachable {
 		log.Info("ES cannot be reached yet, re-queuing", "namespace", d.ES.Namespace, "es_name", d.ES.Name)
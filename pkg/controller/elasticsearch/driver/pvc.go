@@ -22,12 +22,16 @@ import (
 // This covers:
 // * leftover PVCs created for StatefulSets that do not exist anymore
 // * leftover PVCs created for StatefulSets replicas that don't exist anymore (eg. downscale from 5 to 3 nodes)
+// PVCs of a cluster scaled down to zero nodes are retained, to be reused when the cluster is scaled up again.
 func GarbageCollectPVCs(
 	k8sClient k8s.Client,
 	es esv1.Elasticsearch,
 	actualStatefulSets sset.StatefulSetList,
 	expectedStatefulSets sset.StatefulSetList,
 ) error {
+	if es.Spec.ScaledToZero() {
+		return nil
+	}
 	// PVCs are using the same labels as their corresponding StatefulSet, so we can filter on ES cluster name.
 	var pvcs corev1.PersistentVolumeClaimList
 	ns := client.InNamespace(es.Namespace)
@@ -0,0 +1,307 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/hash"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const (
+	// recreateStatefulSetAnnotationPrefix is the prefix of the annotations set on the Elasticsearch resource while one
+	// of its StatefulSets is recreated to expand its volumes. Their value is the statefulSetRecreation of the
+	// StatefulSet named by the suffix.
+	recreateStatefulSetAnnotationPrefix = "elasticsearch.k8s.elastic.co/recreate-"
+
+	// defaultStorageClassAnnotation marks the storage class used when a claim does not specify one.
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	// betaDefaultStorageClassAnnotation is the beta version of defaultStorageClassAnnotation, still supported by k8s.
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// statefulSetRecreation holds what is needed to recreate a StatefulSet with expanded volumes, the rest of the StatefulSet
+// being the expected one. It is kept small since it is stored in an annotation of the Elasticsearch resource.
+type statefulSetRecreation struct {
+	// UID of the StatefulSet to delete, to tell it apart from the recreated one.
+	UID types.UID `json:"uid"`
+	// Replicas of the StatefulSet to delete, kept by the recreated one: scaling is left to the regular reconciliation.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// VolumeClaimTemplates of the recreated StatefulSet, with the expanded storage requests.
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates"`
+}
+
+// handleVolumeExpansion expands the PVCs of the actual StatefulSets whose expected volume claim templates request
+// more storage. Since volume claim templates cannot be updated, the StatefulSets are then deleted without deleting
+// their Pods, and recreated with the expected claims: Pods are adopted by the new StatefulSet and keep running.
+// The volume claims of the StatefulSet to recreate are stored in an annotation of the Elasticsearch resource in the
+// meantime, so the recreation survives an operator restart.
+// It returns true if a StatefulSet is being recreated, in which case the reconciliation should be requeued before
+// applying any other change to the StatefulSets.
+// Decreasing the storage request, or expanding volumes whose storage class does not allow it, is refused with an error.
+// Storage classes are read through storageClassReader, which must not be restricted to the managed namespaces.
+func handleVolumeExpansion(
+	k8sClient k8s.Client,
+	storageClassReader client.Reader,
+	scheme *runtime.Scheme,
+	es *esv1.Elasticsearch,
+	expectedStatefulSets sset.StatefulSetList,
+	actualStatefulSets sset.StatefulSetList,
+) (bool, error) {
+	for _, expected := range expectedStatefulSets {
+		actual, exists := actualStatefulSets.GetByName(expected.Name)
+		if !exists {
+			continue
+		}
+		if _, recreating := es.Annotations[recreateStatefulSetAnnotationPrefix+actual.Name]; recreating {
+			continue
+		}
+		claims, expand, err := expandedClaims(actual, expected)
+		if err != nil {
+			return false, err
+		}
+		if !expand {
+			continue
+		}
+		if err := expandPVCs(k8sClient, storageClassReader, actual, claims); err != nil {
+			return false, err
+		}
+		recreation := statefulSetRecreation{UID: actual.UID, Replicas: actual.Spec.Replicas, VolumeClaimTemplates: claims}
+		if err := annotateForRecreation(k8sClient, es, actual.Name, recreation); err != nil {
+			return false, err
+		}
+	}
+	return recreateStatefulSets(k8sClient, scheme, es, expectedStatefulSets, actualStatefulSets)
+}
+
+// expandedClaims returns the volume claim templates of the actual StatefulSet, with the storage request of the
+// expected ones when it is larger, and whether any such claim was found.
+func expandedClaims(actual, expected appsv1.StatefulSet) ([]corev1.PersistentVolumeClaim, bool, error) {
+	claims := make([]corev1.PersistentVolumeClaim, 0, len(actual.Spec.VolumeClaimTemplates))
+	expand := false
+	for _, actualClaim := range actual.Spec.VolumeClaimTemplates {
+		claim := *actualClaim.DeepCopy()
+		expectedClaim, exists := getClaim(expected.Spec.VolumeClaimTemplates, actualClaim.Name)
+		if exists {
+			actualSize := actualClaim.Spec.Resources.Requests[corev1.ResourceStorage]
+			expectedSize := expectedClaim.Spec.Resources.Requests[corev1.ResourceStorage]
+			switch expectedSize.Cmp(actualSize) {
+			case -1:
+				return nil, false, fmt.Errorf("decreasing the storage request of claim %s of StatefulSet %s from %s to %s is not supported",
+					actualClaim.Name, actual.Name, actualSize.String(), expectedSize.String())
+			case 1:
+				if claim.Spec.Resources.Requests == nil {
+					claim.Spec.Resources.Requests = corev1.ResourceList{}
+				}
+				claim.Spec.Resources.Requests[corev1.ResourceStorage] = expectedSize
+				expand = true
+			}
+		}
+		claims = append(claims, claim)
+	}
+	return claims, expand, nil
+}
+
+func getClaim(claims []corev1.PersistentVolumeClaim, name string) (corev1.PersistentVolumeClaim, bool) {
+	for _, claim := range claims {
+		if claim.Name == name {
+			return claim, true
+		}
+	}
+	return corev1.PersistentVolumeClaim{}, false
+}
+
+// expandPVCs updates the storage request of the existing PVCs of the given StatefulSet to the one of the given claims.
+// All PVCs are checked to belong to a storage class allowing volume expansion before any of them is updated.
+func expandPVCs(
+	k8sClient k8s.Client,
+	storageClassReader client.Reader,
+	statefulSet appsv1.StatefulSet,
+	claims []corev1.PersistentVolumeClaim,
+) error {
+	var toUpdate []corev1.PersistentVolumeClaim
+	for _, claim := range claims {
+		for _, podName := range sset.PodNames(statefulSet) {
+			var pvc corev1.PersistentVolumeClaim
+			key := types.NamespacedName{Namespace: statefulSet.Namespace, Name: fmt.Sprintf("%s-%s", claim.Name, podName)}
+			if err := k8sClient.Get(key, &pvc); err != nil {
+				if errors.IsNotFound(err) {
+					// the PVC will be created by the recreated StatefulSet
+					continue
+				}
+				return err
+			}
+			size := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+			if size.Cmp(pvc.Spec.Resources.Requests[corev1.ResourceStorage]) <= 0 {
+				continue
+			}
+			if err := ensureExpansionAllowed(storageClassReader, pvc); err != nil {
+				return err
+			}
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+			toUpdate = append(toUpdate, pvc)
+		}
+	}
+	for i := range toUpdate {
+		pvc := toUpdate[i]
+		log.Info("Expanding PVC", "namespace", pvc.Namespace, "pvc_name", pvc.Name,
+			"storage", pvc.Spec.Resources.Requests[corev1.ResourceStorage])
+		if err := k8sClient.Update(&pvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureExpansionAllowed returns an error if the storage class of the given PVC does not allow volume expansion.
+func ensureExpansionAllowed(storageClassReader client.Reader, pvc corev1.PersistentVolumeClaim) error {
+	storageClass, err := getStorageClass(storageClassReader, pvc)
+	if err != nil {
+		return err
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return fmt.Errorf("storage class %s of PVC %s does not allow volume expansion", storageClass.Name, pvc.Name)
+	}
+	return nil
+}
+
+// getStorageClass returns the storage class of the given PVC, or the default storage class if none is specified.
+func getStorageClass(storageClassReader client.Reader, pvc corev1.PersistentVolumeClaim) (storagev1.StorageClass, error) {
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+		var storageClass storagev1.StorageClass
+		err := storageClassReader.Get(context.Background(), types.NamespacedName{Name: *pvc.Spec.StorageClassName}, &storageClass)
+		return storageClass, err
+	}
+	var storageClasses storagev1.StorageClassList
+	if err := storageClassReader.List(context.Background(), &storageClasses); err != nil {
+		return storagev1.StorageClass{}, err
+	}
+	for _, storageClass := range storageClasses.Items {
+		if storageClass.Annotations[defaultStorageClassAnnotation] == "true" ||
+			storageClass.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			return storageClass, nil
+		}
+	}
+	return storagev1.StorageClass{}, fmt.Errorf("no storage class specified for PVC %s and no default storage class", pvc.Name)
+}
+
+// annotateForRecreation stores the recreation of the given StatefulSet in an annotation of the Elasticsearch resource.
+func annotateForRecreation(k8sClient k8s.Client, es *esv1.Elasticsearch, name string, recreation statefulSetRecreation) error {
+	asJSON, err := json.Marshal(recreation)
+	if err != nil {
+		return err
+	}
+	if es.Annotations == nil {
+		es.Annotations = map[string]string{}
+	}
+	es.Annotations[recreateStatefulSetAnnotationPrefix+name] = string(asJSON)
+	return k8sClient.Update(es)
+}
+
+// recreateStatefulSets recreates the StatefulSets whose recreation is stored in the annotations of the Elasticsearch
+// resource: the existing StatefulSet is deleted while orphaning its Pods, then created again from the expected one, with
+// the stored replicas and volume claims, once the deletion is effective. The annotation is removed once the recreated
+// StatefulSet is observed.
+// It returns true if a recreation is still in progress.
+func recreateStatefulSets(
+	k8sClient k8s.Client,
+	scheme *runtime.Scheme,
+	es *esv1.Elasticsearch,
+	expectedStatefulSets sset.StatefulSetList,
+	actualStatefulSets sset.StatefulSetList,
+) (bool, error) {
+	inProgress := false
+	recreated := false
+	for annotation, value := range es.Annotations {
+		if !strings.HasPrefix(annotation, recreateStatefulSetAnnotationPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(annotation, recreateStatefulSetAnnotationPrefix)
+		var recreation statefulSetRecreation
+		if err := json.Unmarshal([]byte(value), &recreation); err != nil {
+			return false, err
+		}
+		actual, exists := actualStatefulSets.GetByName(name)
+		expected, isExpected := expectedStatefulSets.GetByName(name)
+		switch {
+		case exists && actual.UID != recreation.UID:
+			// recreated during a previous reconciliation
+			delete(es.Annotations, annotation)
+			recreated = true
+		case !exists && !isExpected:
+			// the node set was removed in the meantime, there is nothing to recreate
+			log.Info("Not recreating StatefulSet not expected anymore", "namespace", es.Namespace, "statefulset_name", name)
+			delete(es.Annotations, annotation)
+			recreated = true
+		case exists:
+			inProgress = true
+			if actual.DeletionTimestamp != nil {
+				// wait for the deletion to be effective
+				continue
+			}
+			log.Info("Deleting StatefulSet to expand its volumes", "namespace", actual.Namespace, "statefulset_name", actual.Name)
+			orphan := metav1.DeletePropagationOrphan
+			opts := []client.DeleteOption{client.PropagationPolicy(orphan), client.Preconditions{UID: &actual.UID}}
+			if err := k8sClient.Delete(&actual, opts...); err != nil && !errors.IsNotFound(err) {
+				return false, err
+			}
+		default:
+			inProgress = true
+			log.Info("Recreating StatefulSet with expanded volumes", "namespace", es.Namespace, "statefulset_name", name)
+			toRecreate := *expected.DeepCopy()
+			toRecreate.Spec.Replicas = recreation.Replicas
+			toRecreate.Spec.VolumeClaimTemplates = recreation.VolumeClaimTemplates
+			toRecreate.Labels = hash.SetTemplateHashLabel(toRecreate.Labels, toRecreate.Spec)
+			if _, err := sset.ReconcileStatefulSet(k8sClient, scheme, *es, toRecreate, nil); err != nil {
+				return false, err
+			}
+		}
+	}
+	if recreated {
+		if err := k8sClient.Update(es); err != nil {
+			return false, err
+		}
+	}
+	return inProgress, nil
+}
+
+// fileSystemResizePending returns true if a PVC of the given Pod waits for the Pod to restart to resize its file system,
+// which is the case for volumes that cannot be expanded online.
+func fileSystemResizePending(k8sClient k8s.Client, statefulSet appsv1.StatefulSet, podName string) (bool, error) {
+	for _, claim := range statefulSet.Spec.VolumeClaimTemplates {
+		var pvc corev1.PersistentVolumeClaim
+		key := types.NamespacedName{Namespace: statefulSet.Namespace, Name: fmt.Sprintf("%s-%s", claim.Name, podName)}
+		if err := k8sClient.Get(key, &pvc); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		for _, condition := range pvc.Status.Conditions {
+			if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,311 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func withStorage(s appsv1.StatefulSet, size string) appsv1.StatefulSet {
+	for i := range s.Spec.VolumeClaimTemplates {
+		s.Spec.VolumeClaimTemplates[i].Spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: resource.MustParse(size),
+		}
+	}
+	return s
+}
+
+func buildPVCWithStorage(name string, storageClass string, size string) *corev1.PersistentVolumeClaim {
+	pvc := buildPVCPtr(name)
+	if storageClass != "" {
+		pvc.Spec.StorageClassName = &storageClass
+	}
+	pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}
+	return pvc
+}
+
+func buildStorageClass(name string, allowExpansion bool, isDefault bool) *storagev1.StorageClass {
+	sc := &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: name},
+		AllowVolumeExpansion: &allowExpansion,
+	}
+	if isDefault {
+		sc.Annotations = map[string]string{defaultStorageClassAnnotation: "true"}
+	}
+	return sc
+}
+
+// namespacedClient behaves like the cache of an operator restricted to some namespaces, which cannot read
+// cluster-scoped resources.
+type namespacedClient struct {
+	k8s.Client
+}
+
+func (c namespacedClient) Get(key client.ObjectKey, obj runtime.Object) error {
+	if key.Namespace == "" {
+		return fmt.Errorf("unable to get: %v because of unknown namespace for the cache", key)
+	}
+	return c.Client.Get(key, obj)
+}
+
+func (c namespacedClient) List(list runtime.Object, opts ...client.ListOption) error {
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Namespace == "" {
+		return fmt.Errorf("unable to list %T: namespace is required for the cache", list)
+	}
+	return c.Client.List(list, opts...)
+}
+
+func Test_expandedClaims(t *testing.T) {
+	tests := []struct {
+		name       string
+		actual     appsv1.StatefulSet
+		expected   appsv1.StatefulSet
+		wantExpand bool
+		wantErr    bool
+	}{
+		{
+			name:       "same storage request",
+			actual:     withStorage(buildSsetWithClaims("sset1", 1, "claim1"), "1Gi"),
+			expected:   withStorage(buildSsetWithClaims("sset1", 1, "claim1"), "1Gi"),
+			wantExpand: false,
+		},
+		{
+			name:       "larger storage request",
+			actual:     withStorage(buildSsetWithClaims("sset1", 1, "claim1"), "1Gi"),
+			expected:   withStorage(buildSsetWithClaims("sset1", 1, "claim1"), "2Gi"),
+			wantExpand: true,
+		},
+		{
+			name:     "smaller storage request",
+			actual:   withStorage(buildSsetWithClaims("sset1", 1, "claim1"), "2Gi"),
+			expected: withStorage(buildSsetWithClaims("sset1", 1, "claim1"), "1Gi"),
+			wantErr:  true,
+		},
+		{
+			name:       "claim not expected anymore",
+			actual:     withStorage(buildSsetWithClaims("sset1", 1, "claim1"), "1Gi"),
+			expected:   withStorage(buildSsetWithClaims("sset1", 1, "claim2"), "2Gi"),
+			wantExpand: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, expand, err := expandedClaims(tt.actual, tt.expected)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantExpand, expand)
+			require.Len(t, claims, len(tt.actual.Spec.VolumeClaimTemplates))
+			if expand {
+				require.Equal(t, tt.expected.Spec.VolumeClaimTemplates, claims)
+			}
+		})
+	}
+}
+
+func Test_handleVolumeExpansion(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	actual := withStorage(buildSsetWithClaims("sset1", 2, "claim1"), "1Gi")
+	actual.UID = "actual-uid"
+	// the StatefulSet is also scaled up, which is left to the regular reconciliation
+	expected := withStorage(buildSsetWithClaims("sset1", 3, "claim1"), "2Gi")
+
+	tests := []struct {
+		name           string
+		storageClasses []runtime.Object
+		objs           []runtime.Object
+		wantErr        bool
+		wantStorage    string
+	}{
+		{
+			name:           "storage class allows volume expansion",
+			storageClasses: []runtime.Object{buildStorageClass("sc", true, false)},
+			objs: []runtime.Object{
+				buildPVCWithStorage("claim1-sset1-0", "sc", "1Gi"),
+				buildPVCWithStorage("claim1-sset1-1", "sc", "1Gi"),
+			},
+			wantStorage: "2Gi",
+		},
+		{
+			name: "default storage class allows volume expansion",
+			storageClasses: []runtime.Object{
+				buildStorageClass("other", false, false),
+				buildStorageClass("default", true, true),
+			},
+			objs: []runtime.Object{
+				buildPVCWithStorage("claim1-sset1-0", "", "1Gi"),
+				buildPVCWithStorage("claim1-sset1-1", "", "1Gi"),
+			},
+			wantStorage: "2Gi",
+		},
+		{
+			name:           "storage class does not allow volume expansion",
+			storageClasses: []runtime.Object{buildStorageClass("sc", false, false)},
+			objs: []runtime.Object{
+				buildPVCWithStorage("claim1-sset1-0", "sc", "1Gi"),
+				buildPVCWithStorage("claim1-sset1-1", "sc", "1Gi"),
+			},
+			wantErr:     true,
+			wantStorage: "1Gi",
+		},
+		{
+			name: "storage class does not exist",
+			objs: []runtime.Object{
+				buildPVCWithStorage("claim1-sset1-0", "sc", "1Gi"),
+				buildPVCWithStorage("claim1-sset1-1", "sc", "1Gi"),
+			},
+			wantErr:     true,
+			wantStorage: "1Gi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := es.DeepCopy()
+			k8sClient := namespacedClient{Client: k8s.WrappedFakeClient(append(tt.objs, es, actual.DeepCopy())...)}
+			storageClassReader := k8s.FakeClient(tt.storageClasses...)
+
+			recreating, err := handleVolumeExpansion(k8sClient, storageClassReader, k8s.Scheme(), es, sset.StatefulSetList{expected}, sset.StatefulSetList{actual})
+			if tt.wantErr {
+				require.Error(t, err)
+				require.False(t, recreating)
+			} else {
+				require.NoError(t, err)
+				require.True(t, recreating)
+			}
+
+			for _, name := range []string{"claim1-sset1-0", "claim1-sset1-1"} {
+				var pvc corev1.PersistentVolumeClaim
+				require.NoError(t, k8sClient.Get(types.NamespacedName{Namespace: "ns", Name: name}, &pvc))
+				require.Equal(t, resource.MustParse(tt.wantStorage), pvc.Spec.Resources.Requests[corev1.ResourceStorage])
+			}
+			var ssetAfter appsv1.StatefulSet
+			err = k8sClient.Get(k8s.ExtractNamespacedName(&actual), &ssetAfter)
+			var retrievedES esv1.Elasticsearch
+			require.NoError(t, k8sClient.Get(k8s.ExtractNamespacedName(es), &retrievedES))
+			if tt.wantErr {
+				// nothing is recreated
+				require.NoError(t, err)
+				require.Empty(t, retrievedES.Annotations)
+				return
+			}
+			// the StatefulSet is deleted, to be recreated
+			require.True(t, errors.IsNotFound(err))
+			require.Contains(t, retrievedES.Annotations, recreateStatefulSetAnnotationPrefix+"sset1")
+		})
+	}
+}
+
+func Test_handleVolumeExpansion_recreation(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	actual := withStorage(buildSsetWithClaims("sset1", 2, "claim1"), "1Gi")
+	actual.UID = "actual-uid"
+	// the StatefulSet is also scaled up, which is left to the regular reconciliation
+	expected := withStorage(buildSsetWithClaims("sset1", 3, "claim1"), "2Gi")
+	k8sClient := k8s.WrappedFakeClient(
+		&es,
+		actual.DeepCopy(),
+		buildPVCWithStorage("claim1-sset1-0", "sc", "1Gi"),
+		buildPVCWithStorage("claim1-sset1-1", "sc", "1Gi"),
+	)
+	storageClassReader := k8s.FakeClient(buildStorageClass("sc", true, false))
+
+	// the PVCs are expanded and the StatefulSet is deleted
+	recreating, err := handleVolumeExpansion(k8sClient, storageClassReader, k8s.Scheme(), &es, sset.StatefulSetList{expected}, sset.StatefulSetList{actual})
+	require.NoError(t, err)
+	require.True(t, recreating)
+	// only what is needed to recreate the StatefulSet is stored
+	var recreation statefulSetRecreation
+	require.NoError(t, json.Unmarshal([]byte(es.Annotations[recreateStatefulSetAnnotationPrefix+"sset1"]), &recreation))
+	require.Equal(t, statefulSetRecreation{
+		UID:                  actual.UID,
+		Replicas:             actual.Spec.Replicas,
+		VolumeClaimTemplates: expected.Spec.VolumeClaimTemplates,
+	}, recreation)
+
+	// the deletion is not observed yet: the StatefulSet is not expanded again, its deletion is retried
+	recreating, err = handleVolumeExpansion(k8sClient, storageClassReader, k8s.Scheme(), &es, sset.StatefulSetList{expected}, sset.StatefulSetList{actual})
+	require.NoError(t, err)
+	require.True(t, recreating)
+
+	// the deletion is observed: the StatefulSet is recreated with the expanded claims and the same replicas
+	recreating, err = handleVolumeExpansion(k8sClient, storageClassReader, k8s.Scheme(), &es, sset.StatefulSetList{expected}, nil)
+	require.NoError(t, err)
+	require.True(t, recreating)
+	var recreated appsv1.StatefulSet
+	require.NoError(t, k8sClient.Get(k8s.ExtractNamespacedName(&actual), &recreated))
+	require.Equal(t, expected.Spec.VolumeClaimTemplates, recreated.Spec.VolumeClaimTemplates)
+	require.Equal(t, int32(2), sset.GetReplicas(recreated))
+	require.True(t, metav1.IsControlledBy(&recreated, &es))
+
+	// the recreated StatefulSet is observed: the recreation is over
+	recreated.UID = "recreated-uid"
+	recreating, err = handleVolumeExpansion(k8sClient, storageClassReader, k8s.Scheme(), &es, sset.StatefulSetList{expected}, sset.StatefulSetList{recreated})
+	require.NoError(t, err)
+	require.False(t, recreating)
+	var retrievedES esv1.Elasticsearch
+	require.NoError(t, k8sClient.Get(k8s.ExtractNamespacedName(&es), &retrievedES))
+	require.Empty(t, retrievedES.Annotations)
+}
+
+func Test_handleVolumeExpansion_recreationNotExpected(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "ns",
+		Name:        "es",
+		Annotations: map[string]string{recreateStatefulSetAnnotationPrefix + "sset1": `{"uid":"actual-uid","volumeClaimTemplates":[]}`},
+	}}
+	k8sClient := k8s.WrappedFakeClient(&es)
+
+	// the node set was removed while its StatefulSet was being recreated: it is not recreated
+	recreating, err := handleVolumeExpansion(k8sClient, k8s.FakeClient(), k8s.Scheme(), &es, nil, nil)
+	require.NoError(t, err)
+	require.False(t, recreating)
+	var statefulSets appsv1.StatefulSetList
+	require.NoError(t, k8sClient.List(&statefulSets))
+	require.Empty(t, statefulSets.Items)
+	var retrievedES esv1.Elasticsearch
+	require.NoError(t, k8sClient.Get(k8s.ExtractNamespacedName(&es), &retrievedES))
+	require.Empty(t, retrievedES.Annotations)
+}
+
+func Test_fileSystemResizePending(t *testing.T) {
+	statefulSet := buildSsetWithClaims("sset1", 2, "claim1")
+	resizing := buildPVCPtr("claim1-sset1-1")
+	resizing.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+		{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+	}
+	k8sClient := k8s.WrappedFakeClient(buildPVCPtr("claim1-sset1-0"), resizing)
+
+	pending, err := fileSystemResizePending(k8sClient, statefulSet, "sset1-0")
+	require.NoError(t, err)
+	require.False(t, pending)
+	pending, err = fileSystemResizePending(k8sClient, statefulSet, "sset1-1")
+	require.NoError(t, err)
+	require.True(t, pending)
+	// PVC not created yet
+	pending, err = fileSystemResizePending(k8sClient, statefulSet, "sset1-2")
+	require.NoError(t, err)
+	require.False(t, pending)
+}
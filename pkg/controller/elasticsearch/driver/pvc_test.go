@@ -148,3 +148,33 @@ func TestGarbageCollectPVCs(t *testing.T) {
 	require.NoError(t, k8sClient.List(&retrievedPVCs))
 	require.Equal(t, 1, len(retrievedPVCs.Items))
 }
+
+func TestGarbageCollectPVCs_scaledToZero(t *testing.T) {
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec:       esv1.ElasticsearchSpec{NodeSets: []esv1.NodeSet{{Name: "sset1", Count: 0}}},
+	}
+	k8sClient := k8s.WrappedFakeClient(buildPVCPtr("claim1-sset1-0"), buildPVCPtr("claim1-sset1-1"))
+	requirePVCs := func(count int) {
+		var retrievedPVCs corev1.PersistentVolumeClaimList
+		require.NoError(t, k8sClient.List(&retrievedPVCs))
+		require.Equal(t, count, len(retrievedPVCs.Items))
+	}
+
+	// scaled down to zero: PVCs are retained
+	stopped := sset.StatefulSetList{buildSsetWithClaims("sset1", 0, "claim1")}
+	require.NoError(t, GarbageCollectPVCs(k8sClient, es, stopped, stopped))
+	requirePVCs(2)
+
+	// scaled up again: PVCs are reused
+	es.Spec.NodeSets[0].Count = 2
+	restarted := sset.StatefulSetList{buildSsetWithClaims("sset1", 2, "claim1")}
+	require.NoError(t, GarbageCollectPVCs(k8sClient, es, stopped, restarted))
+	requirePVCs(2)
+
+	// scaled up with less nodes: leftover PVCs are removed
+	es.Spec.NodeSets[0].Count = 1
+	downscaled := sset.StatefulSetList{buildSsetWithClaims("sset1", 1, "claim1")}
+	require.NoError(t, GarbageCollectPVCs(k8sClient, es, downscaled, downscaled))
+	requirePVCs(1)
+}
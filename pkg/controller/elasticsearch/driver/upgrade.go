@@ -210,6 +210,7 @@ func podsToUpgrade(
 			// We consider a Pod for an upgrade if at least one of the following conditions is met:
 			// 1. The update revision of the Pod does not match the one in the status of the StatefulSet
 			// 2. The Elasticsearch version run by the Pod does not match the expected one in the Elasticsearch object
+			// 3. One of its volumes was expanded and its file system is resized when the Pod restarts
 			// Relying only on Pod revision is not enough since it might not be propagated consistently across all the StatefulSets.
 			// See https://github.com/elastic/cloud-on-k8s/issues/2393#issuecomment-572951884
 			podVersion, err := label.ExtractVersion(pod.Labels)
@@ -218,6 +219,14 @@ func podsToUpgrade(
 			}
 			if sset.PodRevision(pod) != statefulSet.Status.UpdateRevision || !podVersion.IsSame(*esVersion) {
 				toUpgrade = append(toUpgrade, pod)
+				continue
+			}
+			resizePending, err := fileSystemResizePending(client, statefulSet, podName)
+			if err != nil {
+				return toUpgrade, err
+			}
+			if resizePending {
+				toUpgrade = append(toUpgrade, pod)
 			}
 		}
 	}
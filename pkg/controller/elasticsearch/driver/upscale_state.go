@@ -20,6 +20,8 @@ import (
 type upscaleState struct {
 	isBootstrapped      bool
 	allowMasterCreation bool
+	// indicates the cluster is scaled up from zero nodes, all its master nodes must then be created at once
+	upscaleFromZero bool
 	// indicates how many creates, out of createsAllowed, were already recorded
 	recordedCreates int32
 	// indicates how many creates are allowed when taking into account maxSurge setting,
@@ -35,8 +37,9 @@ func newUpscaleState(
 	expectedResources nodespec.ResourcesList,
 ) *upscaleState {
 	return &upscaleState{
-		once: &sync.Once{},
-		ctx:  ctx,
+		once:            &sync.Once{},
+		ctx:             ctx,
+		upscaleFromZero: len(actualStatefulSets) > 0 && actualStatefulSets.ExpectedNodeCount() == 0,
 		createsAllowed: calculateCreatesAllowed(
 			ctx.es.Spec.UpdateStrategy.ChangeBudget.GetMaxSurgeOrDefault(),
 			actualStatefulSets.ExpectedNodeCount(),
@@ -127,7 +130,8 @@ func isMasterNodeJoining(pod corev1.Pod, esState ESState) (bool, error) {
 
 func (s *upscaleState) recordMasterNodeCreation() {
 	// if the cluster is already formed, don't allow more master nodes to be created
-	if s.isBootstrapped {
+	// unless the stopped cluster is restarted: a single master node could not form a quorum again
+	if s.isBootstrapped && !s.upscaleFromZero {
 		s.allowMasterCreation = false
 	}
 	s.recordNodesCreation(1)
@@ -110,6 +110,14 @@ func Test_upscaleState_limitNodesCreation(t *testing.T) {
 			wantSset:    sset.TestSset{Name: "sset", Replicas: 1, Master: true}.Build(),
 			wantState:   &upscaleState{allowMasterCreation: false, isBootstrapped: true, createsAllowed: pointer.Int32(1), recordedCreates: 1},
 		},
+		{
+			name:        "upscale master nodes from 0 to 3 when a stopped cluster is restarted: should go through",
+			state:       &upscaleState{allowMasterCreation: true, isBootstrapped: true, upscaleFromZero: true},
+			actual:      sset.TestSset{Name: "sset", Replicas: 0, Master: true}.Build(),
+			ssetToApply: sset.TestSset{Name: "sset", Replicas: 3, Master: true}.Build(),
+			wantSset:    sset.TestSset{Name: "sset", Replicas: 3, Master: true}.Build(),
+			wantState:   &upscaleState{allowMasterCreation: true, isBootstrapped: true, upscaleFromZero: true, recordedCreates: 3},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -269,6 +277,14 @@ func Test_newUpscaleState(t *testing.T) {
 			},
 			want: &upscaleState{allowMasterCreation: true, isBootstrapped: true, createsAllowed: nil},
 		},
+		{
+			name: "bootstrapped, scaled up from zero",
+			args: args{
+				ctx:    upscaleCtx{k8sClient: k8s.WrappedFakeClient(), es: bootstrappedES},
+				actual: sset.StatefulSetList{sset.TestSset{Name: "sset", Replicas: 0, Master: true}.Build()},
+			},
+			want: &upscaleState{allowMasterCreation: true, isBootstrapped: true, upscaleFromZero: true, createsAllowed: nil},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
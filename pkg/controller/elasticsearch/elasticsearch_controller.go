@@ -25,8 +25,10 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
 	esreconcile "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/reconcile"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/remotecluster"
 	esversion "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/version"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
 	pkgerrors "github.com/pkg/errors"
 	"go.elastic.co/apm"
 	appsv1 "k8s.io/api/apps/v1"
@@ -35,10 +37,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -50,8 +54,8 @@ var log = logf.Log.WithName(name)
 // Add creates a new Elasticsearch Controller and adds it to the Manager with default RBAC. The Manager will set fields
 // on the Controller and Start it when the Manager is Started.
 // this is also called by cmd/main.go
-func Add(mgr manager.Manager, params operator.Parameters) error {
-	reconciler := newReconciler(mgr, params)
+func Add(mgr manager.Manager, accessReviewer rbac.AccessReviewer, params operator.Parameters) error {
+	reconciler := newReconciler(mgr, accessReviewer, params)
 	c, err := add(mgr, reconciler)
 	if err != nil {
 		return err
@@ -60,16 +64,18 @@ func Add(mgr manager.Manager, params operator.Parameters) error {
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileElasticsearch {
+func newReconciler(mgr manager.Manager, accessReviewer rbac.AccessReviewer, params operator.Parameters) *ReconcileElasticsearch {
 	client := k8s.WrapClient(mgr.GetClient())
 	observerSettings := observer.DefaultSettings
 	observerSettings.Tracer = params.Tracer
 	return &ReconcileElasticsearch{
 		Client:      client,
+		apiReader:   mgr.GetAPIReader(),
 		scheme:      mgr.GetScheme(),
 		recorder:    mgr.GetEventRecorderFor(name),
 		esObservers: observer.NewManager(observerSettings),
 
+		accessReviewer: accessReviewer,
 		dynamicWatches: watches.NewDynamicWatches(),
 		expectations:   expectations.NewClustersExpectations(client),
 
@@ -91,6 +97,36 @@ func addWatches(c controller.Controller, r *ReconcileElasticsearch) error {
 		return err
 	}
 
+	// Watch the remote clusters of Elasticsearch clusters, and the clusters they are a remote cluster of, to update
+	// the seeds and the trusted CAs
+	if err := c.Watch(
+		&source.Kind{Type: &esv1.Elasticsearch{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(
+				func(object handler.MapObject) []reconcile.Request {
+					es, ok := object.Object.(*esv1.Elasticsearch)
+					if !ok {
+						return nil
+					}
+					var requests []reconcile.Request
+					for _, ref := range remotecluster.References(*es) {
+						requests = append(requests, reconcile.Request{NamespacedName: ref})
+					}
+					referencing, err := remotecluster.ReferencingClusters(r.Client, *es)
+					if err != nil {
+						log.Error(err, "Failed to list the clusters referencing a remote cluster", "namespace", es.Namespace, "es_name", es.Name)
+					}
+					for _, other := range referencing {
+						requests = append(requests, reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(&other)})
+					}
+					return requests
+				}),
+		},
+		// status updates do not affect other clusters
+		predicate.GenerationChangedPredicate{},
+	); err != nil {
+		return err
+	}
+
 	// Watch StatefulSets
 	if err := c.Watch(
 		&source.Kind{Type: &appsv1.StatefulSet{}}, &handler.EnqueueRequestForOwner{
@@ -159,11 +195,15 @@ var _ reconcile.Reconciler = &ReconcileElasticsearch{}
 type ReconcileElasticsearch struct {
 	k8s.Client
 	operator.Parameters
-	scheme   *runtime.Scheme
-	recorder record.EventRecorder
+	// apiReader reads from the API server directly, for cluster-scoped resources not cached by namespaced operators.
+	apiReader client.Reader
+	scheme    *runtime.Scheme
+	recorder  record.EventRecorder
 
 	esObservers *observer.Manager
 
+	// accessReviewer checks whether remote clusters in other namespaces can be referenced.
+	accessReviewer rbac.AccessReviewer
 	dynamicWatches watches.DynamicWatches
 
 	// expectations help dealing with inconsistencies in our client cache,
@@ -190,7 +230,7 @@ func (r *ReconcileElasticsearch) Reconcile(request reconcile.Request) (reconcile
 
 	if common.IsPaused(es.ObjectMeta) {
 		log.Info("Object is paused. Skipping reconciliation", "namespace", es.Namespace, "es_name", es.Name)
-		return common.PauseRequeue, nil
+		return common.PauseRequeueFor(es.ObjectMeta), nil
 	}
 
 	selector := map[string]string{label.ClusterNameLabelName: es.Name}
@@ -302,6 +342,7 @@ func (r *ReconcileElasticsearch) internalReconcile(
 		ES:                 es,
 		ReconcileState:     reconcileState,
 		Client:             r.Client,
+		APIReader:          r.apiReader,
 		Scheme:             r.scheme,
 		Recorder:           r.recorder,
 		Version:            *ver,
@@ -309,6 +350,7 @@ func (r *ReconcileElasticsearch) internalReconcile(
 		Observers:          r.esObservers,
 		DynamicWatches:     r.dynamicWatches,
 		SupportedVersions:  *supported,
+		AccessReviewer:     r.accessReviewer,
 	}).Reconcile(ctx)
 }
 
@@ -343,4 +385,5 @@ func (r *ReconcileElasticsearch) onDelete(es types.NamespacedName) {
 	r.esObservers.StopObserving(es)
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(keystore.SecureSettingsWatchName(es))
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(http.CertificateWatchKey(esv1.ESNamer, es.Name))
+	r.dynamicWatches.Secrets.RemoveHandlerForKey(remotecluster.CAWatchName(es))
 }
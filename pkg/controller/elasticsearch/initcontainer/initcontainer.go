@@ -18,6 +18,7 @@ func NewInitContainers(
 	elasticsearchImage string,
 	transportCertificatesVolume volume.SecretVolume,
 	clusterName string,
+	plugins []string,
 	keystoreResources *keystore.Resources,
 ) ([]corev1.Container, error) {
 	var containers []corev1.Container
@@ -27,6 +28,10 @@ func NewInitContainers(
 	}
 	containers = append(containers, prepareFsContainer)
 
+	if len(plugins) > 0 {
+		containers = append(containers, NewInstallPluginsInitContainer(plugins))
+	}
+
 	if keystoreResources != nil {
 		containers = append(containers, keystoreResources.InitContainer)
 	}
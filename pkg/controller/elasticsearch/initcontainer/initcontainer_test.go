@@ -16,6 +16,7 @@ func TestNewInitContainers(t *testing.T) {
 	type args struct {
 		elasticsearchImage string
 		operatorImage      string
+		plugins            []string
 		keystoreResources  *keystore.Resources
 	}
 	tests := []struct {
@@ -32,6 +33,16 @@ func TestNewInitContainers(t *testing.T) {
 			},
 			expectedNumberOfContainers: 2,
 		},
+		{
+			name: "with plugins and keystore resources",
+			args: args{
+				elasticsearchImage: "es-image",
+				operatorImage:      "op-image",
+				plugins:            []string{"repository-s3"},
+				keystoreResources:  &keystore.Resources{},
+			},
+			expectedNumberOfContainers: 3,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -39,6 +50,7 @@ func TestNewInitContainers(t *testing.T) {
 				tt.args.elasticsearchImage,
 				volume.SecretVolume{},
 				"clustername",
+				tt.args.plugins,
 				tt.args.keystoreResources,
 			)
 			assert.NoError(t, err)
@@ -46,3 +58,10 @@ func TestNewInitContainers(t *testing.T) {
 		})
 	}
 }
+
+func TestNewInstallPluginsInitContainer(t *testing.T) {
+	container := NewInstallPluginsInitContainer([]string{"repository-s3", "https://example.com/my plugin.zip"})
+	assert.Equal(t, InstallPluginsContainerName, container.Name)
+	// plugins are passed as arguments of the script
+	assert.Equal(t, []string{"bash", "-c", installPluginsScript, InstallPluginsContainerName, "repository-s3", "https://example.com/my plugin.zip"}, container.Command)
+}
@@ -0,0 +1,63 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package initcontainer
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// InstallPluginsContainerName is the name of the container that installs the plugins specified in the
+	// Elasticsearch resource.
+	InstallPluginsContainerName = "elastic-internal-install-plugins"
+
+	PluginBinPath = "/usr/share/elasticsearch/bin/elasticsearch-plugin"
+
+	// installPluginsScript installs the plugins given as arguments. Plugins already installed are skipped: the plugins
+	// directory is an emptyDir volume, which outlives the init containers if they are restarted.
+	installPluginsScript = `
+set -u
+for plugin in "$@"; do
+	if ! output=$(` + PluginBinPath + ` install --batch "$plugin" 2>&1); then
+		if [[ "$output" == *"already exists"* ]]; then
+			echo "Plugin $plugin already installed"
+			continue
+		fi
+		echo "$output"
+		exit 1
+	fi
+	echo "$output"
+done
+`
+)
+
+// pluginsResources are the default request and limits for the init container. The plugin installation tool runs a JVM.
+var pluginsResources = corev1.ResourceRequirements{
+	Requests: map[corev1.ResourceName]resource.Quantity{
+		corev1.ResourceMemory: resource.MustParse("256Mi"),
+		corev1.ResourceCPU:    resource.MustParse("0.1"),
+	},
+	Limits: map[corev1.ResourceName]resource.Quantity{
+		corev1.ResourceMemory: resource.MustParse("256Mi"),
+	},
+}
+
+// NewInstallPluginsInitContainer creates an init container installing the given plugins in the plugins directory
+// prepared by the prepare-fs init container. It inherits the image and the volume mounts of the Elasticsearch
+// container.
+func NewInstallPluginsInitContainer(plugins []string) corev1.Container {
+	privileged := false
+	return corev1.Container{
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Name:            InstallPluginsContainerName,
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &privileged,
+		},
+		// plugins are passed as arguments of the script rather than rendered into it, so they do not need to be escaped
+		Command:   append([]string{"bash", "-c", installPluginsScript, InstallPluginsContainerName}, plugins...),
+		Resources: pluginsResources,
+	}
+}
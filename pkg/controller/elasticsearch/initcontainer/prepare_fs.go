@@ -6,6 +6,7 @@ package initcontainer
 
 import (
 	"path"
+	"time"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
@@ -13,6 +14,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
 	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/zone"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -20,6 +22,10 @@ import (
 
 const (
 	initContainerTransportCertificatesVolumeMountPath = "/mnt/elastic-internal/transport-certificates"
+
+	// zoneWaitTimeout is how long the init container waits for the operator to set the zone of the pod, before failing
+	// so that the pod is restarted.
+	zoneWaitTimeout = 5 * time.Minute
 )
 
 // Volumes that are shared between the prepare-fs init container and the ES container
@@ -156,5 +162,8 @@ func RenderPrepareFsScript() (string, error) {
 			esvolume.NodeTransportCertificateCertFile,
 		),
 		TransportCertificatesSecretVolumeMountPath: esvolume.TransportCertificatesSecretVolumeMountPath,
+		AnnotationsFilePath:                        path.Join(esvolume.DownwardAPIMountPath, esvolume.AnnotationsFile),
+		ZoneAnnotationName:                         zone.AnnotationName,
+		ZoneWaitTimeoutSeconds:                     int(zoneWaitTimeout.Seconds()),
 	})
 }
@@ -33,6 +33,13 @@ type TemplateParams struct {
 	// TransportCertificatesSecretVolumeMountPath is the path to the volume in the es container that contains the
 	// transport certificates.
 	TransportCertificatesSecretVolumeMountPath string
+
+	// AnnotationsFilePath is the path to the annotations of the pod exposed through the downward API, if any.
+	AnnotationsFilePath string
+	// ZoneAnnotationName is the name of the annotation holding the zone of the pod.
+	ZoneAnnotationName string
+	// ZoneWaitTimeoutSeconds is how long to wait for the zone annotation before failing.
+	ZoneWaitTimeoutSeconds int
 }
 
 // RenderScriptTemplate renders scriptTemplate using the given TemplateParams
@@ -47,6 +54,8 @@ func RenderScriptTemplate(params TemplateParams) (string, error) {
 const (
 	PrepareFsScriptConfigKey  = "prepare-fs.sh"
 	UnsupportedDistroExitCode = 42
+	// ZoneNotSetExitCode is the exit code of the script if the zone annotation is not set in time.
+	ZoneNotSetExitCode = 43
 )
 
 // scriptTemplate is the main script to be run
@@ -125,6 +134,27 @@ var scriptTemplate = template.Must(template.New("").Parse(
 	fi
 	echo "chown duration: $(duration $chown_start) sec."
 
+	######################
+	#  Wait for zone     #
+	######################
+
+	# the annotations of the pod are only exposed if zone awareness is enabled:
+	# wait for the operator to set the zone of the pod, which is passed to Elasticsearch as an env var
+	ANNOTATIONS_FILE={{ .AnnotationsFilePath }}
+	if [[ -f ${ANNOTATIONS_FILE} ]]; then
+		echo "waiting for the zone annotation (${ANNOTATIONS_FILE})"
+		wait_start=$(date +%s)
+		while ! grep -q "^{{ .ZoneAnnotationName }}=" ${ANNOTATIONS_FILE}
+		do
+			if [[ $(duration $wait_start) -ge {{ .ZoneWaitTimeoutSeconds }} ]]; then
+				>&2 echo "zone annotation {{ .ZoneAnnotationName }} not set after {{ .ZoneWaitTimeoutSeconds }} sec.: check that the operator is running and allowed to get nodes and update pods"
+				exit ` + fmt.Sprintf("%d", ZoneNotSetExitCode) + `
+			fi
+			sleep 0.2
+		done
+		echo "wait duration: $(duration $wait_start) sec."
+	fi
+
 	######################
 	#  Wait for certs    #
 	######################
@@ -34,6 +34,19 @@ func TestRenderScriptTemplate(t *testing.T) {
 				"ln -sf /secrets/users /usr/share/elasticsearch/users",
 			},
 		},
+		{
+			name: "Bounded wait for the zone annotation",
+			params: TemplateParams{
+				AnnotationsFilePath:    "/mnt/elastic-internal/downward-api/annotations",
+				ZoneAnnotationName:     "elasticsearch.k8s.elastic.co/zone",
+				ZoneWaitTimeoutSeconds: 300,
+			},
+			wantSubstr: []string{
+				`while ! grep -q "^elasticsearch.k8s.elastic.co/zone=" ${ANNOTATIONS_FILE}`,
+				"if [[ $(duration $wait_start) -ge 300 ]]; then",
+				"exit 43",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package license
+
+import (
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+// ExpiryWarningPeriod is the period before the expiry of a license during which a warning is emitted, the same as the
+// one used by Elasticsearch to log license expiry warnings.
+const ExpiryWarningPeriod = 7 * 24 * time.Hour
+
+// maxExpiryDateInMillis is the latest expiry date that can be represented as a time.Time. Some licenses, such as
+// basic ones, expire later than that.
+const maxExpiryDateInMillis = math.MaxInt64 / int64(time.Millisecond)
+
+// expiryTime returns the expiry time of the given license, or nil if it does not expire.
+func expiryTime(l esclient.License) *time.Time {
+	if l.Type == string(esclient.ElasticsearchLicenseTypeBasic) || l.ExpiryDateInMillis <= 0 ||
+		l.ExpiryDateInMillis > maxExpiryDateInMillis {
+		return nil
+	}
+	t := l.ExpiryTime().UTC()
+	return &t
+}
+
+// Status returns the status of the given license, or nil if the license is unknown.
+func Status(l *esclient.License) *esv1.ElasticsearchLicenseStatus {
+	if l == nil {
+		return nil
+	}
+	status := esv1.ElasticsearchLicenseStatus{Type: l.Type}
+	if t := expiryTime(*l); t != nil {
+		expiry := metav1.NewTime(t.Truncate(time.Second))
+		status.ExpiryTime = &expiry
+	}
+	return &status
+}
+
+// ExpiresSoon returns true if the given license expires within the ExpiryWarningPeriod as of now.
+func ExpiresSoon(l *esclient.License, now time.Time) bool {
+	if l == nil {
+		return false
+	}
+	t := expiryTime(*l)
+	return t != nil && t.Before(now.Add(ExpiryWarningPeriod))
+}
@@ -0,0 +1,97 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package license
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func TestStatus(t *testing.T) {
+	expiry := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiryTime := metav1.NewTime(expiry)
+	tests := []struct {
+		name    string
+		license *esclient.License
+		want    *esv1.ElasticsearchLicenseStatus
+	}{
+		{
+			name:    "license not observed",
+			license: nil,
+			want:    nil,
+		},
+		{
+			name:    "platinum license",
+			license: &esclient.License{Type: "platinum", ExpiryDateInMillis: millis(expiry)},
+			want:    &esv1.ElasticsearchLicenseStatus{Type: "platinum", ExpiryTime: &expiryTime},
+		},
+		{
+			name:    "basic license",
+			license: &esclient.License{Type: "basic"},
+			want:    &esv1.ElasticsearchLicenseStatus{Type: "basic"},
+		},
+		{
+			name:    "license expiring too far in the future",
+			license: &esclient.License{Type: "platinum", ExpiryDateInMillis: math.MaxInt64},
+			want:    &esv1.ElasticsearchLicenseStatus{Type: "platinum"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Status(tt.license))
+		})
+	}
+}
+
+func TestExpiresSoon(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		license *esclient.License
+		want    bool
+	}{
+		{
+			name:    "license not observed",
+			license: nil,
+			want:    false,
+		},
+		{
+			name:    "basic license",
+			license: &esclient.License{Type: "basic"},
+			want:    false,
+		},
+		{
+			name:    "expires after the warning period",
+			license: &esclient.License{Type: "platinum", ExpiryDateInMillis: millis(now.Add(30 * 24 * time.Hour))},
+			want:    false,
+		},
+		{
+			name:    "expires within the warning period",
+			license: &esclient.License{Type: "platinum", ExpiryDateInMillis: millis(now.Add(24 * time.Hour))},
+			want:    true,
+		},
+		{
+			name:    "expired",
+			license: &esclient.License{Type: "gold", ExpiryDateInMillis: millis(now.Add(-time.Hour))},
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ExpiresSoon(tt.license, now))
+		})
+	}
+}
@@ -13,11 +13,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/defaults"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
 	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/zone"
 )
 
 const (
@@ -73,23 +75,28 @@ func DefaultEnvVars(httpCfg commonv1.HTTPConfig, headlessServiceName string) []c
 }
 
 // DefaultAffinity returns the default affinity for pods in a cluster.
-func DefaultAffinity(esName string) *corev1.Affinity {
-	return &corev1.Affinity{
-		// prefer to avoid two pods in the same cluster being co-located on a single node
-		PodAntiAffinity: &corev1.PodAntiAffinity{
-			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
-				{
-					Weight: 100,
-					PodAffinityTerm: corev1.PodAffinityTerm{
-						TopologyKey: "kubernetes.io/hostname",
-						LabelSelector: &metav1.LabelSelector{
-							MatchLabels: map[string]string{
-								label.ClusterNameLabelName: esName,
-							},
-						},
+func DefaultAffinity(es esv1.Elasticsearch) *corev1.Affinity {
+	terms := []corev1.WeightedPodAffinityTerm{
+		{
+			Weight: 100,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				TopologyKey: "kubernetes.io/hostname",
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						label.ClusterNameLabelName: es.Name,
 					},
 				},
 			},
 		},
 	}
+	if es.Spec.ZoneAwareness != nil {
+		// also prefer to spread the pods across zones
+		terms = append(terms, zone.AntiAffinityTerm(es.Name, *es.Spec.ZoneAwareness))
+	}
+	return &corev1.Affinity{
+		// prefer to avoid two pods in the same cluster being co-located on a single node
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: terms,
+		},
+	}
 }
@@ -0,0 +1,32 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package nodespec
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+)
+
+// MaxAutomaticHeapSize is the maximum heap size set by the operator. Above 32GB the JVM cannot use compressed
+// ordinary object pointers anymore, 31GB keeps a safety margin below that threshold.
+var MaxAutomaticHeapSize = resource.MustParse("31Gi")
+
+// heapEnvVar returns the ES_JAVA_OPTS env var sizing the JVM heap to half of the given memory limit, leaving the other
+// half to off-heap structures and the filesystem cache. The heap is capped to MaxAutomaticHeapSize.
+func heapEnvVar(memoryLimit resource.Quantity) corev1.EnvVar {
+	heap := memoryLimit.Value() / 2
+	if heap > MaxAutomaticHeapSize.Value() {
+		heap = MaxAutomaticHeapSize.Value()
+	}
+	heapMB := heap / (1024 * 1024)
+	return corev1.EnvVar{
+		Name:  settings.EnvEsJavaOpts,
+		Value: fmt.Sprintf("-Xms%dm -Xmx%dm", heapMB, heapMB),
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/network"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/zone"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 )
 
@@ -32,7 +33,7 @@ func BuildPodTemplateSpec(
 	cfg settings.CanonicalConfig,
 	keystoreResources *keystore.Resources,
 ) (corev1.PodTemplateSpec, error) {
-	volumes, volumeMounts := buildVolumes(es.Name, nodeSet, keystoreResources)
+	volumes, volumeMounts := buildVolumes(es, nodeSet, keystoreResources)
 	labels, err := buildLabels(es, cfg, nodeSet, keystoreResources)
 	if err != nil {
 		return corev1.PodTemplateSpec{}, err
@@ -41,10 +42,16 @@ func BuildPodTemplateSpec(
 	builder := defaults.NewPodTemplateBuilder(nodeSet.PodTemplate, esv1.ElasticsearchContainerName).
 		WithDockerImage(es.Spec.Image, container.ImageRepository(container.ElasticsearchImage, es.Spec.Version))
 
+	// size the heap from the memory limit specified by the user, unless ES_JAVA_OPTS is also specified
+	if memoryLimit, exists := builder.Container.Resources.Limits[corev1.ResourceMemory]; exists && !memoryLimit.IsZero() {
+		builder = builder.WithEnv(heapEnvVar(memoryLimit))
+	}
+
 	initContainers, err := initcontainer.NewInitContainers(
 		builder.Container.Image,
 		transportCertificatesVolume(es.Name),
 		es.Name,
+		es.Spec.Plugins,
 		keystoreResources,
 	)
 	if err != nil {
@@ -57,7 +64,7 @@ func BuildPodTemplateSpec(
 		WithTerminationGracePeriod(DefaultTerminationGracePeriodSeconds).
 		WithPorts(defaultContainerPorts).
 		WithReadinessProbe(*NewReadinessProbe()).
-		WithAffinity(DefaultAffinity(es.Name)).
+		WithAffinity(DefaultAffinity(es)).
 		WithEnv(DefaultEnvVars(es.Spec.HTTP, HeadlessServiceName(esv1.StatefulSet(es.Name, nodeSet.Name)))...).
 		WithVolumes(volumes...).
 		WithVolumeMounts(volumeMounts...).
@@ -67,6 +74,10 @@ func BuildPodTemplateSpec(
 		WithPreStopHook(*NewPreStopHook()).
 		WithInitContainerDefaults()
 
+	if es.Spec.ZoneAwareness != nil {
+		builder = builder.WithEnv(zone.EnvVar())
+	}
+
 	return builder.PodTemplate, nil
 }
 
@@ -15,11 +15,14 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/initcontainer"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+	esvolume "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/volume"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/zone"
 	"github.com/go-test/deep"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -104,7 +107,7 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 	terminationGracePeriodSeconds := DefaultTerminationGracePeriodSeconds
 	varFalse := false
 
-	volumes, volumeMounts := buildVolumes(sampleES.Name, nodeSet, nil)
+	volumes, volumeMounts := buildVolumes(sampleES, nodeSet, nil)
 	// should be sorted
 	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
 	sort.Slice(volumeMounts, func(i, j int) bool { return volumeMounts[i].Name < volumeMounts[j].Name })
@@ -114,6 +117,7 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 		transportCertificatesVolume(sampleES.Name),
 		sampleES.Name,
 		nil,
+		nil,
 	)
 	require.NoError(t, err)
 	// should be patched with volume and env
@@ -187,7 +191,7 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 			},
 			TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
 			AutomountServiceAccountToken:  &varFalse,
-			Affinity:                      DefaultAffinity(sampleES.Name),
+			Affinity:                      DefaultAffinity(sampleES),
 		},
 	}
 
@@ -195,6 +199,112 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 	require.Nil(t, deep.Equal(expected, actual))
 }
 
+func TestBuildPodTemplateSpec_zoneAwareness(t *testing.T) {
+	es := *sampleES.DeepCopy()
+	es.Spec.ZoneAwareness = &esv1.ZoneAwareness{}
+	nodeSet := es.Spec.NodeSets[0]
+	ver, err := version.Parse(es.Spec.Version)
+	require.NoError(t, err)
+	cfg, err := settings.NewMergedESConfig(es.Name, *ver, es.Spec.HTTP, *nodeSet.Config, &certificates.CertificateResources{})
+	require.NoError(t, err)
+
+	actual, err := BuildPodTemplateSpec(es, nodeSet, cfg, nil)
+	require.NoError(t, err)
+
+	// pods are spread across hosts and zones
+	require.Equal(t, DefaultAffinity(es), actual.Spec.Affinity)
+	require.Len(t, actual.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 2)
+	// the zone is passed to Elasticsearch
+	var esContainer corev1.Container
+	for _, c := range actual.Spec.Containers {
+		if c.Name == esv1.ElasticsearchContainerName {
+			esContainer = c
+		}
+	}
+	require.Contains(t, esContainer.Env, zone.EnvVar())
+	// the annotations of the pod are exposed to the init container
+	found := false
+	for _, v := range actual.Spec.Volumes {
+		if v.Name == esvolume.DownwardAPIVolumeName {
+			found = true
+			require.Contains(t, v.DownwardAPI.Items, corev1.DownwardAPIVolumeFile{
+				Path:     esvolume.AnnotationsFile,
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations"},
+			})
+		}
+	}
+	require.True(t, found)
+}
+
+func TestBuildPodTemplateSpec_heapSize(t *testing.T) {
+	withResources := func(javaOpts string, memoryLimit string) esv1.Elasticsearch {
+		es := *sampleES.DeepCopy()
+		esContainer := &es.Spec.NodeSets[0].PodTemplate.Spec.Containers[1]
+		if javaOpts != "" {
+			esContainer.Env = append(esContainer.Env, corev1.EnvVar{Name: settings.EnvEsJavaOpts, Value: javaOpts})
+		}
+		if memoryLimit != "" {
+			esContainer.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memoryLimit)}
+		}
+		return es
+	}
+	tests := []struct {
+		name         string
+		es           esv1.Elasticsearch
+		wantJavaOpts string
+	}{
+		{
+			name:         "default resources: Elasticsearch default heap",
+			es:           withResources("", ""),
+			wantJavaOpts: "",
+		},
+		{
+			name:         "memory limit: heap is half the limit",
+			es:           withResources("", "4Gi"),
+			wantJavaOpts: "-Xms2048m -Xmx2048m",
+		},
+		{
+			name:         "large memory limit: heap is capped",
+			es:           withResources("", "128Gi"),
+			wantJavaOpts: "-Xms31744m -Xmx31744m",
+		},
+		{
+			name:         "user-provided heap is kept",
+			es:           withResources("-Xms1g -Xmx1g", "4Gi"),
+			wantJavaOpts: "-Xms1g -Xmx1g",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeSet := tt.es.Spec.NodeSets[0]
+			ver, err := version.Parse(tt.es.Spec.Version)
+			require.NoError(t, err)
+			cfg, err := settings.NewMergedESConfig(tt.es.Name, *ver, tt.es.Spec.HTTP, *nodeSet.Config, &certificates.CertificateResources{})
+			require.NoError(t, err)
+
+			actual, err := BuildPodTemplateSpec(tt.es, nodeSet, cfg, nil)
+			require.NoError(t, err)
+
+			var javaOpts []string
+			for _, c := range actual.Spec.Containers {
+				if c.Name != esv1.ElasticsearchContainerName {
+					continue
+				}
+				for _, env := range c.Env {
+					if env.Name == settings.EnvEsJavaOpts {
+						javaOpts = append(javaOpts, env.Value)
+					}
+				}
+			}
+			if tt.wantJavaOpts == "" {
+				require.Empty(t, javaOpts)
+			} else {
+				require.Equal(t, []string{tt.wantJavaOpts}, javaOpts)
+			}
+		})
+	}
+}
+
 func Test_getDefaultContainerPorts(t *testing.T) {
 	tt := []struct {
 		name string
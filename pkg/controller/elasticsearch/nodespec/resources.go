@@ -17,6 +17,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/zone"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 )
 
@@ -61,6 +62,11 @@ func BuildExpectedResources(
 		if err != nil {
 			return nil, err
 		}
+		if es.Spec.ZoneAwareness != nil {
+			if err := cfg.MergeWith(zone.Config()); err != nil {
+				return nil, err
+			}
+		}
 
 		// build stateful set and associated headless service
 		statefulSet, err := BuildStatefulSet(es, nodeSpec, cfg, keystoreResources, existingStatefulSets, scheme)
@@ -16,9 +16,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-var downwardAPIVolume = volume.DownwardAPI{}
-
-func buildVolumes(esName string, nodeSpec esv1.NodeSet, keystoreResources *keystore.Resources) ([]corev1.Volume, []corev1.VolumeMount) {
+func buildVolumes(es esv1.Elasticsearch, nodeSpec esv1.NodeSet, keystoreResources *keystore.Resources) ([]corev1.Volume, []corev1.VolumeMount) {
+	esName := es.Name
+	// the annotations of the pod are exposed for the init container to wait for its zone
+	downwardAPIVolume := volume.DownwardAPI{}.WithAnnotations(es.Spec.ZoneAwareness != nil)
 
 	configVolume := settings.ConfigSecretVolume(esv1.StatefulSet(esName, nodeSpec.Name))
 	probeSecret := volume.NewSelectiveSecretVolumeWithMountPath(
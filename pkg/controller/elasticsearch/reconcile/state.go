@@ -11,6 +11,7 @@ import (
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 )
@@ -39,12 +40,35 @@ func AvailableElasticsearchNodes(pods []corev1.Pod) []corev1.Pod {
 	return nodesAvailable
 }
 
+// nodeSetsStatus returns the status of the node sets of the given cluster, based on the given pods.
+func nodeSetsStatus(es esv1.Elasticsearch, pods []corev1.Pod) []esv1.NodeSetStatus {
+	readyNodes := make(map[string]int32)
+	for _, pod := range AvailableElasticsearchNodes(pods) {
+		readyNodes[pod.Labels[label.StatefulSetNameLabelName]]++
+	}
+	var statuses []esv1.NodeSetStatus
+	for _, nodeSet := range es.Spec.NodeSets {
+		status := esv1.NodeSetStatus{
+			Name:          nodeSet.Name,
+			ExpectedNodes: nodeSet.Count,
+			ReadyNodes:    readyNodes[esv1.StatefulSet(es.Name, nodeSet.Name)],
+		}
+		// the configuration is validated beforehand: roles are omitted if it cannot be parsed
+		if cfg, err := esv1.UnpackConfig(nodeSet.Config); err == nil {
+			status.Roles = cfg.Node.Roles()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
 func (s *State) updateWithPhase(
 	phase esv1.ElasticsearchOrchestrationPhase,
 	resourcesState ResourcesState,
 	observedState observer.State,
 ) *State {
 	s.status.AvailableNodes = int32(len(AvailableElasticsearchNodes(resourcesState.CurrentPods)))
+	s.status.NodeSets = nodeSetsStatus(s.cluster, resourcesState.CurrentPods)
 	s.status.Phase = phase
 
 	s.status.Health = esv1.ElasticsearchUnknownHealth
@@ -62,6 +86,44 @@ func (s *State) UpdateElasticsearchState(
 	return s.updateWithPhase(s.status.Phase, resourcesState, observedState)
 }
 
+// UpdateElasticsearchLicense updates the license section of the resource status, unless the license was not observed.
+func (s *State) UpdateElasticsearchLicense(license *esv1.ElasticsearchLicenseStatus) *State {
+	if license != nil {
+		s.status.License = license
+	}
+	return s
+}
+
+// UpdateElasticsearchRemoteClusters updates the status of the remote cluster connections in the resource status.
+func (s *State) UpdateElasticsearchRemoteClusters(remoteClusters []esv1.RemoteClusterStatus) *State {
+	if len(remoteClusters) == 0 {
+		remoteClusters = nil
+	}
+	s.status.RemoteClusters = remoteClusters
+	return s
+}
+
+// UpdateElasticsearchSnapshotPolicies updates the status of the snapshot lifecycle policies in the resource status.
+func (s *State) UpdateElasticsearchSnapshotPolicies(policies []esv1.SnapshotPolicyStatus) *State {
+	if len(policies) == 0 {
+		policies = nil
+	}
+	s.status.SnapshotPolicies = policies
+	return s
+}
+
+// UpdateElasticsearchExternalURL updates the URL of the load balancer of the cluster in the resource status.
+func (s *State) UpdateElasticsearchExternalURL(url string) *State {
+	s.status.ExternalURL = url
+	return s
+}
+
+// UpdateElasticsearchPlugins updates the plugins installed on the nodes of the cluster in the resource status.
+func (s *State) UpdateElasticsearchPlugins(plugins []string) *State {
+	s.status.Plugins = plugins
+	return s
+}
+
 // UpdateElasticsearchReady marks Elasticsearch as being ready in the resource status.
 func (s *State) UpdateElasticsearchReady(
 	resourcesState ResourcesState,
@@ -78,6 +140,7 @@ func (s *State) IsElasticsearchReady(observedState observer.State) bool {
 // UpdateElasticsearchApplyingChanges marks Elasticsearch as being the applying changes phase in the resource status.
 func (s *State) UpdateElasticsearchApplyingChanges(pods []corev1.Pod) *State {
 	s.status.AvailableNodes = int32(len(AvailableElasticsearchNodes(pods)))
+	s.status.NodeSets = nodeSetsStatus(s.cluster, pods)
 	s.status.Phase = esv1.ElasticsearchApplyingChangesPhase
 	s.status.Health = esv1.ElasticsearchRedHealth
 	return s
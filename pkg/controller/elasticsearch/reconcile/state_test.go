@@ -11,12 +11,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/observer"
 )
 
@@ -222,6 +224,20 @@ func TestState_Apply(t *testing.T) {
 	}
 }
 
+func nodeSetPod(statefulSetName string, ready bool) corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{label.StatefulSetNameLabelName: statefulSetName}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: status},
+			{Type: corev1.ContainersReady, Status: status},
+		}},
+	}
+}
+
 func TestState_UpdateElasticsearchState(t *testing.T) {
 	type args struct {
 		resourcesState ResourcesState
@@ -264,6 +280,50 @@ func TestState_UpdateElasticsearchState(t *testing.T) {
 
 			},
 		},
+		{
+			name: "node sets readiness is reported",
+			cluster: esv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "es"},
+				Spec: esv1.ElasticsearchSpec{NodeSets: []esv1.NodeSet{
+					{
+						Name:  "master",
+						Count: 3,
+						Config: &commonv1.Config{Data: map[string]interface{}{
+							esv1.NodeMaster: true, esv1.NodeData: false, esv1.NodeIngest: false, esv1.NodeML: false,
+						}},
+					},
+					{
+						Name:  "data",
+						Count: 2,
+						Config: &commonv1.Config{Data: map[string]interface{}{
+							esv1.NodeMaster: false, esv1.NodeML: false,
+						}},
+					},
+					{
+						Name:  "coordinating",
+						Count: 1,
+						Config: &commonv1.Config{Data: map[string]interface{}{
+							esv1.NodeMaster: false, esv1.NodeData: false, esv1.NodeIngest: false, esv1.NodeML: false,
+						}},
+					},
+				}},
+			},
+			args: args{
+				resourcesState: ResourcesState{CurrentPods: []corev1.Pod{
+					nodeSetPod("es-es-master", true),
+					nodeSetPod("es-es-master", true),
+					nodeSetPod("es-es-master", false),
+					nodeSetPod("es-es-data", true),
+				}},
+			},
+			stateAssertions: func(s *State) {
+				assert.Equal(t, []esv1.NodeSetStatus{
+					{Name: "master", Roles: []string{"master"}, ExpectedNodes: 3, ReadyNodes: 2},
+					{Name: "data", Roles: []string{"data", "ingest"}, ExpectedNodes: 2, ReadyNodes: 1},
+					{Name: "coordinating", ExpectedNodes: 1, ReadyNodes: 0},
+				}, s.status.NodeSets)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -310,3 +370,16 @@ func TestState_UpdateElasticsearchMigrating(t *testing.T) {
 		})
 	}
 }
+
+func TestState_UpdateElasticsearchLicense(t *testing.T) {
+	previous := &esv1.ElasticsearchLicenseStatus{Type: "basic"}
+	s := NewState(esv1.Elasticsearch{Status: esv1.ElasticsearchStatus{License: previous}})
+
+	// the license was not observed: keep the previous one
+	s.UpdateElasticsearchLicense(nil)
+	assert.Equal(t, previous, s.status.License)
+
+	platinum := &esv1.ElasticsearchLicenseStatus{Type: "platinum"}
+	s.UpdateElasticsearchLicense(platinum)
+	assert.Equal(t, platinum, s.status.License)
+}
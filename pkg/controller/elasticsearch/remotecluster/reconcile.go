@@ -0,0 +1,145 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remotecluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/network"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/nodespec"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
+)
+
+var log = logf.Log.WithName("elasticsearch-controller")
+
+// Reconcile configures the seeds of the remote clusters of the given cluster in its persistent cluster settings, and
+// removes the remote clusters previously configured by the operator that are not specified anymore. Remote clusters
+// configured through the Elasticsearch API are left untouched.
+// It returns the status of the connections to the remote clusters, including the ones that could not be configured.
+func Reconcile(
+	ctx context.Context,
+	c k8s.Client,
+	accessReviewer rbac.AccessReviewer,
+	es esv1.Elasticsearch,
+	esClient esclient.RemoteClusterClient,
+) ([]esv1.RemoteClusterStatus, error) {
+	statuses := make([]esv1.RemoteClusterStatus, 0, len(es.Spec.RemoteClusters))
+	expected := make(map[string][]string, len(es.Spec.RemoteClusters))
+	for _, remote := range es.Spec.RemoteClusters {
+		status := esv1.RemoteClusterStatus{Name: remote.Name}
+		seeds, err := expectedSeeds(c, accessReviewer, es, remote)
+		if err != nil {
+			return nil, err
+		}
+		switch ref := remote.ElasticsearchRefOrDefault(es.Namespace); {
+		case len(seeds) > 0:
+			expected[remote.Name] = seeds
+		case ref != nil:
+			status.Error = fmt.Sprintf("Elasticsearch cluster %s not found or not allowed", ref.NamespacedName())
+		default:
+			status.Error = "No seeds specified"
+		}
+		statuses = append(statuses, status)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, esclient.DefaultReqTimeout)
+	defer cancel()
+	actual, err := esClient.GetRemoteClusters(reqCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]esclient.RemoteCluster)
+	for name, seeds := range expected {
+		if info, exists := actual[name]; !exists || !sameSeeds(info.Seeds, seeds) {
+			changes[name] = esclient.RemoteCluster{Seeds: seeds}
+		}
+	}
+	for _, previous := range es.Status.RemoteClusters {
+		if _, stillExpected := expected[previous.Name]; stillExpected {
+			continue
+		}
+		if _, exists := actual[previous.Name]; exists {
+			// null seeds remove the remote cluster
+			changes[previous.Name] = esclient.RemoteCluster{Seeds: nil}
+		}
+	}
+	if len(changes) > 0 {
+		log.Info("Updating remote clusters", "namespace", es.Namespace, "es_name", es.Name, "remote_clusters", changes)
+		reqCtx, cancel := context.WithTimeout(ctx, esclient.DefaultReqTimeout)
+		defer cancel()
+		if err := esClient.UpdateSettings(reqCtx, esclient.Settings{
+			PersistentSettings: &esclient.SettingsGroup{Cluster: esclient.Cluster{RemoteClusters: changes}},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range statuses {
+		if _, changed := changes[statuses[i].Name]; changed {
+			// connecting, reported on the next reconciliation
+			continue
+		}
+		if info, exists := actual[statuses[i].Name]; exists {
+			statuses[i].Connected = info.Connected
+			statuses[i].NodesConnected = info.NumNodesConnected
+		}
+	}
+	return statuses, nil
+}
+
+// expectedSeeds returns the seeds of the given remote cluster: the headless services of the master nodes of the
+// remote cluster if it is managed by the operator, the specified seeds otherwise. No seeds are returned if the remote
+// cluster is not found or not allowed.
+func expectedSeeds(c k8s.Client, accessReviewer rbac.AccessReviewer, es esv1.Elasticsearch, remote esv1.RemoteCluster) ([]string, error) {
+	ref := remote.ElasticsearchRefOrDefault(es.Namespace)
+	if ref == nil {
+		return remote.Seeds, nil
+	}
+	var remoteES esv1.Elasticsearch
+	if err := c.Get(ref.NamespacedName(), &remoteES); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	allowed, err := accessReviewer.AccessAllowed(es.Spec.ServiceAccountName, es.Namespace, &remoteES)
+	if err != nil || !allowed {
+		return nil, err
+	}
+	return Seeds(remoteES), nil
+}
+
+// Seeds returns the transport addresses of the headless services of the master node sets of the given cluster.
+func Seeds(es esv1.Elasticsearch) []string {
+	var seeds []string
+	for _, nodeSet := range es.Spec.NodeSets {
+		cfg, err := esv1.UnpackConfig(nodeSet.Config)
+		if err != nil || !cfg.Node.Master || nodeSet.Count == 0 {
+			continue
+		}
+		svc := nodespec.HeadlessServiceName(esv1.StatefulSet(es.Name, nodeSet.Name))
+		seeds = append(seeds, fmt.Sprintf("%s.%s.svc:%d", svc, es.Namespace, network.TransportPort))
+	}
+	return seeds
+}
+
+// sameSeeds returns true if both lists hold the same seeds, regardless of their order.
+func sameSeeds(actual, expected []string) bool {
+	a := append([]string{}, actual...)
+	e := append([]string{}, expected...)
+	sort.Strings(a)
+	sort.Strings(e)
+	return reflect.DeepEqual(a, e)
+}
@@ -0,0 +1,107 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remotecluster
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
+)
+
+// mockClient returns a client returning the given remote info, and recording the cluster settings updates.
+func mockClient(remoteInfo string, updates *[]string) esclient.Client {
+	return esclient.NewMockClient(version.MustParse("7.5.0"), func(req *http.Request) *http.Response {
+		if req.Method == http.MethodPut && req.URL.Path == "/_cluster/settings" {
+			body, _ := ioutil.ReadAll(req.Body)
+			*updates = append(*updates, string(body))
+			return esclient.NewMockResponse(200, req, `{"acknowledged":true}`)
+		}
+		return esclient.NewMockResponse(200, req, remoteInfo)
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	eastCluster := &esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "east"},
+		Spec: esv1.ElasticsearchSpec{NodeSets: []esv1.NodeSet{
+			{Name: "masters", Count: 3},
+			{Name: "data", Count: 3, Config: &commonv1.Config{Data: map[string]interface{}{"node.master": false}}},
+		}},
+	}
+	east := esv1.RemoteCluster{Name: "east", ElasticsearchRef: &commonv1.ObjectSelector{Name: "east"}}
+	external := esv1.RemoteCluster{Name: "external", Seeds: []string{"external:9300"}, CASecretName: "external-ca"}
+	es := func(status []esv1.RemoteClusterStatus, remoteClusters ...esv1.RemoteCluster) esv1.Elasticsearch {
+		return esv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+			Spec:       esv1.ElasticsearchSpec{RemoteClusters: remoteClusters},
+			Status:     esv1.ElasticsearchStatus{RemoteClusters: status},
+		}
+	}
+	tests := []struct {
+		name         string
+		es           esv1.Elasticsearch
+		objs         []runtime.Object
+		remoteInfo   string
+		wantStatuses []esv1.RemoteClusterStatus
+		wantUpdates  []string
+	}{
+		{
+			name:         "configure remote clusters",
+			es:           es(nil, east, external),
+			objs:         []runtime.Object{eastCluster},
+			remoteInfo:   `{}`,
+			wantStatuses: []esv1.RemoteClusterStatus{{Name: "east"}, {Name: "external"}},
+			wantUpdates: []string{
+				`{"persistent":{"cluster":{"remote":{"east":{"seeds":["east-es-masters.ns.svc:9300"]},"external":{"seeds":["external:9300"]}}}}}`,
+			},
+		},
+		{
+			name:       "remote clusters already configured",
+			es:         es([]esv1.RemoteClusterStatus{{Name: "east"}, {Name: "external"}}, east, external),
+			objs:       []runtime.Object{eastCluster},
+			remoteInfo: `{"east":{"seeds":["east-es-masters.ns.svc:9300"],"connected":true,"num_nodes_connected":3},"external":{"seeds":["external:9300"],"connected":false}}`,
+			wantStatuses: []esv1.RemoteClusterStatus{
+				{Name: "east", Connected: true, NodesConnected: 3},
+				{Name: "external"},
+			},
+		},
+		{
+			name:         "remove remote clusters not specified anymore",
+			es:           es([]esv1.RemoteClusterStatus{{Name: "east"}, {Name: "external"}}, east),
+			objs:         []runtime.Object{eastCluster},
+			remoteInfo:   `{"east":{"seeds":["east-es-masters.ns.svc:9300"],"connected":true,"num_nodes_connected":3},"external":{"seeds":["external:9300"]},"manual":{"seeds":["manual:9300"]}}`,
+			wantStatuses: []esv1.RemoteClusterStatus{{Name: "east", Connected: true, NodesConnected: 3}},
+			wantUpdates:  []string{`{"persistent":{"cluster":{"remote":{"external":{"seeds":null}}}}}`},
+		},
+		{
+			name:         "referenced cluster not found",
+			es:           es(nil, east),
+			remoteInfo:   `{}`,
+			wantStatuses: []esv1.RemoteClusterStatus{{Name: "east", Error: "Elasticsearch cluster ns/east not found or not allowed"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updates []string
+			c := k8s.WrappedFakeClient(tt.objs...)
+			statuses, err := Reconcile(context.Background(), c, rbac.NewPermissiveAccessReviewer(), tt.es, mockClient(tt.remoteInfo, &updates))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatuses, statuses)
+			require.Equal(t, tt.wantUpdates, updates)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remotecluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/certificates/transport"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
+)
+
+// TrustedReferencingClustersAnnotation lists, on an Elasticsearch resource, the clusters referencing it as a remote
+// cluster whose transport CA it trusts, as comma-separated <namespace>/<name> names.
+const TrustedReferencingClustersAnnotation = "elasticsearch.k8s.elastic.co/trusted-referencing-clusters"
+
+// CAWatchName returns the name of the watch on the secrets holding the CA certificates trusted by the given cluster
+// for its remote cluster connections.
+func CAWatchName(es types.NamespacedName) string {
+	return fmt.Sprintf("%s-%s-remote-cluster-ca", es.Namespace, es.Name)
+}
+
+// WatchCASecrets registers a watch for the given secrets holding the CA certificates trusted by the given cluster, or
+// removes it if there are none.
+func WatchCASecrets(watched watches.DynamicWatches, es types.NamespacedName, secrets []types.NamespacedName) error {
+	watchName := CAWatchName(es)
+	if len(secrets) == 0 {
+		watched.Secrets.RemoveHandlerForKey(watchName)
+		return nil
+	}
+	return watched.Secrets.AddHandler(watches.NamedWatch{
+		Name:    watchName,
+		Watched: secrets,
+		Watcher: es,
+	})
+}
+
+// TrustedCAs returns the PEM-encoded CA certificates of the transport layer of the clusters the given cluster must
+// trust: its remote clusters, and the clusters managed by the operator that have it as a remote cluster and are
+// trusted by it (see trustsReferencingCluster). References not allowed by the access reviewer are ignored. The secrets
+// the certificates are read from are returned as well, whether they exist or not, so they can be watched.
+func TrustedCAs(c k8s.Client, accessReviewer rbac.AccessReviewer, es esv1.Elasticsearch) ([]byte, []types.NamespacedName, error) {
+	var secrets []types.NamespacedName
+	for _, remote := range es.Spec.RemoteClusters {
+		ref := remote.ElasticsearchRefOrDefault(es.Namespace)
+		if ref == nil {
+			secrets = append(secrets, types.NamespacedName{Namespace: es.Namespace, Name: remote.CASecretName})
+			continue
+		}
+		var remoteES esv1.Elasticsearch
+		if err := c.Get(ref.NamespacedName(), &remoteES); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		allowed, err := accessReviewer.AccessAllowed(es.Spec.ServiceAccountName, es.Namespace, &remoteES)
+		if err != nil {
+			return nil, nil, err
+		}
+		if allowed {
+			secrets = append(secrets, transport.PublicCertsSecretRef(ref.NamespacedName()))
+		}
+	}
+
+	// the clusters connecting to this cluster must be trusted as well, transport connections being mutually authenticated
+	referencing, err := ReferencingClusters(c, es)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, other := range referencing {
+		trusted, err := trustsReferencingCluster(accessReviewer, es, other)
+		if err != nil {
+			return nil, nil, err
+		}
+		if trusted {
+			secrets = append(secrets, transport.PublicCertsSecretRef(k8s.ExtractNamespacedName(&other)))
+		}
+	}
+
+	secrets = unique(secrets)
+	var cas []byte
+	for _, secretRef := range secrets {
+		var secret corev1.Secret
+		if err := c.Get(secretRef, &secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				// trusted once it exists
+				continue
+			}
+			return nil, nil, err
+		}
+		ca := secret.Data[certificates.CAFileName]
+		if len(ca) > 0 && ca[len(ca)-1] != '\n' {
+			ca = append(ca, '\n')
+		}
+		cas = append(cas, ca...)
+	}
+	return cas, secrets, nil
+}
+
+// trustsReferencingCluster returns true if the given cluster trusts the CA of the other given cluster, which has it as
+// a remote cluster. Anyone allowed to create an Elasticsearch resource can reference any cluster, being referenced is
+// not enough to be trusted: the referencing cluster must be listed in the TrustedReferencingClustersAnnotation of the
+// cluster, or be allowed to access it while RBAC is enforced on references.
+func trustsReferencingCluster(accessReviewer rbac.AccessReviewer, es esv1.Elasticsearch, other esv1.Elasticsearch) (bool, error) {
+	otherKey := k8s.ExtractNamespacedName(&other)
+	for _, trusted := range strings.Split(es.Annotations[TrustedReferencingClustersAnnotation], ",") {
+		if strings.TrimSpace(trusted) == otherKey.String() {
+			return true, nil
+		}
+	}
+	if rbac.IsPermissive(accessReviewer) {
+		return false, nil
+	}
+	return accessReviewer.AccessAllowed(other.Spec.ServiceAccountName, other.Namespace, &es)
+}
+
+// ReferencingClusters returns the clusters managed by the operator that have the given cluster as a remote cluster.
+func ReferencingClusters(c k8s.Client, es esv1.Elasticsearch) ([]esv1.Elasticsearch, error) {
+	var clusters esv1.ElasticsearchList
+	if err := c.List(&clusters); err != nil {
+		return nil, err
+	}
+	var referencing []esv1.Elasticsearch
+	for _, other := range clusters.Items {
+		for _, ref := range References(other) {
+			if ref == k8s.ExtractNamespacedName(&es) {
+				referencing = append(referencing, other)
+				break
+			}
+		}
+	}
+	return referencing, nil
+}
+
+// References returns the clusters managed by the operator that are remote clusters of the given cluster.
+func References(es esv1.Elasticsearch) []types.NamespacedName {
+	var refs []types.NamespacedName
+	for _, remote := range es.Spec.RemoteClusters {
+		if ref := remote.ElasticsearchRefOrDefault(es.Namespace); ref != nil {
+			refs = append(refs, ref.NamespacedName())
+		}
+	}
+	return refs
+}
+
+// unique returns the given secrets sorted and without duplicates, for the trusted CAs to be stable.
+func unique(secrets []types.NamespacedName) []types.NamespacedName {
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].String() < secrets[j].String()
+	})
+	var result []types.NamespacedName
+	for i, s := range secrets {
+		if i > 0 && s == secrets[i-1] {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
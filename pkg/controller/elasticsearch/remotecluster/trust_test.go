@@ -0,0 +1,176 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remotecluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/certificates/transport"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
+)
+
+// denyOtherNamespaces is an access reviewer denying references across namespaces.
+type denyOtherNamespaces struct{}
+
+func (denyOtherNamespaces) AccessAllowed(_ string, sourceNamespace string, object runtime.Object) (bool, error) {
+	return object.(*esv1.Elasticsearch).Namespace == sourceNamespace, nil
+}
+
+func cluster(namespace, name string, remoteClusters ...esv1.RemoteCluster) *esv1.Elasticsearch {
+	return &esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       esv1.ElasticsearchSpec{RemoteClusters: remoteClusters},
+	}
+}
+
+func caSecret(nsn types.NamespacedName, ca string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: nsn.Namespace, Name: nsn.Name},
+		Data:       map[string][]byte{certificates.CAFileName: []byte(ca)},
+	}
+}
+
+func publicCA(namespace, name, ca string) *corev1.Secret {
+	return caSecret(transport.PublicCertsSecretRef(types.NamespacedName{Namespace: namespace, Name: name}), ca)
+}
+
+func TestTrustedCAs(t *testing.T) {
+	east := esv1.RemoteCluster{Name: "east", ElasticsearchRef: &commonv1.ObjectSelector{Name: "east"}}
+	west := esv1.RemoteCluster{Name: "west", ElasticsearchRef: &commonv1.ObjectSelector{Name: "west", Namespace: "other"}}
+	external := esv1.RemoteCluster{Name: "external", Seeds: []string{"external:9300"}, CASecretName: "external-ca"}
+	tests := []struct {
+		name           string
+		es             *esv1.Elasticsearch
+		objs           []runtime.Object
+		accessReviewer rbac.AccessReviewer
+		wantCAs        string
+		wantSecrets    []types.NamespacedName
+	}{
+		{
+			name:           "no remote clusters",
+			es:             cluster("ns", "es"),
+			accessReviewer: rbac.NewPermissiveAccessReviewer(),
+		},
+		{
+			name: "managed and external remote clusters",
+			es:   cluster("ns", "es", east, external),
+			objs: []runtime.Object{
+				cluster("ns", "east"),
+				publicCA("ns", "east", "east-ca\n"),
+				caSecret(types.NamespacedName{Namespace: "ns", Name: "external-ca"}, "external-ca"),
+			},
+			accessReviewer: rbac.NewPermissiveAccessReviewer(),
+			wantCAs:        "east-ca\nexternal-ca\n",
+			wantSecrets: []types.NamespacedName{
+				transport.PublicCertsSecretRef(types.NamespacedName{Namespace: "ns", Name: "east"}),
+				{Namespace: "ns", Name: "external-ca"},
+			},
+		},
+		{
+			name: "unsolicited clusters having this cluster as remote cluster are not trusted",
+			es:   cluster("ns", "east"),
+			objs: []runtime.Object{
+				cluster("ns", "es", east),
+				cluster("other", "es", esv1.RemoteCluster{Name: "east", ElasticsearchRef: &commonv1.ObjectSelector{Name: "east", Namespace: "ns"}}),
+				publicCA("ns", "es", "es-ca\n"),
+				publicCA("other", "es", "other-es-ca\n"),
+			},
+			accessReviewer: rbac.NewPermissiveAccessReviewer(),
+		},
+		{
+			name: "clusters having this cluster as remote cluster trusted through the annotation",
+			es: func() *esv1.Elasticsearch {
+				es := cluster("ns", "east")
+				es.Annotations = map[string]string{TrustedReferencingClustersAnnotation: "other/es, ns/unrelated"}
+				return es
+			}(),
+			objs: []runtime.Object{
+				cluster("ns", "es", east),
+				cluster("other", "es", esv1.RemoteCluster{Name: "east", ElasticsearchRef: &commonv1.ObjectSelector{Name: "east", Namespace: "ns"}}),
+				cluster("ns", "unrelated", west),
+				publicCA("ns", "es", "es-ca\n"),
+				publicCA("other", "es", "other-es-ca\n"),
+				publicCA("ns", "unrelated", "unrelated-ca\n"),
+			},
+			accessReviewer: rbac.NewPermissiveAccessReviewer(),
+			wantCAs:        "other-es-ca\n",
+			wantSecrets: []types.NamespacedName{
+				transport.PublicCertsSecretRef(types.NamespacedName{Namespace: "other", Name: "es"}),
+			},
+		},
+		{
+			name: "clusters having this cluster as remote cluster allowed by RBAC",
+			es:   cluster("ns", "east"),
+			objs: []runtime.Object{
+				cluster("ns", "es", east),
+				cluster("ns", "unrelated", west),
+				publicCA("ns", "es", "es-ca\n"),
+				publicCA("ns", "unrelated", "unrelated-ca\n"),
+			},
+			accessReviewer: denyOtherNamespaces{},
+			wantCAs:        "es-ca\n",
+			wantSecrets: []types.NamespacedName{
+				transport.PublicCertsSecretRef(types.NamespacedName{Namespace: "ns", Name: "es"}),
+			},
+		},
+		{
+			name: "clusters having this cluster as remote cluster denied by RBAC",
+			es:   cluster("ns", "east"),
+			objs: []runtime.Object{
+				cluster("other", "es", esv1.RemoteCluster{Name: "east", ElasticsearchRef: &commonv1.ObjectSelector{Name: "east", Namespace: "ns"}}),
+				publicCA("other", "es", "other-es-ca\n"),
+			},
+			accessReviewer: denyOtherNamespaces{},
+		},
+		{
+			name: "references to other namespaces not allowed",
+			es:   cluster("ns", "es", east, west),
+			objs: []runtime.Object{
+				cluster("ns", "east"),
+				cluster("other", "west"),
+				cluster("other", "es", esv1.RemoteCluster{Name: "es", ElasticsearchRef: &commonv1.ObjectSelector{Name: "es", Namespace: "ns"}}),
+				publicCA("ns", "east", "east-ca\n"),
+				publicCA("other", "west", "west-ca\n"),
+				publicCA("other", "es", "other-es-ca\n"),
+			},
+			accessReviewer: denyOtherNamespaces{},
+			wantCAs:        "east-ca\n",
+			wantSecrets: []types.NamespacedName{
+				transport.PublicCertsSecretRef(types.NamespacedName{Namespace: "ns", Name: "east"}),
+			},
+		},
+		{
+			name: "remote cluster and CA not created yet",
+			es:   cluster("ns", "es", east, external),
+			objs: []runtime.Object{
+				cluster("ns", "east"),
+			},
+			accessReviewer: rbac.NewPermissiveAccessReviewer(),
+			wantSecrets: []types.NamespacedName{
+				transport.PublicCertsSecretRef(types.NamespacedName{Namespace: "ns", Name: "east"}),
+				{Namespace: "ns", Name: "external-ca"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrappedFakeClient(append(tt.objs, tt.es)...)
+			cas, secrets, err := TrustedCAs(c, tt.accessReviewer, *tt.es)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCAs, string(cas))
+			require.Equal(t, tt.wantSecrets, secrets)
+		})
+	}
+}
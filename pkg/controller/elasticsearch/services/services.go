@@ -35,6 +35,25 @@ func ExternalServiceURL(es esv1.Elasticsearch) string {
 	return stringsutil.Concat(es.Spec.HTTP.Protocol(), "://", ExternalServiceName(es.Name), ".", es.Namespace, globalServiceSuffix, ":", strconv.Itoa(network.HTTPPort))
 }
 
+// LoadBalancerURL returns the URL used to reach Elasticsearch through the load balancer provisioned for the given
+// external service. It returns an empty string if the service is not of type LoadBalancer, or if the load balancer
+// is not provisioned yet.
+func LoadBalancerURL(es esv1.Elasticsearch, svc corev1.Service) string {
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return ""
+	}
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		address := ingress.Hostname
+		if address == "" {
+			address = ingress.IP
+		}
+		if address != "" {
+			return stringsutil.Concat(es.Spec.HTTP.Protocol(), "://", address, ":", strconv.Itoa(network.HTTPPort))
+		}
+	}
+	return ""
+}
+
 // NewExternalService returns the external service associated to the given cluster
 // It is used by users to perform requests against one of the cluster nodes.
 func NewExternalService(es esv1.Elasticsearch) *corev1.Service {
@@ -56,6 +56,47 @@ func TestExternalServiceURL(t *testing.T) {
 	}
 }
 
+func TestLoadBalancerURL(t *testing.T) {
+	es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	loadBalancer := func(ingress ...corev1.LoadBalancerIngress) corev1.Service {
+		return corev1.Service{
+			Spec:   corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress}},
+		}
+	}
+	tests := []struct {
+		name string
+		svc  corev1.Service
+		want string
+	}{
+		{
+			name: "ClusterIP service",
+			svc:  corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}},
+			want: "",
+		},
+		{
+			name: "load balancer not provisioned yet",
+			svc:  loadBalancer(),
+			want: "",
+		},
+		{
+			name: "load balancer with an IP",
+			svc:  loadBalancer(corev1.LoadBalancerIngress{IP: "10.0.0.1"}),
+			want: "https://10.0.0.1:9200",
+		},
+		{
+			name: "load balancer with a hostname",
+			svc:  loadBalancer(corev1.LoadBalancerIngress{IP: "10.0.0.1", Hostname: "es.example.com"}),
+			want: "https://es.example.com:9200",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LoadBalancerURL(es, tt.svc))
+		})
+	}
+}
+
 func TestElasticsearchURL(t *testing.T) {
 	type args struct {
 		es   esv1.Elasticsearch
@@ -17,4 +17,8 @@ const (
 	// to be referenced in ES configuration file
 	EnvPodName = "POD_NAME"
 	EnvPodIP   = "POD_IP"
+
+	// EnvZone is injected as env var into the ES container if zone awareness is enabled, to be referenced in the ES
+	// configuration file
+	EnvZone = "ZONE"
 )
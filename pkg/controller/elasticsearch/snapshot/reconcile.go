@@ -0,0 +1,148 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	pkgerrors "github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+var log = logf.Log.WithName("elasticsearch-controller")
+
+// Reconcile registers the snapshot repositories and applies the snapshot lifecycle policies specified for the given
+// Elasticsearch cluster. Repositories and policies are only updated if they differ from the specified ones, to not
+// verify repositories or bump the version of policies on every reconciliation.
+// It returns the status of the policies, including the ones that could not be applied.
+func Reconcile(ctx context.Context, es esv1.Elasticsearch, esClient esclient.SnapshotClient) ([]esv1.SnapshotPolicyStatus, error) {
+	if es.Spec.Snapshots == nil {
+		return nil, nil
+	}
+	for _, repository := range es.Spec.Snapshots.Repositories {
+		if err := reconcileRepository(ctx, es, esClient, repository); err != nil {
+			return nil, pkgerrors.Wrapf(err, "failed to register snapshot repository %s", repository.Name)
+		}
+	}
+	var errs []error
+	statuses := make([]esv1.SnapshotPolicyStatus, 0, len(es.Spec.Snapshots.Policies))
+	for _, policy := range es.Spec.Snapshots.Policies {
+		status, err := reconcilePolicy(ctx, es, esClient, policy)
+		if err != nil {
+			err = pkgerrors.Wrapf(err, "failed to apply snapshot lifecycle policy %s", policy.Name)
+			status.Error = err.Error()
+			errs = append(errs, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, utilerrors.NewAggregate(errs)
+}
+
+func reconcileRepository(ctx context.Context, es esv1.Elasticsearch, esClient esclient.SnapshotClient, repository esv1.SnapshotRepository) error {
+	expected := esclient.SnapshotRepository{Type: repository.Type, Settings: configData(repository.Settings)}
+	reqCtx, cancel := context.WithTimeout(ctx, esclient.DefaultReqTimeout)
+	defer cancel()
+	actual, err := esClient.GetSnapshotRepository(reqCtx, repository.Name)
+	if err != nil && !esclient.IsNotFound(err) {
+		return err
+	}
+	if err == nil && actual.Type == expected.Type && equivalent(actual.Settings, expected.Settings) {
+		return nil
+	}
+	log.Info("Registering snapshot repository",
+		"namespace", es.Namespace, "es_name", es.Name, "repository", repository.Name, "type", repository.Type)
+	reqCtx, cancel = context.WithTimeout(ctx, esclient.DefaultReqTimeout)
+	defer cancel()
+	return esClient.UpsertSnapshotRepository(reqCtx, repository.Name, expected)
+}
+
+func reconcilePolicy(ctx context.Context, es esv1.Elasticsearch, esClient esclient.SnapshotClient, policy esv1.SnapshotPolicy) (esv1.SnapshotPolicyStatus, error) {
+	status := esv1.SnapshotPolicyStatus{Name: policy.Name}
+	expected := esclient.SnapshotLifecyclePolicyDefinition{
+		Name:       policy.SnapshotNameOrDefault(),
+		Schedule:   policy.Schedule,
+		Repository: policy.Repository,
+		Config:     configData(policy.Config),
+		Retention:  configData(policy.Retention),
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, esclient.DefaultReqTimeout)
+	defer cancel()
+	actual, err := esClient.GetSnapshotLifecyclePolicy(reqCtx, policy.Name)
+	if err != nil && !esclient.IsNotFound(err) {
+		return status, err
+	}
+	if err == nil {
+		// the last invocations are preserved when the policy is updated
+		status = policyStatus(policy.Name, actual)
+		if equivalentPolicies(actual.Policy, expected) {
+			return status, nil
+		}
+	}
+	log.Info("Applying snapshot lifecycle policy",
+		"namespace", es.Namespace, "es_name", es.Name, "policy", policy.Name)
+	reqCtx, cancel = context.WithTimeout(ctx, esclient.DefaultReqTimeout)
+	defer cancel()
+	return status, esClient.UpsertSnapshotLifecyclePolicy(reqCtx, policy.Name, expected)
+}
+
+// policyStatus returns the status of the given snapshot lifecycle policy.
+func policyStatus(name string, policy esclient.SnapshotLifecyclePolicy) esv1.SnapshotPolicyStatus {
+	status := esv1.SnapshotPolicyStatus{Name: name}
+	if policy.LastSuccess != nil {
+		t := metav1.NewTime(policy.LastSuccess.Time())
+		status.LastSuccessTime = &t
+	}
+	if policy.LastFailure != nil {
+		t := metav1.NewTime(policy.LastFailure.Time())
+		status.LastFailureTime = &t
+		status.LastFailureMessage = policy.LastFailure.Details
+	}
+	return status
+}
+
+func configData(config *commonv1.Config) map[string]interface{} {
+	if config == nil {
+		return nil
+	}
+	return config.Data
+}
+
+func equivalentPolicies(actual, expected esclient.SnapshotLifecyclePolicyDefinition) bool {
+	return actual.Name == expected.Name &&
+		actual.Schedule == expected.Schedule &&
+		actual.Repository == expected.Repository &&
+		equivalent(actual.Config, expected.Config) &&
+		equivalent(actual.Retention, expected.Retention)
+}
+
+// equivalent returns true if the given settings are the same once flattened, regardless of the type of their values:
+// Elasticsearch returns the settings of repositories as strings.
+func equivalent(actual, expected map[string]interface{}) bool {
+	return reflect.DeepEqual(flatten("", actual, map[string]string{}), flatten("", expected, map[string]string{}))
+}
+
+// flatten adds the given settings to the given map, with dotted keys and string values.
+func flatten(prefix string, settings map[string]interface{}, into map[string]string) map[string]string {
+	for k, v := range settings {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			flatten(key, nested, into)
+			continue
+		}
+		into[key] = fmt.Sprintf("%v", v)
+	}
+	return into
+}
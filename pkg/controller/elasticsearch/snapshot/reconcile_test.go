@@ -0,0 +1,184 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snapshot
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+)
+
+const (
+	fsRepository  = `{"backups":{"type":"fs","settings":{"location":"/backups","compress":"true"}}}`
+	nightlyPolicy = `{"nightly":{"version":1,"policy":{"name":"<nightly-{now/d}>","schedule":"0 30 1 * * ?","repository":"backups"},
+"last_success":{"snapshot_name":"nightly-2020.01.01","time":1577842200000},
+"last_failure":{"snapshot_name":"nightly-2019.12.31","time":1577755800000,"details":"repository missing"}}}`
+	notFound = `{"error":{"reason":"not found"}, "status":404}`
+)
+
+// request is a request received by the mock Elasticsearch client.
+type request struct {
+	method string
+	path   string
+}
+
+func mockClient(responses map[string]string, requests *[]request) esclient.Client {
+	return esclient.NewMockClient(version.MustParse("7.5.0"), func(req *http.Request) *http.Response {
+		*requests = append(*requests, request{method: req.Method, path: req.URL.Path})
+		if req.Method != http.MethodGet {
+			return esclient.NewMockResponse(200, req, `{"acknowledged":true}`)
+		}
+		response, exists := responses[req.URL.Path]
+		if !exists {
+			return esclient.NewMockResponse(404, req, notFound)
+		}
+		return esclient.NewMockResponse(200, req, response)
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	repository := esv1.SnapshotRepository{
+		Name:     "backups",
+		Type:     "fs",
+		Settings: &commonv1.Config{Data: map[string]interface{}{"location": "/backups", "compress": true}},
+	}
+	policy := esv1.SnapshotPolicy{Name: "nightly", Schedule: "0 30 1 * * ?", Repository: "backups"}
+	es := func(snapshots *esv1.SnapshotsSpec) esv1.Elasticsearch {
+		return esv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+			Spec:       esv1.ElasticsearchSpec{Version: "7.5.0", Snapshots: snapshots},
+		}
+	}
+	lastSuccess := metav1.NewTime(time.Date(2020, 1, 1, 1, 30, 0, 0, time.UTC))
+	lastFailure := metav1.NewTime(time.Date(2019, 12, 31, 1, 30, 0, 0, time.UTC))
+
+	tests := []struct {
+		name         string
+		es           esv1.Elasticsearch
+		responses    map[string]string
+		wantErr      bool
+		wantStatuses []esv1.SnapshotPolicyStatus
+		wantUpdates  []string
+	}{
+		{
+			name: "no snapshots",
+			es:   es(nil),
+		},
+		{
+			name:         "register the repository and create the policy",
+			es:           es(&esv1.SnapshotsSpec{Repositories: []esv1.SnapshotRepository{repository}, Policies: []esv1.SnapshotPolicy{policy}}),
+			wantStatuses: []esv1.SnapshotPolicyStatus{{Name: "nightly"}},
+			wantUpdates:  []string{"/_snapshot/backups", "/_slm/policy/nightly"},
+		},
+		{
+			name: "repository and policy already up-to-date",
+			es:   es(&esv1.SnapshotsSpec{Repositories: []esv1.SnapshotRepository{repository}, Policies: []esv1.SnapshotPolicy{policy}}),
+			responses: map[string]string{
+				"/_snapshot/backups":   fsRepository,
+				"/_slm/policy/nightly": nightlyPolicy,
+			},
+			wantStatuses: []esv1.SnapshotPolicyStatus{
+				{Name: "nightly", LastSuccessTime: &lastSuccess, LastFailureTime: &lastFailure, LastFailureMessage: "repository missing"},
+			},
+		},
+		{
+			name: "policy schedule changed",
+			es: es(&esv1.SnapshotsSpec{Policies: []esv1.SnapshotPolicy{
+				{Name: "nightly", Schedule: "0 30 2 * * ?", Repository: "backups"},
+			}}),
+			responses: map[string]string{
+				"/_slm/policy/nightly": nightlyPolicy,
+			},
+			wantStatuses: []esv1.SnapshotPolicyStatus{
+				{Name: "nightly", LastSuccessTime: &lastSuccess, LastFailureTime: &lastFailure, LastFailureMessage: "repository missing"},
+			},
+			wantUpdates: []string{"/_slm/policy/nightly"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []request
+			statuses, err := Reconcile(context.Background(), tt.es, mockClient(tt.responses, &requests))
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			for i := range statuses {
+				// compare times in UTC
+				for _, ts := range []*metav1.Time{statuses[i].LastSuccessTime, statuses[i].LastFailureTime} {
+					if ts != nil {
+						*ts = metav1.NewTime(ts.UTC())
+					}
+				}
+			}
+			if len(tt.wantStatuses) == 0 {
+				require.Empty(t, statuses)
+			} else {
+				require.Equal(t, tt.wantStatuses, statuses)
+			}
+			var updates []string
+			for _, r := range requests {
+				if r.method == http.MethodPut {
+					updates = append(updates, r.path)
+				}
+			}
+			require.Equal(t, tt.wantUpdates, updates)
+		})
+	}
+}
+
+func TestReconcile_policyError(t *testing.T) {
+	es := esv1.Elasticsearch{
+		Spec: esv1.ElasticsearchSpec{Snapshots: &esv1.SnapshotsSpec{Policies: []esv1.SnapshotPolicy{
+			{Name: "nightly", Schedule: "0 30 1 * * ?", Repository: "backups"},
+			{Name: "hourly", Schedule: "0 0 * * * ?", Repository: "backups"},
+		}}},
+	}
+	client := esclient.NewMockClient(version.MustParse("7.5.0"), func(req *http.Request) *http.Response {
+		if req.Method == http.MethodPut && req.URL.Path == "/_slm/policy/nightly" {
+			return esclient.NewMockResponse(400, req, `{"error":{"reason":"invalid schedule"}, "status":400}`)
+		}
+		if req.Method == http.MethodGet {
+			return esclient.NewMockResponse(404, req, notFound)
+		}
+		return esclient.NewMockResponse(200, req, `{"acknowledged":true}`)
+	})
+	statuses, err := Reconcile(context.Background(), es, client)
+	require.Error(t, err)
+	// the failure is reported in the status of the policy, other policies are still applied
+	require.Len(t, statuses, 2)
+	require.Contains(t, statuses[0].Error, "invalid schedule")
+	require.Equal(t, esv1.SnapshotPolicyStatus{Name: "hourly"}, statuses[1])
+}
+
+func Test_equivalent(t *testing.T) {
+	require.True(t, equivalent(nil, map[string]interface{}{}))
+	require.True(t, equivalent(
+		map[string]interface{}{"compress": "true", "chunk_size": "1g"},
+		map[string]interface{}{"compress": true, "chunk_size": "1g"},
+	))
+	require.True(t, equivalent(
+		map[string]interface{}{"client.endpoint": "s3.amazonaws.com"},
+		map[string]interface{}{"client": map[string]interface{}{"endpoint": "s3.amazonaws.com"}},
+	))
+	require.False(t, equivalent(
+		map[string]interface{}{"location": "/backups"},
+		map[string]interface{}{"location": "/other"},
+	))
+	require.False(t, equivalent(
+		map[string]interface{}{"location": "/backups"},
+		map[string]interface{}{"location": "/backups", "compress": true},
+	))
+}
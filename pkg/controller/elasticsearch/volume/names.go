@@ -39,4 +39,5 @@ const (
 	DownwardAPIVolumeName = "downward-api"
 	DownwardAPIMountPath  = "/mnt/elastic-internal/downward-api"
 	LabelsFile            = "labels"
+	AnnotationsFile       = "annotations"
 )
@@ -0,0 +1,114 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package zone
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	common "github.com/elastic/cloud-on-k8s/pkg/controller/common/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/settings"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/sset"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+const (
+	// AnnotationName is the annotation holding the zone of the Kubernetes node an Elasticsearch pod is scheduled on.
+	// It is copied from the labels of the Kubernetes node by the operator, since they are not available through the
+	// downward API.
+	AnnotationName = "elasticsearch.k8s.elastic.co/zone"
+
+	// attributeName is the node attribute holding the zone of the Elasticsearch nodes.
+	attributeName = "zone"
+)
+
+var log = logf.Log.WithName("zone-awareness")
+
+// Config returns the Elasticsearch configuration setting the zone node attribute and enabling shard allocation
+// awareness for it.
+func Config() *common.CanonicalConfig {
+	return common.MustCanonicalConfig(map[string]interface{}{
+		esv1.NodeAttrZone: "${" + settings.EnvZone + "}",
+		esv1.ClusterRoutingAllocationAwarenessAttributes: attributeName,
+	})
+}
+
+// EnvVar returns the env var exposing the zone annotation of the pod to Elasticsearch.
+func EnvVar() corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: settings.EnvZone,
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: fmt.Sprintf("metadata.annotations['%s']", AnnotationName)},
+		},
+	}
+}
+
+// AntiAffinityTerm returns a pod anti-affinity term preferring to spread the pods of the given cluster across zones.
+func AntiAffinityTerm(esName string, zoneAwareness esv1.ZoneAwareness) corev1.WeightedPodAffinityTerm {
+	return corev1.WeightedPodAffinityTerm{
+		Weight: 100,
+		PodAffinityTerm: corev1.PodAffinityTerm{
+			TopologyKey: zoneAwareness.TopologyKeyOrDefault(),
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					label.ClusterNameLabelName: esName,
+				},
+			},
+		},
+	}
+}
+
+// AnnotatePods annotates the scheduled pods of the given cluster with the zone of their Kubernetes node, if zone
+// awareness is enabled. Pods whose Kubernetes node does not have a zone are reported in the returned error.
+// Kubernetes nodes are read through nodeReader, which must not be restricted to the managed namespaces.
+func AnnotatePods(c k8s.Client, nodeReader client.Reader, es esv1.Elasticsearch) error {
+	if es.Spec.ZoneAwareness == nil {
+		return nil
+	}
+	topologyKey := es.Spec.ZoneAwareness.TopologyKeyOrDefault()
+	pods, err := sset.GetActualPodsForCluster(c, es)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			// not scheduled yet
+			continue
+		}
+		if _, exists := pod.Annotations[AnnotationName]; exists {
+			// pods do not move across Kubernetes nodes
+			continue
+		}
+		var node corev1.Node
+		if err := nodeReader.Get(context.Background(), types.NamespacedName{Name: pod.Spec.NodeName}, &node); err != nil {
+			return err
+		}
+		zone, exists := node.Labels[topologyKey]
+		if !exists {
+			errs = append(errs, fmt.Errorf("Kubernetes node %s of pod %s has no %s label", node.Name, pod.Name, topologyKey))
+			continue
+		}
+		log.Info("Setting pod zone",
+			"namespace", pod.Namespace, "es_name", es.Name, "pod_name", pod.Name, "zone", zone)
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[AnnotationName] = zone
+		if err := c.Update(&pod); err != nil {
+			return err
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,154 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package zone
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func node(name string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func pod(name string, nodeName string, annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        name,
+			Labels:      map[string]string{label.ClusterNameLabelName: "es"},
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+func TestConfig(t *testing.T) {
+	cfg, err := Config().Render()
+	require.NoError(t, err)
+	require.Equal(t, "cluster:\n  routing:\n    allocation:\n      awareness:\n        attributes: zone\nnode:\n  attr:\n    zone: ${ZONE}\n", string(cfg))
+}
+
+// namespacedClient behaves like the cache of an operator restricted to some namespaces, which cannot read
+// cluster-scoped resources.
+type namespacedClient struct {
+	k8s.Client
+}
+
+func (c namespacedClient) Get(key client.ObjectKey, obj runtime.Object) error {
+	if key.Namespace == "" {
+		return fmt.Errorf("unable to get: %v because of unknown namespace for the cache", key)
+	}
+	return c.Client.Get(key, obj)
+}
+
+// splitObjects returns the Kubernetes nodes and the namespaced objects of objs.
+func splitObjects(objs []runtime.Object) (nodes []runtime.Object, namespaced []runtime.Object) {
+	for _, obj := range objs {
+		if _, isNode := obj.(*corev1.Node); isNode {
+			nodes = append(nodes, obj)
+		} else {
+			namespaced = append(namespaced, obj)
+		}
+	}
+	return nodes, namespaced
+}
+
+func TestAnnotatePods(t *testing.T) {
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec:       esv1.ElasticsearchSpec{ZoneAwareness: &esv1.ZoneAwareness{}},
+	}
+	tests := []struct {
+		name            string
+		es              esv1.Elasticsearch
+		objs            []runtime.Object
+		wantErr         bool
+		wantAnnotations map[string]string
+	}{
+		{
+			name: "zone awareness disabled",
+			es:   esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}},
+			objs: []runtime.Object{
+				node("node1", map[string]string{esv1.DefaultZoneTopologyKey: "zone-a"}),
+				pod("es-0", "node1", nil),
+			},
+			wantAnnotations: map[string]string{"es-0": ""},
+		},
+		{
+			name: "annotate scheduled pods",
+			es:   es,
+			objs: []runtime.Object{
+				node("node1", map[string]string{esv1.DefaultZoneTopologyKey: "zone-a"}),
+				node("node2", map[string]string{esv1.DefaultZoneTopologyKey: "zone-b"}),
+				pod("es-0", "node1", nil),
+				pod("es-1", "node2", nil),
+				pod("es-2", "", nil),
+			},
+			wantAnnotations: map[string]string{"es-0": "zone-a", "es-1": "zone-b", "es-2": ""},
+		},
+		{
+			name: "custom topology key",
+			es: esv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+				Spec:       esv1.ElasticsearchSpec{ZoneAwareness: &esv1.ZoneAwareness{TopologyKey: "rack"}},
+			},
+			objs: []runtime.Object{
+				node("node1", map[string]string{esv1.DefaultZoneTopologyKey: "zone-a", "rack": "rack-1"}),
+				pod("es-0", "node1", nil),
+			},
+			wantAnnotations: map[string]string{"es-0": "rack-1"},
+		},
+		{
+			name: "pod already annotated",
+			es:   es,
+			objs: []runtime.Object{
+				node("node1", map[string]string{esv1.DefaultZoneTopologyKey: "zone-b"}),
+				pod("es-0", "node1", map[string]string{AnnotationName: "zone-a"}),
+			},
+			wantAnnotations: map[string]string{"es-0": "zone-a"},
+		},
+		{
+			name: "Kubernetes node without zone",
+			es:   es,
+			objs: []runtime.Object{
+				node("node1", nil),
+				node("node2", map[string]string{esv1.DefaultZoneTopologyKey: "zone-b"}),
+				pod("es-0", "node1", nil),
+				pod("es-1", "node2", nil),
+			},
+			wantErr:         true,
+			wantAnnotations: map[string]string{"es-0": "", "es-1": "zone-b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, namespaced := splitObjects(tt.objs)
+			c := namespacedClient{Client: k8s.WrappedFakeClient(namespaced...)}
+			err := AnnotatePods(c, k8s.FakeClient(nodes...), tt.es)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			for name, want := range tt.wantAnnotations {
+				var p corev1.Pod
+				require.NoError(t, c.Get(types.NamespacedName{Namespace: "ns", Name: name}, &p))
+				require.Equal(t, want, p.Annotations[AnnotationName])
+			}
+		})
+	}
+}
@@ -24,3 +24,12 @@ const (
 	ServerSSLCertificate = "server.ssl.certificate"
 	ServerSSLKey         = "server.ssl.key"
 )
+
+// associationSettings are the settings of the Elasticsearch connection provided by the association.
+var associationSettings = []string{
+	ElasticsearchHosts,
+	ElasticsearchUsername,
+	ElasticsearchPassword,
+	ElasticsearchSslCertificateAuthorities,
+	ElasticsearchSslVerificationMode,
+}
@@ -6,6 +6,7 @@ package config
 
 import (
 	"context"
+	"fmt"
 	"path"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
@@ -68,6 +69,11 @@ func NewConfigSettings(ctx context.Context, client k8s.Client, kb kbv1.Kibana, v
 		return CanonicalConfig{}, err
 	}
 
+	configMapSettings, err := getConfigMapSettings(client, kb)
+	if err != nil {
+		return CanonicalConfig{}, err
+	}
+
 	cfg := settings.MustCanonicalConfig(baseSettings(&kb))
 	kibanaTLSCfg := settings.MustCanonicalConfig(kibanaTLSSettings(kb))
 	versionSpecificCfg := VersionDefaults(&kb, v)
@@ -78,6 +84,7 @@ func NewConfigSettings(ctx context.Context, client k8s.Client, kb kbv1.Kibana, v
 			filteredCurrCfg,
 			versionSpecificCfg,
 			kibanaTLSCfg,
+			configMapSettings,
 			userSettings); err != nil {
 			return CanonicalConfig{}, err
 		}
@@ -94,6 +101,7 @@ func NewConfigSettings(ctx context.Context, client k8s.Client, kb kbv1.Kibana, v
 		filteredCurrCfg,
 		versionSpecificCfg,
 		kibanaTLSCfg,
+		configMapSettings,
 		settings.MustCanonicalConfig(elasticsearchTLSSettings(kb)),
 		settings.MustCanonicalConfig(
 			map[string]interface{}{
@@ -135,6 +143,34 @@ func getExistingConfig(client k8s.Client, kb kbv1.Kibana) (*settings.CanonicalCo
 	return cfg, nil
 }
 
+// getConfigMapSettings retrieves the configuration held in the ConfigMap referenced by the given Kibana, if any.
+// If Kibana is associated with Elasticsearch, the Elasticsearch connection settings of the ConfigMap are removed
+// so that they do not conflict with the ones provided by the association.
+func getConfigMapSettings(client k8s.Client, kb kbv1.Kibana) (*settings.CanonicalConfig, error) {
+	if kb.Spec.ConfigRef == nil {
+		return nil, nil
+	}
+	var configMap corev1.ConfigMap
+	nsn := types.NamespacedName{Namespace: kb.Namespace, Name: kb.Spec.ConfigRef.ConfigMapName}
+	if err := client.Get(nsn, &configMap); err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve Kibana config map %s", nsn)
+	}
+	rawCfg, exists := configMap.Data[SettingsFilename]
+	if !exists {
+		return nil, fmt.Errorf("Kibana config map %s does not contain the %s key", nsn, SettingsFilename)
+	}
+	cfg, err := settings.ParseConfig([]byte(rawCfg))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse Kibana config map %s", nsn)
+	}
+	if kb.RequiresAssociation() {
+		if err := cfg.Remove(associationSettings...); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
 // filterExistingConfig filters an existing config for only items we want to preserve between spec changes
 // because they cannot be generated deterministically, e.g. encryption keys
 func filterExistingConfig(cfg *settings.CanonicalConfig) (*settings.CanonicalConfig, error) {
@@ -182,8 +218,12 @@ func kibanaTLSSettings(kb kbv1.Kibana) map[string]interface{} {
 }
 
 func elasticsearchTLSSettings(kb kbv1.Kibana) map[string]interface{} {
+	verificationMode := kb.AssociationConf().GetSSLVerificationMode()
+	if verificationMode == "" {
+		verificationMode = commonv1.SSLVerificationModeCertificate
+	}
 	cfg := map[string]interface{}{
-		ElasticsearchSslVerificationMode: "certificate",
+		ElasticsearchSslVerificationMode: verificationMode,
 	}
 
 	if kb.AssociationConf().GetCACertProvided() {
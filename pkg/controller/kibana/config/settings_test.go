@@ -63,6 +63,31 @@ func TestNewConfigSettings(t *testing.T) {
 			SettingsFilename: []byte("xpack.security.encryptionKey: thisismyencryptionkey"),
 		},
 	}
+	userConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "user-config",
+			Namespace: defaultKb.Namespace,
+		},
+		Data: map[string]string{
+			SettingsFilename: `
+server.basePath: /kibana
+logging.verbose: true
+elasticsearch:
+  hosts: ["https://other-es:9200"]
+  username: "other-user"
+  requestTimeout: 60000
+`,
+		},
+	}
+	authSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "auth-secret",
+			Namespace: defaultKb.Namespace,
+		},
+		Data: map[string][]byte{
+			"elastic": []byte("password"),
+		},
+	}
 	type args struct {
 		client k8s.Client
 		kb     func() kbv1.Kibana
@@ -224,6 +249,84 @@ func TestNewConfigSettings(t *testing.T) {
 			},
 			want: defaultConfig,
 		},
+		{
+			name: "with config map",
+			args: args{
+				client: k8s.WrappedFakeClient(existingSecret, userConfigMap),
+				kb: func() kbv1.Kibana {
+					kb := mkKibana()
+					kb.Spec = kbv1.KibanaSpec{
+						ConfigRef: &kbv1.ConfigMapRef{ConfigMapName: "user-config"},
+					}
+					return kb
+				},
+			},
+			want: append(defaultConfig, []byte(`
+server.basePath: /kibana
+logging.verbose: true
+elasticsearch.hosts: ["https://other-es:9200"]
+elasticsearch.username: "other-user"
+elasticsearch.requestTimeout: 60000`)...),
+		},
+		{
+			name: "with config map and user config: user config takes precedence",
+			args: args{
+				client: k8s.WrappedFakeClient(existingSecret, userConfigMap),
+				kb: func() kbv1.Kibana {
+					kb := mkKibana()
+					kb.Spec = kbv1.KibanaSpec{
+						ConfigRef: &kbv1.ConfigMapRef{ConfigMapName: "user-config"},
+						Config: &commonv1.Config{
+							Data: map[string]interface{}{
+								"logging.verbose": false,
+							},
+						},
+					}
+					return kb
+				},
+			},
+			want: append(defaultConfig, []byte(`
+server.basePath: /kibana
+logging.verbose: false
+elasticsearch.hosts: ["https://other-es:9200"]
+elasticsearch.username: "other-user"
+elasticsearch.requestTimeout: 60000`)...),
+		},
+		{
+			name: "with config map and association: the association provides the Elasticsearch connection",
+			args: args{
+				client: k8s.WrappedFakeClient(existingSecret, userConfigMap, authSecret),
+				kb: func() kbv1.Kibana {
+					kb := mkKibana()
+					kb.Spec = kbv1.KibanaSpec{
+						ElasticsearchRef: commonv1.ObjectSelector{Name: "test-es"},
+						ConfigRef:        &kbv1.ConfigMapRef{ConfigMapName: "user-config"},
+					}
+					kb.SetAssociationConf(&commonv1.AssociationConf{
+						AuthSecretName: "auth-secret",
+						AuthSecretKey:  "elastic",
+						CASecretName:   "ca-secret",
+						CACertProvided: true,
+						URL:            "https://es-url:9200",
+					})
+					return kb
+				},
+			},
+			want: func() []byte {
+				cfg, err := settings.ParseConfig(defaultConfig)
+				require.NoError(t, err)
+				assocCfg, err := settings.ParseConfig(associationConfig)
+				require.NoError(t, err)
+				require.NoError(t, cfg.MergeWith(assocCfg, settings.MustCanonicalConfig(map[string]interface{}{
+					"server.basePath":              "/kibana",
+					"logging.verbose":              true,
+					"elasticsearch.requestTimeout": 60000,
+				})))
+				bytes, err := cfg.Render()
+				require.NoError(t, err)
+				return bytes
+			}(),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -250,6 +353,40 @@ func TestNewConfigSettings(t *testing.T) {
 	}
 }
 
+func Test_elasticsearchTLSSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *commonv1.AssociationConf
+		want map[string]interface{}
+	}{
+		{
+			name: "certificate verification by default",
+			conf: &commonv1.AssociationConf{URL: "https://es-url:9200"},
+			want: map[string]interface{}{ElasticsearchSslVerificationMode: "certificate"},
+		},
+		{
+			name: "verification mode set by the association",
+			conf: &commonv1.AssociationConf{URL: "https://es-url:9200", SSLVerificationMode: commonv1.SSLVerificationModeFull},
+			want: map[string]interface{}{ElasticsearchSslVerificationMode: "full"},
+		},
+		{
+			name: "with CA",
+			conf: &commonv1.AssociationConf{URL: "https://es-url:9200", CASecretName: "ca-secret", CACertProvided: true},
+			want: map[string]interface{}{
+				ElasticsearchSslVerificationMode:       "certificate",
+				ElasticsearchSslCertificateAuthorities: "/usr/share/kibana/config/elasticsearch-certs/ca.crt",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := mkKibana()
+			kb.SetAssociationConf(tt.conf)
+			require.Equal(t, tt.want, elasticsearchTLSSettings(kb))
+		})
+	}
+}
+
 // TestNewConfigSettingsCreateEncryptionKey checks that we generate a new key if none is specified
 func TestNewConfigSettingsCreateEncryptionKey(t *testing.T) {
 	client := k8s.WrapClient(fake.NewFakeClient())
@@ -409,6 +546,69 @@ func Test_getExistingConfig(t *testing.T) {
 	}
 }
 
+func Test_getConfigMapSettings(t *testing.T) {
+	kb := mkKibana()
+	kb.Spec.ConfigRef = &kbv1.ConfigMapRef{ConfigMapName: "user-config"}
+	configMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "user-config", Namespace: kb.Namespace},
+			Data:       data,
+		}
+	}
+	tests := []struct {
+		name    string
+		kb      kbv1.Kibana
+		client  k8s.Client
+		want    *settings.CanonicalConfig
+		wantErr bool
+	}{
+		{
+			name:   "no config map referenced",
+			kb:     mkKibana(),
+			client: k8s.WrappedFakeClient(),
+		},
+		{
+			name:   "happy path",
+			kb:     kb,
+			client: k8s.WrappedFakeClient(configMap(map[string]string{SettingsFilename: "server.basePath: /kibana"})),
+			want:   settings.MustCanonicalConfig(map[string]interface{}{"server.basePath": "/kibana"}),
+		},
+		{
+			name:    "config map does not exist",
+			kb:      kb,
+			client:  k8s.WrappedFakeClient(),
+			wantErr: true,
+		},
+		{
+			name:    "no kibana.yml in config map",
+			kb:      kb,
+			client:  k8s.WrappedFakeClient(configMap(map[string]string{"other.yml": "server.basePath: /kibana"})),
+			wantErr: true,
+		},
+		{
+			name:    "cannot parse yaml",
+			kb:      kb,
+			client:  k8s.WrappedFakeClient(configMap(map[string]string{SettingsFilename: ":-{"})),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getConfigMapSettings(tt.client, tt.kb)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == nil {
+				require.Nil(t, got)
+				return
+			}
+			require.Empty(t, got.Diff(tt.want, nil))
+		})
+	}
+}
+
 func Test_filterExistingConfig(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -83,6 +83,25 @@ func secretWatchKey(kibana types.NamespacedName) string {
 	return fmt.Sprintf("%s-%s-es-auth-secret", kibana.Namespace, kibana.Name)
 }
 
+func configMapWatchKey(kibana types.NamespacedName) string {
+	return fmt.Sprintf("%s-%s-config-map", kibana.Namespace, kibana.Name)
+}
+
+// watchConfigMap watches the ConfigMap referenced by the given Kibana, if any, to reconcile the Kibana configuration
+// on changes.
+func (d *driver) watchConfigMap(kb *kbv1.Kibana) error {
+	kbNamespacedName := k8s.ExtractNamespacedName(kb)
+	if kb.Spec.ConfigRef == nil {
+		d.dynamicWatches.ConfigMaps.RemoveHandlerForKey(configMapWatchKey(kbNamespacedName))
+		return nil
+	}
+	return d.dynamicWatches.ConfigMaps.AddHandler(watches.NamedWatch{
+		Name:    configMapWatchKey(kbNamespacedName),
+		Watched: []types.NamespacedName{{Namespace: kb.Namespace, Name: kb.Spec.ConfigRef.ConfigMapName}},
+		Watcher: kbNamespacedName,
+	})
+}
+
 // getStrategyType decides which deployment strategy (RollingUpdate or Recreate) to use based on whether the version
 // upgrade is in progress. Kibana does not support a smooth rolling upgrade from one version to another:
 // running multiple versions simultaneously may lead to concurrency bugs and data corruption.
@@ -131,7 +150,7 @@ func (d *driver) deploymentParams(kb *kbv1.Kibana) (deployment.Params, error) {
 	kbNamespacedName := k8s.ExtractNamespacedName(kb)
 	// we need to deref the secret here (if any) to include it in the checksum otherwise Kibana will not be rolled on contents changes
 	if kb.AssociationConf().AuthIsConfigured() {
-		esAuthSecret := types.NamespacedName{Name: kb.AssociationConf().GetAuthSecretName(), Namespace: kb.Namespace}
+		esAuthSecret := kb.AssociationConf().AuthSecretRef(kb.Namespace)
 		if err := d.dynamicWatches.Secrets.AddHandler(watches.NamedWatch{
 			Name:    secretWatchKey(kbNamespacedName),
 			Watched: []types.NamespacedName{esAuthSecret},
@@ -168,6 +187,10 @@ func (d *driver) deploymentParams(kb *kbv1.Kibana) (deployment.Params, error) {
 		if certPem, ok := esPublicCASecret.Data[certificates.CertFileName]; ok {
 			_, _ = configChecksum.Write(certPem)
 		}
+		// the CA bundle may change independently of the certificate, for instance if additional CAs are trusted
+		if caPem, ok := esPublicCASecret.Data[certificates.CAFileName]; ok {
+			_, _ = configChecksum.Write(caPem)
+		}
 
 		// TODO: this is a little ugly as it reaches into the ES controller bits
 		esCertsVolume := es.CaCertSecretVolume(*kb)
@@ -242,6 +265,12 @@ func (d *driver) Reconcile(
 	if !association.IsConfiguredIfSet(kb, d.recorder) {
 		return results
 	}
+	// the CA of an external Elasticsearch cluster is optional
+	if kb.Spec.ExternalElasticsearch != nil && !(kb.AssociationConf().AuthIsConfigured() && kb.AssociationConf().URLIsConfigured()) {
+		log.Info("External Elasticsearch not configured yet: skipping Kibana deployment reconciliation",
+			"namespace", kb.Namespace, "kibana_name", kb.Name)
+		return results
+	}
 
 	svc, err := common.ReconcileService(ctx, d.client, d.scheme, NewService(*kb), kb)
 	if err != nil {
@@ -254,6 +283,12 @@ func (d *driver) Reconcile(
 		return results
 	}
 
+	// the configuration is rendered in the config secret, whose content is part of the pods checksum:
+	// changes in the referenced ConfigMap roll the Kibana pods
+	if err := d.watchConfigMap(kb); err != nil {
+		return results.WithError(err)
+	}
+
 	kbSettings, err := config.NewConfigSettings(ctx, d.client, *kb, d.version)
 	if err != nil {
 		return results.WithError(err)
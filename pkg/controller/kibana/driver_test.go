@@ -388,6 +388,26 @@ func TestDriverDeploymentParams(t *testing.T) {
 	}
 }
 
+func TestDriverDeploymentParams_checksumTakesCABundleIntoAccount(t *testing.T) {
+	checksum := func(caBundle []byte) string {
+		initialObjects := defaultInitialObjects()
+		initialObjects[0].(*corev1.Secret).Data[certificates.CAFileName] = caBundle
+		client := k8s.WrappedFakeClient(initialObjects...)
+		w := watches.NewDynamicWatches()
+		require.NoError(t, w.Secrets.InjectScheme(scheme.Scheme))
+		kb := kibanaFixture()
+		d, err := newDriver(client, scheme.Scheme, w, record.NewFakeRecorder(100), kb)
+		require.NoError(t, err)
+		params, err := d.deploymentParams(kb)
+		require.NoError(t, err)
+		return params.PodTemplateSpec.Labels[configChecksumLabel]
+	}
+
+	esCA := checksum([]byte("es-ca"))
+	require.Equal(t, esCA, checksum([]byte("es-ca")))
+	require.NotEqual(t, esCA, checksum([]byte("es-ca\nadditional-ca")))
+}
+
 func TestMinSupportedVersion(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -6,7 +6,6 @@ package es
 
 import (
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/volume"
@@ -27,12 +26,8 @@ func CaCertSecretVolume(kb kbv1.Kibana) volume.SecretVolume {
 
 // GetAuthSecret returns the Elasticsearch auth secret for the given Kibana resource.
 func GetAuthSecret(client k8s.Client, kb kbv1.Kibana) (*corev1.Secret, error) {
-	esAuthSecret := types.NamespacedName{
-		Name:      kb.AssociationConf().GetAuthSecretName(),
-		Namespace: kb.Namespace,
-	}
 	var secret corev1.Secret
-	err := client.Get(esAuthSecret, &secret)
+	err := client.Get(kb.AssociationConf().AuthSecretRef(kb.Namespace), &secret)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +108,11 @@ func addWatches(c controller.Controller, r *ReconcileKibana) error {
 		return err
 	}
 
+	// dynamically watch the ConfigMaps holding user-provided configuration
+	if err := c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, r.dynamicWatches.ConfigMaps); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -150,7 +155,7 @@ func (r *ReconcileKibana) Reconcile(request reconcile.Request) (reconcile.Result
 	// skip reconciliation if paused
 	if common.IsPaused(kb.ObjectMeta) {
 		log.Info("Object is paused. Skipping reconciliation", "namespace", kb.Namespace, "kibana_name", kb.Name)
-		return common.PauseRequeue, nil
+		return common.PauseRequeueFor(kb.ObjectMeta), nil
 	}
 
 	// check for compatibility with the operator version
@@ -234,4 +239,5 @@ func (r *ReconcileKibana) onDelete(obj types.NamespacedName) {
 	// Clean up watches
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(keystore.SecureSettingsWatchName(obj))
 	r.dynamicWatches.Secrets.RemoveHandlerForKey(secretWatchKey(obj))
+	r.dynamicWatches.ConfigMaps.RemoveHandlerForKey(configMapWatchKey(obj))
 }
@@ -5,6 +5,8 @@
 package kibana
 
 import (
+	"strconv"
+
 	corev1 "k8s.io/api/core/v1"
 
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
@@ -12,6 +14,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
 	kbname "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/name"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/pod"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/stringsutil"
 )
 
 func NewService(kb kbv1.Kibana) *corev1.Service {
@@ -34,3 +37,8 @@ func NewService(kb kbv1.Kibana) *corev1.Service {
 
 	return defaults.SetServiceDefaults(&svc, labels, labels, ports)
 }
+
+// ServiceURL returns the URL used to reach Kibana through its HTTP service.
+func ServiceURL(kb kbv1.Kibana) string {
+	return stringsutil.Concat(kb.Spec.HTTP.Protocol(), "://", kbname.HTTPService(kb.Name), ".", kb.Namespace, ".svc:", strconv.Itoa(pod.HTTPPort))
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/pod"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/compare"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewService(t *testing.T) {
@@ -135,3 +136,10 @@ func mkKibana(httpConf commonv1.HTTPConfig) kbv1.Kibana {
 		},
 	}
 }
+
+func TestServiceURL(t *testing.T) {
+	kb := kbv1.Kibana{ObjectMeta: metav1.ObjectMeta{Name: "kibana-test", Namespace: "test"}}
+	require.Equal(t, "https://kibana-test-kb-http.test.svc:5601", ServiceURL(kb))
+	kb.Spec.HTTP.TLS.SelfSignedCertificate = &commonv1.SelfSignedCertificate{Disabled: true}
+	require.Equal(t, "http://kibana-test-kb-http.test.svc:5601", ServiceURL(kb))
+}
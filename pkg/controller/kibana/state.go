@@ -28,9 +28,18 @@ func NewState(request reconcile.Request, kb *kbv1.Kibana) State {
 }
 
 // UpdateKibanaState updates the Kibana status based on the given deployment.
+// Kibana is only considered green once the deployment is available with at least one instance ready.
 func (s State) UpdateKibanaState(deployment appsv1.Deployment) {
 	s.Kibana.Status.AvailableNodes = deployment.Status.AvailableReplicas
+	s.Kibana.Status.ReadyNodes = deployment.Status.ReadyReplicas
+	s.Kibana.Status.ExpectedNodes = 0
+	if deployment.Spec.Replicas != nil {
+		s.Kibana.Status.ExpectedNodes = *deployment.Spec.Replicas
+	}
 	s.Kibana.Status.Health = kbv1.KibanaRed
+	if deployment.Status.AvailableReplicas == 0 {
+		return
+	}
 	for _, c := range deployment.Status.Conditions {
 		if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
 			s.Kibana.Status.Health = kbv1.KibanaGreen
@@ -0,0 +1,79 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibana
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+)
+
+func deploymentWithStatus(replicas int32, status appsv1.DeploymentStatus) appsv1.Deployment {
+	return appsv1.Deployment{
+		Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: status,
+	}
+}
+
+func availableCondition(status corev1.ConditionStatus) []appsv1.DeploymentCondition {
+	return []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: status}}
+}
+
+func TestState_UpdateKibanaState(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment appsv1.Deployment
+		want       kbv1.KibanaStatus
+	}{
+		{
+			name:       "no instance ready",
+			deployment: deploymentWithStatus(3, appsv1.DeploymentStatus{Conditions: availableCondition(corev1.ConditionFalse)}),
+			want:       kbv1.KibanaStatus{ExpectedNodes: 3, Health: kbv1.KibanaRed},
+		},
+		{
+			name: "scaling up: some instances not ready yet",
+			deployment: deploymentWithStatus(3, appsv1.DeploymentStatus{
+				ReadyReplicas:     1,
+				AvailableReplicas: 1,
+				Conditions:        availableCondition(corev1.ConditionTrue),
+			}),
+			want: func() kbv1.KibanaStatus {
+				status := kbv1.KibanaStatus{ReadyNodes: 1, ExpectedNodes: 3, Health: kbv1.KibanaGreen}
+				status.AvailableNodes = 1
+				return status
+			}(),
+		},
+		{
+			name: "all instances ready",
+			deployment: deploymentWithStatus(3, appsv1.DeploymentStatus{
+				ReadyReplicas:     3,
+				AvailableReplicas: 3,
+				Conditions:        availableCondition(corev1.ConditionTrue),
+			}),
+			want: func() kbv1.KibanaStatus {
+				status := kbv1.KibanaStatus{ReadyNodes: 3, ExpectedNodes: 3, Health: kbv1.KibanaGreen}
+				status.AvailableNodes = 3
+				return status
+			}(),
+		},
+		{
+			name:       "scaled down to zero",
+			deployment: deploymentWithStatus(0, appsv1.DeploymentStatus{Conditions: availableCondition(corev1.ConditionTrue)}),
+			want:       kbv1.KibanaStatus{Health: kbv1.KibanaRed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewState(reconcile.Request{}, &kbv1.Kibana{})
+			state.UpdateKibanaState(tt.deployment)
+			require.Equal(t, tt.want, state.Kibana.Status)
+		})
+	}
+}
@@ -6,11 +6,16 @@ package kibanaassociation
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/reconciler"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/tracing"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/rbac"
 	"go.elastic.co/apm"
 	corev1 "k8s.io/api/core/v1"
@@ -20,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -32,13 +38,15 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
-	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates/http"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
 	elasticsearchuser "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/user"
+	kbctl "github.com/elastic/cloud-on-k8s/pkg/controller/kibana"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
 	kblabel "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
@@ -62,23 +70,66 @@ import (
 // this controller does nothing.
 
 const (
+	// name is the name of the controller, it also names its work queue in the exported workqueue_* metrics.
 	name = "kibana-association-controller"
+	// kibanaKind is the kind of the associated resources, as reported to external systems.
+	kibanaKind = "Kibana"
 	// kibanaUserSuffix is used to suffix user and associated secret resources.
 	kibanaUserSuffix = "kibana-user"
 	// ElasticsearchCASecretSuffix is used as suffix for CAPublicCertSecretName
 	ElasticsearchCASecretSuffix = "kb-es-ca" // nolint
+	// DefaultRequeueInterval is the default interval pending associations are reconciled again after.
+	DefaultRequeueInterval = 10 * time.Second
+	// DefaultReconcileTimeout is the default duration after which a reconciliation is aborted and requeued.
+	DefaultReconcileTimeout = 2 * time.Minute
 )
 
 var (
 	log            = logf.Log.WithName(name)
-	defaultRequeue = reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}
+	defaultRequeue = reconcile.Result{Requeue: true, RequeueAfter: DefaultRequeueInterval}
+	// forwardReferenceRequeue is used for forward reference tolerant associations while the referenced
+	// Elasticsearch cluster does not exist, its creation is caught by the Elasticsearch watch anyway.
+	forwardReferenceRequeue = reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Minute}
+	// caRotationRequeue is used while a CA rotation is staged, to check whether the Kibana pods use the staged CA since
+	// they are not watched.
+	caRotationRequeue = reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}
+	// aliasRequeue is used for associations referencing Elasticsearch through an alias, to resolve it again since
+	// changes to the aliases ConfigMap are not watched if the operator namespace is not cached.
+	aliasRequeue = reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Minute}
 )
 
 // Add creates a new Association Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager, accessReviewer rbac.AccessReviewer, params operator.Parameters) error {
 	r := newReconciler(mgr, accessReviewer, params)
-	c, err := add(mgr, r)
+	if params.AssociationInventoryURL != "" {
+		r.inventory = association.NewInventoryExporter(params.AssociationInventoryURL)
+		if params.AssociationInventorySigningSecret.Name != "" {
+			// read the signing secret directly from the API server, the operator namespace may not be cached
+			r.inventory.WithSigningKey(association.SecretSigningKey(mgr.GetAPIReader(), params.AssociationInventorySigningSecret))
+		}
+		if err := mgr.Add(r.inventory); err != nil {
+			return err
+		}
+	}
+	if params.AssociationAuditLogPath != "" {
+		audit, err := association.NewFileAuditLogger(params.AssociationAuditLogPath)
+		if err != nil {
+			return err
+		}
+		r.audit = audit
+		// closes the audit log file when the manager stops
+		if err := mgr.Add(r.audit); err != nil {
+			return err
+		}
+	}
+	if params.AssociationLivenessThreshold > 0 {
+		r.liveness = association.NewReconciliationLiveness(params.AssociationLivenessThreshold)
+		if err := mgr.AddHealthzCheck(name, r.liveness.Checker()); err != nil {
+			return err
+		}
+	}
+	c, err := add(mgr, r, params.AssociationMaxConcurrentReconciles)
 	if err != nil {
 		return err
 	}
@@ -89,19 +140,36 @@ func Add(mgr manager.Manager, accessReviewer rbac.AccessReviewer, params operato
 func newReconciler(mgr manager.Manager, accessReviewer rbac.AccessReviewer, params operator.Parameters) *ReconcileAssociation {
 	client := k8s.WrapClient(mgr.GetClient())
 	return &ReconcileAssociation{
-		Client:         client,
-		accessReviewer: accessReviewer,
-		scheme:         mgr.GetScheme(),
-		watches:        watches.NewDynamicWatches(),
-		recorder:       mgr.GetEventRecorderFor(name),
-		Parameters:     params,
+		Client:              client,
+		apiReader:           mgr.GetAPIReader(),
+		accessReviewer:      accessReviewer,
+		scheme:              mgr.GetScheme(),
+		watches:             watches.NewDynamicWatches(),
+		recorder:            mgr.GetEventRecorderFor(name),
+		esCallsLimiter:      association.NewESCallsLimiter(association.DefaultESCallsInterval, association.DefaultESCallsBurst),
+		failureGrace:        association.NewFailureGracePeriod(params.AssociationFailureGracePeriod),
+		statusThrottle:      association.NewStatusUpdateThrottle(params.AssociationStatusUpdateInterval),
+		startupJitter:       association.NewStartupJitter(params.AssociationStartupJitter, time.Now()),
+		pendingBackoff:      association.NewPendingBackoff(association.DefaultMaxPendingBackoff),
+		probe:               association.NewProbeConfig(params.AssociationProbeTimeout, params.AssociationProbeRetries),
+		credentialsVerdicts: association.NewProbeVerdicts(),
+		newESClient:         esclient.NewElasticsearchClient,
+		publisher:           association.NoopPublisher{},
+		ownerRefMode:        association.OwnerRefMode(params.AssociationOwnerRefMode),
+		encryptionPolicy:    association.EncryptionAtRestPolicy(params.AssociationEncryptedNamespaces),
+		globalCA:            association.GlobalCA{Reader: mgr.GetAPIReader(), Secret: params.AssociationGlobalCA},
+		Parameters:          params,
 	}
 }
 
-// add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) (controller.Controller, error) {
+// add adds a new Controller to mgr with r as the reconcile.Reconciler, reconciling up to maxConcurrentReconciles
+// associations concurrently. The work queue never hands the same Kibana to two workers at once.
+func add(mgr manager.Manager, r reconcile.Reconciler, maxConcurrentReconciles int) (controller.Controller, error) {
+	if maxConcurrentReconciles <= 0 {
+		maxConcurrentReconciles = 1
+	}
 	// Create a new controller
-	c, err := controller.New(name, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(name, mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: maxConcurrentReconciles})
 	if err != nil {
 		return c, err
 	}
@@ -113,30 +181,179 @@ var _ reconcile.Reconciler = &ReconcileAssociation{}
 // ReconcileAssociation reconciles a Kibana resource for association with Elasticsearch
 type ReconcileAssociation struct {
 	k8s.Client
+	// apiReader reads the aliases ConfigMap directly from the API server, the operator namespace may not be cached
+	apiReader      client.Reader
 	accessReviewer rbac.AccessReviewer
 	scheme         *runtime.Scheme
 	recorder       record.EventRecorder
 	watches        watches.DynamicWatches
+	// esCallsLimiter rate limits the Elasticsearch API calls performed for each association
+	esCallsLimiter *association.ESCallsLimiter
+	// failureGrace delays the transition of associations to the Failed status
+	failureGrace *association.FailureGracePeriod
+	// statusThrottle enforces a minimum interval between two status updates of an association
+	statusThrottle *association.StatusUpdateThrottle
+	// startupJitter spreads the initial reconciliations when the operator starts
+	startupJitter *association.StartupJitter
+	// pendingBackoff increases the delay between the reconciliations of associations which remain pending
+	pendingBackoff *association.PendingBackoff
+	// probe bounds the time spent probing Elasticsearch
+	probe association.ProbeConfig
+	// credentialsVerdicts holds the last verdict of the credentials probe of each association
+	credentialsVerdicts *association.ProbeVerdicts
+	newESClient         esClientFactory
+	// inventory exports the association state on status changes, if configured
+	inventory *association.InventoryExporter
+	// publisher publishes the association lifecycle events
+	publisher association.Publisher
+	// ownerRefMode defines how the resources derived from associations are owned and cleaned up
+	ownerRefMode association.OwnerRefMode
+	// encryptionPolicy lists the namespaces in which association secrets are encrypted at rest
+	encryptionPolicy association.EncryptionAtRestPolicy
+	// globalCA is the CA trusted by all associated resources, if configured
+	globalCA association.GlobalCA
+	// audit records the inputs and outputs of each reconciliation, if configured
+	audit *association.AuditLogger
+	// liveness tracks the running reconciliations for the liveness endpoint, if configured
+	liveness *association.ReconciliationLiveness
 	operator.Parameters
 	// iteration is the number of times this controller has run its Reconcile method
 	iteration uint64
 }
 
 func (r *ReconcileAssociation) onDelete(obj types.NamespacedName) error {
+	// Clean up the resources, each concern independently of the others
+	err := association.RunCleanup(obj,
+		association.CleanupStep{
+			Name: "derived-secrets",
+			// derived secrets may not be garbage collected through an owner reference
+			Run: func() error {
+				return association.DeleteDerivedSecrets(r.Client, r.ownerRefMode, obj.Namespace, NewResourceSelector(obj.Name))
+			},
+		},
+		association.CleanupStep{
+			Name: "external-credentials",
+			Run:  func() error { return r.deleteExternalCredentials(obj) },
+		},
+		association.CleanupStep{
+			Name: "es-user",
+			Run:  func() error { return user.DeleteUser(r.Client, NewUserLabelSelector(obj)) },
+		},
+	)
+	if err != nil {
+		// retried on the next reconciliation, already deleted resources are ignored
+		return err
+	}
+	// the inventory is told about the deletion even if the association is not tracked, it may have been exported
+	// before the operator restarted
+	if r.inventory != nil {
+		r.inventory.ExportDeletion(kibanaKind, obj)
+	}
+	// the deletion is published once, when the resources of an association still tracked in memory are cleaned up:
+	// the following reconciliations of the deleted Kibana find nothing to forget
+	tracked := r.isTracked(obj)
 	// Clean up memory
 	r.watches.ElasticsearchClusters.RemoveHandlerForKey(elasticsearchWatchName(obj))
+	r.watches.Secrets.RemoveHandlerForKey(elasticsearchWatchName(obj))
 	r.watches.Secrets.RemoveHandlerForKey(esCAWatchName(obj))
-	// Delete user
-	return user.DeleteUser(r.Client, NewUserLabelSelector(obj))
+	r.removeDependenciesWatches(obj)
+	r.esCallsLimiter.Forget(obj)
+	r.failureGrace.Forget(obj)
+	r.statusThrottle.Forget(obj)
+	r.startupJitter.Forget(obj)
+	r.pendingBackoff.Forget(obj)
+	association.ForgetStatus(kibanaKind, obj)
+	r.credentialsVerdicts.Forget(obj)
+	if !tracked {
+		return nil
+	}
+	association.PublishDeletion(r.publisher, kibanaKind, obj, time.Now())
+	log.Info("Association resources cleaned up", "iteration", atomic.LoadUint64(&r.iteration),
+		"namespace", obj.Namespace, "kibana_name", obj.Name)
+	return nil
+}
+
+// isTracked returns true if watches are registered for the association of the given Kibana, which is the case once
+// it has been reconciled until they are removed on deletion.
+func (r *ReconcileAssociation) isTracked(kibana types.NamespacedName) bool {
+	for _, w := range []struct {
+		watch *watches.DynamicEnqueueRequest
+		name  string
+	}{
+		{watch: r.watches.ElasticsearchClusters, name: elasticsearchWatchName(kibana)},
+		{watch: r.watches.Secrets, name: elasticsearchWatchName(kibana)},
+		{watch: r.watches.Secrets, name: esCAWatchName(kibana)},
+	} {
+		for _, registration := range w.watch.Registrations() {
+			if registration == w.name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deleteExternalCredentials deletes the credentials secret of the given Kibana association if it was created in a
+// dedicated credentials namespace, where it cannot be garbage collected through an owner reference.
+func (r *ReconcileAssociation) deleteExternalCredentials(kibana types.NamespacedName) error {
+	if r.AssociationCredentialsNamespace == "" || r.AssociationCredentialsNamespace == kibana.Namespace {
+		return nil
+	}
+	return association.DeleteSecrets(r.Client, r.AssociationCredentialsNamespace, NewCredentialsLabels(kibana))
 }
 
 // Reconcile reads that state of the cluster for an Association object and makes changes based on the state read and what is in
 // the Association.Spec
 func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	defer common.LogReconciliationRun(log, request, "kibana_name", &r.iteration)()
+	defer association.RecordReconciliationDuration(kibanaKind, request.Namespace, time.Now())
 	tx, ctx := tracing.NewTransaction(r.Tracer, request.NamespacedName, "kibana-association")
 	defer tracing.EndTransaction(tx)
 
+	// bound the whole reconciliation, so that a slow API server does not block the work queue
+	timeout := r.reconcileTimeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if r.liveness != nil {
+		r.liveness.Started(request.NamespacedName, time.Now())
+	}
+	result, err := r.withClient(r.Client.WithContext(ctx)).reconcile(ctx, request)
+	if r.liveness != nil {
+		r.liveness.Completed(request.NamespacedName, time.Now(), err)
+	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		log.Info("Reconciliation timed out, requeuing", "namespace", request.Namespace, "kibana_name", request.Name,
+			"timeout", timeout)
+		return r.requeue(), nil
+	}
+	return result, err
+}
+
+// reconcileTimeout returns the duration after which a reconciliation is aborted, which is the configured timeout or
+// DefaultReconcileTimeout if not set.
+func (r *ReconcileAssociation) reconcileTimeout() time.Duration {
+	if r.AssociationReconcileTimeout <= 0 {
+		return DefaultReconcileTimeout
+	}
+	return r.AssociationReconcileTimeout
+}
+
+// withClient returns a shallow copy of this reconciler performing its requests with the given client. The copy shares
+// the in-memory state of this reconciler, such as the dynamic watches.
+func (r *ReconcileAssociation) withClient(c k8s.Client) *ReconcileAssociation {
+	scoped := *r
+	scoped.Client = c
+	return &scoped
+}
+
+// reconcile reconciles the association of the Kibana resource targeted by the given request, performing its requests
+// within the given context.
+func (r *ReconcileAssociation) reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if delay := r.startupJitter.Delay(request.NamespacedName, time.Now()); delay > 0 {
+		log.V(1).Info("Delaying initial reconciliation", "namespace", request.Namespace, "kibana_name", request.Name, "delay", delay)
+		return reconcile.Result{Requeue: true, RequeueAfter: delay}, nil
+	}
+
 	var kibana kbv1.Kibana
 	if err := association.FetchWithAssociation(ctx, r.Client, request, &kibana); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -157,7 +374,14 @@ func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.R
 
 	if common.IsPaused(kibana.ObjectMeta) {
 		log.Info("Object is paused. Skipping reconciliation", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
-		return common.PauseRequeue, nil
+		return common.PauseRequeueFor(kibana.ObjectMeta), nil
+	}
+
+	if remaining, paused := association.PausedFor(kibana.ObjectMeta, time.Now()); paused {
+		log.Info("Association is paused. Skipping reconciliation", "namespace", kibana.Namespace, "kibana_name", kibana.Name,
+			"resume_in", remaining)
+		// resume as soon as the pause expires, changes to the annotation trigger a reconciliation anyway
+		return reconcile.Result{Requeue: true, RequeueAfter: remaining}, nil
 	}
 
 	compatible, err := r.isCompatible(ctx, &kibana)
@@ -166,11 +390,72 @@ func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.R
 	}
 
 	results := reconciler.NewResult(ctx)
-	newStatus, err := r.reconcileInternal(ctx, &kibana)
+	// used to detect whether the reconciliation modified the Kibana resource
+	resourceVersion := kibana.ResourceVersion
+	newStatus := associationStatus{observedGeneration: kibana.Generation}
+	newStatus.status, newStatus.message, newStatus.blockingDependency, err = r.reconcileDependencies(ctx, &kibana)
+	if err == nil && newStatus.status == commonv1.AssociationUnknown {
+		newStatus.confOwner, newStatus.status, newStatus.message = r.verifyConfOwner(&kibana)
+	}
+	if err == nil && newStatus.status == commonv1.AssociationUnknown {
+		newStatus.status, newStatus.message = r.verifyEncryptionAtRest(&kibana)
+	}
+	if err == nil && newStatus.status == commonv1.AssociationUnknown {
+		newStatus.status, newStatus.message, err = r.verifyElasticsearchVersion(&kibana)
+	}
+	if err == nil && newStatus.status == commonv1.AssociationUnknown && r.isDryRun(&kibana) {
+		newStatus.status, newStatus.message, newStatus.plannedChange, err = r.planAssociationConf(&kibana)
+	}
+	if err == nil && newStatus.status == commonv1.AssociationUnknown {
+		newStatus.status, newStatus.message, newStatus.deferredChange, err = r.deferRestart(&kibana, time.Now())
+	}
+	if err == nil && newStatus.deferredChange != nil {
+		// the user, the credentials and the CA are still reconciled while the configuration change is deferred
+		if status, reconcileErr := r.reconcileAssociation(ctx, &kibana, false); reconcileErr != nil || status != commonv1.AssociationEstablished {
+			newStatus.status, err = status, reconcileErr
+		}
+	}
+	if err == nil && newStatus.status == commonv1.AssociationUnknown {
+		newStatus.status, err = r.reconcileInternal(ctx, &kibana)
+	}
+	reconcileErr := err
 	if err != nil {
 		results.WithError(err)
 		k8s.EmitErrorEvent(r.recorder, err, &kibana, events.EventReconciliationError, "Reconciliation error: %v", err)
 	}
+	newStatus.lastError = association.LastError(kibana.Status.AssociationLastError, reconcileErr, time.Now())
+	newStatus.status = r.failureGrace.Apply(k8s.ExtractNamespacedName(&kibana), newStatus.status, time.Now())
+	if newStatus.status == commonv1.AssociationEstablished {
+		if override, isOverridden := urlOverride(&kibana); isOverridden && newStatus.deferredChange == nil {
+			newStatus.message = fmt.Sprintf("Elasticsearch URL overridden with %s", override)
+		}
+		newStatus.authMode = authMode(kibana.AssociationConf())
+		// the health of an external Elasticsearch cluster is not known
+		if kibana.Spec.ExternalElasticsearch == nil {
+			if newStatus.health, err = r.associationHealth(ctx, &kibana); err != nil {
+				results.WithError(err)
+			}
+			results.WithResult(r.esCallsRetryResult(k8s.ExtractNamespacedName(&kibana)))
+		}
+		if newStatus.caRotation, err = r.caRotationPhase(&kibana); err != nil {
+			results.WithError(err)
+		}
+	}
+
+	if newStatus.dependencies, err = r.observeDependencies(&kibana, time.Now()); err != nil {
+		results.WithError(err)
+	}
+	newStatus.conditions = r.observeConditions(&kibana, newStatus, time.Now())
+
+	if newStatus.status != commonv1.AssociationUnknown {
+		association.RecordReconciliation(kibanaKind, kibana.Namespace, newStatus.status)
+		if tenant := association.Tenant(&kibana); tenant != "" {
+			log.V(1).Info("Tenant association reconciled",
+				"namespace", kibana.Namespace, "kibana_name", kibana.Name, "tenant", tenant, "status", newStatus.status)
+		}
+		association.RecordStatus(kibanaKind, k8s.ExtractNamespacedName(&kibana), newStatus.status)
+	}
+	r.auditReconciliation(kibana, newStatus, kibana.ResourceVersion != resourceVersion, reconcileErr)
 
 	// maybe update status
 	if result, err := r.updateStatus(ctx, kibana, newStatus); err != nil || !reflect.DeepEqual(result, reconcile.Result{}) {
@@ -179,39 +464,450 @@ func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.R
 
 	return results.
 		WithResult(association.RequeueRbacCheck(r.accessReviewer)).
-		WithResult(resultFromStatus(newStatus)).
+		WithResult(r.dependentResultFromStatus(&kibana, newStatus)).
+		WithResult(r.credentialsRotationResult(&kibana)).
+		WithResult(caRotationResult(newStatus.caRotation)).
+		WithResult(deferralResult(&kibana, newStatus.deferredChange, time.Now())).
+		WithResult(aliasResult(&kibana)).
 		Aggregate()
 }
 
-func (r *ReconcileAssociation) updateStatus(ctx context.Context, kibana kbv1.Kibana, newStatus commonv1.AssociationStatus) (reconcile.Result, error) {
+// aliasResult returns the reconcile result requeuing the association if it references Elasticsearch through an alias.
+func aliasResult(kibana *kbv1.Kibana) reconcile.Result {
+	if association.ElasticsearchAlias(kibana) == "" {
+		return reconcile.Result{}
+	}
+	return aliasRequeue
+}
+
+// credentialsRotationResult returns the reconcile result requeuing the association when its credentials must be
+// rotated, if they have a TTL.
+func (r *ReconcileAssociation) credentialsRotationResult(kibana *kbv1.Kibana) reconcile.Result {
+	delay, err := association.CredentialsRotationDelay(r.Client, kibana, r.AssociationCredentialsNamespace, kibanaUserSuffix, time.Now())
+	if err != nil || delay == 0 {
+		// an invalid TTL is reported by the user reconciliation
+		return reconcile.Result{}
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: delay}
+}
+
+// resultFromStatus returns the reconcile result for the given association status, taking into account associations
+// which tolerate forward references to an Elasticsearch cluster not created yet.
+func (r *ReconcileAssociation) resultFromStatus(kibana *kbv1.Kibana, status commonv1.AssociationStatus) reconcile.Result {
+	requeue := r.pendingRequeue(k8s.ExtractNamespacedName(kibana), status)
+	if status != commonv1.AssociationPending || !isForwardReferenceTolerant(kibana) {
+		return resultFromStatus(status, requeue)
+	}
+	esRefKey, err := association.ElasticsearchRefKey(r.apiReader, kibana, r.OperatorNamespace)
+	if err != nil {
+		return resultFromStatus(status, requeue)
+	}
+	if err := r.Get(esRefKey, &esv1.Elasticsearch{}); apierrors.IsNotFound(err) {
+		log.V(1).Info("Referenced Elasticsearch does not exist yet", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
+		return forwardReferenceRequeue
+	}
+	return resultFromStatus(status, requeue)
+}
+
+// dependentResultFromStatus returns the reconcile result for the given association status. Associations waiting for a
+// dependency are not requeued, they are reconciled again when the watched dependency changes.
+func (r *ReconcileAssociation) dependentResultFromStatus(kibana *kbv1.Kibana, newStatus associationStatus) reconcile.Result {
+	if newStatus.blockingDependency != nil {
+		r.pendingBackoff.Forget(k8s.ExtractNamespacedName(kibana))
+		return reconcile.Result{}
+	}
+	return r.resultFromStatus(kibana, newStatus.status)
+}
+
+// pendingRequeue returns the result used to retry the reconciliation of the given association if it is pending or
+// degraded, backing off from the requeue interval while it remains so. Any other status resets the backoff.
+func (r *ReconcileAssociation) pendingRequeue(key types.NamespacedName, status commonv1.AssociationStatus) reconcile.Result {
+	delay := r.pendingBackoff.Next(key, status, r.requeue().RequeueAfter)
+	if delay == 0 {
+		return reconcile.Result{}
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: delay}
+}
+
+// requeue returns the result used to retry the reconciliation of an association, after the configured requeue
+// interval or DefaultRequeueInterval if not set.
+func (r *ReconcileAssociation) requeue() reconcile.Result {
+	if r.AssociationRequeueInterval <= 0 {
+		return defaultRequeue
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: r.AssociationRequeueInterval}
+}
+
+// isForwardReferenceTolerant returns true if the given Kibana is annotated as tolerating forward references.
+func isForwardReferenceTolerant(kibana *kbv1.Kibana) bool {
+	return kibana.Annotations[annotation.ForwardReferenceAnnotation] == "true"
+}
+
+// associationStatus is the part of the Kibana status maintained by this controller.
+type associationStatus struct {
+	status   commonv1.AssociationStatus
+	message  string
+	authMode commonv1.AssociationAuthMode
+	health   commonv1.AssociationHealth
+	// observedGeneration is the generation of Kibana the association was reconciled for
+	observedGeneration int64
+	// dependencies is the observed state of the objects the association depends on
+	dependencies []commonv1.AssociationDependency
+	// plannedChange is the change to the association configuration planned in dry-run mode
+	plannedChange string
+	// confOwner is who manages the Elasticsearch configuration
+	confOwner commonv1.AssociationConfOwner
+	// caRotation is the phase of the orchestrated rotation of the Elasticsearch CA
+	caRotation commonv1.AssociationCARotationPhase
+	// deferredChange describes the changes to the Elasticsearch configuration deferred until the next restart
+	deferredChange *commonv1.AssociationDeferredChange
+	// conditions details the state of the association
+	conditions []commonv1.AssociationCondition
+	// lastError is the error the reconciliation failed with, if any
+	lastError *commonv1.AssociationError
+	// blockingDependency is the watched dependency the association is waiting for, if any
+	blockingDependency *association.Dependency
+}
+
+// applyTo sets the association status into the given Kibana status, it returns true if the Kibana status was modified.
+func (s associationStatus) applyTo(kibanaStatus *kbv1.KibanaStatus) bool {
+	if kibanaStatus.AssociationStatus == s.status &&
+		kibanaStatus.AssociationMessage == s.message &&
+		kibanaStatus.AssociationObservedGeneration == s.observedGeneration &&
+		kibanaStatus.AssociationAuthMode == s.authMode &&
+		kibanaStatus.AssociationHealth == s.health &&
+		reflect.DeepEqual(kibanaStatus.AssociationDependencies, s.dependencies) &&
+		kibanaStatus.AssociationPlannedChange == s.plannedChange &&
+		kibanaStatus.AssociationConfOwner == s.confOwner &&
+		kibanaStatus.AssociationCARotation == s.caRotation &&
+		reflect.DeepEqual(kibanaStatus.AssociationDeferredChange, s.deferredChange) &&
+		reflect.DeepEqual(kibanaStatus.AssociationConditions, s.conditions) &&
+		reflect.DeepEqual(kibanaStatus.AssociationLastError, s.lastError) {
+		return false
+	}
+	kibanaStatus.AssociationStatus = s.status
+	kibanaStatus.AssociationMessage = s.message
+	kibanaStatus.AssociationObservedGeneration = s.observedGeneration
+	kibanaStatus.AssociationAuthMode = s.authMode
+	kibanaStatus.AssociationHealth = s.health
+	kibanaStatus.AssociationDependencies = s.dependencies
+	kibanaStatus.AssociationPlannedChange = s.plannedChange
+	kibanaStatus.AssociationConfOwner = s.confOwner
+	kibanaStatus.AssociationCARotation = s.caRotation
+	kibanaStatus.AssociationDeferredChange = s.deferredChange
+	kibanaStatus.AssociationConditions = s.conditions
+	kibanaStatus.AssociationLastError = s.lastError
+	return true
+}
+
+// associationHealth computes the health of an established association from the health of the Elasticsearch cluster,
+// the health of Kibana and the validity of the credentials, which are probed against Elasticsearch if they exist.
+func (r *ReconcileAssociation) associationHealth(ctx context.Context, kibana *kbv1.Kibana) (commonv1.AssociationHealth, error) {
+	span, _ := apm.StartSpan(ctx, "association_health", tracing.SpanTypeApp)
+	defer span.End()
+
+	esRefKey, err := association.ElasticsearchRefKey(r.apiReader, kibana, r.OperatorNamespace)
+	if err != nil {
+		return commonv1.AssociationHealthRed, err
+	}
+	var es esv1.Elasticsearch
+	if err := r.Get(esRefKey, &es); err != nil {
+		if apierrors.IsNotFound(err) {
+			return commonv1.AssociationHealthRed, nil
+		}
+		return commonv1.AssociationHealthRed, err
+	}
+	credentialsValid, err := hasValidCredentials(r.Client, kibana)
+	if err != nil {
+		return commonv1.AssociationHealthRed, err
+	}
+	if credentialsValid {
+		if credentialsValid, err = r.probeCredentials(ctx, kibana, es); err != nil {
+			return commonv1.AssociationHealthRed, err
+		}
+	}
+	return summarizeHealth(es.Status.Health, kibana.Status.Health, credentialsValid), nil
+}
+
+// hasValidCredentials returns true if the credentials Kibana uses to authenticate against Elasticsearch exist.
+func hasValidCredentials(c k8s.Client, kibana *kbv1.Kibana) (bool, error) {
+	conf := kibana.AssociationConf()
+	if !conf.AuthIsConfigured() {
+		return false, nil
+	}
+	var secret corev1.Secret
+	if err := c.Get(conf.AuthSecretRef(kibana.Namespace), &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(secret.Data[conf.GetAuthSecretKey()]) > 0, nil
+}
+
+// summarizeHealth combines the health signals of an association into a single health.
+func summarizeHealth(esHealth esv1.ElasticsearchHealth, kibanaHealth kbv1.KibanaHealth, credentialsValid bool) commonv1.AssociationHealth {
+	switch {
+	case !credentialsValid, esHealth == esv1.ElasticsearchRedHealth, kibanaHealth != kbv1.KibanaGreen:
+		return commonv1.AssociationHealthRed
+	case esHealth != esv1.ElasticsearchGreenHealth:
+		return commonv1.AssociationHealthYellow
+	default:
+		return commonv1.AssociationHealthGreen
+	}
+}
+
+// authMode returns the mode used by Kibana to authenticate against Elasticsearch with the given association configuration.
+func authMode(conf *commonv1.AssociationConf) commonv1.AssociationAuthMode {
+	if !conf.AuthIsConfigured() {
+		return ""
+	}
+	return commonv1.AssociationAuthSecretRef
+}
+
+func (r *ReconcileAssociation) updateStatus(ctx context.Context, kibana kbv1.Kibana, newStatus associationStatus) (reconcile.Result, error) {
 	span, _ := apm.StartSpan(ctx, "update_status", tracing.SpanTypeApp)
 	defer span.End()
 
-	if !reflect.DeepEqual(kibana.Status.AssociationStatus, newStatus) {
-		oldStatus := kibana.Status.AssociationStatus
-		kibana.Status.AssociationStatus = newStatus
+	oldStatus := kibana.Status.AssociationStatus
+	if newStatus.applyTo(&kibana.Status) {
+		kibanaKey := k8s.ExtractNamespacedName(&kibana)
+		if delay := r.statusThrottle.Delay(kibanaKey, time.Now()); delay > 0 {
+			log.V(1).Info("Deferring association status update", "namespace", kibana.Namespace, "kibana_name", kibana.Name, "delay", delay)
+			return reconcile.Result{Requeue: true, RequeueAfter: delay}, nil
+		}
 		if err := r.Status().Update(&kibana); err != nil {
 			if apierrors.IsConflict(err) {
 				// Conflicts are expected and will be resolved on next loop
 				log.V(1).Info("Conflict while updating status", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
 				return reconcile.Result{Requeue: true}, nil
 			}
-
-			return defaultRequeue, err
+			if r.AssociationTolerateStatusUpdateFailures {
+				// the association itself is reconciled, only its status is not up to date yet
+				log.Error(err, "Failed to update association status, requeuing", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
+				return r.requeue(), nil
+			}
+			return r.requeue(), err
+		}
+		r.statusThrottle.Updated(kibanaKey, time.Now())
+		r.exportToInventory(kibana, newStatus)
+		association.PublishLifecycleEvents(r.publisher, kibanaKind, kibanaKey, oldStatus, newStatus.status, time.Now())
+		if oldStatus != newStatus.status {
+			r.recorder.AnnotatedEventf(&kibana,
+				association.WithTenant(annotation.ForAssociationStatusChange(oldStatus, newStatus.status), association.Tenant(&kibana)),
+				corev1.EventTypeNormal,
+				events.EventAssociationStatusChange,
+				"Association status changed from [%s] to [%s]", oldStatus, newStatus.status)
 		}
-		r.recorder.AnnotatedEventf(&kibana,
-			annotation.ForAssociationStatusChange(oldStatus, newStatus),
-			corev1.EventTypeNormal,
-			events.EventAssociationStatusChange,
-			"Association status changed from [%s] to [%s]", oldStatus, newStatus)
 	}
 	return reconcile.Result{}, nil
 }
 
-func resultFromStatus(status commonv1.AssociationStatus) reconcile.Result {
+// exportToInventory sends the state of the given association to the inventory, if configured.
+func (r *ReconcileAssociation) exportToInventory(kibana kbv1.Kibana, status associationStatus) {
+	if r.inventory == nil {
+		return
+	}
+	record := association.InventoryRecord{
+		Kind:      kibanaKind,
+		Namespace: kibana.Namespace,
+		Name:      kibana.Name,
+		URL:       kbctl.ServiceURL(kibana),
+		Status:    status.status,
+	}
+	if esRefKey, err := association.ElasticsearchRefKey(r.apiReader, &kibana, r.OperatorNamespace); err == nil {
+		record.Elasticsearch.Namespace = esRefKey.Namespace
+		record.Elasticsearch.Name = esRefKey.Name
+	}
+	if kibana.AssociationConf() != nil {
+		record.Elasticsearch.URL = kibana.AssociationConf().GetURL()
+	}
+	r.inventory.Export(record)
+}
+
+// recordTransition records an event for a transition of the association of the given Kibana involving the given
+// dependency. The event is annotated with the current iteration, to correlate it with the reconciliation logs.
+func (r *ReconcileAssociation) recordTransition(
+	kibana *kbv1.Kibana,
+	eventType, reason string,
+	dependency types.NamespacedName,
+	messageFmt string,
+	args ...interface{},
+) {
+	annotations := annotation.ForAssociationTransition(atomic.LoadUint64(&r.iteration), dependency)
+	r.recorder.AnnotatedEventf(kibana, association.WithTenant(annotations, association.Tenant(kibana)), eventType, reason, messageFmt, args...)
+}
+
+// auditReconciliation records the outcome of the reconciliation of the given Kibana in the audit log, if configured.
+func (r *ReconcileAssociation) auditReconciliation(kibana kbv1.Kibana, status associationStatus, updated bool, err error) {
+	if r.audit == nil {
+		return
+	}
+	record := association.AuditRecord{
+		Time:         time.Now(),
+		Kind:         kibanaKind,
+		Namespace:    kibana.Namespace,
+		Name:         kibana.Name,
+		Dependencies: status.dependencies,
+		Status:       status.status,
+		Message:      status.message,
+		Updated:      updated,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	r.audit.Log(record)
+}
+
+// reconcileDependencies checks that the associations this Kibana association depends on are established.
+// It returns a Pending status along with a message describing the blocking dependency if this is not the case,
+// a Failed status if the dependencies are circular, or an unknown status if the association can be reconciled.
+// The dependencies are watched, so that their changes trigger the reconciliation of this association.
+func (r *ReconcileAssociation) reconcileDependencies(ctx context.Context, kibana *kbv1.Kibana) (commonv1.AssociationStatus, string, *association.Dependency, error) {
+	span, _ := apm.StartSpan(ctx, "reconcile_dependencies", tracing.SpanTypeApp)
+	defer span.End()
+
+	kibanaKey := k8s.ExtractNamespacedName(kibana)
+	if !kibana.Spec.ElasticsearchRef.IsDefined() {
+		r.removeDependenciesWatches(kibanaKey)
+		return commonv1.AssociationUnknown, "", nil, nil
+	}
+	dependencies, err := association.Dependencies(kibana)
+	if err != nil {
+		return commonv1.AssociationFailed, "", nil, err
+	}
+	if err := r.setDependenciesWatches(kibanaKey, dependencies); err != nil {
+		return commonv1.AssociationFailed, "", nil, err
+	}
+	if len(dependencies) == 0 {
+		return commonv1.AssociationUnknown, "", nil, nil
+	}
+	cycle, err := association.DependencyCycle(r.Client, association.Dependency{Kind: kibanaKind, NamespacedName: kibanaKey})
+	if err != nil {
+		return commonv1.AssociationFailed, "", nil, err
+	}
+	if cycle != nil {
+		chain := make([]string, len(cycle))
+		for i := range cycle {
+			chain[i] = cycle[i].String()
+		}
+		message := fmt.Sprintf("Circular association dependency: %s", strings.Join(chain, " -> "))
+		r.recorder.Event(kibana, corev1.EventTypeWarning, events.EventAssociationError, message)
+		return commonv1.AssociationFailed, message, nil, nil
+	}
+	blocking, err := association.BlockingDependency(r.Client, kibana)
+	if err != nil {
+		return commonv1.AssociationFailed, "", nil, err
+	}
+	if blocking != nil {
+		log.V(1).Info("Association dependency not established yet", "namespace", kibana.Namespace, "kibana_name", kibana.Name, "dependency", blocking.String())
+		return commonv1.AssociationPending, fmt.Sprintf("Waiting for association %s to be established", blocking), blocking, nil
+	}
+	return commonv1.AssociationUnknown, "", nil, nil
+}
+
+// setDependenciesWatches watches the given dependencies of the given Kibana association, replacing the previous ones.
+func (r *ReconcileAssociation) setDependenciesWatches(kibanaKey types.NamespacedName, dependencies []association.Dependency) error {
+	var kibanas, apmServers []types.NamespacedName
+	for _, dependency := range dependencies {
+		switch dependency.Kind {
+		case kibanaKind:
+			kibanas = append(kibanas, dependency.NamespacedName)
+		default:
+			// the only other kind of dependency
+			apmServers = append(apmServers, dependency.NamespacedName)
+		}
+	}
+	for _, w := range []struct {
+		watch   *watches.DynamicEnqueueRequest
+		watched []types.NamespacedName
+	}{
+		{watch: r.watches.Kibanas, watched: kibanas},
+		{watch: r.watches.ApmServers, watched: apmServers},
+	} {
+		if len(w.watched) == 0 {
+			w.watch.RemoveHandlerForKey(dependenciesWatchName(kibanaKey))
+			continue
+		}
+		if err := w.watch.AddHandler(watches.NamedWatch{
+			Name:    dependenciesWatchName(kibanaKey),
+			Watched: w.watched,
+			Watcher: kibanaKey,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeDependenciesWatches stops watching the dependencies of the given Kibana association.
+func (r *ReconcileAssociation) removeDependenciesWatches(kibanaKey types.NamespacedName) {
+	r.watches.Kibanas.RemoveHandlerForKey(dependenciesWatchName(kibanaKey))
+	r.watches.ApmServers.RemoveHandlerForKey(dependenciesWatchName(kibanaKey))
+}
+
+// verifyEncryptionAtRest checks that the namespaces in which the association secrets are written are encrypted at
+// rest according to the configured policy. It returns a Failed status along with a message listing the offending
+// namespaces if this is not the case, or an unknown status if the association can be reconciled.
+func (r *ReconcileAssociation) verifyEncryptionAtRest(kibana *kbv1.Kibana) (commonv1.AssociationStatus, string) {
+	if !r.encryptionPolicy.IsEnabled() || !kibana.Spec.ElasticsearchRef.IsDefined() {
+		return commonv1.AssociationUnknown, ""
+	}
+	esRefKey, err := association.ElasticsearchRefKey(r.apiReader, kibana, r.OperatorNamespace)
+	if err != nil {
+		// reported by reconcileInternal
+		return commonv1.AssociationUnknown, ""
+	}
+	// CA copy in the Kibana namespace, credentials in the credentials namespace, user in the Elasticsearch namespace
+	credentialsNamespace := kibana.Namespace
+	if r.AssociationCredentialsNamespace != "" {
+		credentialsNamespace = r.AssociationCredentialsNamespace
+	}
+	unencrypted := r.encryptionPolicy.Unencrypted(kibana.Namespace, credentialsNamespace, esRefKey.Namespace)
+	if len(unencrypted) == 0 {
+		return commonv1.AssociationUnknown, ""
+	}
+	message := fmt.Sprintf("Secrets are not encrypted at rest in namespaces %s", strings.Join(unencrypted, ", "))
+	r.recorder.Event(kibana, corev1.EventTypeWarning, events.EventAssociationError, message)
+	return commonv1.AssociationFailed, message
+}
+
+// verifyElasticsearchVersion checks that the version of the referenced Elasticsearch cluster is at least the configured
+// minimum version. It returns a Failed status along with a message if this is not the case, or an unknown status if the
+// association can be reconciled.
+func (r *ReconcileAssociation) verifyElasticsearchVersion(kibana *kbv1.Kibana) (commonv1.AssociationStatus, string, error) {
+	if r.AssociationMinESVersion == nil || !kibana.Spec.ElasticsearchRef.IsDefined() {
+		return commonv1.AssociationUnknown, "", nil
+	}
+	esRefKey, err := association.ElasticsearchRefKey(r.apiReader, kibana, r.OperatorNamespace)
+	if err != nil {
+		// reported by reconcileInternal
+		return commonv1.AssociationUnknown, "", nil
+	}
+	var es esv1.Elasticsearch
+	if err := r.Get(esRefKey, &es); err != nil {
+		if apierrors.IsNotFound(err) {
+			// reported by reconcileInternal
+			return commonv1.AssociationUnknown, "", nil
+		}
+		return commonv1.AssociationPending, "", err
+	}
+	esVersion, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		return commonv1.AssociationFailed, fmt.Sprintf("Invalid Elasticsearch version %s", es.Spec.Version), nil
+	}
+	if !esVersion.IsSameOrAfter(*r.AssociationMinESVersion) {
+		message := fmt.Sprintf("Elasticsearch version %s is older than the minimum supported version %s", esVersion, r.AssociationMinESVersion)
+		r.recorder.Event(kibana, corev1.EventTypeWarning, events.EventAssociationError, message)
+		return commonv1.AssociationFailed, message, nil
+	}
+	return commonv1.AssociationUnknown, "", nil
+}
+
+func resultFromStatus(status commonv1.AssociationStatus, requeue reconcile.Result) reconcile.Result {
 	switch status {
-	case commonv1.AssociationPending:
-		return defaultRequeue // retry
+	case commonv1.AssociationPending, commonv1.AssociationDegraded:
+		return requeue // retry
 	default:
 		return reconcile.Result{} // we are done or there is not much we can do
 	}
@@ -227,28 +923,61 @@ func (r *ReconcileAssociation) isCompatible(ctx context.Context, kibana *kbv1.Ki
 	return compat, err
 }
 
+// reconcileInternal reconciles the association of the given Kibana with Elasticsearch.
 func (r *ReconcileAssociation) reconcileInternal(ctx context.Context, kibana *kbv1.Kibana) (commonv1.AssociationStatus, error) {
+	return r.reconcileAssociation(ctx, kibana, true)
+}
+
+// reconcileAssociation reconciles the association of the given Kibana with Elasticsearch, updating its association
+// configuration only if applyConf is true. It returns an Established status without updating it if the rest of the
+// association is reconciled.
+func (r *ReconcileAssociation) reconcileAssociation(ctx context.Context, kibana *kbv1.Kibana, applyConf bool) (commonv1.AssociationStatus, error) {
 	kibanaKey := k8s.ExtractNamespacedName(kibana)
+	if kibana.Spec.ExternalElasticsearch != nil && kibana.Spec.ElasticsearchRef.IsDefined() {
+		r.recorder.Event(kibana, corev1.EventTypeWarning, events.EventAssociationError,
+			"Elasticsearch reference and external Elasticsearch are mutually exclusive")
+		return commonv1.AssociationFailed, nil
+	}
+	// resolve the referenced ES cluster, possibly through an alias
+	esRefKey, err := association.ElasticsearchRefKey(r.apiReader, kibana, r.OperatorNamespace)
+	if err != nil && kibana.Spec.ElasticsearchRef.IsDefined() {
+		if association.IsAliasNotResolved(err) {
+			// the alias may be defined later on, the aliases ConfigMap is watched
+			k8s.EmitErrorEvent(r.recorder, err, kibana, events.EventAssociationError, "Failed to resolve Elasticsearch alias: %v", err)
+			return commonv1.AssociationPending, nil
+		}
+		// the aliases ConfigMap could not be retrieved
+		return commonv1.AssociationDegraded, err
+	}
+
 	// garbage collect leftover resources that are not required anymore
-	if err := deleteOrphanedResources(ctx, r, kibana); err != nil {
+	if err := deleteOrphanedResources(ctx, r, kibana, esRefKey.Namespace); err != nil {
 		log.Error(err, "Error while trying to delete orphaned resources. Continuing.", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
 	}
 
+	if kibana.Spec.ExternalElasticsearch != nil {
+		return r.reconcileExternalElasticsearch(ctx, kibana)
+	}
+
 	if kibana.Spec.ElasticsearchRef.Name == "" {
 		// stop watching any ES cluster previously referenced for this Kibana resource
 		r.watches.ElasticsearchClusters.RemoveHandlerForKey(elasticsearchWatchName(kibanaKey))
+		r.watches.Secrets.RemoveHandlerForKey(elasticsearchWatchName(kibanaKey))
+		r.watches.Secrets.RemoveHandlerForKey(esCAWatchName(kibanaKey))
+		// credentials created outside of the Kibana namespace are not garbage collected
+		if err := r.deleteExternalCredentials(kibanaKey); err != nil {
+			return commonv1.AssociationUnknown, err
+		}
+		if err := r.removeAssociationConf(kibana); err != nil {
+			return commonv1.AssociationUnknown, err
+		}
+		if err := association.RemoveProvenance(r.Client, kibana); err != nil && !errors.IsConflict(err) {
+			return commonv1.AssociationUnknown, err
+		}
 		// other leftover resources are already garbage-collected
 		return commonv1.AssociationUnknown, nil
 	}
 
-	// this Kibana instance references an Elasticsearch cluster
-	esRef := kibana.Spec.ElasticsearchRef
-	if esRef.Namespace == "" {
-		// no namespace provided: default to Kibana's namespace
-		esRef.Namespace = kibana.Namespace
-	}
-	esRefKey := esRef.NamespacedName()
-
 	// watch the referenced ES cluster for future reconciliations
 	if err := r.watches.ElasticsearchClusters.AddHandler(watches.NamedWatch{
 		Name:    elasticsearchWatchName(kibanaKey),
@@ -258,7 +987,7 @@ func (r *ReconcileAssociation) reconcileInternal(ctx context.Context, kibana *kb
 		return commonv1.AssociationFailed, err
 	}
 
-	userSecretKey := association.UserKey(kibana, kibanaUserSuffix)
+	userSecretKey := association.UserKey(kibana, esRefKey.Namespace, kibanaUserSuffix)
 	// watch the user secret in the ES namespace
 	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
 		Name:    elasticsearchWatchName(kibanaKey),
@@ -284,18 +1013,37 @@ func (r *ReconcileAssociation) reconcileInternal(ctx context.Context, kibana *kb
 		return commonv1.AssociationPending, err
 	}
 
+	if es.Spec.ScaledToZero() {
+		// no node to connect to: keep the current configuration until Elasticsearch is scaled up again, which
+		// triggers a reconciliation through the Elasticsearch watch
+		r.recordTransition(kibana, corev1.EventTypeWarning, events.EventAssociationError, esRefKey,
+			"Referenced Elasticsearch %s scaled to zero", esRefKey)
+		return commonv1.AssociationPending, nil
+	}
+
+	if !association.HasMinESHealth(es.Status.Health, r.AssociationMinESHealth) {
+		// health changes are reported in the Elasticsearch status, which triggers a reconciliation
+		health := es.Status.Health
+		if health == "" {
+			health = esv1.ElasticsearchUnknownHealth
+		}
+		r.recordTransition(kibana, corev1.EventTypeWarning, events.EventAssociationError, esRefKey,
+			"Referenced Elasticsearch %s health is %s, at least %s is required", esRefKey, health, r.AssociationMinESHealth)
+		return commonv1.AssociationPending, nil
+	}
+
 	if err := association.ReconcileEsUser(
 		ctx,
 		r.Client,
 		r.scheme,
 		kibana,
-		map[string]string{
-			AssociationLabelName:      kibana.Name,
-			AssociationLabelNamespace: kibana.Namespace,
-		},
+		association.WithTenant(NewCredentialsLabels(kibanaKey), association.Tenant(kibana)),
 		elasticsearchuser.KibanaSystemUserBuiltinRole,
 		kibanaUserSuffix,
-		es); err != nil {
+		es,
+		r.ownerRefMode,
+		r.AssociationCredentialsNamespace,
+	); err != nil {
 		return commonv1.AssociationPending, err
 	}
 
@@ -303,28 +1051,177 @@ func (r *ReconcileAssociation) reconcileInternal(ctx context.Context, kibana *kb
 	if err != nil {
 		return commonv1.AssociationPending, err
 	}
+	if !caSecret.CACertProvided {
+		// the certificates are probably not created yet
+		r.recordTransition(kibana, corev1.EventTypeWarning, events.EventAssociationError, association.CAWatchedSecrets(esRefKey, r.globalCA)[0],
+			"Elasticsearch CA not ready, proceeding without it")
+	}
+
+	esURL, err := elasticsearchURL(kibana, es)
+	if err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, kibana, events.EventAssociationError, "Invalid Elasticsearch URL override: %v", err)
+		return commonv1.AssociationFailed, nil
+	}
+	verificationMode, err := sslVerificationMode(kibana)
+	if err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, kibana, events.EventAssociationError, "Invalid Elasticsearch SSL verification mode: %v", err)
+		return commonv1.AssociationFailed, nil
+	}
 
-	// construct the expected association configuration
-	authSecret := association.ClearTextSecretKeySelector(kibana, kibanaUserSuffix)
-	expectedESAssoc := &commonv1.AssociationConf{
-		AuthSecretName: authSecret.Name,
-		AuthSecretKey:  authSecret.Key,
-		CACertProvided: caSecret.CACertProvided,
-		CASecretName:   caSecret.Name,
-		URL:            services.ExternalServiceURL(es),
+	if !applyConf {
+		// Kibana keeps its current configuration
+		return commonv1.AssociationEstablished, nil
 	}
 
 	// update the association configuration if necessary
-	return r.updateAssociationConf(ctx, expectedESAssoc, kibana)
+	expected := r.expectedAssociationConf(kibana, caSecret, esURL, verificationMode)
+	status, err = r.updateAssociationConf(ctx, expected, kibana, esRefKey)
+	if status != commonv1.AssociationEstablished || err != nil {
+		return status, err
+	}
+
+	// record the resources the association has been established with
+	if err := association.UpdateProvenance(r.Client, kibana, association.Provenance{
+		ElasticsearchUID:        es.UID,
+		CASecretResourceVersion: caSecret.ResourceVersion,
+	}, time.Now()); err != nil {
+		if errors.IsConflict(err) {
+			return commonv1.AssociationPending, nil
+		}
+		return commonv1.AssociationPending, err
+	}
+	return status, nil
+}
+
+// expectedAssociationConf returns the association configuration of the given Kibana, for the given copy of the
+// Elasticsearch CA, Elasticsearch URL and SSL verification mode.
+func (r *ReconcileAssociation) expectedAssociationConf(
+	kibana *kbv1.Kibana,
+	caSecret association.CASecret,
+	esURL string,
+	verificationMode string,
+) *commonv1.AssociationConf {
+	authSecret := association.ClearTextSecretKeySelector(kibana, r.AssociationCredentialsNamespace, kibanaUserSuffix)
+	conf := &commonv1.AssociationConf{
+		AuthSecretName:      authSecret.Name,
+		AuthSecretKey:       authSecret.Key,
+		CACertProvided:      caSecret.CACertProvided,
+		CASecretName:        caSecret.Name,
+		URL:                 esURL,
+		SSLVerificationMode: verificationMode,
+	}
+	if r.AssociationCredentialsNamespace != kibana.Namespace {
+		conf.AuthSecretNamespace = r.AssociationCredentialsNamespace
+	}
+	return conf
+}
+
+// verifyConfOwner determines who manages the Elasticsearch configuration of the given Kibana, and records an event when
+// the ownership is transferred. The reconciliation proceeds while the association controller owns the configuration.
+// Once released to manual management, the configuration is left as it is and the association is considered established
+// as long as it is configured. Claiming the ownership back overwrites the configuration on the next reconciliation.
+func (r *ReconcileAssociation) verifyConfOwner(kibana *kbv1.Kibana) (commonv1.AssociationConfOwner, commonv1.AssociationStatus, string) {
+	if !kibana.Spec.ElasticsearchRef.IsDefined() {
+		return "", commonv1.AssociationUnknown, ""
+	}
+	owner, err := association.ConfOwner(kibana.ObjectMeta)
+	if err != nil {
+		r.recorder.Event(kibana, corev1.EventTypeWarning, events.EventAssociationError, err.Error())
+		return "", commonv1.AssociationFailed, err.Error()
+	}
+	if previous := kibana.Status.AssociationConfOwner; previous != "" && previous != owner {
+		r.recorder.Eventf(kibana, corev1.EventTypeNormal, events.EventReasonStateChange,
+			"Elasticsearch configuration ownership transferred from %s to %s", previous, owner)
+	}
+	if owner == commonv1.AssociationConfOwnerOperator {
+		return owner, commonv1.AssociationUnknown, ""
+	}
+	if !kibana.AssociationConf().IsConfigured() {
+		return owner, commonv1.AssociationFailed, "Elasticsearch configuration released to manual management but not configured"
+	}
+	return owner, commonv1.AssociationEstablished, "Elasticsearch configuration managed manually"
 }
 
-func (r *ReconcileAssociation) updateAssociationConf(ctx context.Context, expectedESAssoc *commonv1.AssociationConf, kibana *kbv1.Kibana) (commonv1.AssociationStatus, error) {
+// isDryRun returns true if the association of the given Kibana runs in dry-run mode, because it is annotated to or all
+// associations do.
+func (r *ReconcileAssociation) isDryRun(kibana *kbv1.Kibana) bool {
+	return r.AssociationDryRun || kibana.Annotations[annotation.DryRunAnnotation] == "true"
+}
+
+// planAssociationConf computes the association configuration the reconciliation would apply to the given Kibana,
+// without creating or modifying any resource. It returns a Pending status along with a message and the planned change,
+// since the association is not maintained by the operator in dry-run mode.
+func (r *ReconcileAssociation) planAssociationConf(kibana *kbv1.Kibana) (commonv1.AssociationStatus, string, string, error) {
+	expected, message, err := r.plannedAssociationConf(kibana)
+	if err != nil || message != "" {
+		return commonv1.AssociationPending, message, "", err
+	}
+	change := association.PlannedChange(kibana.AssociationConf(), expected, kibana.Namespace)
+	if change == "" {
+		return commonv1.AssociationPending, "Dry run: no change planned", "", nil
+	}
+	return commonv1.AssociationPending, "Dry run: change planned to the Elasticsearch configuration", change, nil
+}
+
+// plannedAssociationConf returns the association configuration the reconciliation would converge to, which is nil if
+// the association configuration would be removed. It returns a message instead if it cannot be determined.
+func (r *ReconcileAssociation) plannedAssociationConf(kibana *kbv1.Kibana) (*commonv1.AssociationConf, string, error) {
+	verificationMode, err := sslVerificationMode(kibana)
+	if err != nil {
+		return nil, fmt.Sprintf("Dry run: invalid Elasticsearch SSL verification mode: %v", err), nil
+	}
+	if kibana.Spec.ExternalElasticsearch != nil && !kibana.Spec.ElasticsearchRef.IsDefined() {
+		return externalAssociationConf(kibana.Spec.ExternalElasticsearch, verificationMode), "", nil
+	}
+	if !kibana.Spec.ElasticsearchRef.IsDefined() {
+		return nil, "", nil
+	}
+	esRefKey, err := association.ElasticsearchRefKey(r.apiReader, kibana, r.OperatorNamespace)
+	if err != nil {
+		return nil, fmt.Sprintf("Dry run: cannot resolve the Elasticsearch reference: %v", err), nil
+	}
+	var es esv1.Elasticsearch
+	if err := r.Get(esRefKey, &es); err != nil {
+		if apierrors.IsNotFound(err) {
+			// the association configuration is removed while Elasticsearch does not exist
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	var caSecret association.CASecret
+	if name, isOverridden := caOverride(kibana); isOverridden {
+		caSecret, err = overriddenCASecret(r.Client, kibana, name)
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Sprintf("Dry run: CA secret %s not found", name), nil
+		}
+	} else {
+		caSecret, err = association.PlanCASecret(r.Client, kibana, esRefKey, ElasticsearchCASecretSuffix, r.globalCA)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	esURL, err := elasticsearchURL(kibana, es)
+	if err != nil {
+		return nil, fmt.Sprintf("Dry run: invalid Elasticsearch URL override: %v", err), nil
+	}
+	return r.expectedAssociationConf(kibana, caSecret, esURL, verificationMode), "", nil
+}
+
+func (r *ReconcileAssociation) updateAssociationConf(
+	ctx context.Context,
+	expectedESAssoc *commonv1.AssociationConf,
+	kibana *kbv1.Kibana,
+	esRefKey types.NamespacedName,
+) (commonv1.AssociationStatus, error) {
 	span, _ := apm.StartSpan(ctx, "update_assoc_conf", tracing.SpanTypeApp)
 	defer span.End()
 
-	if !reflect.DeepEqual(expectedESAssoc, kibana.AssociationConf()) {
+	if !expectedESAssoc.Equivalent(kibana.AssociationConf(), kibana.Namespace) {
+		previousConf := kibana.AssociationConf()
 		log.Info("Updating Kibana spec with Elasticsearch backend configuration", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
-		if err := association.UpdateAssociationConf(r.Client, kibana, expectedESAssoc); err != nil {
+		log.V(1).Info("Elasticsearch backend configuration diff", "namespace", kibana.Namespace, "kibana_name", kibana.Name,
+			"diff", associationConfDiff(previousConf, expectedESAssoc))
+		if err := r.persistAssociationConf(kibana, expectedESAssoc); err != nil {
 			if errors.IsConflict(err) {
 				return commonv1.AssociationPending, nil
 			}
@@ -332,10 +1229,165 @@ func (r *ReconcileAssociation) updateAssociationConf(ctx context.Context, expect
 			return commonv1.AssociationPending, err
 		}
 		kibana.SetAssociationConf(expectedESAssoc)
+		r.recordTransition(kibana, corev1.EventTypeNormal, events.EventAssociationConfigured, esRefKey,
+			"Elasticsearch configuration updated, using %s", expectedESAssoc.GetURL())
+		// Kibana now relies on the new credentials, the previous ones can be removed
+		if err := deletePreviousCredentials(r.Client, kibana, previousConf); err != nil {
+			return commonv1.AssociationPending, err
+		}
 	}
 	return commonv1.AssociationEstablished, nil
 }
 
+// persistAssociationConf sets the given association configuration on the given Kibana. The Kibana resource is also
+// updated by other writers, such as the Kibana controller: a conflict is retried with the latest Kibana resource, as
+// long as its spec the configuration was computed from did not change in the meantime. Scaling Kibana does not affect
+// the configuration: it is still retried in that case.
+func (r *ReconcileAssociation) persistAssociationConf(kibana *kbv1.Kibana, conf *commonv1.AssociationConf) error {
+	key := k8s.ExtractNamespacedName(kibana)
+	generation := kibana.Generation
+	retried := false
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if retried {
+			var latest kbv1.Kibana
+			if err := r.Get(key, &latest); err != nil {
+				return err
+			}
+			if latest.Generation != generation && !onlyScaled(kibana.Spec, latest.Spec) {
+				// the configuration may not be the expected one anymore, the next reconciliation computes it again
+				return errors.NewConflict(kbv1.GroupVersion.WithResource("kibanas").GroupResource(), kibana.Name,
+					fmt.Errorf("spec updated from generation %d to %d", generation, latest.Generation))
+			}
+			log.V(1).Info("Conflict while updating association configuration, retrying", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
+			latest.SetAssociationConf(kibana.AssociationConf())
+			*kibana = latest
+		}
+		retried = true
+		return association.UpdateAssociationConf(r.Client, kibana, conf)
+	})
+}
+
+// onlyScaled returns true if the given Kibana specs only differ by their number of instances.
+func onlyScaled(previous, latest kbv1.KibanaSpec) bool {
+	if previous.Count == latest.Count {
+		return false
+	}
+	latest.Count = previous.Count
+	return reflect.DeepEqual(previous, latest)
+}
+
+// elasticsearchURL returns the URL Kibana should use to reach the given Elasticsearch cluster: the URL specified in the
+// override annotation if any, or the URL of the Elasticsearch external service.
+func elasticsearchURL(kibana *kbv1.Kibana, es esv1.Elasticsearch) (string, error) {
+	override, isOverridden := urlOverride(kibana)
+	if !isOverridden {
+		return services.ExternalServiceURL(es), nil
+	}
+	if err := absoluteHTTPURL(override); err != nil {
+		return "", err
+	}
+	return override, nil
+}
+
+// absoluteHTTPURL returns an error if the given value is not an absolute http or https URL.
+func absoluteHTTPURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%s is not an absolute http or https URL", value)
+	}
+	return nil
+}
+
+// urlOverride returns the Elasticsearch URL override annotation value of the given Kibana, if set.
+func urlOverride(kibana *kbv1.Kibana) (string, bool) {
+	override, isOverridden := kibana.Annotations[annotation.ElasticsearchURLOverrideAnnotation]
+	return override, isOverridden && override != ""
+}
+
+// caOverride returns the name of the secret holding the CA the given Kibana trusts instead of the Elasticsearch CA, if
+// the CA override annotation is set.
+func caOverride(kibana *kbv1.Kibana) (string, bool) {
+	name, isOverridden := kibana.Annotations[annotation.ElasticsearchCAOverrideAnnotation]
+	return name, isOverridden && name != ""
+}
+
+// sslVerificationMode returns the SSL verification mode set by the annotation of the given Kibana, or an empty string
+// for the default one. Disabling the verification must be acknowledged with the insecure annotation.
+func sslVerificationMode(kibana *kbv1.Kibana) (string, error) {
+	mode := kibana.Annotations[annotation.ElasticsearchSSLVerificationModeAnnotation]
+	switch mode {
+	case "", commonv1.SSLVerificationModeFull, commonv1.SSLVerificationModeCertificate:
+		return mode, nil
+	case commonv1.SSLVerificationModeNone:
+		if kibana.Annotations[annotation.ElasticsearchSSLInsecureAnnotation] != "true" {
+			return "", fmt.Errorf("%s requires the %s annotation to be set to \"true\"",
+				mode, annotation.ElasticsearchSSLInsecureAnnotation)
+		}
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%s is not one of %s, %s or %s", mode,
+			commonv1.SSLVerificationModeFull, commonv1.SSLVerificationModeCertificate, commonv1.SSLVerificationModeNone)
+	}
+}
+
+// overriddenCASecret returns the CA secret with the given name provided in the namespace of the given Kibana. It is
+// used as it is, rather than copied from the Elasticsearch CA.
+func overriddenCASecret(c k8s.Client, kibana *kbv1.Kibana, name string) (association.CASecret, error) {
+	var secret corev1.Secret
+	if err := c.Get(types.NamespacedName{Namespace: kibana.Namespace, Name: name}, &secret); err != nil {
+		return association.CASecret{}, err
+	}
+	return association.CASecret{
+		Name:            name,
+		CACertProvided:  len(secret.Data[certificates.CAFileName]) > 0,
+		ResourceVersion: secret.ResourceVersion,
+	}, nil
+}
+
+// deletePreviousCredentials deletes the secret referenced by the previous association configuration if it is not
+// referenced anymore by the current one, and was created by this association.
+func deletePreviousCredentials(c k8s.Client, kibana *kbv1.Kibana, previousConf *commonv1.AssociationConf) error {
+	if !previousConf.AuthIsConfigured() {
+		return nil
+	}
+	previous := previousConf.AuthSecretRef(kibana.Namespace)
+	if kibana.AssociationConf().AuthIsConfigured() && kibana.AssociationConf().AuthSecretRef(kibana.Namespace) == previous {
+		return nil
+	}
+	var secret corev1.Secret
+	if err := c.Get(previous, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !hasBeenCreatedBy(&secret, kibana) {
+		return nil
+	}
+	log.Info("Deleting previous credentials secret", "namespace", secret.Namespace, "secret_name", secret.Name, "kibana_name", kibana.Name)
+	if err := c.Delete(&secret); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// removeAssociationConf removes the Elasticsearch configuration set by this controller from the given Kibana, once its
+// Elasticsearch reference is removed. A configuration managed manually is preserved, as well as the Kibana
+// configuration in its spec which is never set by this controller.
+func (r *ReconcileAssociation) removeAssociationConf(kibana *kbv1.Kibana) error {
+	if owner, err := association.ConfOwner(kibana.ObjectMeta); err != nil || owner != commonv1.AssociationConfOwnerOperator {
+		return nil
+	}
+	if err := association.RemoveAssociationConf(r.Client, kibana); err != nil && !errors.IsConflict(err) && !errors.IsNotFound(err) {
+		return err
+	}
+	kibana.SetAssociationConf(nil)
+	return nil
+}
+
 // Unbind removes the association resources
 func (r *ReconcileAssociation) Unbind(kibana commonv1.Associated) error {
 	kibanaKey := k8s.ExtractNamespacedName(kibana)
@@ -353,8 +1405,9 @@ func (r *ReconcileAssociation) getElasticsearch(ctx context.Context, kibana *kbv
 
 	var es esv1.Elasticsearch
 	if err := r.Get(esRefKey, &es); err != nil {
-		k8s.EmitErrorEvent(r.recorder, err, kibana, events.EventAssociationError, "Failed to find referenced backend %s: %v", esRefKey, err)
 		if apierrors.IsNotFound(err) {
+			r.recordTransition(kibana, corev1.EventTypeWarning, events.EventAssociationError, esRefKey,
+				"Referenced Elasticsearch %s not found", esRefKey)
 			// ES not found. 2 options:
 			// - not created yet: that's ok, we'll reconcile on creation event
 			// - deleted: existing resources will be garbage collected
@@ -370,7 +1423,8 @@ func (r *ReconcileAssociation) getElasticsearch(ctx context.Context, kibana *kbv
 
 			return es, commonv1.AssociationPending, nil
 		}
-		return es, commonv1.AssociationFailed, err
+		k8s.EmitErrorEvent(r.recorder, err, kibana, events.EventAssociationError, "Failed to find referenced backend %s: %v", esRefKey, err)
+		return es, commonv1.AssociationDegraded, err
 	}
 	return es, "", nil
 }
@@ -380,17 +1434,50 @@ func (r *ReconcileAssociation) reconcileElasticsearchCA(ctx context.Context, kib
 	defer span.End()
 
 	kibanaKey := k8s.ExtractNamespacedName(kibana)
+	if name, isOverridden := caOverride(kibana); isOverridden {
+		// watch the provided CA secret instead of the ES CA secret
+		if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
+			Name:    esCAWatchName(kibanaKey),
+			Watched: []types.NamespacedName{{Namespace: kibana.Namespace, Name: name}},
+			Watcher: kibanaKey,
+		}); err != nil {
+			return association.CASecret{}, err
+		}
+		return overriddenCASecret(r.Client, kibana, name)
+	}
 	// watch ES CA secret to reconcile on any change
 	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
 		Name:    esCAWatchName(kibanaKey),
-		Watched: []types.NamespacedName{http.PublicCertsSecretRef(esv1.ESNamer, es)},
+		Watched: association.CAWatchedSecrets(es, r.globalCA),
 		Watcher: kibanaKey,
 	}); err != nil {
 		return association.CASecret{}, err
 	}
+	if err := association.CheckGlobalCA(r.globalCA); err != nil {
+		// the watch set above triggers a reconciliation once the global CA secret is fixed
+		r.recorder.Eventf(kibana, corev1.EventTypeWarning, events.EventAssociationError,
+			"Global CA secret %s unavailable, trusting the Elasticsearch CA only: %v", r.globalCA.Secret, err)
+	}
 	// Build the labels applied on the secret
 	labels := kblabel.NewLabels(kibana.Name)
 	labels[AssociationLabelName] = kibana.Name
+	labels[AssociationLabelNamespace] = kibana.Namespace
+	labels = association.WithTenant(labels, association.Tenant(kibana))
+	if association.IsCARotationOrchestrated(kibana) {
+		caSecret, _, err := association.ReconcileRotatedCASecret(
+			r.Client,
+			r.scheme,
+			kibana,
+			es,
+			labels,
+			ElasticsearchCASecretSuffix,
+			r.ownerRefMode,
+			r.globalCA,
+			r.kibanaUsesStagedCA(kibana),
+			time.Now(),
+		)
+		return caSecret, err
+	}
 	return association.ReconcileCASecret(
 		r.Client,
 		r.scheme,
@@ -398,12 +1485,15 @@ func (r *ReconcileAssociation) reconcileElasticsearchCA(ctx context.Context, kib
 		es,
 		labels,
 		ElasticsearchCASecretSuffix,
+		r.ownerRefMode,
+		r.globalCA,
 	)
 }
 
 // deleteOrphanedResources deletes resources created by this association that are left over from previous reconciliation
 // attempts. Common use case is an Elasticsearch reference in Kibana spec that was removed.
-func deleteOrphanedResources(ctx context.Context, c k8s.Client, kibana *kbv1.Kibana) error {
+// esRefNamespace is the namespace of the currently referenced Elasticsearch cluster.
+func deleteOrphanedResources(ctx context.Context, c k8s.Client, kibana *kbv1.Kibana, esRefNamespace string) error {
 	span, _ := apm.StartSpan(ctx, "delete_orphaned_resources", tracing.SpanTypeApp)
 	defer span.End()
 
@@ -414,14 +1504,6 @@ func deleteOrphanedResources(ctx context.Context, c k8s.Client, kibana *kbv1.Kib
 		return err
 	}
 
-	// Namespace in reference can be empty, in that case we compare it with the namespace of Kibana
-	var esRefNamespace string
-	if kibana.Spec.ElasticsearchRef.IsDefined() && kibana.Spec.ElasticsearchRef.Namespace != "" {
-		esRefNamespace = kibana.Spec.ElasticsearchRef.Namespace
-	} else {
-		esRefNamespace = kibana.Namespace
-	}
-
 	for _, s := range secrets.Items {
 		if metav1.IsControlledBy(&s, kibana) || hasBeenCreatedBy(&s, kibana) {
 			if !kibana.Spec.ElasticsearchRef.IsDefined() {
@@ -431,15 +1513,31 @@ func deleteOrphanedResources(ctx context.Context, c k8s.Client, kibana *kbv1.Kib
 				if err := c.Delete(&s); err != nil && !apierrors.IsNotFound(err) {
 					return err
 				}
-			} else if value, ok := s.Labels[common.TypeLabelName]; ok && value == user.UserType &&
-				esRefNamespace != s.Namespace {
-				// User secret may live in an other namespace, check if it has changed
-				log.Info("Deleting secret", "namespace", s.Namespace, "secretname", s.Name, "kibana_name", kibana.Name)
-				if err := c.Delete(&s); err != nil && !apierrors.IsNotFound(err) {
-					return err
-				}
 			}
 		}
 	}
+	if !kibana.Spec.ElasticsearchRef.IsDefined() {
+		return nil
+	}
+	return deleteMigratedUsers(c, kibana, esRefNamespace)
+}
+
+// deleteMigratedUsers deletes the user secrets of the given Kibana association left in the namespace of a previously
+// referenced Elasticsearch cluster, after the Elasticsearch reference moved to another namespace. The user secret is
+// owned by the previous Elasticsearch cluster and would otherwise outlive the association as long as that cluster exists.
+func deleteMigratedUsers(c k8s.Client, kibana *kbv1.Kibana, esRefNamespace string) error {
+	var secrets corev1.SecretList
+	if err := c.List(&secrets, NewUserLabelSelector(k8s.ExtractNamespacedName(kibana))); err != nil {
+		return err
+	}
+	for _, s := range secrets.Items {
+		if s.Namespace == esRefNamespace || !hasBeenCreatedBy(&s, kibana) || s.Labels[common.TypeLabelName] != user.UserType {
+			continue
+		}
+		log.Info("Deleting user secret of previously referenced Elasticsearch", "namespace", s.Namespace, "secret_name", s.Name, "kibana_name", kibana.Name)
+		if err := c.Delete(&s); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
 	return nil
 }
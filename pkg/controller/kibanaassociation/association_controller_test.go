@@ -6,20 +6,34 @@ package kibanaassociation
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/operator"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
 	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
@@ -185,6 +199,60 @@ func Test_deleteOrphanedResources(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "ES namespace has changed, user left in the previous ES namespace",
+			kibana: kbv1.Kibana{
+				ObjectMeta: kibanaFixtureObjectMeta,
+				Spec: kbv1.KibanaSpec{
+					ElasticsearchRef: commonv1.ObjectSelector{
+						Name:      esFixture.Name,
+						Namespace: "ns2", // Kibana does not reference the ns1 namespace anymore
+					},
+				},
+			},
+			initialObjects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userName,
+						Namespace: "ns1", // the namespace of the previously referenced ES
+						Labels: map[string]string{
+							AssociationLabelName:      kibanaFixture.Name,
+							AssociationLabelNamespace: kibanaFixture.Namespace,
+							common.TypeLabelName:      user.UserType,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      userName,
+						Namespace: "ns2", // the namespace of the currently referenced ES
+						Labels: map[string]string{
+							AssociationLabelName:      kibanaFixture.Name,
+							AssociationLabelNamespace: kibanaFixture.Namespace,
+							common.TypeLabelName:      user.UserType,
+						},
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ns1-kibana-foo-kibana-user",
+						Namespace: "ns1", // user of a Kibana with the same name in another namespace
+						Labels: map[string]string{
+							AssociationLabelName:      kibanaFixture.Name,
+							AssociationLabelNamespace: "ns1",
+							common.TypeLabelName:      user.UserType,
+						},
+					},
+				},
+			},
+			postCondition: func(c k8s.Client) {
+				assert.Error(t, c.Get(types.NamespacedName{Namespace: "ns1", Name: userName}, &corev1.Secret{}),
+					"User secret in the previous ES namespace should have been removed")
+				assert.NoError(t, c.Get(types.NamespacedName{Namespace: "ns2", Name: userName}, &corev1.Secret{}))
+				assert.NoError(t, c.Get(types.NamespacedName{Namespace: "ns1", Name: "ns1-kibana-foo-kibana-user"}, &corev1.Secret{}))
+			},
+			wantErr: false,
+		},
 		{
 			name:    "nothing to delete",
 			kibana:  kbv1.Kibana{},
@@ -294,7 +362,9 @@ func Test_deleteOrphanedResources(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := k8s.WrappedFakeClient(tt.initialObjects...)
-			if err := deleteOrphanedResources(context.Background(), c, &tt.kibana); (err != nil) != tt.wantErr {
+			esRefKey, err := association.ElasticsearchRefKey(k8s.FakeClient(tt.initialObjects...), &tt.kibana, "")
+			assert.NoError(t, err)
+			if err := deleteOrphanedResources(context.Background(), c, &tt.kibana, esRefKey.Namespace); (err != nil) != tt.wantErr {
 				t.Errorf("deleteOrphanedResources() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if tt.postCondition != nil {
@@ -321,3 +391,1212 @@ func assertExpectObjectsExist(t *testing.T, c k8s.Client) {
 		Name:      association.ElasticsearchCACertSecretName(&kibanaFixture, ElasticsearchCASecretSuffix),
 	}, &corev1.Secret{}))
 }
+
+func Test_associationStatus_applyTo(t *testing.T) {
+	established := associationStatus{
+		status:   commonv1.AssociationEstablished,
+		authMode: authMode(&commonv1.AssociationConf{AuthSecretName: userSecretName, AuthSecretKey: userName}),
+	}
+	assert.Equal(t, commonv1.AssociationAuthSecretRef, established.authMode)
+
+	var status kbv1.KibanaStatus
+	// status is updated
+	assert.True(t, established.applyTo(&status))
+	assert.Equal(t, commonv1.AssociationEstablished, status.AssociationStatus)
+	assert.Equal(t, commonv1.AssociationAuthSecretRef, status.AssociationAuthMode)
+	// no-op if already applied
+	assert.False(t, established.applyTo(&status))
+	// a new generation of Kibana is observed
+	established.observedGeneration = 2
+	assert.True(t, established.applyTo(&status))
+	assert.Equal(t, int64(2), status.AssociationObservedGeneration)
+	// auth mode is reset when the association is not established anymore
+	assert.True(t, associationStatus{status: commonv1.AssociationPending, message: "waiting"}.applyTo(&status))
+	assert.Equal(t, kbv1.KibanaStatus{AssociationStatus: commonv1.AssociationPending, AssociationMessage: "waiting"}, status)
+}
+
+func TestReconcileAssociation_updateStatus(t *testing.T) {
+	newStatus := associationStatus{status: commonv1.AssociationEstablished}
+	tests := []struct {
+		name       string
+		tolerate   bool
+		wantResult reconcile.Result
+		wantErr    bool
+	}{
+		{name: "status update failure is fatal by default", wantResult: defaultRequeue, wantErr: true},
+		{name: "status update failure is tolerated", tolerate: true, wantResult: defaultRequeue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the status update fails since Kibana does not exist
+			r := &ReconcileAssociation{
+				Client:     k8s.WrappedFakeClient(),
+				recorder:   record.NewFakeRecorder(10),
+				Parameters: operator.Parameters{AssociationTolerateStatusUpdateFailures: tt.tolerate},
+			}
+			result, err := r.updateStatus(context.Background(), *kibanaFixture.DeepCopy(), newStatus)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantResult, result)
+		})
+	}
+}
+
+func TestReconcileAssociation_updateStatus_throttled(t *testing.T) {
+	kb := kibanaFixture.DeepCopy()
+	c := k8s.WrappedFakeClient(kb)
+	r := &ReconcileAssociation{
+		Client:         c,
+		recorder:       record.NewFakeRecorder(10),
+		publisher:      association.NoopPublisher{},
+		statusThrottle: association.NewStatusUpdateThrottle(time.Hour),
+	}
+	update := func(status commonv1.AssociationStatus) reconcile.Result {
+		var current kbv1.Kibana
+		assert.NoError(t, c.Get(k8s.ExtractNamespacedName(kb), &current))
+		result, err := r.updateStatus(context.Background(), current, associationStatus{status: status})
+		assert.NoError(t, err)
+		return result
+	}
+	// the first update is written
+	assert.Equal(t, reconcile.Result{}, update(commonv1.AssociationPending))
+	// unchanged status, nothing to write
+	assert.Equal(t, reconcile.Result{}, update(commonv1.AssociationPending))
+	// the next change is deferred
+	result := update(commonv1.AssociationEstablished)
+	assert.True(t, result.Requeue)
+	assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter <= time.Hour)
+	var current kbv1.Kibana
+	assert.NoError(t, c.Get(k8s.ExtractNamespacedName(kb), &current))
+	assert.Equal(t, commonv1.AssociationPending, current.Status.AssociationStatus)
+}
+
+func Test_summarizeHealth(t *testing.T) {
+	tests := []struct {
+		name             string
+		esHealth         esv1.ElasticsearchHealth
+		kibanaHealth     kbv1.KibanaHealth
+		credentialsValid bool
+		want             commonv1.AssociationHealth
+	}{
+		{
+			name:             "all healthy",
+			esHealth:         esv1.ElasticsearchGreenHealth,
+			kibanaHealth:     kbv1.KibanaGreen,
+			credentialsValid: true,
+			want:             commonv1.AssociationHealthGreen,
+		},
+		{
+			name:             "ES yellow",
+			esHealth:         esv1.ElasticsearchYellowHealth,
+			kibanaHealth:     kbv1.KibanaGreen,
+			credentialsValid: true,
+			want:             commonv1.AssociationHealthYellow,
+		},
+		{
+			name:             "ES health unknown",
+			esHealth:         esv1.ElasticsearchUnknownHealth,
+			kibanaHealth:     kbv1.KibanaGreen,
+			credentialsValid: true,
+			want:             commonv1.AssociationHealthYellow,
+		},
+		{
+			name:             "ES red",
+			esHealth:         esv1.ElasticsearchRedHealth,
+			kibanaHealth:     kbv1.KibanaGreen,
+			credentialsValid: true,
+			want:             commonv1.AssociationHealthRed,
+		},
+		{
+			name:             "Kibana not available",
+			esHealth:         esv1.ElasticsearchGreenHealth,
+			kibanaHealth:     kbv1.KibanaRed,
+			credentialsValid: true,
+			want:             commonv1.AssociationHealthRed,
+		},
+		{
+			name:             "invalid credentials",
+			esHealth:         esv1.ElasticsearchGreenHealth,
+			kibanaHealth:     kbv1.KibanaGreen,
+			credentialsValid: false,
+			want:             commonv1.AssociationHealthRed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, summarizeHealth(tt.esHealth, tt.kibanaHealth, tt.credentialsValid))
+		})
+	}
+}
+
+func Test_hasValidCredentials(t *testing.T) {
+	kb := kibanaFixture.DeepCopy()
+	kb.SetAssociationConf(&commonv1.AssociationConf{AuthSecretName: userSecretName, AuthSecretKey: userName})
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: kb.Namespace, Name: userSecretName},
+		Data:       map[string][]byte{userName: []byte("password")},
+	}
+
+	valid, err := hasValidCredentials(k8s.WrappedFakeClient(credentials), kb)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = hasValidCredentials(k8s.WrappedFakeClient(), kb)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	valid, err = hasValidCredentials(k8s.WrappedFakeClient(credentials), kibanaFixture.DeepCopy())
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func Test_deletePreviousCredentials(t *testing.T) {
+	previousConf := &commonv1.AssociationConf{AuthSecretName: userSecretName, AuthSecretKey: userName}
+	previousSecret := func(labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: userSecretName, Labels: labels}}
+	}
+	tests := []struct {
+		name        string
+		currentConf *commonv1.AssociationConf
+		secret      *corev1.Secret
+		wantDeleted bool
+	}{
+		{
+			name:        "previous credentials moved to another namespace are deleted",
+			currentConf: &commonv1.AssociationConf{AuthSecretName: userSecretName, AuthSecretKey: userName, AuthSecretNamespace: "credentials"},
+			secret:      previousSecret(NewCredentialsLabels(k8s.ExtractNamespacedName(&kibanaFixture))),
+			wantDeleted: true,
+		},
+		{
+			name:        "credentials still in use are preserved",
+			currentConf: previousConf,
+			secret:      previousSecret(NewCredentialsLabels(k8s.ExtractNamespacedName(&kibanaFixture))),
+			wantDeleted: false,
+		},
+		{
+			name:        "secrets not created by the association are preserved",
+			currentConf: &commonv1.AssociationConf{AuthSecretName: userSecretName, AuthSecretKey: userName, AuthSecretNamespace: "credentials"},
+			secret:      previousSecret(nil),
+			wantDeleted: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrappedFakeClient(tt.secret)
+			kb := kibanaFixture.DeepCopy()
+			kb.SetAssociationConf(tt.currentConf)
+			assert.NoError(t, deletePreviousCredentials(c, kb, previousConf))
+			err := c.Get(k8s.ExtractNamespacedName(tt.secret), &corev1.Secret{})
+			assert.Equal(t, tt.wantDeleted, apierrors.IsNotFound(err))
+		})
+	}
+}
+
+func Test_elasticsearchURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		override *string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "no override",
+			want: "https://es-foo-es-http.default.svc:9200",
+		},
+		{
+			name:     "empty override",
+			override: pointer(""),
+			want:     "https://es-foo-es-http.default.svc:9200",
+		},
+		{
+			name:     "override",
+			override: pointer("https://es-foo-es-default-0.es-foo-es-default.default.svc:9200"),
+			want:     "https://es-foo-es-default-0.es-foo-es-default.default.svc:9200",
+		},
+		{
+			name:     "invalid override",
+			override: pointer("es-foo:9200"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := kibanaFixture.DeepCopy()
+			if tt.override != nil {
+				kb.Annotations = map[string]string{annotation.ElasticsearchURLOverrideAnnotation: *tt.override}
+			}
+			got, err := elasticsearchURL(kb, esFixture)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_sslVerificationMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:        "full",
+			annotations: map[string]string{annotation.ElasticsearchSSLVerificationModeAnnotation: "full"},
+			want:        commonv1.SSLVerificationModeFull,
+		},
+		{
+			name:        "certificate",
+			annotations: map[string]string{annotation.ElasticsearchSSLVerificationModeAnnotation: "certificate"},
+			want:        commonv1.SSLVerificationModeCertificate,
+		},
+		{
+			name: "none when insecure",
+			annotations: map[string]string{
+				annotation.ElasticsearchSSLVerificationModeAnnotation: "none",
+				annotation.ElasticsearchSSLInsecureAnnotation:         "true",
+			},
+			want: commonv1.SSLVerificationModeNone,
+		},
+		{
+			name:        "none without the insecure annotation",
+			annotations: map[string]string{annotation.ElasticsearchSSLVerificationModeAnnotation: "none"},
+			wantErr:     true,
+		},
+		{
+			name: "none when not insecure",
+			annotations: map[string]string{
+				annotation.ElasticsearchSSLVerificationModeAnnotation: "none",
+				annotation.ElasticsearchSSLInsecureAnnotation:         "false",
+			},
+			wantErr: true,
+		},
+		{
+			name:        "invalid",
+			annotations: map[string]string{annotation.ElasticsearchSSLVerificationModeAnnotation: "strict"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := kibanaFixture.DeepCopy()
+			kb.Annotations = tt.annotations
+			got, err := sslVerificationMode(kb)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func pointer(s string) *string {
+	return &s
+}
+
+func TestReconcileAssociation_verifyEncryptionAtRest(t *testing.T) {
+	tests := []struct {
+		name                 string
+		policy               association.EncryptionAtRestPolicy
+		credentialsNamespace string
+		kibana               *kbv1.Kibana
+		wantStatus           commonv1.AssociationStatus
+		wantMessage          string
+	}{
+		{
+			name:       "verification disabled",
+			kibana:     kibanaFixture.DeepCopy(),
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:       "no Elasticsearch reference",
+			policy:     association.EncryptionAtRestPolicy{"other"},
+			kibana:     &kbv1.Kibana{ObjectMeta: kibanaFixtureObjectMeta},
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:       "all namespaces encrypted",
+			policy:     association.EncryptionAtRestPolicy{association.AllNamespacesEncrypted},
+			kibana:     kibanaFixture.DeepCopy(),
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:       "Kibana and Elasticsearch namespace encrypted",
+			policy:     association.EncryptionAtRestPolicy{"default"},
+			kibana:     kibanaFixture.DeepCopy(),
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:                 "credentials namespace not encrypted",
+			policy:               association.EncryptionAtRestPolicy{"default"},
+			credentialsNamespace: "credentials",
+			kibana:               kibanaFixture.DeepCopy(),
+			wantStatus:           commonv1.AssociationFailed,
+			wantMessage:          "Secrets are not encrypted at rest in namespaces credentials",
+		},
+		{
+			name:   "Kibana and Elasticsearch namespaces not encrypted",
+			policy: association.EncryptionAtRestPolicy{"other"},
+			kibana: func() *kbv1.Kibana {
+				kb := kibanaFixture.DeepCopy()
+				kb.Namespace = "kb"
+				kb.Spec.ElasticsearchRef.Namespace = "es"
+				return kb
+			}(),
+			wantStatus:  commonv1.AssociationFailed,
+			wantMessage: "Secrets are not encrypted at rest in namespaces es, kb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ReconcileAssociation{
+				Client:           k8s.WrappedFakeClient(),
+				recorder:         record.NewFakeRecorder(10),
+				encryptionPolicy: tt.policy,
+				Parameters: operator.Parameters{
+					AssociationCredentialsNamespace: tt.credentialsNamespace,
+				},
+			}
+			status, message := r.verifyEncryptionAtRest(tt.kibana)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantMessage, message)
+		})
+	}
+}
+
+func TestReconcileAssociation_verifyElasticsearchVersion(t *testing.T) {
+	minVersion := version.MustParse("7.4.0")
+	esWithVersion := func(v string) *esv1.Elasticsearch {
+		es := esFixture.DeepCopy()
+		es.Spec.Version = v
+		return es
+	}
+	tests := []struct {
+		name        string
+		minVersion  *version.Version
+		es          *esv1.Elasticsearch
+		wantStatus  commonv1.AssociationStatus
+		wantMessage string
+	}{
+		{
+			name:       "no minimum version",
+			es:         esWithVersion("6.8.0"),
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:       "Elasticsearch does not exist",
+			minVersion: &minVersion,
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:       "same version",
+			minVersion: &minVersion,
+			es:         esWithVersion("7.4.0"),
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:       "more recent version",
+			minVersion: &minVersion,
+			es:         esWithVersion("7.6.1"),
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:        "older version",
+			minVersion:  &minVersion,
+			es:          esWithVersion("7.3.2"),
+			wantStatus:  commonv1.AssociationFailed,
+			wantMessage: "Elasticsearch version 7.3.2 is older than the minimum supported version 7.4.0",
+		},
+		{
+			name:        "invalid version",
+			minVersion:  &minVersion,
+			es:          esWithVersion("latest"),
+			wantStatus:  commonv1.AssociationFailed,
+			wantMessage: "Invalid Elasticsearch version latest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objs []runtime.Object
+			if tt.es != nil {
+				objs = append(objs, tt.es)
+			}
+			r := &ReconcileAssociation{
+				Client:     k8s.WrappedFakeClient(objs...),
+				recorder:   record.NewFakeRecorder(10),
+				Parameters: operator.Parameters{AssociationMinESVersion: tt.minVersion},
+			}
+			status, message, err := r.verifyElasticsearchVersion(kibanaFixture.DeepCopy())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantMessage, message)
+		})
+	}
+}
+
+func TestReconcileAssociation_verifyConfOwner(t *testing.T) {
+	kibanaWith := func(owner string, previous commonv1.AssociationConfOwner, conf *commonv1.AssociationConf) *kbv1.Kibana {
+		kb := kibanaFixture.DeepCopy()
+		if owner != "" {
+			kb.Annotations = map[string]string{annotation.ConfOwnerAnnotation: owner}
+		}
+		kb.Status.AssociationConfOwner = previous
+		kb.SetAssociationConf(conf)
+		return kb
+	}
+	conf := &commonv1.AssociationConf{AuthSecretName: userSecretName, AuthSecretKey: userName, CASecretName: "ca", URL: "https://es:9200"}
+	tests := []struct {
+		name        string
+		kibana      *kbv1.Kibana
+		wantOwner   commonv1.AssociationConfOwner
+		wantStatus  commonv1.AssociationStatus
+		wantMessage string
+		wantEvent   bool
+	}{
+		{
+			name:       "no Elasticsearch reference",
+			kibana:     &kbv1.Kibana{ObjectMeta: kibanaFixtureObjectMeta},
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:       "owned by the operator by default",
+			kibana:     kibanaWith("", "", nil),
+			wantOwner:  commonv1.AssociationConfOwnerOperator,
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:        "released to manual management",
+			kibana:      kibanaWith("manual", commonv1.AssociationConfOwnerOperator, conf),
+			wantOwner:   commonv1.AssociationConfOwnerManual,
+			wantStatus:  commonv1.AssociationEstablished,
+			wantMessage: "Elasticsearch configuration managed manually",
+			wantEvent:   true,
+		},
+		{
+			name:        "released to manual management without configuration",
+			kibana:      kibanaWith("manual", commonv1.AssociationConfOwnerManual, nil),
+			wantOwner:   commonv1.AssociationConfOwnerManual,
+			wantStatus:  commonv1.AssociationFailed,
+			wantMessage: "Elasticsearch configuration released to manual management but not configured",
+		},
+		{
+			name:       "claimed back by the operator",
+			kibana:     kibanaWith("operator", commonv1.AssociationConfOwnerManual, conf),
+			wantOwner:  commonv1.AssociationConfOwnerOperator,
+			wantStatus: commonv1.AssociationUnknown,
+			wantEvent:  true,
+		},
+		{
+			name:        "invalid owner",
+			kibana:      kibanaWith("someone", commonv1.AssociationConfOwnerOperator, conf),
+			wantStatus:  commonv1.AssociationFailed,
+			wantMessage: `invalid value "someone" for annotation association.k8s.elastic.co/es-conf-owner, expected operator or manual`,
+			wantEvent:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			r := &ReconcileAssociation{Client: k8s.WrappedFakeClient(), recorder: recorder}
+			owner, status, message := r.verifyConfOwner(tt.kibana)
+			assert.Equal(t, tt.wantOwner, owner)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantMessage, message)
+			assert.Equal(t, tt.wantEvent, len(recorder.Events) > 0)
+		})
+	}
+}
+
+func TestReconcileAssociation_reconcileDependencies(t *testing.T) {
+	dependentKibana := func(name, dependsOn string) *kbv1.Kibana {
+		kb := kibanaFixture.DeepCopy()
+		kb.Name = name
+		if dependsOn != "" {
+			kb.Annotations = map[string]string{annotation.DependsOnAnnotation: dependsOn}
+		}
+		return kb
+	}
+	tests := []struct {
+		name         string
+		kibana       *kbv1.Kibana
+		runtimeObjs  []runtime.Object
+		wantStatus   commonv1.AssociationStatus
+		wantMessage  string
+		wantBlocked  bool
+		wantEvent    bool
+		wantWatchers []types.NamespacedName
+	}{
+		{
+			name:       "no dependency",
+			kibana:     dependentKibana("kb-a", ""),
+			wantStatus: commonv1.AssociationUnknown,
+		},
+		{
+			name:         "dependency not established",
+			kibana:       dependentKibana("kb-a", "Kibana/kb-b"),
+			runtimeObjs:  []runtime.Object{dependentKibana("kb-b", "")},
+			wantStatus:   commonv1.AssociationPending,
+			wantMessage:  "Waiting for association Kibana/default/kb-b to be established",
+			wantBlocked:  true,
+			wantWatchers: []types.NamespacedName{{Namespace: "default", Name: "kb-a"}},
+		},
+		{
+			name:         "circular dependency",
+			kibana:       dependentKibana("kb-a", "Kibana/kb-b"),
+			runtimeObjs:  []runtime.Object{dependentKibana("kb-a", "Kibana/kb-b"), dependentKibana("kb-b", "Kibana/kb-a")},
+			wantStatus:   commonv1.AssociationFailed,
+			wantMessage:  "Circular association dependency: Kibana/default/kb-a -> Kibana/default/kb-b -> Kibana/default/kb-a",
+			wantEvent:    true,
+			wantWatchers: []types.NamespacedName{{Namespace: "default", Name: "kb-a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReconciler(t, k8s.WrappedFakeClient(tt.runtimeObjs...))
+			recorder := r.recorder.(*record.FakeRecorder)
+			status, message, blocking, err := r.reconcileDependencies(context.Background(), tt.kibana)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantMessage, message)
+			assert.Equal(t, tt.wantBlocked, blocking != nil)
+			assert.Equal(t, tt.wantEvent, len(recorder.Events) > 0)
+			assert.Equal(t, tt.wantWatchers, r.watches.Kibanas.Watchers(types.NamespacedName{Namespace: "default", Name: "kb-b"}))
+			// associations waiting for a dependency rely on the watches rather than on requeues
+			newStatus := associationStatus{status: status, blockingDependency: blocking}
+			if tt.wantBlocked {
+				assert.Equal(t, reconcile.Result{}, r.dependentResultFromStatus(tt.kibana, newStatus))
+			}
+		})
+	}
+}
+
+func TestReconcileAssociation_reconcileInternal_tenant(t *testing.T) {
+	kb := kibanaFixture.DeepCopy()
+	kb.Labels = map[string]string{association.TenantLabelName: "team-a"}
+	es := esFixture.DeepCopy()
+	es.Spec.Version = "7.5.0"
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")},
+	}
+	c := k8s.WrappedFakeClient(kb, es, esCerts)
+	status, err := newTestReconciler(t, c).reconcileInternal(context.Background(), kb)
+	assert.NoError(t, err)
+	assert.Equal(t, commonv1.AssociationEstablished, status)
+
+	// the tenant is propagated to the derived secrets
+	for _, name := range []string{userSecretName, userName, "kibana-foo-kb-es-ca"} {
+		var secret corev1.Secret
+		assert.NoError(t, c.Get(types.NamespacedName{Namespace: "default", Name: name}, &secret))
+		assert.Equal(t, "team-a", secret.Labels[association.TenantLabelName], name)
+	}
+}
+
+func TestReconcileAssociation_reconcileInternal_crossNamespace(t *testing.T) {
+	// Elasticsearch in a shared namespace, Kibana in a team namespace
+	kb := kibanaFixture.DeepCopy()
+	kb.Namespace = "team-a"
+	kb.Spec.ElasticsearchRef = commonv1.ObjectSelector{Namespace: "elastic-system", Name: esFixture.Name}
+	es := esFixture.DeepCopy()
+	es.Namespace = "elastic-system"
+	es.Spec.Version = "7.5.0"
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "elastic-system", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")},
+	}
+	c := k8s.WrappedFakeClient(kb, es, esCerts)
+	status, err := newTestReconciler(t, c).reconcileInternal(context.Background(), kb)
+	assert.NoError(t, err)
+	assert.Equal(t, commonv1.AssociationEstablished, status)
+
+	// the user is created in the Elasticsearch namespace
+	assert.NoError(t, c.Get(types.NamespacedName{Namespace: "elastic-system", Name: "team-a-kibana-foo-kibana-user"}, &corev1.Secret{}))
+	// the credentials and the CA are copied to the Kibana namespace, and garbage collected along with Kibana
+	for _, name := range []string{userSecretName, "kibana-foo-kb-es-ca"} {
+		var secret corev1.Secret
+		assert.NoError(t, c.Get(types.NamespacedName{Namespace: "team-a", Name: name}, &secret))
+		assert.True(t, metav1.IsControlledBy(&secret, kb), name)
+	}
+	conf := kb.AssociationConf()
+	assert.Equal(t, "kibana-foo-kb-es-ca", conf.GetCASecretName())
+	assert.Equal(t, types.NamespacedName{Namespace: "team-a", Name: userSecretName}, conf.AuthSecretRef(kb.Namespace))
+	assert.Equal(t, "https://es-foo-es-http.elastic-system.svc:9200", conf.GetURL())
+}
+
+func TestReconcileAssociation_reconcileInternal_alias(t *testing.T) {
+	kb := kibanaFixture.DeepCopy()
+	kb.Annotations = map[string]string{annotation.ElasticsearchAliasAnnotation: "prod"}
+	es := esFixture.DeepCopy()
+	es.Namespace = "elastic-system"
+	es.Spec.Version = "7.5.0"
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "elastic-system", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")},
+	}
+	// the aliases ConfigMap is in the operator namespace, which is not cached
+	aliases := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "elastic-operator", Name: association.AliasesConfigMapName},
+		Data:       map[string]string{"prod": "elastic-system/es-foo"},
+	}
+	r := newTestReconciler(t, k8s.WrappedFakeClient(kb, es, esCerts))
+	r.OperatorNamespace = "elastic-operator"
+	r.apiReader = k8s.FakeClient(aliases)
+
+	status, err := r.reconcileInternal(context.Background(), kb)
+	assert.NoError(t, err)
+	assert.Equal(t, commonv1.AssociationEstablished, status)
+	assert.Equal(t, "https://es-foo-es-http.elastic-system.svc:9200", kb.AssociationConf().GetURL())
+	// the alias is resolved again periodically
+	assert.Equal(t, aliasRequeue, aliasResult(kb))
+	assert.Equal(t, reconcile.Result{}, aliasResult(&kibanaFixture))
+}
+
+func TestReconcileAssociation_reconcileInternal_scaledToZero(t *testing.T) {
+	kb := kibanaFixture.DeepCopy()
+	es := esFixture.DeepCopy()
+	es.Spec.Version = "7.5.0"
+	es.Spec.NodeSets = []esv1.NodeSet{{Name: "default", Count: 0}}
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")},
+	}
+	c := k8s.WrappedFakeClient(kb, es, esCerts)
+	r := newTestReconciler(t, c)
+	recorder := &annotatedRecorder{FakeRecorder: record.NewFakeRecorder(10)}
+	r.recorder = recorder
+	scale := func(count int32) {
+		assert.NoError(t, c.Get(k8s.ExtractNamespacedName(es), es))
+		es.Spec.NodeSets[0].Count = count
+		assert.NoError(t, c.Update(es))
+	}
+
+	// created with zero nodes: the association is pending
+	status, err := r.reconcileInternal(context.Background(), kb)
+	assert.NoError(t, err)
+	assert.Equal(t, commonv1.AssociationPending, status)
+	assert.Equal(t, []string{"Warning AssociationError Referenced Elasticsearch default/es-foo scaled to zero"}, recorder.events)
+	assert.Nil(t, kb.AssociationConf())
+
+	// scaled up: the association is established
+	scale(3)
+	status, err = r.reconcileInternal(context.Background(), kb)
+	assert.NoError(t, err)
+	assert.Equal(t, commonv1.AssociationEstablished, status)
+	conf := kb.AssociationConf()
+	assert.Equal(t, "https://es-foo-es-http.default.svc:9200", conf.GetURL())
+
+	// scaled down to zero again: the association is pending, the configuration is retained for the next scale up
+	scale(0)
+	status, err = r.reconcileInternal(context.Background(), kb)
+	assert.NoError(t, err)
+	assert.Equal(t, commonv1.AssociationPending, status)
+	assert.Equal(t, conf, kb.AssociationConf())
+}
+
+func TestReconcileAssociation_reconcileInternal_minESHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		minHealth  esv1.ElasticsearchHealth
+		health     esv1.ElasticsearchHealth
+		wantStatus commonv1.AssociationStatus
+		wantEvents []string
+	}{
+		{
+			name:       "no minimum health",
+			health:     esv1.ElasticsearchRedHealth,
+			wantStatus: commonv1.AssociationEstablished,
+		},
+		{
+			name:       "red Elasticsearch",
+			minHealth:  esv1.ElasticsearchYellowHealth,
+			health:     esv1.ElasticsearchRedHealth,
+			wantStatus: commonv1.AssociationPending,
+			wantEvents: []string{"Warning AssociationError Referenced Elasticsearch default/es-foo health is red, at least yellow is required"},
+		},
+		{
+			name:       "unknown Elasticsearch health",
+			minHealth:  esv1.ElasticsearchYellowHealth,
+			wantStatus: commonv1.AssociationPending,
+			wantEvents: []string{"Warning AssociationError Referenced Elasticsearch default/es-foo health is unknown, at least yellow is required"},
+		},
+		{
+			name:       "yellow Elasticsearch",
+			minHealth:  esv1.ElasticsearchYellowHealth,
+			health:     esv1.ElasticsearchYellowHealth,
+			wantStatus: commonv1.AssociationEstablished,
+		},
+		{
+			name:       "green Elasticsearch required",
+			minHealth:  esv1.ElasticsearchGreenHealth,
+			health:     esv1.ElasticsearchYellowHealth,
+			wantStatus: commonv1.AssociationPending,
+			wantEvents: []string{"Warning AssociationError Referenced Elasticsearch default/es-foo health is yellow, at least green is required"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := kibanaFixture.DeepCopy()
+			es := esFixture.DeepCopy()
+			es.Spec.Version = "7.5.0"
+			es.Status.Health = tt.health
+			esCerts := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-foo-es-http-certs-public"},
+				Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")},
+			}
+			r := newTestReconciler(t, k8s.WrappedFakeClient(kb, es, esCerts))
+			r.AssociationMinESHealth = tt.minHealth
+			recorder := &annotatedRecorder{FakeRecorder: record.NewFakeRecorder(10)}
+			r.recorder = recorder
+			status, err := r.reconcileInternal(context.Background(), kb)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantStatus == commonv1.AssociationPending {
+				assert.Equal(t, tt.wantEvents, recorder.events)
+				assert.Nil(t, kb.AssociationConf())
+			}
+		})
+	}
+}
+
+// annotatedRecorder records the annotated events, which the fake recorder does not format properly.
+type annotatedRecorder struct {
+	*record.FakeRecorder
+	events      []string
+	annotations []map[string]string
+}
+
+func (r *annotatedRecorder) AnnotatedEventf(_ runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.events = append(r.events, fmt.Sprintf(eventtype+" "+reason+" "+messageFmt, args...))
+	r.annotations = append(r.annotations, annotations)
+}
+
+func TestReconcileAssociation_reconcileInternal_events(t *testing.T) {
+	es := esFixture.DeepCopy()
+	es.Spec.Version = "7.5.0"
+	tests := []struct {
+		name             string
+		objs             []runtime.Object
+		wantEvents       []string
+		wantDependencies []string
+	}{
+		{
+			name:             "Elasticsearch not found",
+			wantEvents:       []string{"Warning AssociationError Referenced Elasticsearch default/es-foo not found"},
+			wantDependencies: []string{"default/es-foo"},
+		},
+		{
+			name: "Elasticsearch CA not ready",
+			objs: []runtime.Object{es},
+			wantEvents: []string{
+				"Warning AssociationError Elasticsearch CA not ready, proceeding without it",
+				"Normal AssociationConfigured Elasticsearch configuration updated, using https://es-foo-es-http.default.svc:9200",
+			},
+			wantDependencies: []string{"default/es-foo-es-http-certs-public", "default/es-foo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := kibanaFixture.DeepCopy()
+			r := newTestReconciler(t, k8s.WrappedFakeClient(append(tt.objs, kb)...))
+			r.iteration = 42
+			recorder := &annotatedRecorder{FakeRecorder: record.NewFakeRecorder(10)}
+			r.recorder = recorder
+			_, err := r.reconcileInternal(context.Background(), kb)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantEvents, recorder.events)
+			for i, annotations := range recorder.annotations {
+				assert.Equal(t, "42", annotations[annotation.IterationAnnotation])
+				assert.Equal(t, tt.wantDependencies[i], annotations[annotation.DependencyAnnotation])
+			}
+		})
+	}
+}
+
+func TestReconcileAssociation_planAssociationConf(t *testing.T) {
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")},
+	}
+	establishedConf := &commonv1.AssociationConf{
+		AuthSecretName: userSecretName,
+		AuthSecretKey:  userName,
+		CACertProvided: true,
+		CASecretName:   "kibana-foo-kb-es-ca",
+		URL:            "https://es-foo-es-http.default.svc:9200",
+	}
+	withConf := func(kb kbv1.Kibana, conf *commonv1.AssociationConf) *kbv1.Kibana {
+		kb = *kb.DeepCopy()
+		kb.SetAssociationConf(conf)
+		return &kb
+	}
+	tests := []struct {
+		name        string
+		kibana      *kbv1.Kibana
+		objs        []runtime.Object
+		wantMessage string
+		wantChange  string
+	}{
+		{
+			name:        "Elasticsearch does not exist",
+			kibana:      withConf(kibanaFixture, nil),
+			wantMessage: "Dry run: no change planned",
+		},
+		{
+			name:        "new association",
+			kibana:      withConf(kibanaFixture, nil),
+			objs:        []runtime.Object{esFixture.DeepCopy(), esCerts},
+			wantMessage: "Dry run: change planned to the Elasticsearch configuration",
+			wantChange: `authSecretName: "" -> "kibana-foo-kibana-user"
+authSecretKey: "" -> "default-kibana-foo-kibana-user"
+authSecretNamespace: "" -> "default"
+caCertProvided: "false" -> "true"
+caSecretName: "" -> "kibana-foo-kb-es-ca"
+url: "" -> "https://es-foo-es-http.default.svc:9200"`,
+		},
+		{
+			name:        "established association",
+			kibana:      withConf(kibanaFixture, establishedConf),
+			objs:        []runtime.Object{esFixture.DeepCopy(), esCerts},
+			wantMessage: "Dry run: no change planned",
+		},
+		{
+			name:        "Elasticsearch reference removed",
+			kibana:      withConf(kbv1.Kibana{ObjectMeta: kibanaFixtureObjectMeta}, establishedConf),
+			objs:        []runtime.Object{esFixture.DeepCopy(), esCerts},
+			wantMessage: "Dry run: change planned to the Elasticsearch configuration",
+			wantChange: `authSecretName: "kibana-foo-kibana-user" -> ""
+authSecretKey: "default-kibana-foo-kibana-user" -> ""
+authSecretNamespace: "default" -> ""
+caCertProvided: "true" -> "false"
+caSecretName: "kibana-foo-kb-es-ca" -> ""
+url: "https://es-foo-es-http.default.svc:9200" -> ""`,
+		},
+		{
+			name: "external Elasticsearch",
+			kibana: withConf(kbv1.Kibana{
+				ObjectMeta: kibanaFixtureObjectMeta,
+				Spec: kbv1.KibanaSpec{ExternalElasticsearch: &commonv1.ExternalElasticsearch{
+					URL:            "https://es.example.com:9200",
+					AuthSecretName: "es-credentials",
+					AuthSecretKey:  "kibana",
+				}},
+			}, nil),
+			wantMessage: "Dry run: change planned to the Elasticsearch configuration",
+			wantChange: `authSecretName: "" -> "es-credentials"
+authSecretKey: "" -> "kibana"
+authSecretNamespace: "" -> "default"
+url: "" -> "https://es.example.com:9200"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrappedFakeClient(tt.objs...)
+			r := &ReconcileAssociation{Client: c}
+			status, message, change, err := r.planAssociationConf(tt.kibana)
+			assert.NoError(t, err)
+			assert.Equal(t, commonv1.AssociationPending, status)
+			assert.Equal(t, tt.wantMessage, message)
+			assert.Equal(t, tt.wantChange, change)
+			// the association secrets are not created
+			for _, name := range []string{userSecretName, "kibana-foo-kb-es-ca"} {
+				err := c.Get(types.NamespacedName{Namespace: "default", Name: name}, &corev1.Secret{})
+				assert.True(t, apierrors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func TestReconcileAssociation_reconcileElasticsearchCA_override(t *testing.T) {
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("es-ca")},
+	}
+	gatewayCA := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gateway-ca", ResourceVersion: "3"},
+		Data:       map[string][]byte{"ca.crt": []byte("gateway-ca")},
+	}
+	kb := kibanaFixture.DeepCopy()
+	kb.Annotations = map[string]string{annotation.ElasticsearchCAOverrideAnnotation: "gateway-ca"}
+	esKey := k8s.ExtractNamespacedName(&esFixture)
+
+	// the provided CA secret does not exist yet
+	c := k8s.WrappedFakeClient(esCerts)
+	r := newTestReconciler(t, c)
+	_, err := r.reconcileElasticsearchCA(context.Background(), kb, esKey)
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Contains(t, r.watches.Secrets.Registrations(), esCAWatchName(k8s.ExtractNamespacedName(kb)))
+
+	// the provided CA secret is used as it is
+	c = k8s.WrappedFakeClient(esCerts, gatewayCA)
+	r = newTestReconciler(t, c)
+	caSecret, err := r.reconcileElasticsearchCA(context.Background(), kb, esKey)
+	assert.NoError(t, err)
+	assert.Equal(t, association.CASecret{Name: "gateway-ca", CACertProvided: true, ResourceVersion: "3"}, caSecret)
+	// the Elasticsearch CA is not copied
+	err = c.Get(types.NamespacedName{
+		Namespace: "default",
+		Name:      association.ElasticsearchCACertSecretName(kb, ElasticsearchCASecretSuffix),
+	}, &corev1.Secret{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+// contextClient fails the requests once the context it is configured with is done, like the API server client.
+type contextClient struct {
+	k8s.Client
+	ctx context.Context
+}
+
+func (c contextClient) WithContext(ctx context.Context) k8s.Client {
+	return contextClient{Client: c.Client.WithContext(ctx), ctx: ctx}
+}
+
+func (c contextClient) Get(key client.ObjectKey, obj runtime.Object) error {
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return c.ctx.Err()
+	}
+	return c.Client.Get(key, obj)
+}
+
+func TestReconcileAssociation_Reconcile_timeout(t *testing.T) {
+	r := &ReconcileAssociation{
+		Client:        contextClient{Client: k8s.WrappedFakeClient(kibanaFixture.DeepCopy())},
+		startupJitter: association.NewStartupJitter(0, time.Now()),
+		Parameters:    operator.Parameters{AssociationReconcileTimeout: time.Nanosecond},
+	}
+	// the timeout is not reported as an error
+	result, err := r.Reconcile(reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(&kibanaFixture)})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRequeue, result)
+
+	assert.Equal(t, DefaultReconcileTimeout, (&ReconcileAssociation{}).reconcileTimeout())
+}
+
+func TestReconcileAssociation_isDryRun(t *testing.T) {
+	kb := kibanaFixture.DeepCopy()
+	r := &ReconcileAssociation{}
+	assert.False(t, r.isDryRun(kb))
+	kb.Annotations = map[string]string{annotation.DryRunAnnotation: "true"}
+	assert.True(t, r.isDryRun(kb))
+	// all associations run in dry-run mode
+	r.AssociationDryRun = true
+	assert.True(t, r.isDryRun(kibanaFixture.DeepCopy()))
+}
+
+func TestReconcileAssociation_resultFromStatus(t *testing.T) {
+	forwardRefKibana := kibanaFixture.DeepCopy()
+	forwardRefKibana.Annotations = map[string]string{annotation.ForwardReferenceAnnotation: "true"}
+	tests := []struct {
+		name        string
+		kibana      *kbv1.Kibana
+		status      commonv1.AssociationStatus
+		runtimeObjs []runtime.Object
+		interval    time.Duration
+		want        reconcile.Result
+	}{
+		{
+			name:   "pending: default requeue",
+			kibana: kibanaFixture.DeepCopy(),
+			status: commonv1.AssociationPending,
+			want:   defaultRequeue,
+		},
+		{
+			name:     "pending: configured requeue interval",
+			kibana:   kibanaFixture.DeepCopy(),
+			status:   commonv1.AssociationPending,
+			interval: time.Second,
+			want:     reconcile.Result{Requeue: true, RequeueAfter: time.Second},
+		},
+		{
+			name:   "degraded: default requeue",
+			kibana: kibanaFixture.DeepCopy(),
+			status: commonv1.AssociationDegraded,
+			want:   defaultRequeue,
+		},
+		{
+			name:   "failed: no requeue",
+			kibana: kibanaFixture.DeepCopy(),
+			status: commonv1.AssociationFailed,
+			want:   reconcile.Result{},
+		},
+		{
+			name:   "pending forward reference with missing Elasticsearch: long requeue",
+			kibana: forwardRefKibana,
+			status: commonv1.AssociationPending,
+			want:   forwardReferenceRequeue,
+		},
+		{
+			name:        "pending forward reference with existing Elasticsearch: default requeue",
+			kibana:      forwardRefKibana,
+			status:      commonv1.AssociationPending,
+			runtimeObjs: []runtime.Object{&esFixture},
+			want:        defaultRequeue,
+		},
+		{
+			name:   "established forward reference: no requeue",
+			kibana: forwardRefKibana,
+			status: commonv1.AssociationEstablished,
+			want:   reconcile.Result{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ReconcileAssociation{
+				Client:         k8s.WrappedFakeClient(tt.runtimeObjs...),
+				Parameters:     operator.Parameters{AssociationRequeueInterval: tt.interval},
+				pendingBackoff: association.NewPendingBackoff(association.DefaultMaxPendingBackoff),
+			}
+			assert.Equal(t, tt.want, r.resultFromStatus(tt.kibana, tt.status))
+		})
+	}
+}
+
+func TestReconcileAssociation_resultFromStatus_backoff(t *testing.T) {
+	r := &ReconcileAssociation{
+		Client:         k8s.WrappedFakeClient(),
+		pendingBackoff: association.NewPendingBackoff(association.DefaultMaxPendingBackoff),
+	}
+	kb := kibanaFixture.DeepCopy()
+	// the delay increases while the association remains pending
+	assert.Equal(t, defaultRequeue, r.resultFromStatus(kb, commonv1.AssociationPending))
+	assert.Equal(t, reconcile.Result{Requeue: true, RequeueAfter: 20 * time.Second}, r.resultFromStatus(kb, commonv1.AssociationPending))
+	// and is reset once it is established
+	assert.Equal(t, reconcile.Result{}, r.resultFromStatus(kb, commonv1.AssociationEstablished))
+	assert.Equal(t, defaultRequeue, r.resultFromStatus(kb, commonv1.AssociationPending))
+}
+
+func TestReconcileAssociation_onDelete(t *testing.T) {
+	kibanaKey := k8s.ExtractNamespacedName(&kibanaFixture)
+	userSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      userName,
+		Labels:    NewUserLabelSelector(kibanaKey),
+	}}
+	unrelatedUserSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "default-kibana-bar-kibana-user",
+		Labels:    NewUserLabelSelector(types.NamespacedName{Namespace: "default", Name: "kibana-bar"}),
+	}}
+	c := k8s.WrappedFakeClient(userSecret, unrelatedUserSecret)
+	r := newTestReconciler(t, c)
+	r.esCallsLimiter = association.NewESCallsLimiter(association.DefaultESCallsInterval, association.DefaultESCallsBurst)
+	r.failureGrace = association.NewFailureGracePeriod(0)
+	r.startupJitter = association.NewStartupJitter(0, time.Now())
+	r.credentialsVerdicts = association.NewProbeVerdicts()
+	r.publisher = association.NoopPublisher{}
+
+	assert.NoError(t, r.onDelete(kibanaKey))
+	assert.True(t, apierrors.IsNotFound(c.Get(k8s.ExtractNamespacedName(userSecret), &corev1.Secret{})))
+	assert.NoError(t, c.Get(k8s.ExtractNamespacedName(unrelatedUserSecret), &corev1.Secret{}))
+	// resources already deleted are ignored
+	assert.NoError(t, r.onDelete(kibanaKey))
+	// no watch registered yet
+	r.watches = watches.DynamicWatches{}
+	assert.NoError(t, r.onDelete(kibanaKey))
+}
+
+// recordingPublisher records the published association lifecycle events.
+type recordingPublisher struct {
+	events []association.LifecycleEvent
+}
+
+func (p *recordingPublisher) Publish(event association.LifecycleEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestReconcileAssociation_onDelete_publishedOnce(t *testing.T) {
+	kibanaKey := k8s.ExtractNamespacedName(&kibanaFixture)
+	r := newTestReconciler(t, k8s.WrappedFakeClient())
+	r.esCallsLimiter = association.NewESCallsLimiter(association.DefaultESCallsInterval, association.DefaultESCallsBurst)
+	r.failureGrace = association.NewFailureGracePeriod(0)
+	r.startupJitter = association.NewStartupJitter(0, time.Now())
+	r.credentialsVerdicts = association.NewProbeVerdicts()
+	publisher := &recordingPublisher{}
+	r.publisher = publisher
+	require.NoError(t, r.watches.ElasticsearchClusters.AddHandler(watches.NamedWatch{
+		Name:    elasticsearchWatchName(kibanaKey),
+		Watched: []types.NamespacedName{{Namespace: "default", Name: "es-foo"}},
+		Watcher: kibanaKey,
+	}))
+
+	// the deletion of a tracked association is published once cleaned up
+	require.NoError(t, r.onDelete(kibanaKey))
+	require.Len(t, publisher.events, 1)
+	require.Equal(t, association.LifecycleEventDeleted, publisher.events[0].Type)
+	require.Empty(t, r.watches.ElasticsearchClusters.Registrations())
+	// but not on the following reconciliations of the deleted Kibana
+	require.NoError(t, r.onDelete(kibanaKey))
+	require.Len(t, publisher.events, 1)
+}
+
+func TestReconcileAssociation_onDelete_inventory(t *testing.T) {
+	received := make(chan association.InventoryRecord, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record association.InventoryRecord
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+		received <- record
+	}))
+	defer server.Close()
+
+	r := newTestReconciler(t, k8s.WrappedFakeClient())
+	r.esCallsLimiter = association.NewESCallsLimiter(association.DefaultESCallsInterval, association.DefaultESCallsBurst)
+	r.failureGrace = association.NewFailureGracePeriod(0)
+	r.startupJitter = association.NewStartupJitter(0, time.Now())
+	r.credentialsVerdicts = association.NewProbeVerdicts()
+	r.publisher = association.NoopPublisher{}
+	r.inventory = association.NewInventoryExporter(server.URL)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		_ = r.inventory.Start(stop)
+	}()
+
+	// the deletion is exported even if the association is not tracked
+	require.NoError(t, r.onDelete(k8s.ExtractNamespacedName(&kibanaFixture)))
+	select {
+	case record := <-received:
+		require.Equal(t, association.InventoryRecord{Kind: kibanaKind, Namespace: "default", Name: "kibana-foo", Deleted: true}, record)
+	case <-time.After(5 * time.Second):
+		t.Fatal("deletion not exported to the inventory")
+	}
+}
+
+func TestReconcileAssociation_reconcileInternal_removedReference(t *testing.T) {
+	conf := `{"authSecretName":"kibana-foo-kibana-user","authSecretKey":"default-kibana-foo-kibana-user","url":"https://es-foo-es-http.default.svc:9200"}`
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantConf    bool
+	}{
+		{
+			name:        "configuration set by the operator is removed",
+			annotations: map[string]string{annotation.AssociationConfAnnotation: conf},
+		},
+		{
+			name: "configuration managed manually is preserved",
+			annotations: map[string]string{
+				annotation.AssociationConfAnnotation: conf,
+				annotation.ConfOwnerAnnotation:       string(commonv1.AssociationConfOwnerManual),
+			},
+			wantConf: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := kibanaFixture.DeepCopy()
+			kb.Spec.ElasticsearchRef = commonv1.ObjectSelector{}
+			kb.Spec.Config = &commonv1.Config{Data: map[string]interface{}{"elasticsearch.requestTimeout": float64(60000)}}
+			kb.Annotations = tt.annotations
+			c := k8s.WrappedFakeClient(kb)
+			status, err := newTestReconciler(t, c).reconcileInternal(context.Background(), kb)
+			assert.NoError(t, err)
+			assert.Equal(t, commonv1.AssociationUnknown, status)
+
+			var updated kbv1.Kibana
+			assert.NoError(t, c.Get(k8s.ExtractNamespacedName(kb), &updated))
+			_, hasConf := updated.Annotations[annotation.AssociationConfAnnotation]
+			assert.Equal(t, tt.wantConf, hasConf)
+			// the Kibana configuration set by the user is preserved
+			assert.Equal(t, kb.Spec.Config, updated.Spec.Config)
+		})
+	}
+}
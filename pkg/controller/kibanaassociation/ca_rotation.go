@@ -0,0 +1,64 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	kblabel "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// kibanaUsesStagedCA returns a function checking whether the given Kibana uses the CA bundle staged at a given time.
+// Kibana reads its CA bundle on startup, and its pods are rolled when the bundle changes: the staged bundle is in use
+// once all the Kibana pods have been created since it was staged, and are ready.
+func (r *ReconcileAssociation) kibanaUsesStagedCA(kibana *kbv1.Kibana) association.StagedCAInUseFunc {
+	return func(stagedAt time.Time) (bool, error) {
+		var pods corev1.PodList
+		if err := r.List(&pods,
+			client.InNamespace(kibana.Namespace),
+			client.MatchingLabels{kblabel.KibanaNameLabelName: kibana.Name},
+		); err != nil {
+			return false, err
+		}
+		if len(pods.Items) == 0 {
+			return false, nil
+		}
+		for _, pod := range pods.Items {
+			// creation timestamps have a one second precision, like the staged-at annotation
+			if pod.CreationTimestamp.Time.Before(stagedAt) || !k8s.IsPodReady(pod) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// caRotationPhase returns the phase of the orchestrated rotation of the Elasticsearch CA trusted by the given Kibana.
+func (r *ReconcileAssociation) caRotationPhase(kibana *kbv1.Kibana) (commonv1.AssociationCARotationPhase, error) {
+	if !association.IsCARotationOrchestrated(kibana) {
+		return "", nil
+	}
+	staged, err := association.IsCARotationStaged(r.Client, kibana, ElasticsearchCASecretSuffix)
+	if err != nil || !staged {
+		return "", err
+	}
+	return commonv1.AssociationCARotationStaged, nil
+}
+
+// caRotationResult returns the reconcile result requeuing the association while a CA rotation is staged.
+func caRotationResult(phase commonv1.AssociationCARotationPhase) reconcile.Result {
+	if phase == commonv1.AssociationCARotationStaged {
+		return caRotationRequeue
+	}
+	return reconcile.Result{}
+}
@@ -0,0 +1,101 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	kblabel "github.com/elastic/cloud-on-k8s/pkg/controller/kibana/label"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileAssociation_kibanaUsesStagedCA(t *testing.T) {
+	stagedAt := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	kibanaPod := func(name string, createdAt time.Time, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         kibanaFixture.Namespace,
+				Name:              name,
+				Labels:            map[string]string{kblabel.KibanaNameLabelName: kibanaFixture.Name},
+				CreationTimestamp: metav1.NewTime(createdAt),
+			},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: status},
+				{Type: corev1.ContainersReady, Status: status},
+			}},
+		}
+	}
+	tests := []struct {
+		name string
+		pods []runtime.Object
+		want bool
+	}{
+		{name: "no Kibana pod", want: false},
+		{
+			name: "pod created before the CA was staged",
+			pods: []runtime.Object{kibanaPod("a", stagedAt.Add(time.Second), true), kibanaPod("b", stagedAt.Add(-time.Second), true)},
+			want: false,
+		},
+		{
+			name: "pod not ready",
+			pods: []runtime.Object{kibanaPod("a", stagedAt.Add(time.Second), true), kibanaPod("b", stagedAt.Add(time.Second), false)},
+			want: false,
+		},
+		{
+			name: "all pods restarted with the staged CA",
+			pods: []runtime.Object{kibanaPod("a", stagedAt, true), kibanaPod("b", stagedAt.Add(time.Second), true)},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ReconcileAssociation{Client: k8s.WrappedFakeClient(tt.pods...)}
+			inUse, err := r.kibanaUsesStagedCA(kibanaFixture.DeepCopy())(stagedAt)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, inUse)
+		})
+	}
+}
+
+func TestReconcileAssociation_caRotationPhase(t *testing.T) {
+	stagedCopy := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: kibanaFixture.Namespace, Name: "kibana-foo-kb-es-ca"},
+		Data:       map[string][]byte{"ca.crt": []byte("new\nold"), association.PreviousCAFileName: []byte("old")},
+	}
+	kb := kibanaFixture.DeepCopy()
+	r := &ReconcileAssociation{Client: k8s.WrappedFakeClient(stagedCopy)}
+
+	// the rotation is not orchestrated
+	phase, err := r.caRotationPhase(kb)
+	require.NoError(t, err)
+	require.Equal(t, commonv1.AssociationCARotationPhase(""), phase)
+	require.Equal(t, reconcile.Result{}, caRotationResult(phase))
+
+	kb.Annotations = map[string]string{annotation.CARotationAnnotation: association.CARotationOrchestrated}
+	phase, err = r.caRotationPhase(kb)
+	require.NoError(t, err)
+	require.Equal(t, commonv1.AssociationCARotationStaged, phase)
+	require.Equal(t, caRotationRequeue, caRotationResult(phase))
+
+	// the rotation is over
+	r.Client = k8s.WrappedFakeClient()
+	phase, err = r.caRotationPhase(kb)
+	require.NoError(t, err)
+	require.Equal(t, commonv1.AssociationCARotationPhase(""), phase)
+}
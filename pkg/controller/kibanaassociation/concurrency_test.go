@@ -0,0 +1,167 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// optimisticClient rejects the updates of objects whose resource version is not the latest one with a conflict, as the
+// API server does. The fake client accepts them.
+type optimisticClient struct {
+	k8s.Client
+	mutex sync.Mutex
+}
+
+func (c *optimisticClient) Update(obj runtime.Object, opts ...client.UpdateOption) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	current := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	if err := c.Client.Get(k8s.ExtractNamespacedName(accessor), current); err != nil {
+		return err
+	}
+	currentAccessor, err := meta.Accessor(current)
+	if err != nil {
+		return err
+	}
+	if currentAccessor.GetResourceVersion() != accessor.GetResourceVersion() {
+		return apierrors.NewConflict(kbv1.GroupVersion.WithResource("kibanas").GroupResource(), accessor.GetName(), nil)
+	}
+	return c.Client.Update(obj, opts...)
+}
+
+func concurrencyFixtures() (*kbv1.Kibana, []runtime.Object) {
+	es := esFixture.DeepCopy()
+	es.Spec.Version = "7.5.0"
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")},
+	}
+	return kibanaFixture.DeepCopy(), []runtime.Object{es, esCerts}
+}
+
+func fetchKibana(t *testing.T, c k8s.Client) kbv1.Kibana {
+	var kb kbv1.Kibana
+	require.NoError(t, association.FetchWithAssociation(context.Background(), c,
+		reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(&kibanaFixture)}, &kb))
+	return kb
+}
+
+func TestReconcileAssociation_reconcileInternal_conflictRetried(t *testing.T) {
+	tests := []struct {
+		name string
+		// concurrentUpdate is applied by another writer to the Kibana resource read by the reconciliation
+		concurrentUpdate func(kb *kbv1.Kibana)
+		wantStatus       commonv1.AssociationStatus
+		wantConf         bool
+	}{
+		{
+			name:             "metadata updated: retried with the latest Kibana",
+			concurrentUpdate: func(kb *kbv1.Kibana) { kb.Labels = map[string]string{"updated": "true"} },
+			wantStatus:       commonv1.AssociationEstablished,
+			wantConf:         true,
+		},
+		{
+			name:             "spec updated: not retried",
+			concurrentUpdate: func(kb *kbv1.Kibana) { kb.Generation++ },
+			wantStatus:       commonv1.AssociationPending,
+		},
+		{
+			name: "Kibana scaled: retried with the latest Kibana",
+			concurrentUpdate: func(kb *kbv1.Kibana) {
+				kb.Spec.Count = 3
+				kb.Generation++
+			},
+			wantStatus: commonv1.AssociationEstablished,
+			wantConf:   true,
+		},
+		{
+			name: "Kibana scaled and reconfigured: not retried",
+			concurrentUpdate: func(kb *kbv1.Kibana) {
+				kb.Spec.Count = 3
+				kb.Spec.Image = "my-image"
+				kb.Generation++
+			},
+			wantStatus: commonv1.AssociationPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb, objs := concurrencyFixtures()
+			c := &optimisticClient{Client: k8s.WrappedFakeClient(append(objs, kb)...)}
+			r := newTestReconciler(t, c)
+
+			stale := fetchKibana(t, c)
+			updated := fetchKibana(t, c)
+			tt.concurrentUpdate(&updated)
+			require.NoError(t, c.Update(&updated))
+
+			status, err := r.reconcileInternal(context.Background(), &stale)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, status)
+
+			persisted := fetchKibana(t, c)
+			require.Equal(t, tt.wantConf, persisted.AssociationConf() != nil)
+			// the concurrent update is preserved
+			require.Equal(t, updated.Labels, persisted.Labels)
+			require.Equal(t, updated.Generation, persisted.Generation)
+			require.Equal(t, updated.Spec, persisted.Spec)
+		})
+	}
+}
+
+func TestReconcileAssociation_reconcileInternal_concurrent(t *testing.T) {
+	kb, objs := concurrencyFixtures()
+	c := &optimisticClient{Client: k8s.WrappedFakeClient(append(objs, kb)...)}
+	r := newTestReconciler(t, c)
+
+	// all reconciliations start from the same version of the shared Kibana
+	const reconciliations = 5
+	kibanas := make([]kbv1.Kibana, reconciliations)
+	for i := range kibanas {
+		kibanas[i] = fetchKibana(t, c)
+	}
+	statuses := make([]commonv1.AssociationStatus, reconciliations)
+	errs := make([]error, reconciliations)
+	var wg sync.WaitGroup
+	for i := range kibanas {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i], errs[i] = r.reconcileInternal(context.Background(), &kibanas[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range kibanas {
+		require.NoError(t, errs[i])
+		require.Equal(t, commonv1.AssociationEstablished, statuses[i])
+	}
+	persisted := fetchKibana(t, c)
+	require.NotNil(t, persisted.AssociationConf())
+	// the reconciliations losing the race retry with the latest Kibana and converge on the same configuration
+	require.Equal(t, kibanas[0].AssociationConf(), persisted.AssociationConf())
+}
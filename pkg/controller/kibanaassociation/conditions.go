@@ -0,0 +1,72 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+)
+
+// observeConditions returns the conditions of the association of the given Kibana resource: one per missing
+// dependency among the observed ones, and whether its association configuration was set by this controller.
+// The transition time of a condition which did not change since the last observation is preserved, so the status is
+// not updated on every reconciliation.
+func (r *ReconcileAssociation) observeConditions(kibana *kbv1.Kibana, status associationStatus, now time.Time) []commonv1.AssociationCondition {
+	if !kibana.Spec.ElasticsearchRef.IsDefined() {
+		return nil
+	}
+	esRefKey, err := association.ElasticsearchRefKey(r.apiReader, kibana, r.OperatorNamespace)
+	if err != nil {
+		return nil
+	}
+	var conditions []commonv1.AssociationCondition
+	for _, dep := range r.dependencies(kibana, esRefKey) {
+		if !isMissing(status.dependencies, dep) {
+			continue
+		}
+		conditions = append(conditions, commonv1.AssociationCondition{
+			Reason:  dep.missing,
+			Message: fmt.Sprintf("%s %s not found", dep.kind, dep.key),
+		})
+	}
+	conf := kibana.AssociationConf()
+	if status.status == commonv1.AssociationEstablished && status.confOwner != commonv1.AssociationConfOwnerManual && conf.URLIsConfigured() {
+		conditions = append(conditions, commonv1.AssociationCondition{
+			Reason:  commonv1.AssociationKibanaUpdated,
+			Message: fmt.Sprintf("Kibana configured to use Elasticsearch at %s", conf.GetURL()),
+		})
+	}
+	for i := range conditions {
+		conditions[i].LastTransitionTime = conditionTransitionTime(kibana.Status.AssociationConditions, conditions[i], now)
+	}
+	return conditions
+}
+
+// isMissing returns true if the given dependency was observed as not existing.
+func isMissing(observed []commonv1.AssociationDependency, dep dependency) bool {
+	for _, o := range observed {
+		if o.Kind == dep.kind && o.Namespace == dep.key.Namespace && o.Name == dep.key.Name {
+			return !o.Exists
+		}
+	}
+	return false
+}
+
+// conditionTransitionTime returns the transition time of the given condition in the previous conditions if it did not
+// change, or now otherwise.
+func conditionTransitionTime(previous []commonv1.AssociationCondition, condition commonv1.AssociationCondition, now time.Time) metav1.Time {
+	for _, p := range previous {
+		if p.Reason == condition.Reason && p.Message == condition.Message {
+			return p.LastTransitionTime
+		}
+	}
+	return metav1.NewTime(now.Truncate(time.Second))
+}
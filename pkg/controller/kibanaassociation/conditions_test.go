@@ -0,0 +1,63 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileAssociation_observeConditions(t *testing.T) {
+	firstObservation := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	secondObservation := firstObservation.Add(time.Hour)
+	userSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: userName}}
+	r := &ReconcileAssociation{Client: k8s.WrappedFakeClient(userSecret)}
+	at := metav1.NewTime(firstObservation)
+
+	// no Elasticsearch reference
+	require.Nil(t, r.observeConditions(&kbv1.Kibana{ObjectMeta: kibanaFixtureObjectMeta}, associationStatus{}, firstObservation))
+
+	// Elasticsearch, the credentials and the CA are missing
+	kb := kibanaFixture.DeepCopy()
+	status := associationStatus{status: commonv1.AssociationPending}
+	var err error
+	status.dependencies, err = r.observeDependencies(kb, firstObservation)
+	require.NoError(t, err)
+	conditions := r.observeConditions(kb, status, firstObservation)
+	require.Equal(t, []commonv1.AssociationCondition{
+		{Reason: commonv1.AssociationElasticsearchNotFound, Message: "Elasticsearch default/es-foo not found", LastTransitionTime: at},
+		{Reason: commonv1.AssociationUsersSecretMissing, Message: "Secret default/kibana-foo-kibana-user not found", LastTransitionTime: at},
+		{Reason: commonv1.AssociationCACertMissing, Message: "Secret default/es-foo-es-http-certs-public not found", LastTransitionTime: at},
+	}, conditions)
+
+	// the association is established: the transition time of unchanged conditions is preserved
+	kb.Status.AssociationConditions = conditions
+	kb.SetAssociationConf(&commonv1.AssociationConf{
+		AuthSecretName: userSecretName,
+		AuthSecretKey:  userName,
+		URL:            "https://es-foo-es-http.default.svc:9200",
+	})
+	status.status = commonv1.AssociationEstablished
+	conditions = r.observeConditions(kb, status, secondObservation)
+	require.Len(t, conditions, 4)
+	require.Equal(t, at, conditions[0].LastTransitionTime)
+	require.Equal(t, commonv1.AssociationCondition{
+		Reason:             commonv1.AssociationKibanaUpdated,
+		Message:            "Kibana configured to use Elasticsearch at https://es-foo-es-http.default.svc:9200",
+		LastTransitionTime: metav1.NewTime(secondObservation),
+	}, conditions[3])
+
+	// not updated by this controller while the configuration is managed manually
+	status.confOwner = commonv1.AssociationConfOwnerManual
+	require.Len(t, r.observeConditions(kb, status, secondObservation), 3)
+}
@@ -0,0 +1,142 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+)
+
+// deferRestart defers the changes to the association configuration restarting Kibana if it is annotated to. They are
+// applied at once during the maintenance window or when enough of them accumulated, Kibana keeps its current
+// configuration until then. A change of the Elasticsearch CA is never deferred: Kibana could not connect to
+// Elasticsearch anymore until the window opens. It returns a Pending status along with a message and the deferred
+// changes while they are deferred, the rest of the association being still reconciled, or an unknown status if the
+// association can be fully reconciled.
+func (r *ReconcileAssociation) deferRestart(
+	kibana *kbv1.Kibana,
+	now time.Time,
+) (commonv1.AssociationStatus, string, *commonv1.AssociationDeferredChange, error) {
+	if !kibana.Spec.ElasticsearchRef.IsDefined() || !kibana.AssociationConf().IsConfigured() {
+		// there is no configuration to keep in the meantime
+		return commonv1.AssociationUnknown, "", nil, nil
+	}
+	deferral, deferred, err := association.RestartDeferralFor(kibana.ObjectMeta)
+	if err != nil {
+		// apply the changes rather than deferring them indefinitely
+		r.recorder.Event(kibana, corev1.EventTypeWarning, events.EventAssociationError, err.Error())
+		return commonv1.AssociationUnknown, "", nil, nil
+	}
+	if !deferred {
+		return commonv1.AssociationUnknown, "", nil, nil
+	}
+	digest, pending, err := r.pendingRestart(kibana)
+	if err != nil {
+		return commonv1.AssociationPending, "", nil, err
+	}
+	if !pending {
+		return commonv1.AssociationUnknown, "", nil, nil
+	}
+	change := nextDeferredChange(kibana.Status.AssociationDeferredChange, digest, now)
+	if deferral.Apply(change.Changes, now) {
+		log.Info("Applying deferred changes to the Elasticsearch configuration", "namespace", kibana.Namespace,
+			"kibana_name", kibana.Name, "changes", change.Changes)
+		return commonv1.AssociationUnknown, "", nil, nil
+	}
+	return commonv1.AssociationPending, deferredMessage(deferral, change), change, nil
+}
+
+// pendingRestart returns true if the reconciliation would change the association configuration of the given Kibana, and
+// not its copy of the Elasticsearch CA, along with a digest of the change.
+func (r *ReconcileAssociation) pendingRestart(kibana *kbv1.Kibana) (string, bool, error) {
+	expected, message, err := r.plannedAssociationConf(kibana)
+	if err != nil || message != "" || expected == nil {
+		// not deferred, the reconciliation reports the issue or removes the configuration
+		return "", false, err
+	}
+	esRefKey, err := association.ElasticsearchRefKey(r.apiReader, kibana, r.OperatorNamespace)
+	if err != nil {
+		return "", false, err
+	}
+	// an overridden CA is used as it is, there is no copy to compare with the Elasticsearch CA
+	if _, isOverridden := caOverride(kibana); !isOverridden {
+		caChanged, _, err := association.CASecretChanged(r.Client, kibana, esRefKey, ElasticsearchCASecretSuffix, r.globalCA)
+		if err != nil {
+			return "", false, err
+		}
+		if caChanged {
+			// applied right away along with any other change, the previous CA may not be trusted anymore
+			return "", false, nil
+		}
+	}
+	if expected.Equivalent(kibana.AssociationConf(), kibana.Namespace) {
+		return "", false, nil
+	}
+	conf, err := json.Marshal(expected)
+	if err != nil {
+		return "", false, err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(conf)), true, nil
+}
+
+// nextDeferredChange returns the deferred changes once the change with the given digest is observed, counting it if
+// it differs from the latest deferred one.
+func nextDeferredChange(previous *commonv1.AssociationDeferredChange, digest string, now time.Time) *commonv1.AssociationDeferredChange {
+	if previous == nil {
+		return &commonv1.AssociationDeferredChange{
+			// the status is serialized with a second precision
+			Since:   metav1.NewTime(now.UTC().Truncate(time.Second)),
+			Changes: 1,
+			Digest:  digest,
+		}
+	}
+	change := previous.DeepCopy()
+	if change.Digest != digest {
+		change.Changes++
+		change.Digest = digest
+	}
+	return change
+}
+
+// deferredMessage describes the given deferred changes.
+func deferredMessage(deferral association.RestartDeferral, change *commonv1.AssociationDeferredChange) string {
+	var until []string
+	if deferral.Window != nil {
+		until = append(until, fmt.Sprintf("the maintenance window %s UTC", deferral.Window))
+	}
+	if deferral.Batch > 0 {
+		until = append(until, fmt.Sprintf("%d changes accumulated", deferral.Batch))
+	}
+	return fmt.Sprintf("%d change(s) to the Elasticsearch configuration deferred until %s", change.Changes, strings.Join(until, " or "))
+}
+
+// deferralResult returns the reconcile result requeuing the association at the start of the maintenance window, if
+// changes are deferred until then.
+func deferralResult(kibana *kbv1.Kibana, change *commonv1.AssociationDeferredChange, now time.Time) reconcile.Result {
+	if change == nil {
+		return reconcile.Result{}
+	}
+	deferral, _, err := association.RestartDeferralFor(kibana.ObjectMeta)
+	if err != nil {
+		return reconcile.Result{}
+	}
+	delay := deferral.Until(now)
+	if delay == 0 {
+		return reconcile.Result{}
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: delay}
+}
@@ -0,0 +1,178 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileAssociation_deferRestart(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("new")},
+	}
+	caCopy := func(ca string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kibana-foo-kb-es-ca"},
+			Data:       map[string][]byte{"ca.crt": []byte(ca)},
+		}
+	}
+	establishedConf := func(url string) *commonv1.AssociationConf {
+		return &commonv1.AssociationConf{
+			AuthSecretName:      userSecretName,
+			AuthSecretKey:       userName,
+			AuthSecretNamespace: "default",
+			CACertProvided:      true,
+			CASecretName:        "kibana-foo-kb-es-ca",
+			URL:                 url,
+		}
+	}
+	// the association configuration Kibana is expected to use
+	currentURL := "https://es-foo-es-http.default.svc:9200"
+	// a previous association configuration, changed by the reconciliation
+	previousURL := "https://es-previous-es-http.default.svc:9200"
+	kibana := func(annotations map[string]string, deferred *commonv1.AssociationDeferredChange, url string) *kbv1.Kibana {
+		kb := kibanaFixture.DeepCopy()
+		kb.Annotations = annotations
+		kb.Status.AssociationDeferredChange = deferred
+		kb.SetAssociationConf(establishedConf(url))
+		return kb
+	}
+	window := map[string]string{annotation.RestartWindowAnnotation: "02:00-04:00"}
+	reconcile := func(t *testing.T, kb *kbv1.Kibana, ca string, now time.Time) (commonv1.AssociationStatus, string, *commonv1.AssociationDeferredChange) {
+		r := &ReconcileAssociation{
+			Client:   k8s.WrappedFakeClient(esFixture.DeepCopy(), esCerts, caCopy(ca)),
+			recorder: record.NewFakeRecorder(10),
+		}
+		status, message, change, err := r.deferRestart(kb, now)
+		require.NoError(t, err)
+		return status, message, change
+	}
+
+	// changes are not deferred without annotation
+	status, _, change := reconcile(t, kibana(nil, nil, previousURL), "new", now)
+	require.Equal(t, commonv1.AssociationUnknown, status)
+	require.Nil(t, change)
+
+	// nothing to defer
+	status, _, change = reconcile(t, kibana(window, nil, currentURL), "new", now)
+	require.Equal(t, commonv1.AssociationUnknown, status)
+	require.Nil(t, change)
+
+	// the CA changed: never deferred, Kibana would not trust the new CA until the maintenance window
+	status, _, change = reconcile(t, kibana(window, nil, currentURL), "old", now)
+	require.Equal(t, commonv1.AssociationUnknown, status)
+	require.Nil(t, change)
+	// nor along with a change of the association configuration
+	status, _, change = reconcile(t, kibana(window, nil, previousURL), "old", now)
+	require.Equal(t, commonv1.AssociationUnknown, status)
+	require.Nil(t, change)
+
+	// the association configuration changed: deferred until the maintenance window, the association is pending
+	status, message, change := reconcile(t, kibana(window, nil, previousURL), "new", now)
+	require.Equal(t, commonv1.AssociationPending, status)
+	require.Equal(t, "1 change(s) to the Elasticsearch configuration deferred until the maintenance window 02:00-04:00 UTC", message)
+	require.Equal(t, 1, change.Changes)
+	require.Equal(t, metav1.NewTime(now), change.Since)
+
+	// the same change is observed again: not counted twice
+	deferred := change
+	_, _, change = reconcile(t, kibana(window, deferred, previousURL), "new", now.Add(time.Hour))
+	require.Equal(t, deferred, change)
+
+	// another change: counted and applied once the batch is complete
+	deferred = change.DeepCopy()
+	deferred.Digest = "previous"
+	batch := map[string]string{annotation.RestartWindowAnnotation: "02:00-04:00", annotation.RestartBatchAnnotation: "3"}
+	status, message, change = reconcile(t, kibana(batch, deferred, previousURL), "new", now)
+	require.Equal(t, commonv1.AssociationPending, status)
+	require.Equal(t, "2 change(s) to the Elasticsearch configuration deferred until the maintenance window 02:00-04:00 UTC or 3 changes accumulated", message)
+	require.Equal(t, 2, change.Changes)
+	deferred = change.DeepCopy()
+	deferred.Digest = "previous"
+	status, _, change = reconcile(t, kibana(batch, deferred, previousURL), "new", now)
+	require.Equal(t, commonv1.AssociationUnknown, status)
+	require.Nil(t, change)
+
+	// applied during the maintenance window
+	status, _, change = reconcile(t, kibana(window, deferred, previousURL), "new", time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC))
+	require.Equal(t, commonv1.AssociationUnknown, status)
+	require.Nil(t, change)
+
+	// invalid annotations do not defer the changes
+	status, _, change = reconcile(t, kibana(map[string]string{annotation.RestartWindowAnnotation: "tonight"}, nil, previousURL), "new", now)
+	require.Equal(t, commonv1.AssociationUnknown, status)
+	require.Nil(t, change)
+}
+
+func Test_deferralResult(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	kb := kibanaFixture.DeepCopy()
+	kb.Annotations = map[string]string{annotation.RestartWindowAnnotation: "02:00-04:00"}
+	change := &commonv1.AssociationDeferredChange{Changes: 1}
+
+	require.Equal(t, reconcile.Result{}, deferralResult(kb, nil, now))
+	require.Equal(t, reconcile.Result{Requeue: true, RequeueAfter: 14 * time.Hour}, deferralResult(kb, change, now))
+	kb.Annotations = map[string]string{annotation.RestartBatchAnnotation: "3"}
+	require.Equal(t, reconcile.Result{}, deferralResult(kb, change, now))
+}
+
+func TestReconcileAssociation_reconcile_deferred(t *testing.T) {
+	kb := kibanaFixture.DeepCopy()
+	es := esFixture.DeepCopy()
+	es.Spec.Version = "7.5.0"
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")},
+	}
+	c := k8s.WrappedFakeClient(kb, es, esCerts)
+	r := newTestReconciler(t, c)
+	r.failureGrace = association.NewFailureGracePeriod(0)
+	r.startupJitter = association.NewStartupJitter(0, time.Now())
+	r.publisher = association.NoopPublisher{}
+	request := reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(kb)}
+	status, err := r.reconcileInternal(context.Background(), kb)
+	require.NoError(t, err)
+	require.Equal(t, commonv1.AssociationEstablished, status)
+
+	// change the association configuration outside of the maintenance window, and remove the user
+	var established kbv1.Kibana
+	require.NoError(t, association.FetchWithAssociation(context.Background(), c, request, &established))
+	conf := established.AssociationConf()
+	inTwoHours := time.Now().UTC().Add(2 * time.Hour)
+	established.Annotations[annotation.RestartWindowAnnotation] = fmt.Sprintf("%s-%s",
+		inTwoHours.Format("15:04"), inTwoHours.Add(time.Hour).Format("15:04"))
+	established.Annotations[annotation.ElasticsearchSSLVerificationModeAnnotation] = commonv1.SSLVerificationModeCertificate
+	require.NoError(t, c.Update(&established))
+	userKey := association.UserKey(kb, es.Namespace, kibanaUserSuffix)
+	require.NoError(t, c.Delete(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: userKey.Namespace, Name: userKey.Name}}))
+
+	_, err = r.reconcile(context.Background(), request)
+	require.NoError(t, err)
+	var deferred kbv1.Kibana
+	require.NoError(t, association.FetchWithAssociation(context.Background(), c, request, &deferred))
+	// the change is deferred and reported as pending
+	require.Equal(t, conf, deferred.AssociationConf())
+	require.Equal(t, commonv1.AssociationPending, deferred.Status.AssociationStatus)
+	require.NotNil(t, deferred.Status.AssociationDeferredChange)
+	// the user is still reconciled
+	require.NoError(t, c.Get(userKey, &corev1.Secret{}))
+}
@@ -0,0 +1,114 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+)
+
+// dependency is an object the association of a Kibana resource depends on.
+type dependency struct {
+	kind string
+	key  types.NamespacedName
+	obj  runtime.Object
+	// missing is the reason of the condition reported while the object does not exist
+	missing commonv1.AssociationConditionReason
+}
+
+// dependencies returns the objects watched for the association of the given Kibana resource, along with the
+// credentials secret used by Kibana.
+func (r *ReconcileAssociation) dependencies(kibana *kbv1.Kibana, esRefKey types.NamespacedName) []dependency {
+	credentialsNamespace := kibana.Namespace
+	if r.AssociationCredentialsNamespace != "" {
+		credentialsNamespace = r.AssociationCredentialsNamespace
+	}
+	deps := []dependency{
+		{kind: "Elasticsearch", key: esRefKey, obj: &esv1.Elasticsearch{}, missing: commonv1.AssociationElasticsearchNotFound},
+		{
+			kind:    "Secret",
+			key:     association.UserKey(kibana, esRefKey.Namespace, kibanaUserSuffix),
+			obj:     &corev1.Secret{},
+			missing: commonv1.AssociationUsersSecretMissing,
+		},
+		{
+			kind: "Secret",
+			key: types.NamespacedName{
+				Namespace: credentialsNamespace,
+				Name:      association.ClearTextSecretKeySelector(kibana, credentialsNamespace, kibanaUserSuffix).Name,
+			},
+			obj:     &corev1.Secret{},
+			missing: commonv1.AssociationUsersSecretMissing,
+		},
+	}
+	for _, ca := range association.CAWatchedSecrets(esRefKey, r.globalCA) {
+		deps = append(deps, dependency{kind: "Secret", key: ca, obj: &corev1.Secret{}, missing: commonv1.AssociationCACertMissing})
+	}
+	return deps
+}
+
+// observeDependencies returns the observed state of the objects the association of the given Kibana resource depends
+// on, or nil if the referenced Elasticsearch cluster cannot be resolved.
+// The transition time of an object which did not change since the last observation is preserved, so the status is
+// not updated on every reconciliation.
+func (r *ReconcileAssociation) observeDependencies(kibana *kbv1.Kibana, now time.Time) ([]commonv1.AssociationDependency, error) {
+	if !kibana.Spec.ElasticsearchRef.IsDefined() {
+		return nil, nil
+	}
+	esRefKey, err := association.ElasticsearchRefKey(r.apiReader, kibana, r.OperatorNamespace)
+	if err != nil {
+		if association.IsAliasNotResolved(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	deps := r.dependencies(kibana, esRefKey)
+	observed := make([]commonv1.AssociationDependency, 0, len(deps))
+	for _, dep := range deps {
+		state := commonv1.AssociationDependency{
+			Kind:      dep.kind,
+			Namespace: dep.key.Namespace,
+			Name:      dep.key.Name,
+		}
+		if err := r.Get(dep.key, dep.obj); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		} else if err == nil {
+			accessor, err := meta.Accessor(dep.obj)
+			if err != nil {
+				return nil, err
+			}
+			state.Exists = true
+			state.ResourceVersion = accessor.GetResourceVersion()
+			state.Generation = accessor.GetGeneration()
+		}
+		state.LastTransitionTime = lastTransitionTime(kibana.Status.AssociationDependencies, state, now)
+		observed = append(observed, state)
+	}
+	return observed, nil
+}
+
+// lastTransitionTime returns the transition time of the given dependency state in the previous observations if it did
+// not change, or now otherwise.
+func lastTransitionTime(previous []commonv1.AssociationDependency, state commonv1.AssociationDependency, now time.Time) metav1.Time {
+	for _, p := range previous {
+		if p.Kind == state.Kind && p.Namespace == state.Namespace && p.Name == state.Name &&
+			p.Exists == state.Exists && p.ResourceVersion == state.ResourceVersion {
+			return p.LastTransitionTime
+		}
+	}
+	return metav1.NewTime(now.Truncate(time.Second))
+}
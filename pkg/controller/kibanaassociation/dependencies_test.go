@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileAssociation_observeDependencies(t *testing.T) {
+	firstObservation := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	secondObservation := firstObservation.Add(time.Hour)
+	es := esFixture.DeepCopy()
+	es.ResourceVersion = "1"
+	es.Generation = 4
+	userSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: userName, ResourceVersion: "2"}}
+	r := &ReconcileAssociation{Client: k8s.WrappedFakeClient(es, userSecret)}
+
+	// no Elasticsearch reference
+	deps, err := r.observeDependencies(&kbv1.Kibana{ObjectMeta: kibanaFixtureObjectMeta}, firstObservation)
+	require.NoError(t, err)
+	require.Nil(t, deps)
+
+	kb := kibanaFixture.DeepCopy()
+	deps, err = r.observeDependencies(kb, firstObservation)
+	require.NoError(t, err)
+	at := metav1.NewTime(firstObservation)
+	require.Equal(t, []commonv1.AssociationDependency{
+		{Kind: "Elasticsearch", Namespace: "default", Name: "es-foo", Exists: true, ResourceVersion: "1", Generation: 4, LastTransitionTime: at},
+		{Kind: "Secret", Namespace: "default", Name: userName, Exists: true, ResourceVersion: "2", LastTransitionTime: at},
+		{Kind: "Secret", Namespace: "default", Name: userSecretName, Exists: false, LastTransitionTime: at},
+		{Kind: "Secret", Namespace: "default", Name: "es-foo-es-http-certs-public", Exists: false, LastTransitionTime: at},
+	}, deps)
+
+	// the transition time of unchanged dependencies is preserved
+	kb.Status.AssociationDependencies = deps
+	userSecret.ResourceVersion = "3"
+	require.NoError(t, r.Update(userSecret))
+	deps, err = r.observeDependencies(kb, secondObservation)
+	require.NoError(t, err)
+	require.Equal(t, at, deps[0].LastTransitionTime)
+	require.NotEqual(t, "2", deps[1].ResourceVersion)
+	require.Equal(t, metav1.NewTime(secondObservation), deps[1].LastTransitionTime)
+	require.Equal(t, at, deps[2].LastTransitionTime)
+}
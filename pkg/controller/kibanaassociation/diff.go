@@ -0,0 +1,49 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"net/url"
+
+	"github.com/google/go-cmp/cmp"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+)
+
+// redacted replaces the credentials removed from the logged association configurations.
+const redacted = "REDACTED"
+
+// associationConfDiff returns a human readable diff between the current and the expected association configurations,
+// for debug logs. Passwords embedded in the URLs are redacted.
+func associationConfDiff(current, expected *commonv1.AssociationConf) string {
+	return cmp.Diff(scrubbedAssociationConf(current), scrubbedAssociationConf(expected))
+}
+
+// scrubbedAssociationConf returns a copy of the given association configuration that can be logged. A nil configuration
+// is returned as an empty one, as both are equivalent.
+func scrubbedAssociationConf(conf *commonv1.AssociationConf) commonv1.AssociationConf {
+	if conf == nil {
+		return commonv1.AssociationConf{}
+	}
+	scrubbed := *conf
+	scrubbed.URL = scrubbedURL(conf.URL)
+	return scrubbed
+}
+
+// scrubbedURL redacts the password of the user information of the given URL, if any.
+func scrubbedURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// cannot tell which part holds credentials, the whole URL is redacted
+		return redacted
+	}
+	if u.User == nil {
+		return rawURL
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	return u.String()
+}
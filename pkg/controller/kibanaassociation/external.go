@@ -0,0 +1,111 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/events"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/user"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/watches"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// reconcileExternalElasticsearch configures the given Kibana to use the external Elasticsearch cluster specified in its
+// spec. The cluster is not managed by the operator: no user is created and no CA is copied, the secrets provided in the
+// Kibana namespace are referenced as they are once they exist. The user created in a previously referenced cluster is
+// deleted.
+func (r *ReconcileAssociation) reconcileExternalElasticsearch(ctx context.Context, kibana *kbv1.Kibana) (commonv1.AssociationStatus, error) {
+	kibanaKey := k8s.ExtractNamespacedName(kibana)
+	external := kibana.Spec.ExternalElasticsearch
+
+	// stop watching any ES cluster previously referenced for this Kibana resource
+	r.watches.ElasticsearchClusters.RemoveHandlerForKey(elasticsearchWatchName(kibanaKey))
+	r.watches.Secrets.RemoveHandlerForKey(esCAWatchName(kibanaKey))
+	// credentials created outside of the Kibana namespace are not garbage collected
+	if err := r.deleteExternalCredentials(kibanaKey); err != nil {
+		return commonv1.AssociationUnknown, err
+	}
+	// the user created in the previously referenced ES cluster must not outlive the association
+	if err := user.DeleteUser(r.Client, NewUserLabelSelector(kibanaKey)); err != nil {
+		return commonv1.AssociationUnknown, err
+	}
+
+	if err := absoluteHTTPURL(external.URL); err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, kibana, events.EventAssociationError, "Invalid external Elasticsearch URL: %v", err)
+		return commonv1.AssociationFailed, nil
+	}
+	verificationMode, err := sslVerificationMode(kibana)
+	if err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, kibana, events.EventAssociationError, "Invalid Elasticsearch SSL verification mode: %v", err)
+		return commonv1.AssociationFailed, nil
+	}
+
+	authSecretKey := types.NamespacedName{Namespace: kibana.Namespace, Name: external.AuthSecretName}
+	watched := []types.NamespacedName{authSecretKey}
+	if external.CASecretName != "" {
+		watched = append(watched, types.NamespacedName{Namespace: kibana.Namespace, Name: external.CASecretName})
+	}
+	// watch the provided secrets, to configure Kibana as soon as they are created
+	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
+		Name:    elasticsearchWatchName(kibanaKey),
+		Watched: watched,
+		Watcher: kibanaKey,
+	}); err != nil {
+		return commonv1.AssociationFailed, err
+	}
+
+	if exists, err := r.secretHasKey(authSecretKey, external.AuthSecretKey); err != nil || !exists {
+		if err == nil {
+			r.recorder.Eventf(kibana, corev1.EventTypeWarning, events.EventAssociationError,
+				"Key %s not found in the external Elasticsearch credentials secret %s", external.AuthSecretKey, authSecretKey)
+		}
+		return commonv1.AssociationPending, err
+	}
+	if external.CASecretName != "" {
+		caSecretKey := types.NamespacedName{Namespace: kibana.Namespace, Name: external.CASecretName}
+		if exists, err := r.secretHasKey(caSecretKey, certificates.CAFileName); err != nil || !exists {
+			if err == nil {
+				r.recorder.Eventf(kibana, corev1.EventTypeWarning, events.EventAssociationError,
+					"Key %s not found in the external Elasticsearch CA secret %s", certificates.CAFileName, caSecretKey)
+			}
+			return commonv1.AssociationPending, err
+		}
+	}
+
+	return r.updateAssociationConf(ctx, externalAssociationConf(external, verificationMode), kibana, types.NamespacedName{})
+}
+
+// externalAssociationConf returns the association configuration of a Kibana using the given external Elasticsearch
+// cluster, with the given SSL verification mode.
+func externalAssociationConf(external *commonv1.ExternalElasticsearch, verificationMode string) *commonv1.AssociationConf {
+	return &commonv1.AssociationConf{
+		AuthSecretName:      external.AuthSecretName,
+		AuthSecretKey:       external.AuthSecretKey,
+		CACertProvided:      external.CASecretName != "",
+		CASecretName:        external.CASecretName,
+		URL:                 external.URL,
+		SSLVerificationMode: verificationMode,
+	}
+}
+
+// secretHasKey returns true if the given secret exists and holds a non-empty value for the given key.
+func (r *ReconcileAssociation) secretHasKey(key types.NamespacedName, dataKey string) (bool, error) {
+	var secret corev1.Secret
+	if err := r.Get(key, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(secret.Data[dataKey]) > 0, nil
+}
@@ -0,0 +1,131 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+func TestReconcileAssociation_reconcileInternal_externalElasticsearch(t *testing.T) {
+	external := commonv1.ExternalElasticsearch{
+		URL:            "https://es.example.com:9200",
+		AuthSecretName: "es-credentials",
+		AuthSecretKey:  "kibana",
+	}
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-credentials"},
+		Data:       map[string][]byte{"kibana": []byte("password")},
+	}
+	ca := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-ca"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca")},
+	}
+	// user created in the Elasticsearch cluster previously referenced by Kibana
+	esUser := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "es-ns",
+			Name:      "kibana-foo-kibana-user",
+			Labels:    NewUserLabelSelector(k8s.ExtractNamespacedName(&kibanaFixture)),
+		},
+	}
+	kibana := func(ref commonv1.ObjectSelector, mutate func(*commonv1.ExternalElasticsearch)) *kbv1.Kibana {
+		kb := kibanaFixture.DeepCopy()
+		kb.Spec.ElasticsearchRef = ref
+		kb.Spec.ExternalElasticsearch = external.DeepCopy()
+		if mutate != nil {
+			mutate(kb.Spec.ExternalElasticsearch)
+		}
+		return kb
+	}
+	tests := []struct {
+		name       string
+		kibana     *kbv1.Kibana
+		objs       []runtime.Object
+		wantStatus commonv1.AssociationStatus
+		wantConf   *commonv1.AssociationConf
+	}{
+		{
+			name:       "configured with the provided credentials",
+			kibana:     kibana(commonv1.ObjectSelector{}, nil),
+			objs:       []runtime.Object{credentials},
+			wantStatus: commonv1.AssociationEstablished,
+			wantConf:   &commonv1.AssociationConf{AuthSecretName: "es-credentials", AuthSecretKey: "kibana", URL: "https://es.example.com:9200"},
+		},
+		{
+			name:       "configured with the provided credentials and CA",
+			kibana:     kibana(commonv1.ObjectSelector{}, func(e *commonv1.ExternalElasticsearch) { e.CASecretName = "es-ca" }),
+			objs:       []runtime.Object{credentials, ca},
+			wantStatus: commonv1.AssociationEstablished,
+			wantConf: &commonv1.AssociationConf{
+				AuthSecretName: "es-credentials",
+				AuthSecretKey:  "kibana",
+				CACertProvided: true,
+				CASecretName:   "es-ca",
+				URL:            "https://es.example.com:9200",
+			},
+		},
+		{
+			name:       "switched from an Elasticsearch reference",
+			kibana:     kibana(commonv1.ObjectSelector{}, nil),
+			objs:       []runtime.Object{credentials, esUser},
+			wantStatus: commonv1.AssociationEstablished,
+			wantConf:   &commonv1.AssociationConf{AuthSecretName: "es-credentials", AuthSecretKey: "kibana", URL: "https://es.example.com:9200"},
+		},
+		{
+			name:       "credentials secret does not exist yet",
+			kibana:     kibana(commonv1.ObjectSelector{}, nil),
+			wantStatus: commonv1.AssociationPending,
+		},
+		{
+			name:       "credentials secret does not hold the user",
+			kibana:     kibana(commonv1.ObjectSelector{}, func(e *commonv1.ExternalElasticsearch) { e.AuthSecretKey = "other" }),
+			objs:       []runtime.Object{credentials},
+			wantStatus: commonv1.AssociationPending,
+		},
+		{
+			name:       "CA secret does not exist yet",
+			kibana:     kibana(commonv1.ObjectSelector{}, func(e *commonv1.ExternalElasticsearch) { e.CASecretName = "es-ca" }),
+			objs:       []runtime.Object{credentials},
+			wantStatus: commonv1.AssociationPending,
+		},
+		{
+			name:       "invalid URL",
+			kibana:     kibana(commonv1.ObjectSelector{}, func(e *commonv1.ExternalElasticsearch) { e.URL = "es.example.com:9200" }),
+			objs:       []runtime.Object{credentials},
+			wantStatus: commonv1.AssociationFailed,
+		},
+		{
+			name:       "along with an Elasticsearch reference",
+			kibana:     kibana(kibanaFixture.Spec.ElasticsearchRef, nil),
+			objs:       []runtime.Object{credentials, esFixture.DeepCopy()},
+			wantStatus: commonv1.AssociationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k8s.WrappedFakeClient(append(tt.objs, tt.kibana)...)
+			r := newTestReconciler(t, c)
+			status, err := r.reconcileInternal(context.Background(), tt.kibana)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, status)
+			require.Equal(t, tt.wantConf, tt.kibana.AssociationConf())
+			// the Elasticsearch cluster is not looked up, no user is created and a previous one is deleted
+			require.Empty(t, r.watches.ElasticsearchClusters.Registrations())
+			var users corev1.SecretList
+			require.NoError(t, c.List(&users, NewUserLabelSelector(k8s.ExtractNamespacedName(tt.kibana))))
+			require.Empty(t, users.Items)
+		})
+	}
+}
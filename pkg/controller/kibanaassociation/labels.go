@@ -39,6 +39,14 @@ func hasBeenCreatedBy(object metav1.Object, kibana *kbv1.Kibana) bool {
 	return true
 }
 
+// NewCredentialsLabels returns the labels applied to the resources holding the credentials of the given Kibana association.
+func NewCredentialsLabels(kibana types.NamespacedName) map[string]string {
+	return map[string]string{
+		AssociationLabelName:      kibana.Name,
+		AssociationLabelNamespace: kibana.Namespace,
+	}
+}
+
 func NewUserLabelSelector(
 	namespacedName types.NamespacedName,
 ) client.MatchingLabels {
@@ -0,0 +1,125 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/certificates"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+// authenticatePath is requested to verify the credentials used by Kibana.
+const authenticatePath = "/_security/_authenticate"
+
+// esClientFactory creates the Elasticsearch client probes are performed with.
+type esClientFactory func(
+	dialer net.Dialer, esURL string, esUser esclient.UserAuth, v version.Version, caCerts []*x509.Certificate,
+) esclient.Client
+
+// errESCallsRateLimited is returned by a probe attempt not performed because of the rate limiting of the Elasticsearch
+// API calls of the association.
+var errESCallsRateLimited = errors.New("Elasticsearch API calls rate limited")
+
+// probeCredentials verifies that Elasticsearch accepts the credentials Kibana uses to authenticate. Only an
+// authentication failure invalidates the credentials: if the probe is rate limited, times out or fails for another
+// reason, the verdict of the previous probe is returned not to report a false failure.
+// The probe targets the Elasticsearch service, never the URL Kibana may be pointed to by the override annotation, so that
+// the credentials are only sent to the referenced Elasticsearch cluster.
+// Each attempt of the probe is subject to the rate limiting of the Elasticsearch API calls of the association.
+func (r *ReconcileAssociation) probeCredentials(ctx context.Context, kibana *kbv1.Kibana, es esv1.Elasticsearch) (bool, error) {
+	key := k8s.ExtractNamespacedName(kibana)
+	if delay := r.esCallsLimiter.Delay(key); delay > 0 {
+		log.V(1).Info("Elasticsearch API calls rate limited, skipping the credentials probe",
+			"namespace", kibana.Namespace, "kibana_name", kibana.Name, "delay", delay)
+		return r.credentialsVerdicts.Get(key), nil
+	}
+
+	client, err := r.probeClient(kibana, es)
+	if err != nil {
+		return r.credentialsVerdicts.Get(key), err
+	}
+	defer client.Close()
+
+	err = r.probe.Run(ctx, func(ctx context.Context) error {
+		if !r.esCallsLimiter.Allow(key) {
+			return errESCallsRateLimited
+		}
+		req, err := http.NewRequest(http.MethodGet, authenticatePath, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Request(ctx, req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}, func(err error) bool {
+		return err != errESCallsRateLimited && !esclient.IsUnauthorized(err)
+	})
+	switch {
+	case err == nil:
+		r.credentialsVerdicts.Set(key, true)
+	case esclient.IsUnauthorized(err):
+		r.credentialsVerdicts.Set(key, false)
+	case err == errESCallsRateLimited:
+		log.V(1).Info("Elasticsearch API calls rate limited, keeping the previous verdict",
+			"namespace", kibana.Namespace, "kibana_name", kibana.Name)
+	default:
+		log.Info("Elasticsearch credentials probe failed, keeping the previous verdict",
+			"namespace", kibana.Namespace, "kibana_name", kibana.Name, "error", err.Error())
+	}
+	return r.credentialsVerdicts.Get(key), nil
+}
+
+// esCallsRetryResult returns the reconcile result requeuing the given association once it is allowed to call
+// Elasticsearch again, so that a probe skipped because of rate limiting is eventually performed.
+func (r *ReconcileAssociation) esCallsRetryResult(key types.NamespacedName) reconcile.Result {
+	delay := r.esCallsLimiter.Delay(key)
+	if delay == 0 {
+		return reconcile.Result{}
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: delay}
+}
+
+// probeClient returns an Elasticsearch client authenticated with the credentials of the given Kibana association.
+func (r *ReconcileAssociation) probeClient(kibana *kbv1.Kibana, es esv1.Elasticsearch) (esclient.Client, error) {
+	conf := kibana.AssociationConf()
+	var authSecret corev1.Secret
+	if err := r.Get(conf.AuthSecretRef(kibana.Namespace), &authSecret); err != nil {
+		return nil, err
+	}
+	var caCerts []*x509.Certificate
+	if conf.GetCACertProvided() {
+		var caSecret corev1.Secret
+		if err := r.Get(types.NamespacedName{Namespace: kibana.Namespace, Name: conf.GetCASecretName()}, &caSecret); err != nil {
+			return nil, err
+		}
+		certs, err := certificates.ParsePEMCerts(caSecret.Data[certificates.CAFileName])
+		if err != nil {
+			return nil, err
+		}
+		caCerts = certs
+	}
+	v, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		return nil, err
+	}
+	user := esclient.UserAuth{Name: conf.GetAuthSecretKey(), Password: string(authSecret.Data[conf.GetAuthSecretKey()])}
+	return r.newESClient(r.Dialer, services.ExternalServiceURL(es), user, *v, caCerts), nil
+}
@@ -0,0 +1,99 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/version"
+	esclient "github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/client"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/elasticsearch/services"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/net"
+)
+
+func TestReconcileAssociation_probeCredentials(t *testing.T) {
+	kb := kibanaFixture.DeepCopy()
+	// the URL Kibana is pointed to by the override annotation is never probed
+	kb.Annotations = map[string]string{annotation.ElasticsearchURLOverrideAnnotation: "https://override.example.com:9200"}
+	kb.SetAssociationConf(&commonv1.AssociationConf{AuthSecretName: userSecretName, AuthSecretKey: userName, URL: "https://override.example.com:9200"})
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: kb.Namespace, Name: userSecretName},
+		Data:       map[string][]byte{userName: []byte("password")},
+	}
+	es := esv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: kb.Namespace, Name: "es-foo"},
+		Spec:       esv1.ElasticsearchSpec{Version: "7.5.0"},
+	}
+
+	// statusCodes are returned by Elasticsearch, one per request
+	var statusCodes []int
+	var requests []*http.Request
+	r := &ReconcileAssociation{
+		Client:              k8s.WrappedFakeClient(credentials),
+		esCallsLimiter:      association.NewESCallsLimiter(time.Hour, 5),
+		probe:               association.ProbeConfig{Timeout: time.Second, Retries: 1},
+		credentialsVerdicts: association.NewProbeVerdicts(),
+		newESClient: func(_ net.Dialer, esURL string, esUser esclient.UserAuth, v version.Version, _ []*x509.Certificate) esclient.Client {
+			require.Equal(t, services.ExternalServiceURL(es), esURL)
+			return esclient.NewMockClientWithUser(v, esUser, func(req *http.Request) *http.Response {
+				requests = append(requests, req)
+				statusCode := statusCodes[0]
+				statusCodes = statusCodes[1:]
+				return esclient.NewMockResponse(statusCode, req, "{}")
+			})
+		},
+	}
+
+	// credentials accepted after a transient failure
+	statusCodes = []int{503, 200}
+	valid, err := r.probeCredentials(context.Background(), kb, es)
+	require.NoError(t, err)
+	require.True(t, valid)
+	require.Len(t, requests, 2)
+	require.Equal(t, authenticatePath, requests[1].URL.Path)
+	user, password, _ := requests[1].BasicAuth()
+	require.Equal(t, userName, user)
+	require.Equal(t, "password", password)
+
+	// credentials rejected, not retried
+	requests = nil
+	statusCodes = []int{401}
+	valid, err = r.probeCredentials(context.Background(), kb, es)
+	require.NoError(t, err)
+	require.False(t, valid)
+	require.Len(t, requests, 1)
+
+	// Elasticsearch unavailable, the previous verdict is kept
+	requests = nil
+	statusCodes = []int{503, 503}
+	valid, err = r.probeCredentials(context.Background(), kb, es)
+	require.NoError(t, err)
+	require.False(t, valid)
+	require.Len(t, requests, 2)
+
+	// rate limited, the previous verdict is kept without calling Elasticsearch
+	requests = nil
+	valid, err = r.probeCredentials(context.Background(), kb, es)
+	require.NoError(t, err)
+	require.False(t, valid)
+	require.Empty(t, requests)
+	// the association is requeued once it can call Elasticsearch again
+	result := r.esCallsRetryResult(k8s.ExtractNamespacedName(kb))
+	require.True(t, result.Requeue)
+	require.True(t, result.RequeueAfter > 0)
+}
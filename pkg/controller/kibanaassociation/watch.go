@@ -5,18 +5,27 @@
 package kibanaassociation
 
 import (
+	"reflect"
+
+	apmv1 "github.com/elastic/cloud-on-k8s/pkg/apis/apm/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/association"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 func addWatches(c controller.Controller, r *ReconcileAssociation) error {
 	// Watch for changes to Kibana resources
-	if err := c.Watch(&source.Kind{Type: &kbv1.Kibana{}}, &handler.EnqueueRequestForObject{}); err != nil {
+	if err := c.Watch(&source.Kind{Type: &kbv1.Kibana{}}, &handler.EnqueueRequestForObject{}, ignoreAssociationStatusUpdates); err != nil {
 		return err
 	}
 
@@ -30,6 +39,14 @@ func addWatches(c controller.Controller, r *ReconcileAssociation) error {
 		return err
 	}
 
+	// Dynamically watch the associations Kibana associations depend on, including their status updates
+	if err := c.Watch(&source.Kind{Type: &kbv1.Kibana{}}, r.watches.Kibanas); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &apmv1.ApmServer{}}, r.watches.ApmServers); err != nil {
+		return err
+	}
+
 	// Watch Secrets owned by a Kibana resource
 	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
 		OwnerType:    &kbv1.Kibana{},
@@ -38,9 +55,80 @@ func addWatches(c controller.Controller, r *ReconcileAssociation) error {
 		return err
 	}
 
+	// Watch the Elasticsearch aliases ConfigMap to re-resolve the aliased Elasticsearch references. Its events are only
+	// received if the operator namespace is cached, aliased associations are also requeued periodically.
+	if err := c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(object handler.MapObject) []reconcile.Request {
+			if object.Meta.GetNamespace() != r.OperatorNamespace || object.Meta.GetName() != association.AliasesConfigMapName {
+				return nil
+			}
+			requests, err := aliasedKibanaRequests(r)
+			if err != nil {
+				// dropping the event at this point
+				log.Error(err, "failed to list aliased Kibana resources in aliases watch")
+				return nil
+			}
+			return requests
+		}),
+	}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// ignoreAssociationStatusUpdates filters out the updates of Kibana resources which only change the association
+// status, written at the end of each reconciliation: reconciling them again would not do anything. Other changes of the
+// status, such as the health of Kibana the association health depends on, are still reconciled.
+var ignoreAssociationStatusUpdates = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldKibana, ok := e.ObjectOld.(*kbv1.Kibana)
+		if !ok {
+			return true
+		}
+		newKibana, ok := e.ObjectNew.(*kbv1.Kibana)
+		if !ok {
+			return true
+		}
+		return !onlyAssociationStatusChanged(*oldKibana, *newKibana)
+	},
+}
+
+// onlyAssociationStatusChanged returns true if the given versions of a Kibana resource only differ by their association
+// status.
+func onlyAssociationStatusChanged(oldKibana, newKibana kbv1.Kibana) bool {
+	oldMeta, newMeta := oldKibana.ObjectMeta.DeepCopy(), newKibana.ObjectMeta.DeepCopy()
+	for _, m := range []*metav1.ObjectMeta{oldMeta, newMeta} {
+		// bumped on every update
+		m.ResourceVersion = ""
+		m.ManagedFields = nil
+	}
+	if !reflect.DeepEqual(oldMeta, newMeta) || !reflect.DeepEqual(oldKibana.Spec, newKibana.Spec) {
+		return false
+	}
+	oldStatus, newStatus := oldKibana.Status.DeepCopy(), newKibana.Status.DeepCopy()
+	// reset the association fields
+	associationStatus{}.applyTo(oldStatus)
+	associationStatus{}.applyTo(newStatus)
+	return reflect.DeepEqual(oldStatus, newStatus)
+}
+
+// aliasedKibanaRequests returns reconcile requests for all Kibana resources referencing Elasticsearch through an alias.
+func aliasedKibanaRequests(c k8s.Client) ([]reconcile.Request, error) {
+	var kibanas kbv1.KibanaList
+	if err := c.List(&kibanas); err != nil {
+		return nil, err
+	}
+	var requests []reconcile.Request
+	for i := range kibanas.Items {
+		if association.ElasticsearchAlias(&kibanas.Items[i]) == "" {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(&kibanas.Items[i])})
+	}
+	return requests, nil
+}
+
 // elasticsearchWatchName returns the name of the watch setup on an Elasticsearch cluster
 // for a given Kibana resource.
 func elasticsearchWatchName(kibanaKey types.NamespacedName) string {
@@ -51,3 +139,8 @@ func elasticsearchWatchName(kibanaKey types.NamespacedName) string {
 func esCAWatchName(kibana types.NamespacedName) string {
 	return kibana.Namespace + "-" + kibana.Name + "-ca-watch"
 }
+
+// dependenciesWatchName returns the name of the watch setup on the associations a Kibana association depends on
+func dependenciesWatchName(kibana types.NamespacedName) string {
+	return kibana.Namespace + "-" + kibana.Name + "-dependencies-watch"
+}
@@ -0,0 +1,130 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package kibanaassociation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	commonv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/utils/k8s"
+)
+
+// enqueuedOnSecretUpdate returns the requests enqueued by the dynamic watches of r when the given secret is updated.
+func enqueuedOnSecretUpdate(r *ReconcileAssociation, key types.NamespacedName) []reconcile.Request {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+	r.watches.Secrets.Update(event.UpdateEvent{
+		MetaOld:   secret.GetObjectMeta(),
+		ObjectOld: secret,
+		MetaNew:   secret.GetObjectMeta(),
+		ObjectNew: secret,
+	}, q)
+	var requests []reconcile.Request
+	for q.Len() > 0 {
+		item, _ := q.Get()
+		requests = append(requests, item.(reconcile.Request))
+		q.Done(item)
+	}
+	return requests
+}
+
+func TestReconcileAssociation_secretWatches(t *testing.T) {
+	es := esFixture.DeepCopy()
+	es.Spec.Version = "7.5.0"
+	esCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es-foo-es-http-certs-public"},
+		Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert")},
+	}
+	kb := kibanaFixture.DeepCopy()
+	c := k8s.WrappedFakeClient(kb, es, esCerts)
+	r := newTestReconciler(t, c)
+	status, err := r.reconcileInternal(context.Background(), kb)
+	require.NoError(t, err)
+	require.Equal(t, commonv1.AssociationEstablished, status)
+
+	// rotating the credentials of the Elasticsearch user reconciles the association
+	userSecretKey := types.NamespacedName{Namespace: "default", Name: userName}
+	kibanaRequest := reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(kb)}
+	require.Equal(t, []reconcile.Request{kibanaRequest}, enqueuedOnSecretUpdate(r, userSecretKey))
+	// so does rotating the Elasticsearch CA
+	caSecretKey := k8s.ExtractNamespacedName(esCerts)
+	require.Equal(t, []reconcile.Request{kibanaRequest}, enqueuedOnSecretUpdate(r, caSecretKey))
+
+	// the watches are removed along with the Elasticsearch reference
+	kb.Spec.ElasticsearchRef = commonv1.ObjectSelector{}
+	_, err = r.reconcileInternal(context.Background(), kb)
+	require.NoError(t, err)
+	require.Empty(t, enqueuedOnSecretUpdate(r, userSecretKey))
+	require.Empty(t, enqueuedOnSecretUpdate(r, caSecretKey))
+}
+
+func Test_ignoreAssociationStatusUpdates(t *testing.T) {
+	kibana := kbv1.Kibana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "kb", Generation: 1, ResourceVersion: "1"},
+		Spec:       kbv1.KibanaSpec{Version: "7.5.0", ElasticsearchRef: commonv1.ObjectSelector{Name: "es"}},
+		Status:     kbv1.KibanaStatus{Health: kbv1.KibanaRed, AssociationStatus: commonv1.AssociationPending},
+	}
+	tests := []struct {
+		name   string
+		update func(kb *kbv1.Kibana)
+		want   bool
+	}{
+		{
+			name: "association status update",
+			update: func(kb *kbv1.Kibana) {
+				kb.Status.AssociationStatus = commonv1.AssociationEstablished
+				kb.Status.AssociationMessage = "established"
+				kb.Status.AssociationConditions = []commonv1.AssociationCondition{{Message: "established"}}
+			},
+			want: false,
+		},
+		{
+			name: "Kibana health update",
+			update: func(kb *kbv1.Kibana) {
+				kb.Status.Health = kbv1.KibanaGreen
+			},
+			want: true,
+		},
+		{
+			name: "spec update",
+			update: func(kb *kbv1.Kibana) {
+				kb.Generation = 2
+				kb.Spec.Count = 2
+			},
+			want: true,
+		},
+		{
+			name: "annotations update",
+			update: func(kb *kbv1.Kibana) {
+				kb.Annotations = map[string]string{"foo": "bar"}
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := kibana.DeepCopy()
+			updated.ResourceVersion = "2"
+			tt.update(updated)
+			require.Equal(t, tt.want, ignoreAssociationStatusUpdates.Update(event.UpdateEvent{
+				MetaOld:   kibana.GetObjectMeta(),
+				ObjectOld: &kibana,
+				MetaNew:   updated.GetObjectMeta(),
+				ObjectNew: updated,
+			}))
+		})
+	}
+}
@@ -108,6 +108,13 @@ func newSubjectAccessReview(
 	}
 }
 
+// IsPermissive returns true if the given access reviewer allows all accesses, which is the case when RBAC is not
+// enforced on references.
+func IsPermissive(accessReviewer AccessReviewer) bool {
+	_, permissive := accessReviewer.(*permissiveAccessReviewer)
+	return permissive
+}
+
 type permissiveAccessReviewer struct{}
 
 var _ AccessReviewer = &permissiveAccessReviewer{}
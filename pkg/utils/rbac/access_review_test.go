@@ -271,3 +271,12 @@ func Test_newSubjectAccessReview(t *testing.T) {
 		})
 	}
 }
+
+func TestIsPermissive(t *testing.T) {
+	if !IsPermissive(NewPermissiveAccessReviewer()) {
+		t.Error("IsPermissive() = false for the permissive access reviewer")
+	}
+	if IsPermissive(NewSubjectAccessReviewer(fake.NewSimpleClientset())) {
+		t.Error("IsPermissive() = true for the subject access reviewer")
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package rbac
+
+import (
+	authorizationapi "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// secretsVerbs are the verbs required to manage Secrets.
+var secretsVerbs = []string{"get", "list", "watch", "create", "update", "delete"}
+
+// CanManageSecrets returns true if the operator is allowed to manage Secrets in the given namespace.
+// It relies on self subject access reviews, the identity of the operator being derived from the client credentials.
+func CanManageSecrets(client kubernetes.Interface, namespace string) (bool, error) {
+	for _, verb := range secretsVerbs {
+		review := &authorizationapi.SelfSubjectAccessReview{
+			Spec: authorizationapi.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationapi.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      verb,
+					Resource:  "secrets",
+				},
+			},
+		}
+		review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(review)
+		if err != nil {
+			return false, err
+		}
+		if !review.Status.Allowed {
+			log.V(1).Info("Operator is not allowed to manage secrets", "namespace", namespace, "verb", verb)
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,53 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authorizationapi "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCanManageSecrets(t *testing.T) {
+	tests := []struct {
+		name        string
+		deniedVerbs map[string]bool
+		want        bool
+	}{
+		{
+			name: "all verbs allowed",
+			want: true,
+		},
+		{
+			name:        "delete is denied",
+			deniedVerbs: map[string]bool{"delete": true},
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			fakeClient.PrependReactor(
+				"create",
+				"selfsubjectaccessreviews",
+				func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+					object := action.(k8stesting.CreateAction).GetObject().DeepCopyObject()
+					if review, ok := object.(*authorizationapi.SelfSubjectAccessReview); ok {
+						require.Equal(t, "secrets-ns", review.Spec.ResourceAttributes.Namespace)
+						review.Status.Allowed = !tt.deniedVerbs[review.Spec.ResourceAttributes.Verb]
+					}
+					return true, object, nil
+				},
+			)
+			got, err := CanManageSecrets(fakeClient, "secrets-ns")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}